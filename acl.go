@@ -0,0 +1,116 @@
+package osutils
+
+import (
+	"os"
+)
+
+// AccessMask is a set of Windows file access rights.
+type AccessMask uint32
+
+const (
+	// AccessRead is FILE_GENERIC_READ.
+	AccessRead AccessMask = 0x120089
+	// AccessWrite is FILE_GENERIC_WRITE.
+	AccessWrite AccessMask = 0x120116
+	// AccessExecute is FILE_GENERIC_EXECUTE.
+	AccessExecute AccessMask = 0x1200a0
+	// AccessFull is FILE_ALL_ACCESS.
+	AccessFull AccessMask = 0x1f01ff
+)
+
+// SecurityDescriptor is the owner, group and DACL of a file on Windows.
+// SIDs are in their string form such as S-1-5-32-545.
+type SecurityDescriptor struct {
+	Owner string
+	Group string
+	// Protected is whether the DACL does not inherit from the parent.
+	Protected bool
+	Entries   []*AccessEntry
+}
+
+// AccessEntry is an entry of a DACL.
+type AccessEntry struct {
+	SID string
+	// Deny is set for a deny entry, otherwise the entry allows.
+	Deny      bool
+	Mask      AccessMask
+	Inherited bool
+}
+
+// GetSecurityDescriptor returns the security descriptor of the file or dir
+// at absolutePath. This is only supported on Windows.
+func GetSecurityDescriptor(absolutePath string) (*SecurityDescriptor, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	return getSecurityDescriptor(absolutePath)
+}
+
+// SetOwner makes the account with the given SID the owner of the file or
+// dir at absolutePath. Giving away ownership needs the restore privilege.
+// This is only supported on Windows.
+func SetOwner(absolutePath string, sid string) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	return setOwner(absolutePath, sid)
+}
+
+// GrantAccess adds mask to the rights of the account with the given SID on
+// the file or dir at absolutePath, inherited by the contents of a dir.
+// This is only supported on Windows.
+func GrantAccess(absolutePath string, sid string, mask AccessMask) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	return grantAccess(absolutePath, sid, mask)
+}
+
+// LookupSID returns the SID of an account name such as BUILTIN\Users.
+// This is only supported on Windows.
+func LookupSID(account string) (string, error) {
+	return lookupSID(account)
+}
+
+// EnsureOwnerSID is EnsureOwnership for Windows, where owners are SIDs
+// rather than uids and gids, recorded in OwnershipChange.OldOwner and
+// NewOwner.
+func EnsureOwnerSID(absolutePath string, sid string, recursive bool) ([]*OwnershipChange, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	if recursive {
+		if err := checkGuard(absolutePath); err != nil {
+			return nil, err
+		}
+	}
+	var changes []*OwnershipChange
+	if err := walkEnsure(
+		absolutePath,
+		recursive,
+		func(path string, info os.FileInfo) error {
+			securityDescriptor, err := getSecurityDescriptor(path)
+			if err != nil {
+				return err
+			}
+			if securityDescriptor.Owner == sid {
+				return nil
+			}
+			if err := setOwner(path, sid); err != nil {
+				return err
+			}
+			changes = append(
+				changes,
+				&OwnershipChange{
+					Path:     path,
+					OldOwner: securityDescriptor.Owner,
+					NewOwner: sid,
+				},
+			)
+			return nil
+		},
+	); err != nil {
+		return changes, err
+	}
+	return changes, nil
+}
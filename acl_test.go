@@ -0,0 +1,42 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestSecurityDescriptor() {
+	path := filepath.Join(s.tempDir, "file")
+	require.NoError(s.T(), ioutil.WriteFile(path, nil, 0644))
+	if runtime.GOOS != "windows" {
+		_, err := GetSecurityDescriptor(path)
+		require.Equal(s.T(), ErrNotSupported, err)
+		_, err = EnsureOwnerSID(path, "S-1-5-18", false)
+		require.Equal(s.T(), ErrNotSupported, err)
+		return
+	}
+	usersSID, err := LookupSID(`BUILTIN\Users`)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "S-1-5-32-545", usersSID)
+	require.NoError(s.T(), GrantAccess(path, usersSID, AccessRead))
+	securityDescriptor, err := GetSecurityDescriptor(path)
+	require.NoError(s.T(), err)
+	require.NotEmpty(s.T(), securityDescriptor.Owner)
+	found := false
+	for _, entry := range securityDescriptor.Entries {
+		if entry.SID == usersSID && !entry.Deny && entry.Mask&AccessRead == AccessRead {
+			found = true
+		}
+	}
+	require.True(s.T(), found)
+
+	changes, err := EnsureMode(path, 0600, false)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), changes, 1)
+	changes, err = EnsureMode(path, 0600, false)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), changes)
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+
+package osutils
+
+import (
+	"os"
+)
+
+func getSecurityDescriptor(absolutePath string) (*SecurityDescriptor, error) {
+	return nil, ErrNotSupported
+}
+
+func setOwner(absolutePath string, sid string) error {
+	return ErrNotSupported
+}
+
+func grantAccess(absolutePath string, sid string, mask AccessMask) error {
+	return ErrNotSupported
+}
+
+func lookupSID(account string) (string, error) {
+	return "", ErrNotSupported
+}
+
+// fileMode returns the permission bits EnsureMode compares against.
+func fileMode(absolutePath string, info os.FileInfo) (os.FileMode, error) {
+	return info.Mode().Perm(), nil
+}
+
+func setFileMode(absolutePath string, perm os.FileMode, isDir bool) error {
+	return os.Chmod(absolutePath, perm.Perm())
+}
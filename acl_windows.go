@@ -0,0 +1,246 @@
+package osutils
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+func getSecurityDescriptor(absolutePath string) (*SecurityDescriptor, error) {
+	sd, err := windows.GetNamedSecurityInfo(
+		absolutePath,
+		windows.SE_FILE_OBJECT,
+		windows.OWNER_SECURITY_INFORMATION|windows.GROUP_SECURITY_INFORMATION|windows.DACL_SECURITY_INFORMATION,
+	)
+	if err != nil {
+		return nil, err
+	}
+	securityDescriptor := &SecurityDescriptor{}
+	if owner, _, err := sd.Owner(); err == nil && owner != nil {
+		securityDescriptor.Owner = owner.String()
+	}
+	if group, _, err := sd.Group(); err == nil && group != nil {
+		securityDescriptor.Group = group.String()
+	}
+	control, _, err := sd.Control()
+	if err != nil {
+		return nil, err
+	}
+	securityDescriptor.Protected = control&windows.SE_DACL_PROTECTED != 0
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return nil, err
+	}
+	// a nil DACL allows everyone everything and has no entries
+	if dacl == nil {
+		return securityDescriptor, nil
+	}
+	for i := 0; i < int(dacl.AceCount); i++ {
+		var ace *windows.ACCESS_ALLOWED_ACE
+		if err := windows.GetAce(dacl, uint32(i), &ace); err != nil {
+			return nil, err
+		}
+		// other types such as audit and object entries are not listed
+		if ace.Header.AceType != windows.ACCESS_ALLOWED_ACE_TYPE && ace.Header.AceType != windows.ACCESS_DENIED_ACE_TYPE {
+			continue
+		}
+		sid := (*windows.SID)(unsafe.Pointer(&ace.SidStart))
+		securityDescriptor.Entries = append(
+			securityDescriptor.Entries,
+			&AccessEntry{
+				SID:       sid.String(),
+				Deny:      ace.Header.AceType == windows.ACCESS_DENIED_ACE_TYPE,
+				Mask:      AccessMask(ace.Mask),
+				Inherited: ace.Header.AceFlags&windows.INHERITED_ACE != 0,
+			},
+		)
+	}
+	return securityDescriptor, nil
+}
+
+func setOwner(absolutePath string, sid string) error {
+	ownerSID, err := windows.StringToSid(sid)
+	if err != nil {
+		return err
+	}
+	return windows.SetNamedSecurityInfo(
+		absolutePath,
+		windows.SE_FILE_OBJECT,
+		windows.OWNER_SECURITY_INFORMATION,
+		ownerSID,
+		nil,
+		nil,
+		nil,
+	)
+}
+
+func grantAccess(absolutePath string, sid string, mask AccessMask) error {
+	trusteeSID, err := windows.StringToSid(sid)
+	if err != nil {
+		return err
+	}
+	fileInfo, err := os.Stat(absolutePath)
+	if err != nil {
+		return err
+	}
+	sd, err := windows.GetNamedSecurityInfo(absolutePath, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return err
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return err
+	}
+	acl, err := windows.ACLFromEntries(
+		[]windows.EXPLICIT_ACCESS{
+			explicitAccess(trusteeSID, windows.TRUSTEE_IS_UNKNOWN, uint32(mask), aclInheritance(fileInfo.IsDir())),
+		},
+		dacl,
+	)
+	if err != nil {
+		return err
+	}
+	return windows.SetNamedSecurityInfo(absolutePath, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION, nil, nil, acl, nil)
+}
+
+func lookupSID(account string) (string, error) {
+	sid, _, _, err := windows.LookupSID("", account)
+	if err != nil {
+		return "", err
+	}
+	return sid.String(), nil
+}
+
+// fileMode derives permission bits from the allow entries of the DACL: the
+// owner bits from the owner, the group bits from Users and the other bits
+// from Everyone.
+func fileMode(absolutePath string, info os.FileInfo) (os.FileMode, error) {
+	securityDescriptor, err := getSecurityDescriptor(absolutePath)
+	if err != nil {
+		return 0, err
+	}
+	usersSID, worldSID, err := modeSIDs()
+	if err != nil {
+		return 0, err
+	}
+	var perm os.FileMode
+	for _, entry := range securityDescriptor.Entries {
+		if entry.Deny {
+			continue
+		}
+		switch entry.SID {
+		case securityDescriptor.Owner:
+			perm |= permFromMask(entry.Mask) << 6
+		case usersSID.String():
+			perm |= permFromMask(entry.Mask) << 3
+		case worldSID.String():
+			perm |= permFromMask(entry.Mask)
+		}
+	}
+	return perm, nil
+}
+
+// setFileMode replaces the DACL with one granting the owner, Users and
+// Everyone the rights of the owner, group and other bits of perm. SYSTEM
+// and Administrators keep full access.
+func setFileMode(absolutePath string, perm os.FileMode, isDir bool) error {
+	securityDescriptor, err := getSecurityDescriptor(absolutePath)
+	if err != nil {
+		return err
+	}
+	ownerSID, err := windows.StringToSid(securityDescriptor.Owner)
+	if err != nil {
+		return err
+	}
+	usersSID, worldSID, err := modeSIDs()
+	if err != nil {
+		return err
+	}
+	inheritance := aclInheritance(isDir)
+	var entries []windows.EXPLICIT_ACCESS
+	for _, wellKnownSid := range []windows.WELL_KNOWN_SID_TYPE{
+		windows.WinLocalSystemSid,
+		windows.WinBuiltinAdministratorsSid,
+	} {
+		sid, err := windows.CreateWellKnownSid(wellKnownSid)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, explicitAccess(sid, windows.TRUSTEE_IS_WELL_KNOWN_GROUP, uint32(AccessFull), inheritance))
+	}
+	for _, grant := range []struct {
+		sid         *windows.SID
+		trusteeType windows.TRUSTEE_TYPE
+		bits        os.FileMode
+	}{
+		{ownerSID, windows.TRUSTEE_IS_USER, (perm >> 6) & 7},
+		{usersSID, windows.TRUSTEE_IS_WELL_KNOWN_GROUP, (perm >> 3) & 7},
+		{worldSID, windows.TRUSTEE_IS_WELL_KNOWN_GROUP, perm & 7},
+	} {
+		if mask := maskFromPerm(grant.bits); mask != 0 {
+			entries = append(entries, explicitAccess(grant.sid, grant.trusteeType, uint32(mask), inheritance))
+		}
+	}
+	acl, err := windows.ACLFromEntries(entries, nil)
+	if err != nil {
+		return err
+	}
+	return windows.SetNamedSecurityInfo(
+		absolutePath,
+		windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION,
+		nil,
+		nil,
+		acl,
+		nil,
+	)
+}
+
+func modeSIDs() (*windows.SID, *windows.SID, error) {
+	usersSID, err := windows.CreateWellKnownSid(windows.WinBuiltinUsersSid)
+	if err != nil {
+		return nil, nil, err
+	}
+	worldSID, err := windows.CreateWellKnownSid(windows.WinWorldSid)
+	if err != nil {
+		return nil, nil, err
+	}
+	return usersSID, worldSID, nil
+}
+
+// permFromMask returns the rwx bits for mask, counting generic rights.
+func permFromMask(mask AccessMask) os.FileMode {
+	var perm os.FileMode
+	if mask&(windows.FILE_READ_DATA|windows.GENERIC_READ|windows.GENERIC_ALL) != 0 {
+		perm |= 4
+	}
+	if mask&(windows.FILE_WRITE_DATA|windows.GENERIC_WRITE|windows.GENERIC_ALL) != 0 {
+		perm |= 2
+	}
+	if mask&(windows.FILE_EXECUTE|windows.GENERIC_EXECUTE|windows.GENERIC_ALL) != 0 {
+		perm |= 1
+	}
+	return perm
+}
+
+func maskFromPerm(perm os.FileMode) AccessMask {
+	var mask AccessMask
+	if perm&4 != 0 {
+		mask |= AccessRead
+	}
+	if perm&2 != 0 {
+		mask |= AccessWrite
+	}
+	if perm&1 != 0 {
+		mask |= AccessExecute
+	}
+	return mask
+}
+
+func aclInheritance(isDir bool) uint32 {
+	if isDir {
+		return windows.SUB_CONTAINERS_AND_OBJECTS_INHERIT
+	}
+	return windows.NO_INHERITANCE
+}
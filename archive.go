@@ -0,0 +1,297 @@
+package osutils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const sourceDateEpochEnvKey = "SOURCE_DATE_EPOCH"
+
+var (
+	ErrArchiveInsideSrc = errors.New("osutils: archive inside src")
+	// defaultArchiveModTime is the earliest time a zip can hold.
+	defaultArchiveModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type ArchiveOptions struct {
+	// Deterministic makes the archive depend only on the names, types and
+	// contents of the files, so the same tree gives a byte-for-byte
+	// identical archive on any machine: entries are in sorted order, times
+	// are ModTime, owners are root with no names, and modes are 0755 for
+	// dirs and files executable by anyone and 0644 for other files.
+	Deterministic bool
+	// ModTime is the time of every entry if Deterministic. If zero,
+	// $SOURCE_DATE_EPOCH is used if set, and otherwise 1980-01-01 UTC.
+	ModTime time.Time
+	// Ignore excludes matching paths from the archive.
+	Ignore *Ignore
+}
+
+// TarDir writes the tree at src as a tar archive to dst, gzip-compressed if
+// dst ends in .gz or .tgz. Entry names are relative to src with slashes,
+// symlinks are stored as symlinks and other file types are skipped. dst is
+// written atomically and must not be under src, see ErrArchiveInsideSrc.
+func TarDir(src string, dst string, options *ArchiveOptions) error {
+	if !isAbsolutePath(src) || !isAbsolutePath(dst) {
+		return ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &ArchiveOptions{}
+	}
+	return archiveDir(src, dst, options, newTarArchiveWriter)
+}
+
+// ZipDir is TarDir writing a zip archive, with files deflated.
+func ZipDir(src string, dst string, options *ArchiveOptions) error {
+	if !isAbsolutePath(src) || !isAbsolutePath(dst) {
+		return ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &ArchiveOptions{}
+	}
+	return archiveDir(src, dst, options, newZipArchiveWriter)
+}
+
+// ***** PRIVATE *****
+
+// archiveEntryHeader is what the tar and zip writers need of an entry.
+type archiveEntryHeader struct {
+	name       string
+	mode       os.FileMode
+	modTime    time.Time
+	size       int64
+	linkTarget string
+	uid        int
+	gid        int
+}
+
+type archiveWriter interface {
+	writeEntry(archiveEntryHeader *archiveEntryHeader, content io.Reader) error
+	Close() error
+}
+
+func archiveDir(
+	src string,
+	dst string,
+	options *ArchiveOptions,
+	newArchiveWriter func(io.Writer, string, *ArchiveOptions) (archiveWriter, error),
+) error {
+	src = filepath.Clean(src)
+	dst = filepath.Clean(dst)
+	exists, err := isDirExists(src)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrFileDoesNotExist
+	}
+	if isWithin(src, dst) {
+		return ErrArchiveInsideSrc
+	}
+	modTime, err := archiveModTime(options)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(
+		dst,
+		0644,
+		func(writer io.Writer) error {
+			archiveWriter, err := newArchiveWriter(writer, dst, options)
+			if err != nil {
+				return err
+			}
+			if err := filepath.Walk(
+				src,
+				func(path string, info os.FileInfo, err error) error {
+					if err != nil {
+						return err
+					}
+					if path == src {
+						return nil
+					}
+					if options.Ignore != nil {
+						ignored, err := options.Ignore.Match(path, info.IsDir())
+						if err != nil {
+							return err
+						}
+						if ignored {
+							return skipIgnored(info.IsDir())
+						}
+					}
+					return archivePath(archiveWriter, src, path, info, options.Deterministic, modTime)
+				},
+			); err != nil {
+				_ = archiveWriter.Close()
+				return err
+			}
+			return archiveWriter.Close()
+		},
+	)
+}
+
+// archivePath writes one entry. filepath.Walk visits the entries of each
+// dir in sorted order, which is what makes the order deterministic.
+func archivePath(archiveWriter archiveWriter, src string, path string, info os.FileInfo, deterministic bool, modTime time.Time) error {
+	relativePath, err := filepath.Rel(src, path)
+	if err != nil {
+		return err
+	}
+	archiveEntryHeader := &archiveEntryHeader{
+		name:    filepath.ToSlash(relativePath),
+		mode:    info.Mode(),
+		modTime: info.ModTime(),
+	}
+	if uid, gid, ok := fileOwner(info); ok && !deterministic {
+		archiveEntryHeader.uid, archiveEntryHeader.gid = uid, gid
+	}
+	if deterministic {
+		archiveEntryHeader.modTime = modTime
+		archiveEntryHeader.mode = normalizeArchiveMode(info.Mode())
+	}
+	switch {
+	case info.IsDir():
+		archiveEntryHeader.name += "/"
+		return archiveWriter.writeEntry(archiveEntryHeader, nil)
+	case info.Mode()&os.ModeSymlink != 0:
+		linkTarget, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		archiveEntryHeader.linkTarget = filepath.ToSlash(linkTarget)
+		return archiveWriter.writeEntry(archiveEntryHeader, nil)
+	case info.Mode().IsRegular():
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		archiveEntryHeader.size = info.Size()
+		return archiveWriter.writeEntry(archiveEntryHeader, file)
+	default:
+		return nil
+	}
+}
+
+func normalizeArchiveMode(mode os.FileMode) os.FileMode {
+	switch {
+	case mode.IsDir():
+		return os.ModeDir | 0755
+	case mode&os.ModeSymlink != 0:
+		return os.ModeSymlink | 0777
+	case mode.Perm()&0111 != 0:
+		return 0755
+	default:
+		return 0644
+	}
+}
+
+func archiveModTime(options *ArchiveOptions) (time.Time, error) {
+	if !options.ModTime.IsZero() {
+		return options.ModTime.UTC(), nil
+	}
+	if value := os.Getenv(sourceDateEpochEnvKey); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	return defaultArchiveModTime, nil
+}
+
+type tarArchiveWriter struct {
+	tarWriter  *tar.Writer
+	gzipWriter *gzip.Writer
+}
+
+func newTarArchiveWriter(writer io.Writer, dst string, options *ArchiveOptions) (archiveWriter, error) {
+	tarArchiveWriter := &tarArchiveWriter{}
+	if strings.HasSuffix(dst, ".gz") || strings.HasSuffix(dst, ".tgz") {
+		// the gzip header has no name and a zero time unless set
+		tarArchiveWriter.gzipWriter = gzip.NewWriter(writer)
+		writer = tarArchiveWriter.gzipWriter
+	}
+	tarArchiveWriter.tarWriter = tar.NewWriter(writer)
+	return tarArchiveWriter, nil
+}
+
+func (t *tarArchiveWriter) writeEntry(archiveEntryHeader *archiveEntryHeader, content io.Reader) error {
+	header := &tar.Header{
+		Name:    archiveEntryHeader.name,
+		Mode:    int64(archiveEntryHeader.mode.Perm()),
+		ModTime: archiveEntryHeader.modTime,
+		Uid:     archiveEntryHeader.uid,
+		Gid:     archiveEntryHeader.gid,
+	}
+	switch {
+	case archiveEntryHeader.mode.IsDir():
+		header.Typeflag = tar.TypeDir
+	case archiveEntryHeader.mode&os.ModeSymlink != 0:
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = archiveEntryHeader.linkTarget
+	default:
+		header.Typeflag = tar.TypeReg
+		header.Size = archiveEntryHeader.size
+	}
+	if err := t.tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	if content == nil {
+		return nil
+	}
+	_, err := io.CopyN(t.tarWriter, content, archiveEntryHeader.size)
+	return err
+}
+
+func (t *tarArchiveWriter) Close() error {
+	err := t.tarWriter.Close()
+	if t.gzipWriter != nil {
+		if closeErr := t.gzipWriter.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+type zipArchiveWriter struct {
+	zipWriter *zip.Writer
+}
+
+func newZipArchiveWriter(writer io.Writer, dst string, options *ArchiveOptions) (archiveWriter, error) {
+	return &zipArchiveWriter{zipWriter: zip.NewWriter(writer)}, nil
+}
+
+func (z *zipArchiveWriter) writeEntry(archiveEntryHeader *archiveEntryHeader, content io.Reader) error {
+	fileHeader := &zip.FileHeader{
+		Name:     archiveEntryHeader.name,
+		Modified: archiveEntryHeader.modTime,
+		Method:   zip.Deflate,
+	}
+	fileHeader.SetMode(archiveEntryHeader.mode)
+	if archiveEntryHeader.mode.IsDir() {
+		fileHeader.Method = zip.Store
+	}
+	writer, err := z.zipWriter.CreateHeader(fileHeader)
+	if err != nil {
+		return err
+	}
+	switch {
+	case archiveEntryHeader.mode&os.ModeSymlink != 0:
+		_, err = io.WriteString(writer, archiveEntryHeader.linkTarget)
+	case content != nil:
+		_, err = io.CopyN(writer, content, archiveEntryHeader.size)
+	}
+	return err
+}
+
+func (z *zipArchiveWriter) Close() error {
+	return z.zipWriter.Close()
+}
@@ -0,0 +1,86 @@
+package osutils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestTarDir() {
+	s.writeCopyDirSrc()
+	src := filepath.Join(s.tempDir, "src")
+	dst := filepath.Join(s.tempDir, "src.tar.gz")
+	require.NoError(s.T(), TarDir(src, dst, nil))
+	file, err := os.Open(dst)
+	require.NoError(s.T(), err)
+	defer s.checkClose(file)
+	gzipReader, err := gzip.NewReader(file)
+	require.NoError(s.T(), err)
+	tarReader := tar.NewReader(gzipReader)
+	contents := make(map[string]string)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(s.T(), err)
+		data, err := ioutil.ReadAll(tarReader)
+		require.NoError(s.T(), err)
+		contents[header.Name] = string(data) + header.Linkname
+	}
+	expected := map[string]string{"a/": "", "a/1": "1", "b/": "", "b/2": "2"}
+	if runtime.GOOS != "windows" {
+		expected["link"] = "a/1"
+	}
+	require.Equal(s.T(), expected, contents)
+
+	require.Equal(s.T(), ErrArchiveInsideSrc, TarDir(src, filepath.Join(src, "self.tar"), nil))
+	require.Equal(s.T(), ErrFileDoesNotExist, ZipDir(filepath.Join(s.tempDir, "missing"), dst, nil))
+}
+
+func (s *Suite) TestArchiveDirDeterministic() {
+	s.writeCopyDirSrc()
+	src := filepath.Join(s.tempDir, "src")
+	other := filepath.Join(s.tempDir, "other")
+	require.NoError(s.T(), CopyDir(src, other, nil))
+	// differences that must not show in the archives
+	past := time.Now().Add(-time.Hour)
+	require.NoError(s.T(), os.Chtimes(filepath.Join(other, "a", "1"), past, past))
+	require.NoError(s.T(), os.Chmod(filepath.Join(other, "b", "2"), 0600))
+
+	for _, archiveDir := range []func(string, string, *ArchiveOptions) error{TarDir, ZipDir} {
+		var archives [][]byte
+		for i, dir := range []string{src, other} {
+			dst := filepath.Join(s.tempDir, "archive"+string(rune('0'+i)))
+			require.NoError(s.T(), archiveDir(dir, dst, &ArchiveOptions{Deterministic: true}))
+			data, err := ioutil.ReadFile(dst)
+			require.NoError(s.T(), err)
+			archives = append(archives, data)
+		}
+		require.True(s.T(), bytes.Equal(archives[0], archives[1]))
+	}
+
+	dst := filepath.Join(s.tempDir, "epoch.zip")
+	require.NoError(s.T(), os.Setenv("SOURCE_DATE_EPOCH", "1500000000"))
+	defer os.Unsetenv("SOURCE_DATE_EPOCH")
+	require.NoError(s.T(), ZipDir(src, dst, &ArchiveOptions{Deterministic: true}))
+	zipReader, err := zip.OpenReader(dst)
+	require.NoError(s.T(), err)
+	defer s.checkClose(zipReader)
+	require.NotEmpty(s.T(), zipReader.File)
+	for _, zipFile := range zipReader.File {
+		require.True(s.T(), zipFile.Modified.Equal(time.Unix(1500000000, 0)), zipFile.Name)
+		if zipFile.Name == "a/1" {
+			require.Equal(s.T(), os.FileMode(0644), zipFile.Mode())
+		}
+	}
+}
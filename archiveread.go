@@ -0,0 +1,258 @@
+package osutils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+var (
+	ErrEntryNotFound = errors.New("osutils: entry not found")
+	// errArchiveStop stops forEachArchiveEntry without error.
+	errArchiveStop = errors.New("osutils: archive stop")
+)
+
+type ArchiveEntry struct {
+	// Name is the path in the archive with slashes, dirs ending with one.
+	Name       string
+	Size       int64
+	Mode       os.FileMode
+	ModTime    time.Time
+	LinkTarget string
+}
+
+// ListArchive returns the entries of the zip or tar archive at
+// absolutePath, in archive order. The format is detected from the content,
+// tar archives may be compressed with gzip, zstd or xz.
+func ListArchive(absolutePath string) ([]*ArchiveEntry, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	var archiveEntries []*ArchiveEntry
+	if err := forEachArchiveEntry(
+		absolutePath,
+		func(archiveEntry *ArchiveEntry, open func() (io.Reader, error)) error {
+			archiveEntries = append(archiveEntries, archiveEntry)
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	return archiveEntries, nil
+}
+
+// ExtractEntry writes the regular file entryPath of the archive at
+// absoluteArchivePath to dst, atomically and with the mode of the entry,
+// without extracting anything else. A tar archive is read up to the entry,
+// a zip archive seeks to it directly.
+func ExtractEntry(absoluteArchivePath string, entryPath string, dst string) error {
+	if !isAbsolutePath(absoluteArchivePath) || !isAbsolutePath(dst) {
+		return ErrNotAbsolutePath
+	}
+	entryPath = strings.TrimPrefix(entryPath, "./")
+	found := false
+	if err := forEachArchiveEntry(
+		absoluteArchivePath,
+		func(archiveEntry *ArchiveEntry, open func() (io.Reader, error)) error {
+			if strings.TrimPrefix(archiveEntry.Name, "./") != entryPath {
+				return nil
+			}
+			found = true
+			if !archiveEntry.Mode.IsRegular() {
+				return ErrNotRegularFile
+			}
+			reader, err := open()
+			if err != nil {
+				return err
+			}
+			if err := writeAtomic(
+				dst,
+				archiveEntry.Mode.Perm(),
+				func(writer io.Writer) error {
+					_, err := io.Copy(writer, reader)
+					return err
+				},
+			); err != nil {
+				return err
+			}
+			return errArchiveStop
+		},
+	); err != nil {
+		return err
+	}
+	if !found {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// ***** PRIVATE *****
+
+// forEachArchiveEntry calls fn for every entry, with open returning the
+// content of the entry, valid until fn returns. fn returning errArchiveStop
+// stops the iteration without error.
+func forEachArchiveEntry(absolutePath string, fn func(archiveEntry *ArchiveEntry, open func() (io.Reader, error)) error) error {
+	file, err := os.Open(absolutePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	magic, err := readArchiveMagic(file)
+	if err != nil {
+		return err
+	}
+	if isZipMagic(magic) {
+		err = forEachZipEntry(file, fn)
+	} else {
+		err = forEachTarEntry(file, magic, fn)
+	}
+	if err == errArchiveStop {
+		return nil
+	}
+	return err
+}
+
+// readArchiveMagic reads the first bytes of file, enough to tell the
+// formats apart, and seeks back to the start.
+func readArchiveMagic(file *os.File) ([]byte, error) {
+	magic := make([]byte, 6)
+	n, err := io.ReadFull(file, magic)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return magic[:n], nil
+}
+
+// isZipMagic matches a local file header, or the end of central directory
+// record that starts an empty zip.
+func isZipMagic(magic []byte) bool {
+	return bytes.HasPrefix(magic, []byte("PK\x03\x04")) || bytes.HasPrefix(magic, []byte("PK\x05\x06"))
+}
+
+func forEachZipEntry(file *os.File, fn func(archiveEntry *ArchiveEntry, open func() (io.Reader, error)) error) error {
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	zipReader, err := zip.NewReader(file, fileInfo.Size())
+	if err != nil {
+		return err
+	}
+	for _, zipFile := range zipReader.File {
+		if err := forZipEntry(zipFile, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func forZipEntry(zipFile *zip.File, fn func(archiveEntry *ArchiveEntry, open func() (io.Reader, error)) error) error {
+	archiveEntry := &ArchiveEntry{
+		Name:    zipFile.Name,
+		Size:    int64(zipFile.UncompressedSize64),
+		Mode:    zipFile.Mode(),
+		ModTime: zipFile.Modified,
+	}
+	var readCloser io.ReadCloser
+	defer func() {
+		if readCloser != nil {
+			_ = readCloser.Close()
+		}
+	}()
+	open := func() (io.Reader, error) {
+		var err error
+		readCloser, err = zipFile.Open()
+		return readCloser, err
+	}
+	if archiveEntry.Mode&os.ModeSymlink != 0 {
+		linkTarget, err := readZipFile(zipFile)
+		if err != nil {
+			return err
+		}
+		archiveEntry.LinkTarget = string(linkTarget)
+	}
+	return fn(archiveEntry, open)
+}
+
+func readZipFile(zipFile *zip.File) ([]byte, error) {
+	readCloser, err := zipFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer readCloser.Close()
+	return ioutil.ReadAll(readCloser)
+}
+
+func forEachTarEntry(file *os.File, magic []byte, fn func(archiveEntry *ArchiveEntry, open func() (io.Reader, error)) error) error {
+	reader, closeReader, err := decompressTar(bufio.NewReader(file), magic)
+	if err != nil {
+		return err
+	}
+	defer closeReader()
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		archiveEntry := &ArchiveEntry{
+			Name:       header.Name,
+			Size:       header.Size,
+			Mode:       header.FileInfo().Mode(),
+			ModTime:    header.ModTime,
+			LinkTarget: header.Linkname,
+		}
+		if err := fn(
+			archiveEntry,
+			func() (io.Reader, error) {
+				return tarReader, nil
+			},
+		); err != nil {
+			return err
+		}
+	}
+}
+
+// decompressTar returns the tar stream of reader, detecting gzip, zstd and
+// xz from magic.
+func decompressTar(reader io.Reader, magic []byte) (io.Reader, func(), error) {
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gzipReader, func() { _ = gzipReader.Close() }, nil
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zstdReader, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zstdReader, zstdReader.Close, nil
+	case bytes.HasPrefix(magic, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		xzReader, err := xz.NewReader(reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return xzReader, func() {}, nil
+	default:
+		return reader, func() {}, nil
+	}
+}
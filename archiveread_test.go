@@ -0,0 +1,54 @@
+package osutils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestListArchiveAndExtractEntry() {
+	s.writeCopyDirSrc()
+	src := filepath.Join(s.tempDir, "src")
+	tarPath := filepath.Join(s.tempDir, "src.tar")
+	require.NoError(s.T(), TarDir(src, tarPath, nil))
+	tarData, err := ioutil.ReadFile(tarPath)
+	require.NoError(s.T(), err)
+	var zstdTar bytes.Buffer
+	require.NoError(s.T(), ZstdCompress(bytes.NewReader(tarData), &zstdTar))
+	zstdPath := filepath.Join(s.tempDir, "src.tar.zst")
+	require.NoError(s.T(), ioutil.WriteFile(zstdPath, zstdTar.Bytes(), 0644))
+
+	for _, archiveDir := range []func(string, string, *ArchiveOptions) error{TarDir, ZipDir, nil} {
+		archivePath := zstdPath
+		if archiveDir != nil {
+			archivePath = filepath.Join(s.tempDir, "archive")
+			require.NoError(s.T(), archiveDir(src, archivePath, nil))
+		}
+		archiveEntries, err := ListArchive(archivePath)
+		require.NoError(s.T(), err)
+		names := make(map[string]*ArchiveEntry)
+		for _, archiveEntry := range archiveEntries {
+			names[archiveEntry.Name] = archiveEntry
+		}
+		require.Contains(s.T(), names, "a/")
+		require.True(s.T(), names["a/"].Mode.IsDir())
+		require.Equal(s.T(), int64(1), names["b/2"].Size)
+		if runtime.GOOS != "windows" {
+			require.Equal(s.T(), "a/1", names["link"].LinkTarget)
+			require.Equal(s.T(), os.FileMode(0600), names["a/1"].Mode.Perm())
+		}
+
+		dst := filepath.Join(s.tempDir, "extracted")
+		require.NoError(s.T(), ExtractEntry(archivePath, "b/2", dst))
+		data, err := ioutil.ReadFile(dst)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), "2", string(data))
+		require.Equal(s.T(), ErrEntryNotFound, ExtractEntry(archivePath, "missing", dst))
+		require.Equal(s.T(), ErrNotRegularFile, ExtractEntry(archivePath, "a/", dst))
+		require.NoError(s.T(), os.Remove(archivePath))
+	}
+}
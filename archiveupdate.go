@@ -0,0 +1,307 @@
+package osutils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+var ErrInvalidArchiveChange = errors.New("osutils: invalid archive change")
+
+// ArchiveChange adds, replaces or deletes an entry of an archive.
+type ArchiveChange struct {
+	// Name is the path in the archive with slashes.
+	Name string
+	// Delete removes the entry, or everything under it if Name ends with a
+	// slash.
+	Delete bool
+	// Data, or otherwise the content of the file at SourcePath, is the
+	// content of the entry added or replaced.
+	Data       []byte
+	SourcePath string
+	// Mode is the permissions of the entry, 0644 if 0.
+	Mode os.FileMode
+}
+
+// UpdateArchive applies changes to the zip or tar archive at absolutePath,
+// see ListArchive for the formats supported, by writing a new archive to a
+// temp file and renaming it into place. A replaced entry keeps its place,
+// added entries come last in the order of changes. Zip entries that are
+// kept are copied without being recompressed. Deleting an entry that does
+// not exist results in ErrEntryNotFound.
+func UpdateArchive(absolutePath string, changes []*ArchiveChange) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	for _, change := range changes {
+		if err := validateArchiveChange(change); err != nil {
+			return err
+		}
+	}
+	return updateArchive(absolutePath, changes)
+}
+
+// ***** PRIVATE *****
+
+func validateArchiveChange(change *ArchiveChange) error {
+	if change == nil {
+		return ErrNil
+	}
+	name := strings.TrimSuffix(archiveChangeName(change), "/")
+	if name == "" || strings.HasPrefix(name, "/") || path.Clean(name) != name || name == ".." || strings.HasPrefix(name, "../") {
+		return ErrInvalidArchiveChange
+	}
+	if change.Delete && (change.Data != nil || change.SourcePath != "") {
+		return ErrInvalidArchiveChange
+	}
+	if !change.Delete && (strings.HasSuffix(change.Name, "/") || (change.Data != nil && change.SourcePath != "")) {
+		return ErrInvalidArchiveChange
+	}
+	if change.SourcePath != "" && !isAbsolutePath(change.SourcePath) {
+		return ErrNotAbsolutePath
+	}
+	return nil
+}
+
+// archiveUpdate tracks which changes were applied while the entries are
+// copied.
+type archiveUpdate struct {
+	changes []*ArchiveChange
+	applied map[*ArchiveChange]bool
+	modTime time.Time
+}
+
+// match returns the change for the entry name, if any. A leading "./" is
+// ignored on both, as by ExtractEntry.
+func (a *archiveUpdate) match(name string) *ArchiveChange {
+	name = strings.TrimPrefix(name, "./")
+	for _, change := range a.changes {
+		changeName := archiveChangeName(change)
+		if changeName == name || (change.Delete && strings.HasSuffix(changeName, "/") && strings.HasPrefix(name, changeName)) {
+			a.applied[change] = true
+			return change
+		}
+	}
+	return nil
+}
+
+// remaining returns the changes to add at the end, failing if a delete
+// did not match anything.
+func (a *archiveUpdate) remaining() ([]*ArchiveChange, error) {
+	var remaining []*ArchiveChange
+	for _, change := range a.changes {
+		if a.applied[change] {
+			continue
+		}
+		if change.Delete {
+			return nil, ErrEntryNotFound
+		}
+		remaining = append(remaining, change)
+	}
+	return remaining, nil
+}
+
+func updateArchive(absolutePath string, changes []*ArchiveChange) error {
+	fileInfo, err := os.Stat(absolutePath)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(absolutePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	magic, err := readArchiveMagic(file)
+	if err != nil {
+		return err
+	}
+	archiveUpdate := &archiveUpdate{
+		changes: changes,
+		applied: make(map[*ArchiveChange]bool),
+		modTime: time.Now(),
+	}
+	return writeAtomic(
+		absolutePath,
+		fileInfo.Mode().Perm(),
+		func(writer io.Writer) error {
+			if isZipMagic(magic) {
+				return updateZip(file, fileInfo.Size(), writer, archiveUpdate)
+			}
+			return updateTar(file, magic, writer, archiveUpdate)
+		},
+	)
+}
+
+func updateZip(file *os.File, size int64, writer io.Writer, archiveUpdate *archiveUpdate) error {
+	zipReader, err := zip.NewReader(file, size)
+	if err != nil {
+		return err
+	}
+	zipWriter := zip.NewWriter(writer)
+	for _, zipFile := range zipReader.File {
+		change := archiveUpdate.match(zipFile.Name)
+		switch {
+		case change == nil:
+			if err := zipWriter.Copy(zipFile); err != nil {
+				return err
+			}
+		case !change.Delete:
+			if err := writeZipChange(zipWriter, zipFile.Name, change, archiveUpdate.modTime); err != nil {
+				return err
+			}
+		}
+	}
+	remaining, err := archiveUpdate.remaining()
+	if err != nil {
+		return err
+	}
+	for _, change := range remaining {
+		if err := writeZipChange(zipWriter, archiveChangeName(change), change, archiveUpdate.modTime); err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
+
+// writeZipChange writes change as name, which is the name of the entry it
+// replaces so that a "./" prefix is kept.
+func writeZipChange(zipWriter *zip.Writer, name string, change *ArchiveChange, modTime time.Time) error {
+	content, err := archiveChangeContent(change)
+	if err != nil {
+		return err
+	}
+	fileHeader := &zip.FileHeader{
+		Name:     name,
+		Modified: modTime,
+		Method:   zip.Deflate,
+	}
+	fileHeader.SetMode(archiveChangeMode(change))
+	writer, err := zipWriter.CreateHeader(fileHeader)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(content)
+	return err
+}
+
+func updateTar(file *os.File, magic []byte, writer io.Writer, archiveUpdate *archiveUpdate) error {
+	reader, closeReader, err := decompressTar(bufio.NewReader(file), magic)
+	if err != nil {
+		return err
+	}
+	defer closeReader()
+	compressWriter, err := compressTar(writer, magic)
+	if err != nil {
+		return err
+	}
+	tarReader := tar.NewReader(reader)
+	tarWriter := tar.NewWriter(compressWriter)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		change := archiveUpdate.match(header.Name)
+		switch {
+		case change == nil:
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return err
+			}
+			if _, err := io.Copy(tarWriter, tarReader); err != nil {
+				return err
+			}
+		case !change.Delete:
+			if err := writeTarChange(tarWriter, header.Name, change, archiveUpdate.modTime); err != nil {
+				return err
+			}
+		}
+	}
+	remaining, err := archiveUpdate.remaining()
+	if err != nil {
+		return err
+	}
+	for _, change := range remaining {
+		if err := writeTarChange(tarWriter, archiveChangeName(change), change, archiveUpdate.modTime); err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return compressWriter.Close()
+}
+
+func writeTarChange(tarWriter *tar.Writer, name string, change *ArchiveChange, modTime time.Time) error {
+	content, err := archiveChangeContent(change)
+	if err != nil {
+		return err
+	}
+	if err := tarWriter.WriteHeader(
+		&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     int64(archiveChangeMode(change).Perm()),
+			Size:     int64(len(content)),
+			ModTime:  modTime,
+		},
+	); err != nil {
+		return err
+	}
+	_, err = tarWriter.Write(content)
+	return err
+}
+
+// compressTar compresses writer the same way as the archive with magic.
+func compressTar(writer io.Writer, magic []byte) (io.WriteCloser, error) {
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewWriter(writer), nil
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return zstd.NewWriter(writer)
+	case bytes.HasPrefix(magic, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		return xz.NewWriter(writer)
+	default:
+		return nopWriteCloser{writer}, nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func archiveChangeName(change *ArchiveChange) string {
+	return strings.TrimPrefix(change.Name, "./")
+}
+
+func archiveChangeContent(change *ArchiveChange) ([]byte, error) {
+	if change.SourcePath != "" {
+		return ioutil.ReadFile(change.SourcePath)
+	}
+	return change.Data, nil
+}
+
+func archiveChangeMode(change *ArchiveChange) os.FileMode {
+	if change.Mode == 0 {
+		return 0644
+	}
+	return change.Mode.Perm()
+}
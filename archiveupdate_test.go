@@ -0,0 +1,103 @@
+package osutils
+
+import (
+	"archive/tar"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestUpdateArchive() {
+	s.writeCopyDirSrc()
+	src := filepath.Join(s.tempDir, "src")
+	config := filepath.Join(s.tempDir, "config")
+	require.NoError(s.T(), ioutil.WriteFile(config, []byte("key: value\n"), 0644))
+	for _, name := range []string{"archive.zip", "archive.tar", "archive.tar.gz"} {
+		archivePath := filepath.Join(s.tempDir, name)
+		archiveDir := TarDir
+		if name == "archive.zip" {
+			archiveDir = ZipDir
+		}
+		require.NoError(s.T(), archiveDir(src, archivePath, nil))
+		require.NoError(
+			s.T(),
+			UpdateArchive(
+				archivePath,
+				[]*ArchiveChange{
+					{Name: "b/2", Data: []byte("replaced")},
+					{Name: "a/", Delete: true},
+					{Name: "etc/config.yaml", SourcePath: config, Mode: 0600},
+				},
+			),
+		)
+		archiveEntries, err := ListArchive(archivePath)
+		require.NoError(s.T(), err)
+		var names []string
+		for _, archiveEntry := range archiveEntries {
+			names = append(names, archiveEntry.Name)
+		}
+		require.Equal(s.T(), []string{"b/", "b/2", "link", "etc/config.yaml"}, names, name)
+		require.Equal(s.T(), os.FileMode(0600), archiveEntries[len(archiveEntries)-1].Mode.Perm())
+
+		dst := filepath.Join(s.tempDir, "extracted")
+		require.NoError(s.T(), ExtractEntry(archivePath, "b/2", dst))
+		data, err := ioutil.ReadFile(dst)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), "replaced", string(data))
+		require.NoError(s.T(), ExtractEntry(archivePath, "etc/config.yaml", dst))
+		data, err = ioutil.ReadFile(dst)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), "key: value\n", string(data))
+
+		require.Equal(s.T(), ErrEntryNotFound, UpdateArchive(archivePath, []*ArchiveChange{{Name: "missing", Delete: true}}))
+	}
+	archivePath := filepath.Join(s.tempDir, "archive.zip")
+	for _, change := range []*ArchiveChange{
+		{Name: "../escape", Data: []byte{}},
+		{Name: "/absolute", Data: []byte{}},
+		{Name: "both", Data: []byte{}, Delete: true},
+		{Name: "dir/", Data: []byte{}},
+	} {
+		require.Equal(s.T(), ErrInvalidArchiveChange, UpdateArchive(archivePath, []*ArchiveChange{change}), change.Name)
+	}
+}
+
+func (s *Suite) TestUpdateArchiveDotSlash() {
+	archivePath := filepath.Join(s.tempDir, "archive.tar")
+	file, err := os.Create(archivePath)
+	require.NoError(s.T(), err)
+	tarWriter := tar.NewWriter(file)
+	for _, name := range []string{"./a", "./b/1", "./b/2"} {
+		require.NoError(s.T(), tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: 1}))
+		_, err := tarWriter.Write([]byte("x"))
+		require.NoError(s.T(), err)
+	}
+	require.NoError(s.T(), tarWriter.Close())
+	require.NoError(s.T(), file.Close())
+
+	require.NoError(
+		s.T(),
+		UpdateArchive(
+			archivePath,
+			[]*ArchiveChange{
+				{Name: "a", Data: []byte("replaced")},
+				{Name: "./b/", Delete: true},
+				{Name: "./c", Data: []byte("added")},
+			},
+		),
+	)
+	archiveEntries, err := ListArchive(archivePath)
+	require.NoError(s.T(), err)
+	var names []string
+	for _, archiveEntry := range archiveEntries {
+		names = append(names, archiveEntry.Name)
+	}
+	require.Equal(s.T(), []string{"./a", "c"}, names)
+	dst := filepath.Join(s.tempDir, "extracted")
+	require.NoError(s.T(), ExtractEntry(archivePath, "a", dst))
+	data, err := ioutil.ReadFile(dst)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "replaced", string(data))
+}
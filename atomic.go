@@ -0,0 +1,80 @@
+package osutils
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WriteFileAtomic writes data to a temp file in the same dir and renames it
+// over absolutePath, so readers see either the old or the new content.
+func WriteFileAtomic(absolutePath string, data []byte, perm os.FileMode) error {
+	return writeFileAtomic(absolutePath, data, perm)
+}
+
+// ***** PRIVATE *****
+
+func writeFileAtomic(absolutePath string, data []byte, perm os.FileMode) (retErr error) {
+	start := time.Now()
+	defer func() {
+		observeOperation(MetricsOperationWriteFile, start, int64(len(data)), retErr)
+	}()
+	return writeAtomic(
+		absolutePath,
+		perm,
+		func(writer io.Writer) error {
+			_, err := writer.Write(data)
+			return err
+		},
+	)
+}
+
+// writeAtomic calls write with a temp file next to absolutePath, syncs it
+// and renames it into place. The temp file is removed on any error.
+func writeAtomic(absolutePath string, perm os.FileMode, write func(io.Writer) error) error {
+	return writeAtomicWithMode(absolutePath, perm, PortableModeDefault, write)
+}
+
+// writeAtomicWithMode is writeAtomic that also applies portableMode to the
+// temp file, so the data never appears at absolutePath with any other
+// permissions.
+func writeAtomicWithMode(absolutePath string, perm os.FileMode, portableMode PortableMode, write func(io.Writer) error) (retErr error) {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	perm, err := currentPolicy().filePerm(perm)
+	if err != nil {
+		return err
+	}
+	dir, base := filepath.Split(absolutePath)
+	file, err := ioutil.TempFile(dir, "."+base+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}
+	}()
+	if err := write(file); err != nil {
+		return err
+	}
+	if err := file.Chmod(perm); err != nil {
+		return err
+	}
+	if portableMode != PortableModeDefault {
+		if err := applyPortableMode(file.Name(), portableMode, false); err != nil {
+			return err
+		}
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), absolutePath)
+}
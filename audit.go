@@ -0,0 +1,120 @@
+package osutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	auditWriter AuditWriter
+	auditLock   = &sync.RWMutex{}
+)
+
+// AuditRecord describes one executed command. The environment is only
+// recorded as a hash since it routinely contains secrets.
+type AuditRecord struct {
+	Args     []string  `json:"args"`
+	EnvHash  string    `json:"env_hash"`
+	Dir      string    `json:"dir"`
+	User     string    `json:"user"`
+	PID      int       `json:"pid"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	ExitCode int       `json:"exit_code"`
+	Error    string    `json:"error,omitempty"`
+}
+
+type AuditWriter interface {
+	WriteAuditRecord(auditRecord *AuditRecord) error
+}
+
+// SetAuditWriter installs an AuditWriter that is called for every command
+// executed by this package, including each stage of a pipeline. Pass nil to
+// disable auditing. An error from the AuditWriter is returned from the wait
+// function of the command if the command itself succeeded.
+func SetAuditWriter(writer AuditWriter) {
+	auditLock.Lock()
+	defer auditLock.Unlock()
+	auditWriter = writer
+}
+
+// NewJSONLinesAuditWriter returns an AuditWriter writing each record as a
+// line of JSON.
+func NewJSONLinesAuditWriter(writer io.Writer) AuditWriter {
+	return &jsonLinesAuditWriter{
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+// ***** PRIVATE *****
+
+func auditExecCmd(execCmd *exec.Cmd, start time.Time, err error) error {
+	auditLock.RLock()
+	writer := auditWriter
+	auditLock.RUnlock()
+	if writer == nil {
+		return nil
+	}
+	env := execCmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	dir := execCmd.Dir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	auditRecord := &AuditRecord{
+		Args:     execCmd.Args,
+		EnvHash:  hashEnv(env),
+		Dir:      dir,
+		User:     auditUser(),
+		Start:    start,
+		End:      time.Now(),
+		ExitCode: exitCode(err),
+	}
+	if execCmd.Process != nil {
+		auditRecord.PID = execCmd.Process.Pid
+	}
+	if err != nil {
+		auditRecord.Error = err.Error()
+	}
+	return writer.WriteAuditRecord(auditRecord)
+}
+
+func hashEnv(env []string) string {
+	sorted := append([]string{}, env...)
+	sort.Strings(sorted)
+	hash := sha256.New()
+	for _, entry := range sorted {
+		_, _ = io.WriteString(hash, entry)
+		_, _ = hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func auditUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
+
+type jsonLinesAuditWriter struct {
+	encoder *json.Encoder
+	lock    sync.Mutex
+}
+
+func (j *jsonLinesAuditWriter) WriteAuditRecord(auditRecord *AuditRecord) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.encoder.Encode(auditRecord)
+}
@@ -0,0 +1,35 @@
+package osutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestAuditWriter() {
+	var buffer bytes.Buffer
+	SetAuditWriter(NewJSONLinesAuditWriter(&buffer))
+	defer SetAuditWriter(nil)
+	s.execute([]string{"echo", "foo"}, nil)
+	wait, err := ExecutePiped(
+		&PipeCmdList{
+			PipeCmds: []*PipeCmd{
+				&PipeCmd{Args: []string{"echo", "bar"}},
+				&PipeCmd{Args: []string{"cat"}},
+			},
+		},
+	)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), wait())
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Equal(s.T(), 3, len(lines))
+	auditRecord := &AuditRecord{}
+	require.NoError(s.T(), json.Unmarshal([]byte(lines[0]), auditRecord))
+	require.Equal(s.T(), []string{"echo", "foo"}, auditRecord.Args)
+	require.Equal(s.T(), s.tempDir, auditRecord.Dir)
+	require.Equal(s.T(), 0, auditRecord.ExitCode)
+	require.NotEmpty(s.T(), auditRecord.EnvHash)
+	require.True(s.T(), auditRecord.PID > 0)
+}
@@ -0,0 +1,13 @@
+//go:build dragonfly || openbsd
+
+package osutils
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func birthTime(stat *unix.Stat_t) time.Time {
+	return time.Time{}
+}
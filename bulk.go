@@ -0,0 +1,84 @@
+package osutils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PathErrors is the per-path errors of a bulk operation, in the order the
+// paths were given. Paths are quoted in the message with QuotePath.
+type PathErrors []*os.PathError
+
+func (p PathErrors) Error() string {
+	messages := make([]string, len(p))
+	for i, pathError := range p {
+		messages[i] = pathError.Op + " " + QuotePath(pathError.Path) + ": " + pathError.Err.Error()
+	}
+	return "osutils: " + strconv.Itoa(len(p)) + " paths failed: " + strings.Join(messages, "; ")
+}
+
+// MkdirAllMany runs MkdirAll for every path. All paths are validated
+// before any directory is created, and a failure for one path does not stop
+// the others. The error is a PathErrors.
+func MkdirAllMany(absolutePaths []string, perm os.FileMode) error {
+	if err := validatePaths("mkdir", absolutePaths); err != nil {
+		return err
+	}
+	var pathErrors PathErrors
+	for _, absolutePath := range absolutePaths {
+		if err := mkdirAll(absolutePath, perm); err != nil {
+			pathErrors = appendPathError(pathErrors, "mkdir", absolutePath, err)
+		}
+	}
+	return pathErrorsOrNil(pathErrors)
+}
+
+// ExistAll returns the paths that do not exist, so all exist if it returns
+// neither missing paths nor an error. The error is a PathErrors for paths
+// that could not be checked.
+func ExistAll(absolutePaths []string) ([]string, error) {
+	if err := validatePaths("stat", absolutePaths); err != nil {
+		return nil, err
+	}
+	var missing []string
+	var pathErrors PathErrors
+	for _, absolutePath := range absolutePaths {
+		exists, err := isFileExists(absolutePath)
+		if err != nil {
+			pathErrors = appendPathError(pathErrors, "stat", absolutePath, err)
+			continue
+		}
+		if !exists {
+			missing = append(missing, absolutePath)
+		}
+	}
+	return missing, pathErrorsOrNil(pathErrors)
+}
+
+// ***** PRIVATE *****
+
+func validatePaths(op string, absolutePaths []string) error {
+	var pathErrors PathErrors
+	for _, absolutePath := range absolutePaths {
+		if !isAbsolutePath(absolutePath) {
+			pathErrors = appendPathError(pathErrors, op, absolutePath, ErrNotAbsolutePath)
+		}
+	}
+	return pathErrorsOrNil(pathErrors)
+}
+
+func appendPathError(pathErrors PathErrors, op string, path string, err error) PathErrors {
+	if pathError, ok := err.(*os.PathError); ok {
+		return append(pathErrors, pathError)
+	}
+	return append(pathErrors, &os.PathError{Op: op, Path: path, Err: err})
+}
+
+// pathErrorsOrNil avoids returning a nil PathErrors as a non-nil error.
+func pathErrorsOrNil(pathErrors PathErrors) error {
+	if len(pathErrors) == 0 {
+		return nil
+	}
+	return pathErrors
+}
@@ -0,0 +1,40 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestMkdirAllMany() {
+	paths := []string{
+		filepath.Join(s.tempDir, "a", "b"),
+		filepath.Join(s.tempDir, "c"),
+	}
+	missing, err := ExistAll(paths)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), paths, missing)
+
+	err = MkdirAllMany(append([]string{"relative"}, paths...), 0755)
+	require.Error(s.T(), err)
+	pathErrors, ok := err.(PathErrors)
+	require.True(s.T(), ok)
+	require.Len(s.T(), pathErrors, 1)
+	require.Equal(s.T(), ErrNotAbsolutePath, pathErrors[0].Err)
+	// nothing was created since validation failed
+	missing, err = ExistAll(paths)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), missing, 2)
+
+	file := filepath.Join(s.tempDir, "file")
+	require.NoError(s.T(), ioutil.WriteFile(file, nil, 0644))
+	err = MkdirAllMany(append(paths, filepath.Join(file, "sub")), 0755)
+	pathErrors, ok = err.(PathErrors)
+	require.True(s.T(), ok)
+	require.Len(s.T(), pathErrors, 1)
+	require.Equal(s.T(), filepath.Join(file, "sub"), pathErrors[0].Path)
+	missing, err = ExistAll(paths)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), missing)
+}
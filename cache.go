@@ -0,0 +1,195 @@
+package osutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	cacheLockFileName = ".lock"
+	cacheShardLength  = 2
+	cacheDirPerm      = 0755
+	cacheFilePerm     = 0644
+)
+
+type CacheOptions struct {
+	// MaxBytes is the budget for the entries, the least recently used ones
+	// being evicted by Put to stay within it. 0 means no budget.
+	MaxBytes int64
+}
+
+// Cache is an on-disk cache of byte values keyed by string. Entries are
+// files named by the SHA-256 of the key in subdirs sharded by its first
+// byte, written atomically, and an flock on a lock file in the root makes
+// the cache safe to share between processes.
+type Cache struct {
+	root     string
+	maxBytes int64
+}
+
+// OpenCache opens the cache at absoluteDir, creating the dir if necessary.
+func OpenCache(absoluteDir string, options *CacheOptions) (*Cache, error) {
+	if !isAbsolutePath(absoluteDir) {
+		return nil, ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &CacheOptions{}
+	}
+	if err := os.MkdirAll(absoluteDir, cacheDirPerm); err != nil {
+		return nil, err
+	}
+	return &Cache{
+		root:     filepath.Clean(absoluteDir),
+		maxBytes: options.MaxBytes,
+	}, nil
+}
+
+func (c *Cache) Root() string {
+	return c.root
+}
+
+// Get returns the value for key and whether it was found, and marks the
+// entry as recently used.
+func (c *Cache) Get(key string) ([]byte, bool, error) {
+	var value []byte
+	found := false
+	if err := c.withLock(
+		false,
+		func() error {
+			path := c.path(key)
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			value, found = data, true
+			// the modification time tracks use, access times are often off
+			now := time.Now()
+			if err := os.Chtimes(path, now, now); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		},
+	); err != nil {
+		return nil, false, err
+	}
+	return value, found, nil
+}
+
+// Put stores value for key, replacing any previous value, and then evicts
+// the least recently used entries if over budget. Eviction lists the whole
+// cache, which is fine for the thousands of entries a tool cache holds.
+func (c *Cache) Put(key string, value []byte) error {
+	return c.withLock(
+		true,
+		func() error {
+			path := c.path(key)
+			if err := os.MkdirAll(filepath.Dir(path), cacheDirPerm); err != nil {
+				return err
+			}
+			if err := writeFileAtomic(path, value, cacheFilePerm); err != nil {
+				return err
+			}
+			if c.maxBytes > 0 {
+				return c.evict()
+			}
+			return nil
+		},
+	)
+}
+
+// Delete removes the entry for key, and is a no-op if there is none.
+func (c *Cache) Delete(key string) error {
+	return c.withLock(
+		true,
+		func() error {
+			if err := os.Remove(c.path(key)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		},
+	)
+}
+
+// ***** PRIVATE *****
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.root, name[:cacheShardLength], name)
+}
+
+func (c *Cache) withLock(exclusive bool, fn func() error) error {
+	fileLock, err := LockFile(filepath.Join(c.root, cacheLockFileName), exclusive)
+	if err != nil {
+		return err
+	}
+	err = fn()
+	if unlockErr := fileLock.Unlock(); err == nil {
+		err = unlockErr
+	}
+	return err
+}
+
+// evict removes the least recently used entries until the total size is
+// within budget. Temp files of writes in progress start with a dot and are
+// left alone.
+func (c *Cache) evict() error {
+	var fileInfos []os.FileInfo
+	var paths []string
+	var total int64
+	if err := WalkRegularFiles(
+		c.root,
+		func(path string, dirEntry fs.DirEntry) error {
+			if strings.HasPrefix(dirEntry.Name(), ".") || filepath.Dir(path) == c.root {
+				return nil
+			}
+			fileInfo, err := dirEntry.Info()
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			fileInfos = append(fileInfos, fileInfo)
+			paths = append(paths, path)
+			total += fileInfo.Size()
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+	if total <= c.maxBytes {
+		return nil
+	}
+	indexes := make([]int, len(paths))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	// oldest first
+	sort.Slice(
+		indexes,
+		func(i int, j int) bool {
+			return fileInfos[indexes[i]].ModTime().Before(fileInfos[indexes[j]].ModTime())
+		},
+	)
+	for _, i := range indexes {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(paths[i]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= fileInfos[i].Size()
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package osutils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCache() {
+	cache, err := OpenCache(filepath.Join(s.tempDir, "cache"), &CacheOptions{MaxBytes: 25})
+	require.NoError(s.T(), err)
+	_, found, err := cache.Get("missing")
+	require.NoError(s.T(), err)
+	require.False(s.T(), found)
+
+	past := time.Now().Add(-time.Hour)
+	for i, key := range []string{"a", "b"} {
+		require.NoError(s.T(), cache.Put(key, bytes.Repeat([]byte(key), 10)))
+		// distinct modification times without sleeping
+		modTime := past.Add(time.Duration(i) * time.Minute)
+		require.NoError(s.T(), os.Chtimes(cache.path(key), modTime, modTime))
+	}
+	value, found, err := cache.Get("a")
+	require.NoError(s.T(), err)
+	require.True(s.T(), found)
+	require.Equal(s.T(), "aaaaaaaaaa", string(value))
+
+	// b is now the least recently used
+	require.NoError(s.T(), cache.Put("c", bytes.Repeat([]byte("c"), 10)))
+	_, found, err = cache.Get("b")
+	require.NoError(s.T(), err)
+	require.False(s.T(), found)
+	for _, key := range []string{"a", "c"} {
+		_, found, err = cache.Get(key)
+		require.NoError(s.T(), err)
+		require.True(s.T(), found, key)
+	}
+
+	require.NoError(s.T(), cache.Delete("a"))
+	require.NoError(s.T(), cache.Delete("a"))
+	_, found, err = cache.Get("a")
+	require.NoError(s.T(), err)
+	require.False(s.T(), found)
+	require.Equal(s.T(), filepath.Join(s.tempDir, "cache"), filepath.Dir(filepath.Dir(cache.path("a"))))
+
+	_, err = OpenCache("relative", nil)
+	require.Equal(s.T(), ErrNotAbsolutePath, err)
+}
@@ -0,0 +1,122 @@
+package osutils
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUnknownCapability = errors.New("osutils: unknown capability")
+
+	capabilityNames = []string{
+		"cap_chown",
+		"cap_dac_override",
+		"cap_dac_read_search",
+		"cap_fowner",
+		"cap_fsetid",
+		"cap_kill",
+		"cap_setgid",
+		"cap_setuid",
+		"cap_setpcap",
+		"cap_linux_immutable",
+		"cap_net_bind_service",
+		"cap_net_broadcast",
+		"cap_net_admin",
+		"cap_net_raw",
+		"cap_ipc_lock",
+		"cap_ipc_owner",
+		"cap_sys_module",
+		"cap_sys_rawio",
+		"cap_sys_chroot",
+		"cap_sys_ptrace",
+		"cap_sys_pacct",
+		"cap_sys_admin",
+		"cap_sys_boot",
+		"cap_sys_nice",
+		"cap_sys_resource",
+		"cap_sys_time",
+		"cap_sys_tty_config",
+		"cap_mknod",
+		"cap_lease",
+		"cap_audit_write",
+		"cap_audit_control",
+		"cap_setfcap",
+		"cap_mac_override",
+		"cap_mac_admin",
+		"cap_syslog",
+		"cap_wake_alarm",
+		"cap_block_suspend",
+		"cap_audit_read",
+		"cap_perfmon",
+		"cap_bpf",
+		"cap_checkpoint_restore",
+	}
+)
+
+// FileCapabilities are the Linux file capabilities of a binary, by name
+// such as cap_net_bind_service. If Effective is set, the permitted
+// capabilities are raised in the effective set on exec, which is what
+// setcap +ep does.
+type FileCapabilities struct {
+	Permitted   []string
+	Inheritable []string
+	Effective   bool
+}
+
+// GetFileCapabilities returns nil if the file has no capabilities.
+func GetFileCapabilities(absolutePath string) (*FileCapabilities, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	return getFileCapabilities(absolutePath)
+}
+
+// SetFileCapabilities requires CAP_SETFCAP.
+func SetFileCapabilities(absolutePath string, fileCapabilities *FileCapabilities) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	if fileCapabilities == nil {
+		return ErrNil
+	}
+	return setFileCapabilities(absolutePath, fileCapabilities)
+}
+
+func RemoveFileCapabilities(absolutePath string) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	return removeFileCapabilities(absolutePath)
+}
+
+// ***** PRIVATE *****
+
+func capabilitiesToMask(names []string) (uint64, error) {
+	var mask uint64
+	for _, name := range names {
+		found := false
+		for i, capabilityName := range capabilityNames {
+			if strings.EqualFold(name, capabilityName) {
+				mask |= 1 << uint(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, ErrUnknownCapability
+		}
+	}
+	return mask, nil
+}
+
+func maskToCapabilities(mask uint64) []string {
+	var names []string
+	for i, capabilityName := range capabilityNames {
+		if mask&(1<<uint(i)) != 0 {
+			names = append(names, capabilityName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
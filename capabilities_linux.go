@@ -0,0 +1,99 @@
+package osutils
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	capabilityXattr = "security.capability"
+	// see linux/capability.h
+	vfsCapRevisionMask   = 0xFF000000
+	vfsCapRevision1      = 0x01000000
+	vfsCapRevision2      = 0x02000000
+	vfsCapRevision3      = 0x03000000
+	vfsCapFlagsEffective = 0x000001
+	vfsCapRevision1Size  = 4 + 2*4
+	vfsCapRevision2Size  = 4 + 2*2*4
+)
+
+func getFileCapabilities(absolutePath string) (*FileCapabilities, error) {
+	data := make([]byte, vfsCapRevision2Size+4)
+	n, err := unix.Getxattr(absolutePath, capabilityXattr, data)
+	if err != nil {
+		if err == unix.ENODATA {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return decodeFileCapabilities(data[:n])
+}
+
+func setFileCapabilities(absolutePath string, fileCapabilities *FileCapabilities) error {
+	data, err := encodeFileCapabilities(fileCapabilities)
+	if err != nil {
+		return err
+	}
+	return unix.Setxattr(absolutePath, capabilityXattr, data, 0)
+}
+
+func removeFileCapabilities(absolutePath string) error {
+	if err := unix.Removexattr(absolutePath, capabilityXattr); err != nil && err != unix.ENODATA {
+		return err
+	}
+	return nil
+}
+
+// encodeFileCapabilities encodes a revision 2 struct vfs_cap_data.
+func encodeFileCapabilities(fileCapabilities *FileCapabilities) ([]byte, error) {
+	permitted, err := capabilitiesToMask(fileCapabilities.Permitted)
+	if err != nil {
+		return nil, err
+	}
+	inheritable, err := capabilitiesToMask(fileCapabilities.Inheritable)
+	if err != nil {
+		return nil, err
+	}
+	magic := uint32(vfsCapRevision2)
+	if fileCapabilities.Effective {
+		magic |= vfsCapFlagsEffective
+	}
+	data := make([]byte, vfsCapRevision2Size)
+	binary.LittleEndian.PutUint32(data[0:], magic)
+	binary.LittleEndian.PutUint32(data[4:], uint32(permitted))
+	binary.LittleEndian.PutUint32(data[8:], uint32(inheritable))
+	binary.LittleEndian.PutUint32(data[12:], uint32(permitted>>32))
+	binary.LittleEndian.PutUint32(data[16:], uint32(inheritable>>32))
+	return data, nil
+}
+
+func decodeFileCapabilities(data []byte) (*FileCapabilities, error) {
+	if len(data) < 4 {
+		return nil, ErrNotSupported
+	}
+	magic := binary.LittleEndian.Uint32(data)
+	var permitted, inheritable uint64
+	switch magic & vfsCapRevisionMask {
+	case vfsCapRevision1:
+		if len(data) < vfsCapRevision1Size {
+			return nil, ErrNotSupported
+		}
+		permitted = uint64(binary.LittleEndian.Uint32(data[4:]))
+		inheritable = uint64(binary.LittleEndian.Uint32(data[8:]))
+	case vfsCapRevision2, vfsCapRevision3:
+		// revision 3 only appends the root uid of the user namespace
+		if len(data) < vfsCapRevision2Size {
+			return nil, ErrNotSupported
+		}
+		permitted = uint64(binary.LittleEndian.Uint32(data[4:])) | uint64(binary.LittleEndian.Uint32(data[12:]))<<32
+		inheritable = uint64(binary.LittleEndian.Uint32(data[8:])) | uint64(binary.LittleEndian.Uint32(data[16:]))<<32
+	default:
+		return nil, ErrNotSupported
+	}
+	return &FileCapabilities{
+		Permitted:   maskToCapabilities(permitted),
+		Inheritable: maskToCapabilities(inheritable),
+		Effective:   magic&vfsCapFlagsEffective != 0,
+	}, nil
+}
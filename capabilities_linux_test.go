@@ -0,0 +1,34 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestEncodeFileCapabilities() {
+	fileCapabilities := &FileCapabilities{
+		Permitted:   []string{"cap_net_bind_service", "cap_bpf"},
+		Inheritable: []string{},
+		Effective:   true,
+	}
+	data, err := encodeFileCapabilities(fileCapabilities)
+	require.NoError(s.T(), err)
+	decoded, err := decodeFileCapabilities(data)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{"cap_bpf", "cap_net_bind_service"}, decoded.Permitted)
+	require.Empty(s.T(), decoded.Inheritable)
+	require.True(s.T(), decoded.Effective)
+	_, err = encodeFileCapabilities(&FileCapabilities{Permitted: []string{"cap_nope"}})
+	require.Equal(s.T(), ErrUnknownCapability, err)
+}
+
+func (s *Suite) TestGetFileCapabilities() {
+	path := filepath.Join(s.tempDir, "file")
+	require.NoError(s.T(), ioutil.WriteFile(path, nil, 0755))
+	fileCapabilities, err := GetFileCapabilities(path)
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), fileCapabilities)
+	require.NoError(s.T(), RemoveFileCapabilities(path))
+}
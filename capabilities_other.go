@@ -0,0 +1,15 @@
+//go:build !linux
+
+package osutils
+
+func getFileCapabilities(absolutePath string) (*FileCapabilities, error) {
+	return nil, ErrNotSupported
+}
+
+func setFileCapabilities(absolutePath string, fileCapabilities *FileCapabilities) error {
+	return ErrNotSupported
+}
+
+func removeFileCapabilities(absolutePath string) error {
+	return ErrNotSupported
+}
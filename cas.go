@@ -0,0 +1,273 @@
+package osutils
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// MaterializeAuto reflinks if the filesystem supports it, and
+	// otherwise copies, so dst can always be modified.
+	MaterializeAuto MaterializeMode = iota
+	MaterializeCopy
+	// MaterializeHardlink shares the blob itself, which is read-only and
+	// must not be modified through dst.
+	MaterializeHardlink
+	MaterializeReflink
+
+	casBlobsDirName    = "blobs"
+	casTmpDirName      = "tmp"
+	casLockFileName    = ".lock"
+	casBlobPerm        = 0444
+	casDirPerm         = 0755
+	casShardLength     = 2
+	casMaterializePerm = 0644
+)
+
+var ErrInvalidDigest = errors.New("osutils: invalid digest")
+
+type MaterializeMode int
+
+// CAS is a content-addressed store of blobs named by the hex SHA-256 of
+// their content, so storing the same content twice stores it once. Blobs
+// are read-only once stored. An flock on a lock file in the root keeps
+// GC from removing a blob that another process is storing.
+type CAS struct {
+	root string
+}
+
+// OpenCAS opens the store at absoluteDir, creating it if necessary.
+func OpenCAS(absoluteDir string) (*CAS, error) {
+	if !isAbsolutePath(absoluteDir) {
+		return nil, ErrNotAbsolutePath
+	}
+	cas := &CAS{root: filepath.Clean(absoluteDir)}
+	for _, dir := range []string{cas.blobsDir(), cas.tmpDir()} {
+		if err := os.MkdirAll(dir, casDirPerm); err != nil {
+			return nil, err
+		}
+	}
+	return cas, nil
+}
+
+func (c *CAS) Root() string {
+	return c.root
+}
+
+// Put stores the content of reader and returns its digest.
+func (c *CAS) Put(reader io.Reader) (string, error) {
+	var digest string
+	if err := c.withLock(
+		false,
+		func() error {
+			var err error
+			digest, err = c.put(reader)
+			return err
+		},
+	); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// PutFile stores the content of the file at absolutePath and returns its
+// digest.
+func (c *CAS) PutFile(absolutePath string) (string, error) {
+	if !isAbsolutePath(absolutePath) {
+		return "", ErrNotAbsolutePath
+	}
+	file, err := os.Open(absolutePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return c.Put(file)
+}
+
+// Has returns whether the blob for digest is stored.
+func (c *CAS) Has(digest string) (bool, error) {
+	path, err := c.BlobPath(digest)
+	if err != nil {
+		return false, err
+	}
+	return isFileExists(path)
+}
+
+// Open opens the blob for digest for reading.
+func (c *CAS) Open(digest string) (*os.File, error) {
+	path, err := c.BlobPath(digest)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// BlobPath returns where the blob for digest is, or would be, stored.
+func (c *CAS) BlobPath(digest string) (string, error) {
+	if !isValidDigest(digest) {
+		return "", ErrInvalidDigest
+	}
+	return filepath.Join(c.blobsDir(), digest[:casShardLength], digest), nil
+}
+
+// Materialize creates dst with the content of the blob for digest. dst must
+// not exist yet, otherwise ErrFileExists.
+func (c *CAS) Materialize(digest string, dst string, materializeMode MaterializeMode) error {
+	if !isAbsolutePath(dst) {
+		return ErrNotAbsolutePath
+	}
+	path, err := c.BlobPath(digest)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return ErrFileExists
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), casDirPerm); err != nil {
+		return err
+	}
+	switch materializeMode {
+	case MaterializeCopy:
+		return copyBlob(path, dst)
+	case MaterializeHardlink:
+		return os.Link(path, dst)
+	case MaterializeReflink:
+		return reflinkFile(path, dst, casMaterializePerm)
+	case MaterializeAuto:
+		if err := reflinkFile(path, dst, casMaterializePerm); err != ErrNotSupported {
+			return err
+		}
+		return copyBlob(path, dst)
+	default:
+		return ErrNotSupported
+	}
+}
+
+// GC removes the blobs whose digest is not in referenced, and returns the
+// digests removed.
+func (c *CAS) GC(referenced map[string]bool) ([]string, error) {
+	var removed []string
+	if err := c.withLock(
+		true,
+		func() error {
+			return WalkRegularFiles(
+				c.blobsDir(),
+				func(path string, dirEntry fs.DirEntry) error {
+					digest := dirEntry.Name()
+					if !isValidDigest(digest) || referenced[digest] {
+						return nil
+					}
+					// read-only files cannot be removed on Windows
+					_ = os.Chmod(path, casMaterializePerm)
+					if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+						return err
+					}
+					removed = append(removed, digest)
+					return nil
+				},
+			)
+		},
+	); err != nil {
+		return removed, err
+	}
+	return removed, nil
+}
+
+// ***** PRIVATE *****
+
+func (c *CAS) blobsDir() string {
+	return filepath.Join(c.root, casBlobsDirName)
+}
+
+func (c *CAS) tmpDir() string {
+	return filepath.Join(c.root, casTmpDirName)
+}
+
+func (c *CAS) withLock(exclusive bool, fn func() error) error {
+	fileLock, err := LockFile(filepath.Join(c.root, casLockFileName), exclusive)
+	if err != nil {
+		return err
+	}
+	err = fn()
+	if unlockErr := fileLock.Unlock(); err == nil {
+		err = unlockErr
+	}
+	return err
+}
+
+// put hashes the content into a temp file and links it into place, which
+// fails harmlessly if the blob is already stored.
+func (c *CAS) put(reader io.Reader) (string, error) {
+	file, err := ioutil.TempFile(c.tmpDir(), "blob")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	hash, err := newHash(HashAlgorithmSHA256)
+	if err != nil {
+		_ = file.Close()
+		return "", err
+	}
+	if _, err := io.Copy(io.MultiWriter(file, hash), reader); err != nil {
+		_ = file.Close()
+		return "", err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+	path, err := c.BlobPath(digest)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), casDirPerm); err != nil {
+		return "", err
+	}
+	if err := os.Link(file.Name(), path); err != nil {
+		if os.IsExist(err) {
+			return digest, nil
+		}
+		return "", err
+	}
+	// only once the temp file is gone, read-only files cannot be removed
+	// on Windows
+	if err := os.Remove(file.Name()); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(path, casBlobPerm); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// copyBlob copies a blob to a writable dst.
+func copyBlob(path string, dst string) error {
+	if err := copyFile(path, dst); err != nil {
+		return err
+	}
+	return os.Chmod(dst, casMaterializePerm)
+}
+
+func isValidDigest(digest string) bool {
+	if len(digest) != 64 {
+		return false
+	}
+	for i := 0; i < len(digest); i++ {
+		if (digest[i] < '0' || digest[i] > '9') && (digest[i] < 'a' || digest[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
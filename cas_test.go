@@ -0,0 +1,68 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCAS() {
+	cas, err := OpenCAS(filepath.Join(s.tempDir, "cas"))
+	require.NoError(s.T(), err)
+	digest, err := cas.Put(strings.NewReader("hello"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", digest)
+	src := filepath.Join(s.tempDir, "src")
+	require.NoError(s.T(), ioutil.WriteFile(src, []byte("hello"), 0644))
+	fileDigest, err := cas.PutFile(src)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), digest, fileDigest)
+	has, err := cas.Has(digest)
+	require.NoError(s.T(), err)
+	require.True(s.T(), has)
+	file, err := cas.Open(digest)
+	require.NoError(s.T(), err)
+	data, err := ioutil.ReadAll(file)
+	s.checkClose(file)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "hello", string(data))
+	tmpFileInfos, err := ioutil.ReadDir(filepath.Join(cas.Root(), "tmp"))
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), tmpFileInfos)
+
+	for i, materializeMode := range []MaterializeMode{MaterializeAuto, MaterializeCopy, MaterializeHardlink} {
+		dst := filepath.Join(s.tempDir, "tree", string(rune('a'+i)))
+		require.NoError(s.T(), cas.Materialize(digest, dst, materializeMode))
+		data, err := ioutil.ReadFile(dst)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), "hello", string(data))
+		require.Equal(s.T(), ErrFileExists, cas.Materialize(digest, dst, materializeMode))
+	}
+	// an auto materialized file is never the blob itself
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(s.tempDir, "tree", "a"), []byte("changed"), 0644))
+	blobPath, err := cas.BlobPath(digest)
+	require.NoError(s.T(), err)
+	data, err = ioutil.ReadFile(blobPath)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "hello", string(data))
+	otherDigest, err := cas.Put(strings.NewReader("other"))
+	require.NoError(s.T(), err)
+	removed, err := cas.GC(map[string]bool{digest: true})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{otherDigest}, removed)
+	has, err = cas.Has(otherDigest)
+	require.NoError(s.T(), err)
+	require.False(s.T(), has)
+	has, err = cas.Has(digest)
+	require.NoError(s.T(), err)
+	require.True(s.T(), has)
+
+	_, err = cas.Has("ABC")
+	require.Equal(s.T(), ErrInvalidDigest, err)
+	_, err = cas.BlobPath(strings.ToUpper(digest))
+	require.Equal(s.T(), ErrInvalidDigest, err)
+	require.True(s.T(), os.IsNotExist(cas.Materialize(otherDigest, filepath.Join(s.tempDir, "gone"), MaterializeCopy)))
+}
@@ -0,0 +1,405 @@
+package osutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	changeJournalPerm = 0600
+)
+
+var (
+	ErrInvalidChangeJournal = errors.New("osutils: invalid change journal")
+	// ErrChangeJournalInRoot means the journal would record its own writes.
+	ErrChangeJournalInRoot = errors.New("osutils: change journal is inside the watched root")
+	// ErrChangesLost means events after the requested sequence number
+	// were dropped, by a watcher error or by Truncate, and a full sync is
+	// needed.
+	ErrChangesLost = errors.New("osutils: changes lost")
+)
+
+// JournalEntry is one change recorded by a ChangeJournal. Paths are
+// slash-separated and relative to the watched root. A Lost entry marks
+// where events were dropped, and has no Op or paths.
+type JournalEntry struct {
+	Seq     uint64    `json:"s"`
+	Time    time.Time `json:"t"`
+	Op      WatchOp   `json:"o"`
+	Path    string    `json:"p,omitempty"`
+	OldPath string    `json:"f,omitempty"`
+	Lost    bool      `json:"l,omitempty"`
+}
+
+// ChangeJournal appends the events of a FileWatcher to a file, one line
+// each with increasing sequence numbers, so that a sync can ask for the
+// changes since the last sequence number it handled instead of scanning
+// the whole tree. Sequence numbers continue across restarts.
+type ChangeJournal struct {
+	root    string
+	path    string
+	watcher FileWatcher
+	lock    sync.Mutex
+	file    *os.File
+	seq     uint64
+	err     error
+	done    chan struct{}
+}
+
+// StartChangeJournal records the events of watcher, which watches
+// absoluteRoot, to the journal at absoluteJournalPath until Close. The
+// journal takes ownership of watcher. Reopening an existing journal
+// appends a Lost entry first, as nothing was recorded while it was closed.
+// The journal must not be inside absoluteRoot.
+func StartChangeJournal(watcher FileWatcher, absoluteRoot string, absoluteJournalPath string) (*ChangeJournal, error) {
+	if watcher == nil {
+		return nil, ErrNil
+	}
+	if !isAbsolutePath(absoluteRoot) || !isAbsolutePath(absoluteJournalPath) {
+		return nil, ErrNotAbsolutePath
+	}
+	changeJournal := &ChangeJournal{
+		root:    filepath.Clean(absoluteRoot),
+		path:    absoluteJournalPath,
+		watcher: watcher,
+		done:    make(chan struct{}),
+	}
+	if _, ok := changeJournal.relPath(filepath.Clean(absoluteJournalPath)); ok {
+		return nil, ErrChangeJournalInRoot
+	}
+	fileInfo, err := stat(absoluteJournalPath)
+	if err != nil {
+		return nil, err
+	}
+	entries, size, err := readChangeJournal(absoluteJournalPath)
+	if err != nil {
+		return nil, err
+	}
+	file, err := openChangeJournal(absoluteJournalPath, size)
+	if err != nil {
+		return nil, err
+	}
+	changeJournal.file = file
+	if len(entries) > 0 {
+		changeJournal.seq = entries[len(entries)-1].Seq
+	}
+	if fileInfo != nil {
+		changeJournal.record(nil)
+		if changeJournal.err != nil {
+			_ = file.Close()
+			return nil, changeJournal.err
+		}
+	}
+	go changeJournal.run()
+	return changeJournal, nil
+}
+
+// Seq returns the sequence number of the last recorded entry.
+func (c *ChangeJournal) Seq() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.seq
+}
+
+// ChangesSince returns the entries after seq, see ReadChangesSince.
+func (c *ChangeJournal) ChangesSince(seq uint64) ([]*JournalEntry, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return ReadChangesSince(c.path, seq)
+}
+
+// Truncate drops the entries up to and including seq, once every consumer
+// has handled them. ChangesSince for an earlier sequence number then
+// returns ErrChangesLost.
+func (c *ChangeJournal) Truncate(seq uint64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if seq > c.seq {
+		seq = c.seq
+	}
+	entries, _, err := readChangeJournal(c.path)
+	if err != nil {
+		return err
+	}
+	kept := []*JournalEntry{
+		{
+			Seq:  seq,
+			Time: time.Now().UTC(),
+			Lost: true,
+		},
+	}
+	for _, entry := range entries {
+		if entry.Seq > seq {
+			kept = append(kept, entry)
+		}
+	}
+	if err := writeAtomic(
+		c.path,
+		changeJournalPerm,
+		func(writer io.Writer) error {
+			for _, entry := range kept {
+				if err := writeJournalEntry(writer, entry); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+	// the rename replaced the file we were appending to
+	file, err := openChangeJournal(c.path, -1)
+	if err != nil {
+		return err
+	}
+	_ = c.file.Close()
+	c.file = file
+	return nil
+}
+
+// Close closes the watcher, waits for its remaining events to be recorded,
+// and returns the first error hit while recording.
+func (c *ChangeJournal) Close() error {
+	err := c.watcher.Close()
+	<-c.done
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.err != nil {
+		err = c.err
+	}
+	if syncErr := c.file.Sync(); syncErr != nil && err == nil {
+		err = syncErr
+	}
+	if closeErr := c.file.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ReadChangesSince returns the entries of the journal at
+// absoluteJournalPath after seq, so another process can read a journal
+// while it is being written. It returns ErrChangesLost if any of them are
+// Lost. A journal that does not exist yet has no entries.
+func ReadChangesSince(absoluteJournalPath string, seq uint64) ([]*JournalEntry, error) {
+	if !isAbsolutePath(absoluteJournalPath) {
+		return nil, ErrNotAbsolutePath
+	}
+	entries, _, err := readChangeJournal(absoluteJournalPath)
+	if err != nil {
+		return nil, err
+	}
+	var since []*JournalEntry
+	for _, entry := range entries {
+		if entry.Seq <= seq {
+			continue
+		}
+		if entry.Lost {
+			return nil, ErrChangesLost
+		}
+		since = append(since, entry)
+	}
+	return since, nil
+}
+
+// JournalChanges coalesces entries into the paths added, modified and
+// removed overall, so a file created and removed again does not appear. A
+// rename is the removal of OldPath and the addition of Path, and a renamed
+// directory is only reported by its own path.
+func JournalChanges(entries []*JournalEntry) *Changes {
+	states := make(map[string]journalState)
+	for _, entry := range entries {
+		if entry.Lost {
+			continue
+		}
+		switch entry.Op {
+		case WatchOpCreate:
+			states[entry.Path] = journalCreate(states[entry.Path])
+		case WatchOpRemove:
+			journalRemove(states, entry.Path)
+		case WatchOpRename:
+			journalRemove(states, entry.OldPath)
+			states[entry.Path] = journalCreate(states[entry.Path])
+		default:
+			if states[entry.Path] != journalAdded {
+				states[entry.Path] = journalModified
+			}
+		}
+	}
+	changes := &Changes{}
+	for path, state := range states {
+		switch state {
+		case journalAdded:
+			changes.Added = append(changes.Added, path)
+		case journalModified:
+			changes.Modified = append(changes.Modified, path)
+		case journalRemoved:
+			changes.Removed = append(changes.Removed, path)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Modified)
+	sort.Strings(changes.Removed)
+	return changes
+}
+
+// ***** PRIVATE *****
+
+type journalState int
+
+const (
+	journalUnchanged journalState = iota
+	journalAdded
+	journalModified
+	journalRemoved
+)
+
+func journalCreate(state journalState) journalState {
+	switch state {
+	case journalUnchanged:
+		return journalAdded
+	case journalRemoved:
+		return journalModified
+	default:
+		return state
+	}
+}
+
+func journalRemove(states map[string]journalState, path string) {
+	if states[path] == journalAdded {
+		delete(states, path)
+		return
+	}
+	states[path] = journalRemoved
+}
+
+func (c *ChangeJournal) run() {
+	defer close(c.done)
+	events := c.watcher.Events()
+	errs := c.watcher.Errors()
+	for events != nil || errs != nil {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			c.record(event)
+		case _, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			// whatever the error, events may have been missed
+			c.record(nil)
+		}
+	}
+}
+
+// record appends an entry for event, or a Lost entry if event is nil.
+func (c *ChangeJournal) record(event *WatchEvent) {
+	entry := &JournalEntry{
+		Time: time.Now().UTC(),
+		Lost: event == nil,
+	}
+	if event != nil {
+		path, ok := c.relPath(event.Path)
+		if !ok {
+			return
+		}
+		entry.Op = event.Op
+		entry.Path = path
+		if event.OldPath != "" {
+			if entry.OldPath, ok = c.relPath(event.OldPath); !ok {
+				// moved in from outside the root
+				entry.Op = WatchOpCreate
+			}
+		}
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry.Seq = c.seq + 1
+	if err := writeJournalEntry(c.file, entry); err != nil {
+		if c.err == nil {
+			c.err = err
+		}
+		return
+	}
+	c.seq = entry.Seq
+}
+
+func (c *ChangeJournal) relPath(path string) (string, bool) {
+	rel, err := filepath.Rel(c.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// writeJournalEntry writes entry as one line in a single write, so
+// concurrent readers never see half an entry followed by more.
+func writeJournalEntry(writer io.Writer, entry *JournalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
+// openChangeJournal opens the journal for appending, first cutting it to
+// size unless size is negative.
+func openChangeJournal(absoluteJournalPath string, size int64) (*os.File, error) {
+	file, err := os.OpenFile(absoluteJournalPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, changeJournalPerm)
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 {
+		if err := file.Truncate(size); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// readChangeJournal returns the entries of the journal and the size of the
+// complete lines. A trailing partial line is a write in progress, or one
+// cut short by a crash, and is ignored.
+func readChangeJournal(absoluteJournalPath string) ([]*JournalEntry, int64, error) {
+	data, err := ioutil.ReadFile(absoluteJournalPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+	var entries []*JournalEntry
+	var size int64
+	for {
+		index := bytes.IndexByte(data, '\n')
+		if index < 0 {
+			break
+		}
+		line := data[:index]
+		data = data[index+1:]
+		size += int64(index + 1)
+		if len(line) == 0 {
+			continue
+		}
+		entry := &JournalEntry{}
+		if err := json.Unmarshal(line, entry); err != nil {
+			return nil, 0, ErrInvalidChangeJournal
+		}
+		entries = append(entries, entry)
+	}
+	return entries, size, nil
+}
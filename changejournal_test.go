@@ -0,0 +1,102 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestChangeJournal() {
+	root := filepath.Join(s.tempDir, "root")
+	journalPath := filepath.Join(s.tempDir, "journal")
+	watcher, send := s.newFakeWatcher()
+	changeJournal, err := StartChangeJournal(watcher, root, journalPath)
+	require.NoError(s.T(), err)
+	send(&WatchEvent{Op: WatchOpCreate, Path: filepath.Join(root, "a")})
+	send(&WatchEvent{Op: WatchOpWrite, Path: filepath.Join(root, "a")})
+	send(&WatchEvent{Op: WatchOpCreate, Path: filepath.Join(root, "tmp")})
+	send(&WatchEvent{Op: WatchOpRemove, Path: filepath.Join(root, "tmp")})
+	send(&WatchEvent{Op: WatchOpCreate, Path: filepath.Join(s.tempDir, "outside")})
+	s.waitForJournalSeq(changeJournal, 4)
+	entries, err := changeJournal.ChangesSince(0)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), entries, 4)
+	require.Equal(s.T(), uint64(1), entries[0].Seq)
+	require.Equal(s.T(), "a", entries[0].Path)
+	require.Equal(s.T(), &Changes{Added: []string{"a"}}, JournalChanges(entries))
+	require.NoError(s.T(), changeJournal.Close())
+
+	// a partial line from a crash is dropped and numbering continues after
+	// a Lost entry for the events missed while closed
+	file, err := os.OpenFile(journalPath, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(s.T(), err)
+	_, err = file.Write([]byte(`{"s":5,`))
+	require.NoError(s.T(), err)
+	s.checkClose(file)
+	watcher, send = s.newFakeWatcher()
+	changeJournal, err = StartChangeJournal(watcher, root, journalPath)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(5), changeJournal.Seq())
+	_, err = ReadChangesSince(journalPath, 4)
+	require.Equal(s.T(), ErrChangesLost, err)
+	send(&WatchEvent{Op: WatchOpRename, Path: filepath.Join(root, "b"), OldPath: filepath.Join(root, "a")})
+	send(&WatchEvent{Op: WatchOpChmod, Path: filepath.Join(root, "c")})
+	s.waitForJournalSeq(changeJournal, 7)
+	entries, err = ReadChangesSince(journalPath, 5)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "a", entries[0].OldPath)
+	require.Equal(s.T(), &Changes{Added: []string{"b"}, Modified: []string{"c"}, Removed: []string{"a"}}, JournalChanges(entries))
+
+	require.NoError(s.T(), changeJournal.Truncate(6))
+	_, err = changeJournal.ChangesSince(5)
+	require.Equal(s.T(), ErrChangesLost, err)
+	entries, err = changeJournal.ChangesSince(6)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), entries, 1)
+	// appends go to the rewritten journal
+	send(&WatchEvent{Op: WatchOpRemove, Path: filepath.Join(root, "c")})
+	s.waitForJournalSeq(changeJournal, 8)
+	entries, err = changeJournal.ChangesSince(6)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), entries, 2)
+
+	// events missed by the watcher make earlier sequence numbers unusable
+	watcher.sendError(ErrWatchOverflow)
+	s.waitForJournalSeq(changeJournal, 9)
+	_, err = changeJournal.ChangesSince(8)
+	require.Equal(s.T(), ErrChangesLost, err)
+	entries, err = changeJournal.ChangesSince(9)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), entries)
+	require.NoError(s.T(), changeJournal.Close())
+
+	watcher, _ = s.newFakeWatcher()
+	_, err = StartChangeJournal(watcher, root, filepath.Join(root, ".journal"))
+	require.Equal(s.T(), ErrChangeJournalInRoot, err)
+	require.NoError(s.T(), watcher.Close())
+
+	require.NoError(s.T(), ioutil.WriteFile(journalPath, []byte("garbage\n"), 0600))
+	_, err = ReadChangesSince(journalPath, 0)
+	require.Equal(s.T(), ErrInvalidChangeJournal, err)
+}
+
+func (s *Suite) newFakeWatcher() (*Watcher, func(*WatchEvent)) {
+	channels := newWatchChannels()
+	channels.run(func() { <-channels.done })
+	send := func(event *WatchEvent) {
+		require.True(s.T(), channels.send(event))
+	}
+	return &Watcher{watchChannels: channels}, send
+}
+
+func (s *Suite) waitForJournalSeq(changeJournal *ChangeJournal, seq uint64) {
+	require.Eventually(
+		s.T(),
+		func() bool { return changeJournal.Seq() == seq },
+		5*time.Second,
+		10*time.Millisecond,
+	)
+}
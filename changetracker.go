@@ -0,0 +1,207 @@
+package osutils
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	manifestPerm = 0644
+)
+
+var (
+	// ErrManifestInRoot means the manifest would be tracked as a change of
+	// its own tree.
+	ErrManifestInRoot = errors.New("osutils: manifest is inside the tracked root")
+)
+
+type ManifestEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Hash    string    `json:"hash"`
+}
+
+// Manifest maps the slash-separated paths of the regular files of a tree,
+// relative to its root, to their metadata and hash.
+type Manifest struct {
+	Entries map[string]*ManifestEntry `json:"entries"`
+}
+
+type Changes struct {
+	Added    []string
+	Modified []string
+	Removed  []string
+}
+
+func (c *Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Modified) == 0 && len(c.Removed) == 0
+}
+
+// ChangeTracker detects changes to the tree at a root against a manifest
+// persisted at a separate path, which must not be inside the root.
+type ChangeTracker struct {
+	root         string
+	manifestPath string
+	current      *Manifest
+}
+
+func NewChangeTracker(absoluteRoot string, absoluteManifestPath string) (*ChangeTracker, error) {
+	if !isAbsolutePath(absoluteRoot) || !isAbsolutePath(absoluteManifestPath) {
+		return nil, ErrNotAbsolutePath
+	}
+	if isWithin(filepath.Clean(absoluteRoot), filepath.Clean(absoluteManifestPath)) {
+		return nil, ErrManifestInRoot
+	}
+	return &ChangeTracker{
+		root:         absoluteRoot,
+		manifestPath: absoluteManifestPath,
+	}, nil
+}
+
+// Changes compares the tree against the persisted manifest, if any. Files
+// are only re-hashed if their size or modification time changed.
+func (c *ChangeTracker) Changes() (*Changes, error) {
+	previous, err := loadManifest(c.manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	current, err := buildManifest(c.root, previous)
+	if err != nil {
+		return nil, err
+	}
+	c.current = current
+	return diffManifests(previous, current), nil
+}
+
+// Commit persists the manifest computed by the last call to Changes, so that
+// the next call to Changes is relative to it.
+func (c *ChangeTracker) Commit() error {
+	if c.current == nil {
+		return ErrNil
+	}
+	return saveManifest(c.manifestPath, c.current)
+}
+
+func LoadManifest(absolutePath string) (*Manifest, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	return loadManifest(absolutePath)
+}
+
+// BuildManifest builds a manifest of the tree at absoluteRoot, reusing the
+// hashes of previous for files whose size and modification time match.
+// previous may be nil.
+func BuildManifest(absoluteRoot string, previous *Manifest) (*Manifest, error) {
+	if !isAbsolutePath(absoluteRoot) {
+		return nil, ErrNotAbsolutePath
+	}
+	return buildManifest(absoluteRoot, previous)
+}
+
+func (m *Manifest) Save(absolutePath string) error {
+	return saveManifest(absolutePath, m)
+}
+
+// ChangedSince returns the changes from previous to m.
+func (m *Manifest) ChangedSince(previous *Manifest) *Changes {
+	return diffManifests(previous, m)
+}
+
+// ***** PRIVATE *****
+
+func loadManifest(absolutePath string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(absolutePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Manifest{Entries: make(map[string]*ManifestEntry)}, nil
+		}
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Entries == nil {
+		manifest.Entries = make(map[string]*ManifestEntry)
+	}
+	return manifest, nil
+}
+
+func saveManifest(absolutePath string, manifest *Manifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(absolutePath, data, manifestPerm)
+}
+
+func buildManifest(root string, previous *Manifest) (*Manifest, error) {
+	manifest := &Manifest{Entries: make(map[string]*ManifestEntry)}
+	if err := filepath.Walk(
+		root,
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			relativePath, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			relativePath = filepath.ToSlash(relativePath)
+			entry := &ManifestEntry{
+				Size:    info.Size(),
+				ModTime: info.ModTime().UTC(),
+			}
+			if previous != nil {
+				if previousEntry, ok := previous.Entries[relativePath]; ok &&
+					previousEntry.Size == entry.Size && previousEntry.ModTime.Equal(entry.ModTime) {
+					entry.Hash = previousEntry.Hash
+				}
+			}
+			if entry.Hash == "" {
+				if entry.Hash, err = hashFile(path); err != nil {
+					return err
+				}
+			}
+			manifest.Entries[relativePath] = entry
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func diffManifests(previous *Manifest, current *Manifest) *Changes {
+	changes := &Changes{}
+	var previousEntries map[string]*ManifestEntry
+	if previous != nil {
+		previousEntries = previous.Entries
+	}
+	for path, entry := range current.Entries {
+		previousEntry, ok := previousEntries[path]
+		if !ok {
+			changes.Added = append(changes.Added, path)
+		} else if previousEntry.Hash != entry.Hash {
+			changes.Modified = append(changes.Modified, path)
+		}
+	}
+	for path := range previousEntries {
+		if _, ok := current.Entries[path]; !ok {
+			changes.Removed = append(changes.Removed, path)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Modified)
+	sort.Strings(changes.Removed)
+	return changes
+}
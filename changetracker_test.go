@@ -0,0 +1,46 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestChangeTracker() {
+	root := filepath.Join(s.tempDir, "root")
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(root, "dir"), 0755))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(root, "one"), []byte("one"), 0644))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(root, "dir/two"), []byte("two"), 0644))
+	changeTracker, err := NewChangeTracker(root, filepath.Join(s.tempDir, "manifest.json"))
+	require.NoError(s.T(), err)
+	changes, err := changeTracker.Changes()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{"dir/two", "one"}, changes.Added)
+	require.NoError(s.T(), changeTracker.Commit())
+
+	changes, err = changeTracker.Changes()
+	require.NoError(s.T(), err)
+	require.True(s.T(), changes.Empty())
+
+	// touching without changing content is not a modification
+	future := time.Now().Add(time.Hour)
+	require.NoError(s.T(), os.Chtimes(filepath.Join(root, "one"), future, future))
+	changes, err = changeTracker.Changes()
+	require.NoError(s.T(), err)
+	require.True(s.T(), changes.Empty())
+
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(root, "dir/two"), []byte("TWO"), 0644))
+	require.NoError(s.T(), os.Remove(filepath.Join(root, "one")))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(root, "three"), []byte("three"), 0644))
+	changes, err = changeTracker.Changes()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{"three"}, changes.Added)
+	require.Equal(s.T(), []string{"dir/two"}, changes.Modified)
+	require.Equal(s.T(), []string{"one"}, changes.Removed)
+
+	_, err = NewChangeTracker(root, filepath.Join(root, "dir", "manifest.json"))
+	require.Equal(s.T(), ErrManifestInRoot, err)
+}
@@ -0,0 +1,174 @@
+package osutils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrInvalidChecksumFile = errors.New("osutils: invalid checksum file")
+
+	checksumFileNames = map[HashAlgorithm]string{
+		HashAlgorithmSHA256: "SHA256SUMS",
+		HashAlgorithmSHA512: "SHA512SUMS",
+		HashAlgorithmSHA1:   "SHA1SUMS",
+		HashAlgorithmMD5:    "MD5SUMS",
+	}
+	// checksumHexSizes infers the algorithm of a manifest with another name.
+	checksumHexSizes = map[int]HashAlgorithm{
+		64:  HashAlgorithmSHA256,
+		128: HashAlgorithmSHA512,
+		40:  HashAlgorithmSHA1,
+		32:  HashAlgorithmMD5,
+	}
+)
+
+// WriteChecksumFile writes a manifest of the regular files under dir, in
+// the format of sha256sum and friends, to the standard file name for
+// hashAlgorithm in dir, such as SHA256SUMS, and returns its path. Paths
+// are relative to dir with forward slashes, in lexical order.
+func WriteChecksumFile(dir string, hashAlgorithm HashAlgorithm) (string, error) {
+	if !isAbsolutePath(dir) {
+		return "", ErrNotAbsolutePath
+	}
+	name, ok := checksumFileNames[hashAlgorithm]
+	if !ok {
+		return "", ErrUnknownHashAlgorithm
+	}
+	dir = filepath.Clean(dir)
+	manifestPath := filepath.Join(dir, name)
+	var lines []string
+	if err := WalkRegularFiles(
+		dir,
+		func(path string, _ fs.DirEntry) error {
+			if path == manifestPath {
+				return nil
+			}
+			checksum, err := hashFileWithAlgorithm(path, hashAlgorithm)
+			if err != nil {
+				return err
+			}
+			relativePath, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, formatChecksumLine(checksum, filepath.ToSlash(relativePath)))
+			return nil
+		},
+	); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(manifestPath, []byte(joinLines(lines)), 0644); err != nil {
+		return "", err
+	}
+	return manifestPath, nil
+}
+
+// VerifyChecksumFile checks the files listed in the manifest at
+// manifestPath, as written by WriteChecksumFile or sha256sum, against
+// their checksums and returns the paths that are missing or differ, in
+// which case the error is ErrChecksumMismatch. Paths are relative to the
+// dir of the manifest and may not point outside it. The algorithm follows
+// from the name of the manifest, or else the size of the checksums.
+func VerifyChecksumFile(manifestPath string) ([]string, error) {
+	if !isAbsolutePath(manifestPath) {
+		return nil, ErrNotAbsolutePath
+	}
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	hashAlgorithm := HashAlgorithm("")
+	for candidate, name := range checksumFileNames {
+		if filepath.Base(manifestPath) == name {
+			hashAlgorithm = candidate
+		}
+	}
+	dir := filepath.Dir(manifestPath)
+	var failed []string
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			checksum, relativePath, parseErr := parseChecksumLine(line)
+			if parseErr != nil {
+				return nil, parseErr
+			}
+			if hashAlgorithm == "" {
+				if hashAlgorithm = checksumHexSizes[len(checksum)]; hashAlgorithm == "" {
+					return nil, ErrInvalidChecksumFile
+				}
+			}
+			ok, verifyErr := verifyChecksum(dir, relativePath, checksum, hashAlgorithm)
+			if verifyErr != nil {
+				return nil, verifyErr
+			}
+			if !ok {
+				failed = append(failed, relativePath)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(failed) > 0 {
+		return failed, ErrChecksumMismatch
+	}
+	return nil, nil
+}
+
+// ***** PRIVATE *****
+
+// formatChecksumLine escapes names with backslashes, newlines or carriage
+// returns as the coreutils tools do, marking the line with a leading
+// backslash. Other bytes, including invalid UTF-8, are written as is.
+func formatChecksumLine(checksum string, name string) string {
+	if !strings.ContainsAny(name, "\\\n\r") {
+		return fmt.Sprintf("%s  %s", checksum, name)
+	}
+	name = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`).Replace(name)
+	return fmt.Sprintf("\\%s  %s", checksum, name)
+}
+
+func parseChecksumLine(line string) (string, string, error) {
+	escaped := strings.HasPrefix(line, `\`)
+	if escaped {
+		line = line[1:]
+	}
+	i := strings.IndexByte(line, ' ')
+	// the separator is a space and then a space for text mode or * for
+	// binary mode
+	if i <= 0 || i+2 > len(line) || (line[i+1] != ' ' && line[i+1] != '*') {
+		return "", "", ErrInvalidChecksumFile
+	}
+	checksum, name := strings.ToLower(line[:i]), line[i+2:]
+	if escaped {
+		name = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r").Replace(name)
+	}
+	return checksum, name, nil
+}
+
+func verifyChecksum(dir string, relativePath string, checksum string, hashAlgorithm HashAlgorithm) (bool, error) {
+	path, err := safeJoin(dir, filepath.FromSlash(relativePath))
+	if err != nil {
+		return false, err
+	}
+	actual, err := hashFileWithAlgorithm(path, hashAlgorithm)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return actual == checksum, nil
+}
@@ -0,0 +1,52 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestChecksumFile() {
+	src := s.writeCopyDirSrc()
+	manifestPath, err := WriteChecksumFile(src, HashAlgorithmSHA256)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), filepath.Join(src, "SHA256SUMS"), manifestPath)
+	data, err := ioutil.ReadFile(manifestPath)
+	require.NoError(s.T(), err)
+	// sha256sum output for the same files
+	require.Equal(
+		s.T(),
+		"6b86b273ff34fce19d6b804eff5a3f5747ada4eaa22f1d49c01e52ddb7875b4b  a/1\n"+
+			"d4735e3a265e16eee03f59718b9b5d03019c07d8b6c51f90da3a666eec13ab35  b/2\n",
+		string(data),
+	)
+
+	failed, err := VerifyChecksumFile(manifestPath)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), failed)
+
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(src, "a", "1"), []byte("changed"), 0600))
+	require.NoError(s.T(), os.Remove(filepath.Join(src, "b", "2")))
+	failed, err = VerifyChecksumFile(manifestPath)
+	require.Equal(s.T(), ErrChecksumMismatch, err)
+	require.Equal(s.T(), []string{"a/1", "b/2"}, failed)
+
+	other := filepath.Join(src, "checksums.txt")
+	require.NoError(s.T(), ioutil.WriteFile(other, []byte("c4ca4238a0b923820dcc509a6f75849b *../escape\n"), 0644))
+	_, err = VerifyChecksumFile(other)
+	require.Equal(s.T(), ErrPathOutsideDir, err)
+	require.NoError(s.T(), ioutil.WriteFile(other, []byte("not a checksum line\n"), 0644))
+	_, err = VerifyChecksumFile(other)
+	require.Equal(s.T(), ErrInvalidChecksumFile, err)
+}
+
+func (s *Suite) TestChecksumLineEscaping() {
+	line := formatChecksumLine("abc", "a\\b\nc")
+	require.Equal(s.T(), `\abc  a\\b\nc`, line)
+	checksum, name, err := parseChecksumLine(line)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "abc", checksum)
+	require.Equal(s.T(), "a\\b\nc", name)
+}
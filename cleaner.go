@@ -0,0 +1,182 @@
+package osutils
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	CleanReasonAge   CleanReason = "age"
+	CleanReasonCount CleanReason = "count"
+	CleanReasonSize  CleanReason = "size"
+)
+
+// CleanReason is the retention rule a file was removed for.
+type CleanReason string
+
+// Cleaner removes files from a directory according to retention rules, for
+// keeping cache and log directories in check. Files are considered newest
+// first by modification time, and a file is removed if it is older than
+// MaxAge, or if keeping it would exceed MaxCount or MaxTotalSize. Once
+// MaxCount or MaxTotalSize is reached every older file is removed too, so
+// it is always the oldest files that go. Rules left at 0 do not apply.
+type Cleaner struct {
+	MaxAge       time.Duration
+	MaxCount     int
+	MaxTotalSize int64
+	// Pattern restricts the files considered to those whose base name
+	// matches, see filepath.Match. Other files are neither removed nor
+	// counted.
+	Pattern string
+	// Recursive also considers the files in subdirectories, the
+	// directories themselves are left in place.
+	Recursive bool
+	// DryRun reports what would be removed without removing it.
+	DryRun bool
+	// Writer, if set, receives a line per file removed.
+	Writer io.Writer
+}
+
+type CleanedFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Reason  CleanReason
+}
+
+type CleanReport struct {
+	// Removed is what was removed, or would be with DryRun, oldest last.
+	Removed      []*CleanedFile
+	RemovedBytes int64
+	Kept         int
+	KeptBytes    int64
+}
+
+// Clean applies the rules to the files in absoluteDir. The report is
+// returned even on error, covering what was done until then.
+func (c *Cleaner) Clean(absoluteDir string) (*CleanReport, error) {
+	if !isAbsolutePath(absoluteDir) {
+		return nil, ErrNotAbsolutePath
+	}
+	if err := checkGuard(absoluteDir); err != nil {
+		return nil, err
+	}
+	if c.Pattern != "" {
+		if _, err := filepath.Match(c.Pattern, ""); err != nil {
+			return nil, err
+		}
+	}
+	cleanedFiles, err := c.listFiles(absoluteDir)
+	if err != nil {
+		return nil, err
+	}
+	// newest first
+	sort.SliceStable(
+		cleanedFiles,
+		func(i int, j int) bool {
+			return cleanedFiles[i].ModTime.After(cleanedFiles[j].ModTime)
+		},
+	)
+	cleanReport := &CleanReport{}
+	now := time.Now()
+	// once the count or size is reached, a smaller older file must not be
+	// kept in place of the newer one that was removed
+	var tripped CleanReason
+	for _, cleanedFile := range cleanedFiles {
+		switch {
+		case c.MaxAge > 0 && now.Sub(cleanedFile.ModTime) > c.MaxAge:
+			cleanedFile.Reason = CleanReasonAge
+		case tripped != "":
+			cleanedFile.Reason = tripped
+		case c.MaxCount > 0 && cleanReport.Kept >= c.MaxCount:
+			cleanedFile.Reason = CleanReasonCount
+			tripped = CleanReasonCount
+		case c.MaxTotalSize > 0 && cleanReport.KeptBytes+cleanedFile.Size > c.MaxTotalSize:
+			cleanedFile.Reason = CleanReasonSize
+			tripped = CleanReasonSize
+		default:
+			cleanReport.Kept++
+			cleanReport.KeptBytes += cleanedFile.Size
+			continue
+		}
+		if err := c.remove(cleanedFile); err != nil {
+			return cleanReport, err
+		}
+		cleanReport.Removed = append(cleanReport.Removed, cleanedFile)
+		cleanReport.RemovedBytes += cleanedFile.Size
+	}
+	return cleanReport, nil
+}
+
+// ***** PRIVATE *****
+
+func (c *Cleaner) listFiles(absoluteDir string) ([]*CleanedFile, error) {
+	var cleanedFiles []*CleanedFile
+	add := func(path string, info os.FileInfo) error {
+		if c.Pattern != "" {
+			if matched, _ := filepath.Match(c.Pattern, info.Name()); !matched {
+				return nil
+			}
+		}
+		cleanedFiles = append(
+			cleanedFiles,
+			&CleanedFile{
+				Path:    path,
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+			},
+		)
+		return nil
+	}
+	if c.Recursive {
+		if err := WalkRegularFiles(
+			absoluteDir,
+			func(path string, dirEntry fs.DirEntry) error {
+				info, err := dirEntry.Info()
+				if err != nil {
+					if os.IsNotExist(err) {
+						return nil
+					}
+					return err
+				}
+				return add(path, info)
+			},
+		); err != nil {
+			return nil, err
+		}
+		return cleanedFiles, nil
+	}
+	fileInfos, err := ioutil.ReadDir(absoluteDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, fileInfo := range fileInfos {
+		if fileInfo.Mode().IsRegular() {
+			if err := add(filepath.Join(absoluteDir, fileInfo.Name()), fileInfo); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return cleanedFiles, nil
+}
+
+func (c *Cleaner) remove(cleanedFile *CleanedFile) error {
+	verb := "would remove"
+	if !c.DryRun {
+		verb = "removed"
+		if err := os.Remove(cleanedFile.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if c.Writer == nil {
+		return nil
+	}
+	_, err := fmt.Fprintf(c.Writer, "%s %s (%s, %d bytes)\n", verb, QuotePath(cleanedFile.Path), cleanedFile.Reason, cleanedFile.Size)
+	return err
+}
@@ -0,0 +1,80 @@
+package osutils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCleaner() {
+	dir := filepath.Join(s.tempDir, "cache")
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	now := time.Now()
+	// 0 is the newest
+	for i, name := range []string{"0.log", "1.log", filepath.Join("sub", "2.log"), "3.log", "keep.txt"} {
+		path := filepath.Join(dir, name)
+		require.NoError(s.T(), ioutil.WriteFile(path, bytes.Repeat([]byte("x"), 10), 0644))
+		modTime := now.Add(-time.Duration(i) * time.Hour)
+		require.NoError(s.T(), os.Chtimes(path, modTime, modTime))
+	}
+
+	var report bytes.Buffer
+	cleaner := &Cleaner{
+		MaxCount:  2,
+		Pattern:   "*.log",
+		Recursive: true,
+		DryRun:    true,
+		Writer:    &report,
+	}
+	cleanReport, err := cleaner.Clean(dir)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), cleanReport.Removed, 2)
+	require.Equal(s.T(), filepath.Join(dir, "sub", "2.log"), cleanReport.Removed[0].Path)
+	require.Equal(s.T(), CleanReasonCount, cleanReport.Removed[0].Reason)
+	require.Equal(s.T(), int64(20), cleanReport.RemovedBytes)
+	require.Equal(s.T(), 2, cleanReport.Kept)
+	require.Equal(s.T(), 2, strings.Count(report.String(), "would remove"))
+	s.checkFileExists(filepath.Join(dir, "3.log"))
+
+	cleanReport, err = (&Cleaner{MaxAge: 150 * time.Minute, MaxTotalSize: 15}).Clean(dir)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), cleanReport.Removed, 3)
+	require.Equal(s.T(), CleanReasonSize, cleanReport.Removed[0].Reason)
+	require.Equal(s.T(), CleanReasonAge, cleanReport.Removed[1].Reason)
+	s.checkFileExists(filepath.Join(dir, "0.log"))
+	s.checkFileExists(filepath.Join(dir, "sub", "2.log"))
+	s.checkFileDoesNotExist(filepath.Join(dir, "1.log"))
+	s.checkFileDoesNotExist(filepath.Join(dir, "3.log"))
+	s.checkFileDoesNotExist(filepath.Join(dir, "keep.txt"))
+
+	_, err = (&Cleaner{}).Clean("relative")
+	require.Equal(s.T(), ErrNotAbsolutePath, err)
+}
+
+func (s *Suite) TestCleanerSizeRemovesOlder() {
+	dir := filepath.Join(s.tempDir, "cache")
+	require.NoError(s.T(), os.MkdirAll(dir, 0755))
+	now := time.Now()
+	// 0 is the newest, 2 is small enough to fit once 1 is removed
+	for i, size := range []int{10, 10, 2} {
+		path := filepath.Join(dir, strconv.Itoa(i))
+		require.NoError(s.T(), ioutil.WriteFile(path, bytes.Repeat([]byte("x"), size), 0644))
+		modTime := now.Add(-time.Duration(i) * time.Hour)
+		require.NoError(s.T(), os.Chtimes(path, modTime, modTime))
+	}
+
+	cleanReport, err := (&Cleaner{MaxTotalSize: 15}).Clean(dir)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), cleanReport.Removed, 2)
+	require.Equal(s.T(), CleanReasonSize, cleanReport.Removed[1].Reason)
+	require.Equal(s.T(), 1, cleanReport.Kept)
+	s.checkFileExists(filepath.Join(dir, "0"))
+	s.checkFileDoesNotExist(filepath.Join(dir, "1"))
+	s.checkFileDoesNotExist(filepath.Join(dir, "2"))
+}
@@ -0,0 +1,131 @@
+package osutils
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Cleanup is a stack of teardown functions for unwinding multi-step
+// operations. Its methods such as NewTempDir and Execute do the same as
+// the package functions and register the teardown of what they create.
+//
+//	cleanup := NewCleanup()
+//	defer cleanup.Run()
+type Cleanup struct {
+	fns  []func() error
+	lock sync.Mutex
+}
+
+func NewCleanup() *Cleanup {
+	return &Cleanup{}
+}
+
+// Add pushes fn onto the stack.
+func (c *Cleanup) Add(fn func() error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.fns = append(c.fns, fn)
+}
+
+// AddPath pushes the removal of absolutePath and everything under it. The
+// package Guard applies.
+func (c *Cleanup) AddPath(absolutePath string) {
+	c.Add(
+		func() error {
+			return removeAll(absolutePath)
+		},
+	)
+}
+
+// Run calls the functions on the stack in reverse order of adding, even if
+// some fail, and empties it. The errors are joined with errors.Join.
+func (c *Cleanup) Run() error {
+	c.lock.Lock()
+	fns := c.fns
+	c.fns = nil
+	c.lock.Unlock()
+	var errs []error
+	for i := len(fns) - 1; i >= 0; i-- {
+		if err := fns[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewTempDir is NewTempDir, removing the dir on Run.
+func (c *Cleanup) NewTempDir() (string, error) {
+	tempDir, err := newTempDir()
+	if err != nil {
+		return "", err
+	}
+	c.AddPath(tempDir)
+	return tempDir, nil
+}
+
+// NewTempSubDir is NewTempSubDir, removing the dir on Run.
+func (c *Cleanup) NewTempSubDir(absoluteBaseDirPath string) (string, error) {
+	subDir, err := newTempSubDir(absoluteBaseDirPath)
+	if err != nil {
+		return "", err
+	}
+	c.AddPath(subDir)
+	return subDir, nil
+}
+
+// NewTempDirTmpfs is NewTempDirTmpfs, closing the TmpfsDir on Run.
+func (c *Cleanup) NewTempDirTmpfs(sizeLimit int64) (*TmpfsDir, error) {
+	tmpfsDir, err := NewTempDirTmpfs(sizeLimit)
+	if err != nil {
+		return nil, err
+	}
+	c.Add(tmpfsDir.Close)
+	return tmpfsDir, nil
+}
+
+// NewWorkspace is NewWorkspace, closing the Workspace on Run.
+func (c *Cleanup) NewWorkspace() (*Workspace, error) {
+	workspace, err := NewWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	c.Add(workspace.Close)
+	return workspace, nil
+}
+
+// Execute is Execute, killing the command on Run if it has not been
+// waited for. The returned wait function may be called any number of
+// times.
+func (c *Cleanup) Execute(cmd *Cmd) (func() error, error) {
+	process, err := startCmd(context.Background(), cmd)
+	if err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	var once sync.Once
+	var waitErr error
+	wait := func() error {
+		once.Do(
+			func() {
+				waitErr = process.wait()
+				close(done)
+			},
+		)
+		return waitErr
+	}
+	c.Add(
+		func() error {
+			select {
+			case <-done:
+				return nil
+			default:
+			}
+			_ = process.execCmd.Process.Kill()
+			// the command failing because it was killed is expected
+			_ = wait()
+			return nil
+		},
+	)
+	return wait, nil
+}
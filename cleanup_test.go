@@ -0,0 +1,52 @@
+package osutils
+
+import (
+	"errors"
+	"runtime"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCleanup() {
+	cleanup := NewCleanup()
+	var order []int
+	errFirst := errors.New("first")
+	cleanup.Add(func() error {
+		order = append(order, 1)
+		return errFirst
+	})
+	tempDir, err := cleanup.NewTempDir()
+	require.NoError(s.T(), err)
+	subDir, err := cleanup.NewTempSubDir(tempDir)
+	require.NoError(s.T(), err)
+	cleanup.Add(func() error {
+		order = append(order, 2)
+		s.checkFileExists(subDir)
+		return nil
+	})
+
+	err = cleanup.Run()
+	require.True(s.T(), errors.Is(err, errFirst))
+	require.Equal(s.T(), []int{2, 1}, order)
+	s.checkFileDoesNotExist(tempDir)
+	require.NoError(s.T(), cleanup.Run())
+}
+
+func (s *Suite) TestCleanupExecute() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sleep not available on windows")
+	}
+	cleanup := NewCleanup()
+	wait, err := cleanup.Execute(&Cmd{Args: []string{"sleep", "60"}})
+	require.NoError(s.T(), err)
+	start := time.Now()
+	require.NoError(s.T(), cleanup.Run())
+	require.True(s.T(), time.Since(start) < 30*time.Second)
+	require.Error(s.T(), wait())
+
+	wait, err = cleanup.Execute(&Cmd{Args: []string{"true"}})
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), wait())
+	require.NoError(s.T(), cleanup.Run())
+}
@@ -0,0 +1,245 @@
+package osutils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	cmdLogTimeFormat = "20060102T150405.000000000"
+	cmdLogSuffix     = ".log"
+	cmdLogPerm       = 0644
+)
+
+// LogRotation controls the log files written for Cmd.LogDir.
+type LogRotation struct {
+	// MaxSize rotates a log file to a numbered file once it would exceed
+	// this many bytes, if non-zero.
+	MaxSize int64
+	// MaxFiles and MaxAge prune the logs of the oldest runs of the same
+	// command name in the dir after each run, if non-zero. MaxFiles counts
+	// runs, each with its stdout and stderr logs, and the logs of the run
+	// that just finished are always kept.
+	MaxFiles int
+	MaxAge   time.Duration
+}
+
+// ***** PRIVATE *****
+
+func setupCmdLogs(process *process, cmd *Cmd) error {
+	logRotation := cmd.LogRotation
+	if logRotation == nil {
+		logRotation = &LogRotation{}
+	}
+	if err := os.MkdirAll(cmd.LogDir, 0755); err != nil {
+		return err
+	}
+	name := cmdLogName(cmd.Args[0])
+	run := time.Now().Format(cmdLogTimeFormat)
+	base := filepath.Join(cmd.LogDir, name+"-"+run)
+	prune := func() error {
+		return pruneCmdLogs(cmd.LogDir, name, run, logRotation)
+	}
+	if process.combined != nil {
+		combined, err := newRotatingFile(base+".combined"+cmdLogSuffix, logRotation.MaxSize)
+		if err != nil {
+			return err
+		}
+		process.cleanups = append(process.cleanups, combined.Close, prune)
+		process.combined = teeWriter(nil, process.combined, combined)
+		process.logPaths = combined.Paths
+		return nil
+	}
+	stdout, err := newRotatingFile(base+".stdout"+cmdLogSuffix, logRotation.MaxSize)
+	if err != nil {
+		return err
+	}
+	process.cleanups = append(process.cleanups, stdout.Close)
+	stderr, err := newRotatingFile(base+".stderr"+cmdLogSuffix, logRotation.MaxSize)
+	if err != nil {
+		return err
+	}
+	process.cleanups = append(
+		process.cleanups,
+		stderr.Close,
+		prune,
+	)
+	// teeing hides a shared writer from os/exec and from the later setups
+	execStdout, execStderr := lockIfShared(process.execCmd.Stdout, process.execCmd.Stderr)
+	process.execCmd.Stdout = teeWriter(nil, execStdout, stdout)
+	process.execCmd.Stderr = teeWriter(nil, execStderr, stderr)
+	process.logPaths = func() []string {
+		return append(stdout.Paths(), stderr.Paths()...)
+	}
+	return nil
+}
+
+// cmdLogName is the base name of the command, restricted to characters that
+// are safe in file names.
+func cmdLogName(arg string) string {
+	return strings.Map(
+		func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+				return r
+			}
+			return '_'
+		},
+		filepath.Base(arg),
+	)
+}
+
+// pruneCmdLogs removes the logs of old runs of name, keeping those of the
+// current run. The logs of a run are name-<run>.<stream>.log and their
+// rotated files, where run is the start time, so runs sort by name.
+func pruneCmdLogs(dir string, name string, currentRun string, logRotation *LogRotation) error {
+	if logRotation.MaxFiles <= 0 && logRotation.MaxAge <= 0 {
+		return nil
+	}
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	runToFileInfos := make(map[string][]os.FileInfo)
+	runToModTime := make(map[string]time.Time)
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.Mode().IsRegular() {
+			continue
+		}
+		run, ok := cmdLogRun(fileInfo.Name(), name)
+		if !ok {
+			continue
+		}
+		runToFileInfos[run] = append(runToFileInfos[run], fileInfo)
+		if fileInfo.ModTime().After(runToModTime[run]) {
+			runToModTime[run] = fileInfo.ModTime()
+		}
+	}
+	runs := make([]string, 0, len(runToFileInfos))
+	for run := range runToFileInfos {
+		runs = append(runs, run)
+	}
+	// newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(runs)))
+	now := time.Now()
+	kept := 0
+	for _, run := range runs {
+		if run == currentRun {
+			kept++
+			continue
+		}
+		if (logRotation.MaxFiles <= 0 || kept < logRotation.MaxFiles) &&
+			(logRotation.MaxAge <= 0 || now.Sub(runToModTime[run]) <= logRotation.MaxAge) {
+			kept++
+			continue
+		}
+		for _, fileInfo := range runToFileInfos[run] {
+			if err := os.Remove(filepath.Join(dir, fileInfo.Name())); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cmdLogRun returns the run of a log file of name, if it is one.
+func cmdLogRun(fileName string, name string) (string, bool) {
+	if !strings.HasPrefix(fileName, name+"-") {
+		return "", false
+	}
+	rest := strings.TrimPrefix(fileName, name+"-")
+	if len(rest) <= len(cmdLogTimeFormat) {
+		return "", false
+	}
+	run := rest[:len(cmdLogTimeFormat)]
+	if _, err := time.Parse(cmdLogTimeFormat, run); err != nil {
+		return "", false
+	}
+	rest = rest[len(cmdLogTimeFormat):]
+	if !strings.HasPrefix(rest, ".") || !strings.Contains(rest, cmdLogSuffix) {
+		return "", false
+	}
+	return run, true
+}
+
+// rotatingFile writes to path, moving the current file to path.N and
+// starting a new one whenever maxSize would be exceeded.
+type rotatingFile struct {
+	path     string
+	maxSize  int64
+	file     *os.File
+	size     int64
+	rotated  []string
+	lock     sync.Mutex
+	closeErr error
+}
+
+func newRotatingFile(path string, maxSize int64) (*rotatingFile, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, cmdLogPerm)
+	if err != nil {
+		return nil, err
+	}
+	return &rotatingFile{
+		path:    path,
+		maxSize: maxSize,
+		file:    file,
+	}, nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.file == nil {
+		return r.closeErr
+	}
+	r.closeErr = r.file.Close()
+	r.file = nil
+	return r.closeErr
+}
+
+// Paths returns the rotated files oldest first, followed by the current file.
+func (r *rotatingFile) Paths() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append(append([]string{}, r.rotated...), r.path)
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	rotatedPath := fmt.Sprintf("%s.%d", r.path, len(r.rotated)+1)
+	if err := os.Rename(r.path, rotatedPath); err != nil {
+		return err
+	}
+	r.rotated = append(r.rotated, rotatedPath)
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, cmdLogPerm)
+	if err != nil {
+		r.file = nil
+		return err
+	}
+	r.file = file
+	r.size = 0
+	return nil
+}
@@ -0,0 +1,92 @@
+package osutils
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCmdLogDir() {
+	logDir := filepath.Join(s.tempDir, "logs")
+	result, err := NewCommand("bash", "-c", "echo one; sleep 0.1; echo two; echo err >&2").
+		LogDir(logDir, &LogRotation{MaxSize: 4}).
+		Run(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "one\ntwo\n", result.Stdout)
+	require.Equal(s.T(), 3, len(result.LogPaths))
+	var stdout []string
+	for _, logPath := range result.LogPaths[:2] {
+		require.True(s.T(), strings.Contains(logPath, ".stdout.log"))
+		data, err := ioutil.ReadFile(logPath)
+		require.NoError(s.T(), err)
+		stdout = append(stdout, string(data))
+	}
+	require.Equal(s.T(), []string{"one\n", "two\n"}, stdout)
+	data, err := ioutil.ReadFile(result.LogPaths[2])
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "err\n", string(data))
+	// the logs of another command whose name starts the same are not pruned
+	other := filepath.Join(logDir, "true-docs-"+time.Now().Format(cmdLogTimeFormat)+".stdout"+cmdLogSuffix)
+	require.NoError(s.T(), ioutil.WriteFile(other, nil, 0644))
+	for i := 0; i < 3; i++ {
+		_, err = NewCommand("true").LogDir(logDir, &LogRotation{MaxFiles: 2}).Run(context.Background())
+		require.NoError(s.T(), err)
+	}
+	// two runs of two logs each, plus the other command
+	matches, err := filepath.Glob(filepath.Join(logDir, "true-*"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 5, len(matches))
+	s.checkFileExists(other)
+	// the current run is kept even if it is too old already
+	result, err = NewCommand("true").LogDir(logDir, &LogRotation{MaxFiles: 1, MaxAge: time.Nanosecond}).Run(context.Background())
+	require.NoError(s.T(), err)
+	for _, logPath := range result.LogPaths {
+		s.checkFileExists(logPath)
+	}
+	matches, err = filepath.Glob(filepath.Join(logDir, "true-2*"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 2, len(matches))
+}
+
+func (s *Suite) TestCmdLogDirSharedWriter() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sh not available on windows")
+	}
+	writer := &overlapWriter{}
+	wait, err := Execute(
+		&Cmd{
+			Args:   []string{"sh", "-c", "for i in 1 2 3 4 5 6 7 8 9 10; do echo out; echo err >&2; done"},
+			Stdout: writer,
+			Stderr: writer,
+			LogDir: filepath.Join(s.tempDir, "logs"),
+		},
+	)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), wait())
+	require.False(s.T(), writer.overlapped.Load())
+	require.Equal(s.T(), int64(80), writer.written.Load())
+}
+
+// overlapWriter records whether two writes were ever in progress at once.
+type overlapWriter struct {
+	active     atomic.Int32
+	overlapped atomic.Bool
+	written    atomic.Int64
+}
+
+func (o *overlapWriter) Write(p []byte) (int, error) {
+	if o.active.Add(1) > 1 {
+		o.overlapped.Store(true)
+	}
+	defer o.active.Add(-1)
+	// long enough for the other stream to catch up
+	time.Sleep(time.Millisecond)
+	o.written.Add(int64(len(p)))
+	return len(p), nil
+}
@@ -0,0 +1,94 @@
+package osutils
+
+import (
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// The codecs below are PipeFuncs, so they can be used as stages of a
+// pipeline without relying on gzip, zstd, xz or base64 binaries on the host,
+// for example:
+//
+//	err := NewPipeline().Cmd("tar", "-cf", "-", "dir").Func(ZstdCompress).Stdout(file).Run(ctx)
+
+func GzipCompress(reader io.Reader, writer io.Writer) error {
+	return compress(gzip.NewWriter(writer), reader)
+}
+
+func GzipDecompress(reader io.Reader, writer io.Writer) error {
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return err
+	}
+	return decompress(gzipReader, writer)
+}
+
+func ZstdCompress(reader io.Reader, writer io.Writer) error {
+	zstdWriter, err := zstd.NewWriter(writer)
+	if err != nil {
+		return err
+	}
+	return compress(zstdWriter, reader)
+}
+
+func ZstdDecompress(reader io.Reader, writer io.Writer) error {
+	zstdReader, err := zstd.NewReader(reader)
+	if err != nil {
+		return err
+	}
+	defer zstdReader.Close()
+	_, err = io.Copy(writer, zstdReader)
+	return err
+}
+
+func XzCompress(reader io.Reader, writer io.Writer) error {
+	xzWriter, err := xz.NewWriter(writer)
+	if err != nil {
+		return err
+	}
+	return compress(xzWriter, reader)
+}
+
+func XzDecompress(reader io.Reader, writer io.Writer) error {
+	xzReader, err := xz.NewReader(reader)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, xzReader)
+	return err
+}
+
+// Base64Encode writes standard base64 with padding and no line breaks.
+func Base64Encode(reader io.Reader, writer io.Writer) error {
+	return compress(base64.NewEncoder(base64.StdEncoding, writer), reader)
+}
+
+// Base64Decode reads standard base64 with padding, ignoring line breaks.
+func Base64Decode(reader io.Reader, writer io.Writer) error {
+	_, err := io.Copy(writer, base64.NewDecoder(base64.StdEncoding, reader))
+	return err
+}
+
+// ***** PRIVATE *****
+
+// compress copies reader into writeCloser, and closes it to flush the
+// trailer even if the copy failed.
+func compress(writeCloser io.WriteCloser, reader io.Reader) error {
+	_, err := io.Copy(writeCloser, reader)
+	if closeErr := writeCloser.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func decompress(readCloser io.ReadCloser, writer io.Writer) error {
+	_, err := io.Copy(writer, readCloser)
+	if closeErr := readCloser.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package osutils
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCodecs() {
+	input := strings.Repeat("hello osutils\n", 1000)
+	for _, codec := range [][2]PipeFunc{
+		{GzipCompress, GzipDecompress},
+		{ZstdCompress, ZstdDecompress},
+		{XzCompress, XzDecompress},
+		{Base64Encode, Base64Decode},
+	} {
+		var encoded bytes.Buffer
+		require.NoError(s.T(), codec[0](strings.NewReader(input), &encoded))
+		require.NotEqual(s.T(), input, encoded.String())
+		var decoded bytes.Buffer
+		require.NoError(s.T(), codec[1](&encoded, &decoded))
+		require.Equal(s.T(), input, decoded.String())
+	}
+	output, err := NewPipeline().Func(ZstdCompress).Func(ZstdDecompress).StdinString(input).Output(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), input, output)
+	if runtime.GOOS != "windows" {
+		output, err = NewPipeline().Cmd("cat").Func(GzipCompress).Cmd("cat").Func(GzipDecompress).StdinString(input).Output(context.Background())
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), input, output)
+	}
+	require.Error(s.T(), GzipDecompress(strings.NewReader("not gzip"), &bytes.Buffer{}))
+}
@@ -0,0 +1,256 @@
+package osutils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTimeout = errors.New("osutils: timeout")
+)
+
+// Result is the outcome of a finished command.
+type Result struct {
+	Args   []string
+	Stdout string
+	Stderr string
+	// Combined is set instead of Stdout and Stderr if the command was run
+	// with combined output.
+	Combined string
+	ExitCode int
+	Start    time.Time
+	Duration time.Duration
+	// LogPaths are the log files written if Cmd.LogDir was set.
+	LogPaths []string
+	// Usage is what was sampled if Cmd.Monitor was set.
+	Usage *ResourceUsage
+}
+
+// Command builds and runs a Cmd, for example:
+//
+//	result, err := NewCommand("git", "status").Dir(dir).Timeout(30*time.Second).Run(ctx)
+//
+// Stdout and stderr are always captured into the Result, and additionally
+// copied to any writers set with Stdout and Stderr.
+type Command struct {
+	cmd      *Cmd
+	env      map[string]string
+	combined bool
+}
+
+func NewCommand(args ...string) *Command {
+	return &Command{
+		cmd: &Cmd{
+			Args: args,
+		},
+		env: make(map[string]string),
+	}
+}
+
+func (c *Command) Dir(absoluteDir string) *Command {
+	c.cmd.AbsoluteDir = absoluteDir
+	return c
+}
+
+// Env sets an environment variable on top of the inherited environment.
+func (c *Command) Env(key string, value string) *Command {
+	c.env[key] = value
+	return c
+}
+
+func (c *Command) Stdin(stdin io.Reader) *Command {
+	c.cmd.Stdin = stdin
+	return c
+}
+
+func (c *Command) StdinString(stdin string) *Command {
+	return c.Stdin(strings.NewReader(stdin))
+}
+
+func (c *Command) Stdout(stdout io.Writer) *Command {
+	c.cmd.Stdout = stdout
+	return c
+}
+
+func (c *Command) Stderr(stderr io.Writer) *Command {
+	c.cmd.Stderr = stderr
+	return c
+}
+
+// Combined captures stdout and stderr as a single stream in
+// Result.Combined, preserving their interleaving, see Cmd.CombinedOutput.
+// A non-nil writer additionally receives the output.
+func (c *Command) Combined(writer io.Writer) *Command {
+	c.combined = true
+	c.cmd.CombinedOutput = writer
+	return c
+}
+
+func (c *Command) LogDir(absoluteDir string, logRotation *LogRotation) *Command {
+	c.cmd.LogDir = absoluteDir
+	c.cmd.LogRotation = logRotation
+	return c
+}
+
+func (c *Command) Heartbeat(heartbeat *Heartbeat) *Command {
+	c.cmd.Heartbeat = heartbeat
+	return c
+}
+
+func (c *Command) Monitor(monitor *Monitor) *Command {
+	c.cmd.Monitor = monitor
+	return c
+}
+
+func (c *Command) Transcript(transcript *Transcript) *Command {
+	c.cmd.Transcript = transcript
+	return c
+}
+
+func (c *Command) Timeout(timeout time.Duration) *Command {
+	c.cmd.Timeout = timeout
+	return c
+}
+
+// Cmd returns the underlying Cmd, with the environment resolved.
+func (c *Command) Cmd() *Cmd {
+	cmd := *c.cmd
+	if len(c.env) > 0 {
+		cmd.Env = mergeEnv(os.Environ(), c.env)
+	}
+	return &cmd
+}
+
+// Run runs the command to completion. The Result is returned even if the
+// command fails, in which case the error is the exec error, or ErrTimeout
+// if the timeout was hit.
+func (c *Command) Run(ctx context.Context) (*Result, error) {
+	cmd := c.Cmd()
+	if c.combined && cmd.CombinedOutput == nil {
+		cmd.CombinedOutput = ioutil.Discard
+	}
+	return runCmd(ctx, cmd)
+}
+
+// ***** PRIVATE *****
+
+func runCmd(ctx context.Context, cmd *Cmd) (*Result, error) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	var combined bytes.Buffer
+	runCmd := *cmd
+	if cmd.CombinedOutput != nil {
+		runCmd.CombinedOutput = teeWriter(&combined, cmd.CombinedOutput)
+	} else {
+		stdoutWriter, stderrWriter := lockIfShared(cmd.Stdout, cmd.Stderr)
+		runCmd.Stdout = teeWriter(&stdout, stdoutWriter)
+		runCmd.Stderr = teeWriter(&stderr, stderrWriter)
+	}
+	result := &Result{
+		Args:  cmd.Args,
+		Start: time.Now(),
+	}
+	process, err := startCmd(ctx, &runCmd)
+	if err != nil {
+		return nil, err
+	}
+	err = process.wait()
+	result.Duration = time.Since(result.Start)
+	if process.logPaths != nil {
+		result.LogPaths = process.logPaths()
+	}
+	result.Usage = process.usage
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	result.Combined = combined.String()
+	result.ExitCode = exitCode(err)
+	return result, err
+}
+
+// teeWriter returns a writer writing to all non-nil writers.
+func teeWriter(buffer *bytes.Buffer, writers ...io.Writer) io.Writer {
+	var nonNil []io.Writer
+	if buffer != nil {
+		nonNil = append(nonNil, buffer)
+	}
+	for _, writer := range writers {
+		if writer != nil {
+			nonNil = append(nonNil, writer)
+		}
+	}
+	switch len(nonNil) {
+	case 0:
+		return nil
+	case 1:
+		return nonNil[0]
+	default:
+		return io.MultiWriter(nonNil...)
+	}
+}
+
+// lockedWriter serializes the writes to writer, for a writer shared by
+// output that is copied concurrently.
+type lockedWriter struct {
+	writer io.Writer
+	lock   sync.Mutex
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.writer.Write(p)
+}
+
+// lockIfShared wraps stdout and stderr in one lockedWriter if they are the
+// same writer. os/exec only serializes the two streams while it can see
+// they are the same, which it no longer can once each is teed separately.
+func lockIfShared(stdout io.Writer, stderr io.Writer) (io.Writer, io.Writer) {
+	if stdout == nil || !isSameWriter(stdout, stderr) {
+		return stdout, stderr
+	}
+	locked := &lockedWriter{writer: stdout}
+	return locked, locked
+}
+
+// isSameWriter compares the writers like os/exec does, where comparing
+// values of an uncomparable type panics.
+func isSameWriter(a io.Writer, b io.Writer) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a == b
+}
+
+// exitCode returns the exit code for an error from waiting on a command, or
+// -1 if the command did not exit normally.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		return exitError.ExitCode()
+	}
+	return -1
+}
+
+// commandError includes what the command named name printed, which is
+// usually the only explanation of the failure, and wraps err so the exit
+// error can still be inspected.
+func commandError(name string, result *Result, err error) error {
+	if result == nil || strings.TrimSpace(result.Stderr) == "" || err == ErrTimeout {
+		return err
+	}
+	return fmt.Errorf("osutils: %s: %s: %w", name, strings.TrimSpace(result.Stderr), err)
+}
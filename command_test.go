@@ -0,0 +1,47 @@
+package osutils
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCommand() {
+	result, err := NewCommand("bash", "-c", `echo "${FOO}"; echo err >&2; exit 3`).
+		Dir(s.tempDir).
+		Env("FOO", "foo").
+		Run(context.Background())
+	require.Error(s.T(), err)
+	require.Equal(s.T(), "foo\n", result.Stdout)
+	require.Equal(s.T(), "err\n", result.Stderr)
+	require.Equal(s.T(), 3, result.ExitCode)
+	result, err = NewCommand("sleep", "10").Timeout(50 * time.Millisecond).Run(context.Background())
+	require.Equal(s.T(), ErrTimeout, err)
+	require.Equal(s.T(), -1, result.ExitCode)
+}
+
+func (s *Suite) TestCommandCombined() {
+	result, err := NewCommand("bash", "-c", "echo one; echo two >&2; echo three").
+		Combined(nil).
+		Run(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "one\ntwo\nthree\n", result.Combined)
+	require.Equal(s.T(), "", result.Stdout)
+	var buffer bytes.Buffer
+	_, err = Execute(&Cmd{Args: []string{"true"}, Stdout: &buffer, CombinedOutput: &buffer})
+	require.Equal(s.T(), ErrMultipleOutputs, err)
+}
+
+func (s *Suite) TestCommandSharedOutput() {
+	var buffer bytes.Buffer
+	result, err := NewCommand("bash", "-c", "for i in 1 2 3 4 5; do echo out; echo err >&2; done").
+		Stdout(&buffer).
+		Stderr(&buffer).
+		Run(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 40, buffer.Len())
+	require.Equal(s.T(), 20, len(result.Stdout))
+	require.Equal(s.T(), 20, len(result.Stderr))
+}
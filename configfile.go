@@ -0,0 +1,157 @@
+package osutils
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	backupSuffix      = ".bak"
+	defaultConfigPerm = 0644
+)
+
+var (
+	JSONCodec Codec = &jsonCodec{}
+	YAMLCodec Codec = &yamlCodec{}
+)
+
+// Codec encodes config files. Implement it to use other formats such as
+// TOML with ReadConfigFile and WriteConfigFile.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type WriteOptions struct {
+	// Perm defaults to 0644.
+	Perm os.FileMode
+	// Backup keeps the previous content at the path with a .bak suffix.
+	Backup bool
+	// Mode takes precedence over Perm unless PortableModeDefault.
+	Mode PortableMode
+}
+
+// ReadConfigFile decodes the file at absolutePath into v while holding a
+// shared lock on its .lock sidecar file.
+func ReadConfigFile(absolutePath string, codec Codec, v interface{}) error {
+	return readConfigFile(absolutePath, codec, v)
+}
+
+// WriteConfigFile encodes v and atomically replaces the file at absolutePath
+// while holding an exclusive lock on its .lock sidecar file.
+func WriteConfigFile(absolutePath string, codec Codec, v interface{}, options *WriteOptions) error {
+	return writeConfigFile(absolutePath, codec, v, options)
+}
+
+func ReadJSONFile(absolutePath string, v interface{}) error {
+	return readConfigFile(absolutePath, JSONCodec, v)
+}
+
+func WriteJSONFile(absolutePath string, v interface{}, options *WriteOptions) error {
+	return writeConfigFile(absolutePath, JSONCodec, v, options)
+}
+
+func ReadYAMLFile(absolutePath string, v interface{}) error {
+	return readConfigFile(absolutePath, YAMLCodec, v)
+}
+
+func WriteYAMLFile(absolutePath string, v interface{}, options *WriteOptions) error {
+	return writeConfigFile(absolutePath, YAMLCodec, v, options)
+}
+
+// ***** PRIVATE *****
+
+func readConfigFile(absolutePath string, codec Codec, v interface{}) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	if codec == nil || v == nil {
+		return ErrNil
+	}
+	fileLock, err := lockFile(lockPathFor(absolutePath), false, true)
+	if err != nil {
+		return err
+	}
+	defer fileLock.Unlock()
+	data, err := ioutil.ReadFile(absolutePath)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(data, v)
+}
+
+func writeConfigFile(absolutePath string, codec Codec, v interface{}, options *WriteOptions) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	if codec == nil {
+		return ErrNil
+	}
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	fileLock, err := lockFile(lockPathFor(absolutePath), true, true)
+	if err != nil {
+		return err
+	}
+	defer fileLock.Unlock()
+	return writeLockedFile(absolutePath, data, options)
+}
+
+// writeLockedFile backs up and atomically replaces a file whose lock the
+// caller holds.
+func writeLockedFile(absolutePath string, data []byte, options *WriteOptions) error {
+	if options == nil {
+		options = &WriteOptions{}
+	}
+	perm := options.Perm
+	if options.Mode != PortableModeDefault {
+		perm = options.Mode.FilePerm()
+	}
+	if perm == 0 {
+		perm = defaultConfigPerm
+	}
+	if options.Backup {
+		exists, err := isRegularFileExists(absolutePath)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if err := copyFile(absolutePath, absolutePath+backupSuffix); err != nil {
+				return err
+			}
+		}
+	}
+	if err := writeFileAtomic(absolutePath, data, perm); err != nil {
+		return err
+	}
+	return ApplyPortableMode(absolutePath, options.Mode)
+}
+
+type jsonCodec struct{}
+
+func (j *jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func (j *jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type yamlCodec struct{}
+
+func (y *yamlCodec) Marshal(v interface{}) ([]byte, error) {
+	return yaml.Marshal(v)
+}
+
+func (y *yamlCodec) Unmarshal(data []byte, v interface{}) error {
+	return yaml.Unmarshal(data, v)
+}
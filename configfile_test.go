@@ -0,0 +1,46 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Name  string `json:"name" yaml:"name"`
+	Count int    `json:"count" yaml:"count"`
+}
+
+func (s *Suite) TestConfigFile() {
+	path := filepath.Join(s.tempDir, "config.json")
+	require.NoError(s.T(), WriteJSONFile(path, &testConfig{Name: "one", Count: 1}, nil))
+	require.NoError(s.T(), WriteJSONFile(path, &testConfig{Name: "two", Count: 2}, &WriteOptions{Backup: true}))
+	config := &testConfig{}
+	require.NoError(s.T(), ReadJSONFile(path, config))
+	require.Equal(s.T(), &testConfig{Name: "two", Count: 2}, config)
+	require.NoError(s.T(), ReadJSONFile(path+".bak", config))
+	require.Equal(s.T(), &testConfig{Name: "one", Count: 1}, config)
+
+	path = filepath.Join(s.tempDir, "config.yaml")
+	require.NoError(s.T(), WriteYAMLFile(path, &testConfig{Name: "three", Count: 3}, nil))
+	data, err := ioutil.ReadFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "name: three\ncount: 3\n", string(data))
+	require.NoError(s.T(), ReadYAMLFile(path, config))
+	require.Equal(s.T(), &testConfig{Name: "three", Count: 3}, config)
+}
+
+func (s *Suite) TestTryLockFile() {
+	path := filepath.Join(s.tempDir, "lock")
+	fileLock, err := LockFile(path, true)
+	require.NoError(s.T(), err)
+	// flock locks are per open file description, so this conflicts even
+	// within the same process
+	_, err = TryLockFile(path, false)
+	require.Equal(s.T(), ErrLocked, err)
+	require.NoError(s.T(), fileLock.Unlock())
+	fileLock, err = TryLockFile(path, false)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), fileLock.Unlock())
+}
@@ -0,0 +1,12 @@
+//go:build !plan9 && !windows
+
+package osutils
+
+import (
+	"errors"
+	"syscall"
+)
+
+func isConnRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,6 @@
+package osutils
+
+// isConnRefused is always false, there are no unix sockets on Plan 9.
+func isConnRefused(err error) bool {
+	return false
+}
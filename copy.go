@@ -0,0 +1,191 @@
+package osutils
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrFileExists = errors.New("osutils: file exists")
+)
+
+type OverwritePolicy int
+
+const (
+	// OverwritePolicyFail fails with ErrFileExists if the destination exists.
+	OverwritePolicyFail OverwritePolicy = iota
+	OverwritePolicyOverwrite
+	// OverwritePolicySkip leaves an existing destination alone.
+	OverwritePolicySkip
+	// OverwritePolicyRenameUnique picks name-1.ext, name-2.ext, ... until a
+	// free destination is found.
+	OverwritePolicyRenameUnique
+)
+
+// CopyFile copies the regular file at src to dst, keeping the permission
+// bits. dst is replaced atomically if it exists.
+func CopyFile(src string, dst string) error {
+	if !isAbsolutePath(src) || !isAbsolutePath(dst) {
+		return ErrNotAbsolutePath
+	}
+	return copyFile(src, dst)
+}
+
+type CopyOptions struct {
+	// VerifyAfterCopy re-reads dst after the copy and fails with
+	// ErrChecksumMismatch if its digest differs from what was copied.
+	VerifyAfterCopy bool
+	Throttle        *Throttle
+	// Mode replaces the permissions of dst unless PortableModeDefault.
+	Mode PortableMode
+}
+
+// CopyFileWithHash copies like CopyFile and returns the hex digest of the
+// copied data, computed in the same read pass.
+func CopyFileWithHash(src string, dst string, hashAlgorithm HashAlgorithm, options *CopyOptions) (string, error) {
+	if !isAbsolutePath(src) || !isAbsolutePath(dst) {
+		return "", ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &CopyOptions{}
+	}
+	return copyFileWithHash(src, dst, hashAlgorithm, options)
+}
+
+// ***** PRIVATE *****
+
+func copyFileWithHash(src string, dst string, hashAlgorithm HashAlgorithm, options *CopyOptions) (string, error) {
+	hash, err := newHash(hashAlgorithm)
+	if err != nil {
+		return "", err
+	}
+	if err := copyFileTee(src, dst, hash, options.Throttle, options.Mode); err != nil {
+		return "", err
+	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+	if options.VerifyAfterCopy {
+		dstDigest, err := hashFileWithAlgorithm(dst, hashAlgorithm)
+		if err != nil {
+			return "", err
+		}
+		if dstDigest != digest {
+			return "", ErrChecksumMismatch
+		}
+	}
+	return digest, nil
+}
+
+func copyFile(src string, dst string) error {
+	return copyFileTee(src, dst, nil, nil, PortableModeDefault)
+}
+
+// copyFileTee copies src to dst, also writing everything read to tee if
+// it is not nil. Unless PortableModeDefault, portableMode is applied before
+// dst appears instead of the permissions of src.
+func copyFileTee(src string, dst string, tee io.Writer, throttle *Throttle, portableMode PortableMode) (retErr error) {
+	throttle.WaitOp()
+	start := time.Now()
+	var n int64
+	defer func() {
+		observeOperation(MetricsOperationCopyFile, start, n, retErr)
+	}()
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	fileInfo, err := srcFile.Stat()
+	if err != nil {
+		return err
+	}
+	if !fileInfo.Mode().IsRegular() {
+		return ErrNotRegularFile
+	}
+	return writeAtomicWithMode(
+		dst,
+		fileInfo.Mode().Perm(),
+		portableMode,
+		func(writer io.Writer) error {
+			reader := throttle.Reader(srcFile)
+			if tee != nil {
+				reader = io.TeeReader(reader, tee)
+			}
+			var err error
+			n, err = io.Copy(writer, reader)
+			return err
+		},
+	)
+}
+
+// moveFile renames, falling back to copy and remove across devices.
+func moveFile(src string, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	var linkError *os.LinkError
+	if !errors.As(err, &linkError) || linkError.Err != errCrossDevice {
+		return err
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// resolveDst applies the policy to dst, returning the path to write to, or
+// "" if the write should be skipped.
+func resolveDst(dst string, overwritePolicy OverwritePolicy) (string, error) {
+	exists, err := lexists(dst)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return dst, nil
+	}
+	switch overwritePolicy {
+	case OverwritePolicyOverwrite:
+		return dst, nil
+	case OverwritePolicySkip:
+		return "", nil
+	case OverwritePolicyRenameUnique:
+		return uniquePath(dst)
+	default:
+		return "", ErrFileExists
+	}
+}
+
+func uniquePath(path string) (string, error) {
+	for i := 1; ; i++ {
+		candidate := uniqueCandidate(path, i)
+		exists, err := lexists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+	}
+}
+
+// uniqueCandidate returns name-i.ext for path name.ext.
+func uniqueCandidate(path string, i int) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + strconv.Itoa(i) + ext
+}
+
+func lexists(path string) (bool, error) {
+	if _, err := os.Lstat(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
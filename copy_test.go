@@ -0,0 +1,31 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCopyFileWithHash() {
+	src := filepath.Join(s.tempDir, "src")
+	dst := filepath.Join(s.tempDir, "dst")
+	require.NoError(s.T(), ioutil.WriteFile(src, []byte("hello"), 0640))
+	digest, err := CopyFileWithHash(src, dst, HashAlgorithmSHA256, &CopyOptions{VerifyAfterCopy: true})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", digest)
+	data, err := ioutil.ReadFile(dst)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "hello", string(data))
+	info, err := os.Stat(dst)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), os.FileMode(0640), info.Mode().Perm())
+
+	digest, err = CopyFileWithHash(src, dst, HashAlgorithmMD5, nil)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "5d41402abc4b2a76b9719d911017c592", digest)
+
+	_, err = CopyFileWithHash(src, dst, HashAlgorithm("crc"), nil)
+	require.Equal(s.T(), ErrUnknownHashAlgorithm, err)
+}
@@ -0,0 +1,162 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type CopyDirOptions struct {
+	// OverwritePolicy applies to files and symlinks. Existing directories
+	// are merged into.
+	OverwritePolicy OverwritePolicy
+	FailureMode     FailureMode
+	// Progress steps by the size of each file copied.
+	Progress Progress
+	Throttle *Throttle
+	// Ignore excludes matching paths from the copy.
+	Ignore *Ignore
+	// Mode replaces the permissions of the files and dirs created unless
+	// PortableModeDefault.
+	Mode PortableMode
+	// CheckSpace fails with ErrInsufficientSpace before anything is
+	// copied if dst does not have room for the files of src, see
+	// PreflightTreeSpaceCheck.
+	CheckSpace bool
+}
+
+// CopyDir copies the tree at src to dst, which is created if needed.
+// Regular files keep their permission bits and are written atomically,
+// symlinks are recreated as they are, and other file types are skipped.
+func CopyDir(src string, dst string, options *CopyDirOptions) error {
+	if !isAbsolutePath(src) || !isAbsolutePath(dst) {
+		return ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &CopyDirOptions{}
+	}
+	exists, err := isDirExists(src)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrFileDoesNotExist
+	}
+	return copyDir(filepath.Clean(src), filepath.Clean(dst), options)
+}
+
+// ***** PRIVATE *****
+
+func copyDir(src string, dst string, options *CopyDirOptions) (retErr error) {
+	defer func(start time.Time) {
+		observeOperation(MetricsOperationCopyDir, start, 0, retErr)
+	}(time.Now())
+	if options.CheckSpace {
+		if err := PreflightTreeSpaceCheck(src, dst); err != nil {
+			return err
+		}
+	}
+	progress := progressOrNop(options.Progress)
+	if err := startTreeProgress(src, progress); err != nil {
+		return err
+	}
+	defer func() {
+		progress.Done(retErr)
+	}()
+	treeTransaction, err := newTreeTransaction(dst, options.FailureMode)
+	if err != nil {
+		return err
+	}
+	return treeTransaction.finish(
+		filepath.Walk(
+			src,
+			func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if options.Ignore != nil {
+					ignored, err := options.Ignore.Match(path, info.IsDir())
+					if err != nil {
+						return err
+					}
+					if ignored {
+						return skipIgnored(info.IsDir())
+					}
+				}
+				rel, err := filepath.Rel(src, path)
+				if err != nil {
+					return err
+				}
+				if err := copyDirEntry(path, filepath.Join(dst, rel), info, options, treeTransaction); err != nil {
+					return err
+				}
+				if info.Mode().IsRegular() {
+					progress.Step(info.Size(), path)
+				}
+				return nil
+			},
+		),
+	)
+}
+
+func copyDirEntry(src string, dst string, info os.FileInfo, options *CopyDirOptions, treeTransaction *treeTransaction) error {
+	switch {
+	case info.IsDir():
+		dstInfo, err := os.Lstat(dst)
+		if err == nil {
+			if !dstInfo.IsDir() {
+				return ErrFileExists
+			}
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		perm := info.Mode().Perm()
+		if options.Mode != PortableModeDefault {
+			perm = options.Mode.DirPerm()
+		}
+		if err := mkdirWithPolicy(dst, perm, currentPolicy()); err != nil {
+			return err
+		}
+		treeTransaction.create(dst)
+		return ApplyPortableMode(dst, options.Mode)
+	case info.Mode().IsRegular(), info.Mode()&os.ModeSymlink != 0:
+		existed, err := lexists(dst)
+		if err != nil {
+			return err
+		}
+		target, err := resolveDst(dst, options.OverwritePolicy)
+		if err != nil || target == "" {
+			return err
+		}
+		if target != dst {
+			existed = false
+		}
+		if info.Mode().IsRegular() {
+			err = copyFileTee(src, target, nil, options.Throttle, options.Mode)
+		} else {
+			err = copySymlink(src, target)
+		}
+		if err != nil {
+			return err
+		}
+		if !existed {
+			treeTransaction.create(target)
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func copySymlink(src string, dst string) error {
+	linkTarget, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(linkTarget, dst)
+}
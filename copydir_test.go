@@ -0,0 +1,59 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCopyDir() {
+	src := s.writeCopyDirSrc()
+	dst := filepath.Join(s.tempDir, "dst")
+	require.NoError(s.T(), CopyDir(src, dst, &CopyDirOptions{FailureMode: FailureModePartialMarker}))
+	data, err := ioutil.ReadFile(filepath.Join(dst, "b", "2"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "2", string(data))
+	info, err := os.Stat(filepath.Join(dst, "a", "1"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), os.FileMode(0600), info.Mode().Perm())
+	linkTarget, err := os.Readlink(filepath.Join(dst, "link"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "a/1", linkTarget)
+	partial, err := IsPartial(dst)
+	require.NoError(s.T(), err)
+	require.False(s.T(), partial)
+
+	require.Equal(s.T(), ErrFileExists, CopyDir(src, dst, nil))
+	require.NoError(s.T(), CopyDir(src, dst, &CopyDirOptions{OverwritePolicy: OverwritePolicyOverwrite}))
+}
+
+func (s *Suite) TestCopyDirRollback() {
+	src := s.writeCopyDirSrc()
+	dst := filepath.Join(s.tempDir, "dst")
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(dst, "b"), 0755))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(dst, "b", "2"), []byte("existing"), 0644))
+
+	require.Equal(s.T(), ErrFileExists, CopyDir(src, dst, &CopyDirOptions{FailureMode: FailureModeRollback}))
+	// a/ was created before the failure and rolled back, b/2 predates the copy
+	s.checkFileDoesNotExist(filepath.Join(dst, "a"))
+	data, err := ioutil.ReadFile(filepath.Join(dst, "b", "2"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "existing", string(data))
+
+	require.Equal(s.T(), ErrFileExists, CopyDir(src, dst, &CopyDirOptions{FailureMode: FailureModePartialMarker}))
+	partial, err := IsPartial(dst)
+	require.NoError(s.T(), err)
+	require.True(s.T(), partial)
+}
+
+func (s *Suite) writeCopyDirSrc() string {
+	src := filepath.Join(s.tempDir, "src")
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(src, "a"), 0755))
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(src, "b"), 0755))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(src, "a", "1"), []byte("1"), 0600))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(src, "b", "2"), []byte("2"), 0644))
+	require.NoError(s.T(), os.Symlink("a/1", filepath.Join(src, "link")))
+	return src
+}
@@ -0,0 +1,231 @@
+package osutils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDuplicateDst = errors.New("osutils: duplicate destination")
+)
+
+type SrcDst struct {
+	Src string
+	Dst string
+}
+
+type CopyFilesOptions struct {
+	// Workers is the number of files copied at once, or runtime.NumCPU()
+	// if not positive.
+	Workers         int
+	OverwritePolicy OverwritePolicy
+	// ContinueOnError copies the remaining files after a failure, otherwise
+	// no new copy is started, and the copies in flight finish.
+	ContinueOnError bool
+	// Progress steps by the bytes copied as they are written, with the
+	// total size of the sources.
+	Progress Progress
+	// OnFile, if set, is called once per file as it is done, from one
+	// goroutine at a time.
+	OnFile   func(copiedFile *CopiedFile)
+	Throttle *Throttle
+	// Mode replaces the permissions of the files created unless
+	// PortableModeDefault.
+	Mode PortableMode
+}
+
+// CopiedFile is the outcome of the copy of one file.
+type CopiedFile struct {
+	Src string
+	// Dst is the path written, which differs from the requested one with
+	// OverwritePolicyRenameUnique, and is empty if the file was skipped or
+	// not copied.
+	Dst      string
+	Size     int64
+	Duration time.Duration
+	Err      error
+}
+
+type CopyFilesReport struct {
+	// Files is in the order of the pairs, with nil for the files not
+	// attempted after a failure.
+	Files    []*CopiedFile
+	Copied   int
+	Skipped  int
+	Failed   int
+	Bytes    int64
+	Duration time.Duration
+}
+
+// CopyFiles copies many regular files concurrently, each like CopyFile,
+// creating the parent dirs of the destinations as needed. Two pairs with
+// the same Dst fail with ErrDuplicateDst before anything is copied. The
+// report is returned even on error, and the error is a PathErrors with the
+// failure of every file that failed.
+func CopyFiles(pairs []SrcDst, options *CopyFilesOptions) (*CopyFilesReport, error) {
+	if options == nil {
+		options = &CopyFilesOptions{}
+	}
+	dsts := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		if !isAbsolutePath(pair.Src) || !isAbsolutePath(pair.Dst) {
+			return nil, ErrNotAbsolutePath
+		}
+		dst := filepath.Clean(pair.Dst)
+		if dsts[dst] {
+			return nil, ErrDuplicateDst
+		}
+		dsts[dst] = true
+	}
+	return copyFiles(pairs, options)
+}
+
+// ***** PRIVATE *****
+
+func copyFiles(pairs []SrcDst, options *CopyFilesOptions) (_ *CopyFilesReport, retErr error) {
+	start := time.Now()
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	progress := progressOrNop(options.Progress)
+	if _, ok := progress.(nopProgress); !ok {
+		var total int64
+		for _, pair := range pairs {
+			if fileInfo, err := os.Stat(pair.Src); err == nil {
+				total += fileInfo.Size()
+			}
+		}
+		progress.Start(total)
+	}
+	defer func() {
+		progress.Done(retErr)
+	}()
+	copyFilesReport := &CopyFilesReport{
+		Files: make([]*CopiedFile, len(pairs)),
+	}
+	var (
+		pathErrors PathErrors
+		failed     bool
+		lock       sync.Mutex
+		waitGroup  sync.WaitGroup
+		semaphore  = make(chan struct{}, workers)
+	)
+	for i, pair := range pairs {
+		semaphore <- struct{}{}
+		lock.Lock()
+		stop := failed && !options.ContinueOnError
+		lock.Unlock()
+		if stop {
+			<-semaphore
+			break
+		}
+		waitGroup.Add(1)
+		go func(i int, pair SrcDst) {
+			defer waitGroup.Done()
+			defer func() { <-semaphore }()
+			copiedFile := copyOneFile(
+				pair,
+				options,
+				&progressWriter{
+					progress: progress,
+					path:     pair.Src,
+					lock:     &lock,
+				},
+			)
+			lock.Lock()
+			defer lock.Unlock()
+			copyFilesReport.Files[i] = copiedFile
+			switch {
+			case copiedFile.Err != nil:
+				copyFilesReport.Failed++
+				failed = true
+				pathErrors = appendPathError(pathErrors, "copy", pair.Src, copiedFile.Err)
+			case copiedFile.Dst == "":
+				copyFilesReport.Skipped++
+			default:
+				copyFilesReport.Copied++
+				copyFilesReport.Bytes += copiedFile.Size
+			}
+			if options.OnFile != nil {
+				options.OnFile(copiedFile)
+			}
+		}(i, pair)
+	}
+	waitGroup.Wait()
+	copyFilesReport.Duration = time.Since(start)
+	return copyFilesReport, pathErrorsOrNil(pathErrors)
+}
+
+func copyOneFile(pair SrcDst, options *CopyFilesOptions, progressWriter *progressWriter) *CopiedFile {
+	start := time.Now()
+	copiedFile := &CopiedFile{
+		Src: pair.Src,
+	}
+	copiedFile.Err = func() error {
+		fileInfo, err := os.Stat(pair.Src)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(pair.Dst), 0755); err != nil {
+			return err
+		}
+		target, reserved, err := reserveDst(pair.Dst, options.OverwritePolicy)
+		if err != nil || target == "" {
+			return err
+		}
+		if err := copyFileTee(pair.Src, target, progressWriter, options.Throttle, options.Mode); err != nil {
+			if reserved {
+				_ = os.Remove(target)
+			}
+			return err
+		}
+		copiedFile.Dst = target
+		copiedFile.Size = fileInfo.Size()
+		return nil
+	}()
+	copiedFile.Duration = time.Since(start)
+	return copiedFile
+}
+
+// reserveDst is resolveDst, except that with OverwritePolicyRenameUnique
+// the destination picked is created empty with O_EXCL, so that another
+// copy in this or another process cannot pick it too before it is written.
+func reserveDst(dst string, overwritePolicy OverwritePolicy) (string, bool, error) {
+	if overwritePolicy != OverwritePolicyRenameUnique {
+		target, err := resolveDst(dst, overwritePolicy)
+		return target, false, err
+	}
+	for i := 0; ; i++ {
+		candidate := dst
+		if i > 0 {
+			candidate = uniqueCandidate(dst, i)
+		}
+		file, err := os.OpenFile(candidate, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			return candidate, true, file.Close()
+		}
+		if !os.IsExist(err) {
+			return "", false, err
+		}
+	}
+}
+
+// progressWriter steps progress by every write, holding lock as the
+// progress is shared by the workers.
+type progressWriter struct {
+	progress Progress
+	path     string
+	lock     *sync.Mutex
+}
+
+func (p *progressWriter) Write(data []byte) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.progress.Step(int64(len(data)), p.path)
+	return len(data), nil
+}
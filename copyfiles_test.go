@@ -0,0 +1,97 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCopyFiles() {
+	var pairs []SrcDst
+	for i := 0; i < 20; i++ {
+		src := filepath.Join(s.tempDir, "src", strconv.Itoa(i))
+		require.NoError(s.T(), os.MkdirAll(filepath.Dir(src), 0755))
+		require.NoError(s.T(), ioutil.WriteFile(src, []byte(strconv.Itoa(i)), 0644))
+		pairs = append(pairs, SrcDst{Src: src, Dst: filepath.Join(s.tempDir, "dst", "sub", strconv.Itoa(i))})
+	}
+	var onFileCalls int
+	copyFilesReport, err := CopyFiles(
+		pairs,
+		&CopyFilesOptions{
+			Workers: 4,
+			OnFile: func(copiedFile *CopiedFile) {
+				onFileCalls++
+			},
+		},
+	)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 20, copyFilesReport.Copied)
+	require.Equal(s.T(), 20, onFileCalls)
+	require.Equal(s.T(), int64(30), copyFilesReport.Bytes)
+	for i, pair := range pairs {
+		require.Equal(s.T(), pair.Dst, copyFilesReport.Files[i].Dst)
+		data, err := ioutil.ReadFile(pair.Dst)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), strconv.Itoa(i), string(data))
+	}
+
+	missing := SrcDst{Src: filepath.Join(s.tempDir, "missing"), Dst: filepath.Join(s.tempDir, "dst", "missing")}
+	copyFilesReport, err = CopyFiles(
+		append([]SrcDst{missing}, pairs[:3]...),
+		&CopyFilesOptions{
+			OverwritePolicy: OverwritePolicySkip,
+			ContinueOnError: true,
+		},
+	)
+	require.Error(s.T(), err)
+	pathErrors, ok := err.(PathErrors)
+	require.True(s.T(), ok)
+	require.Len(s.T(), pathErrors, 1)
+	require.Equal(s.T(), missing.Src, pathErrors[0].Path)
+	require.Equal(s.T(), 1, copyFilesReport.Failed)
+	require.Equal(s.T(), 3, copyFilesReport.Skipped)
+	require.Equal(s.T(), "", copyFilesReport.Files[1].Dst)
+
+	copyFilesReport, err = CopyFiles(append([]SrcDst{missing}, pairs...), &CopyFilesOptions{Workers: 1, OverwritePolicy: OverwritePolicyOverwrite})
+	require.Error(s.T(), err)
+	require.Equal(s.T(), 1, copyFilesReport.Failed)
+	require.True(s.T(), copyFilesReport.Copied < len(pairs))
+	_, err = CopyFiles([]SrcDst{{Src: "relative", Dst: "/dst"}}, nil)
+	require.Equal(s.T(), ErrNotAbsolutePath, err)
+	_, err = CopyFiles([]SrcDst{pairs[0], {Src: pairs[1].Src, Dst: pairs[0].Dst + "/"}}, nil)
+	require.Equal(s.T(), ErrDuplicateDst, err)
+
+	progress := &testProgress{}
+	_, err = CopyFiles(pairs, &CopyFilesOptions{OverwritePolicy: OverwritePolicyOverwrite, Progress: progress})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(30), progress.total)
+	require.Equal(s.T(), int64(30), progress.steps)
+}
+
+func (s *Suite) TestCopyFilesRenameUnique() {
+	dst := filepath.Join(s.tempDir, "dst")
+	require.NoError(s.T(), os.MkdirAll(dst, 0755))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(dst, "x.txt"), []byte("existing"), 0644))
+	var pairs []SrcDst
+	for i, name := range []string{"x.txt", "x-1.txt", "x-2.txt"} {
+		src := filepath.Join(s.tempDir, strconv.Itoa(i))
+		require.NoError(s.T(), ioutil.WriteFile(src, []byte(strconv.Itoa(i)), 0644))
+		pairs = append(pairs, SrcDst{Src: src, Dst: filepath.Join(dst, name)})
+	}
+	copyFilesReport, err := CopyFiles(pairs, &CopyFilesOptions{Workers: 3, OverwritePolicy: OverwritePolicyRenameUnique})
+	require.NoError(s.T(), err)
+	targets := make(map[string]bool)
+	for i, copiedFile := range copyFilesReport.Files {
+		require.False(s.T(), targets[copiedFile.Dst], copiedFile.Dst)
+		targets[copiedFile.Dst] = true
+		data, err := ioutil.ReadFile(copiedFile.Dst)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), strconv.Itoa(i), string(data))
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dst, "x.txt"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "existing", string(data))
+}
@@ -0,0 +1,182 @@
+package osutils
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// crontabMarkerPrefix starts the comment line above each managed entry.
+const crontabMarkerPrefix = "# osutils: "
+
+var (
+	ErrInvalidCrontabEntry = errors.New("osutils: invalid crontab entry")
+	// crontabCommand is swapped in tests.
+	crontabCommand = "crontab"
+)
+
+// CrontabEntry is a job in a crontab. Entries installed by this package
+// have a Name, which is kept in a marker comment on the line above the
+// job so the entry can be found again. Entries without a Name are not
+// managed here.
+type CrontabEntry struct {
+	Name string
+	// Schedule is the five time fields or an @ keyword such as @daily.
+	Schedule string
+	Command  string
+}
+
+// ListCrontab returns the entries in the crontab of the current user,
+// which is empty if the user has none.
+func ListCrontab(ctx context.Context) ([]*CrontabEntry, error) {
+	lines, err := readCrontab(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var crontabEntries []*CrontabEntry
+	name := ""
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, crontabMarkerPrefix) {
+			name = strings.TrimPrefix(trimmed, crontabMarkerPrefix)
+			continue
+		}
+		if crontabEntry := parseCrontabLine(trimmed); crontabEntry != nil {
+			crontabEntry.Name = name
+			crontabEntries = append(crontabEntries, crontabEntry)
+		}
+		name = ""
+	}
+	return crontabEntries, nil
+}
+
+// InstallCrontabEntry adds crontabEntry to the crontab of the current
+// user, replacing a managed entry of the same Name, and returns whether
+// the crontab changed. Lines not managed by this package are kept as they
+// are.
+func InstallCrontabEntry(ctx context.Context, crontabEntry *CrontabEntry) (bool, error) {
+	if err := validateCrontabEntry(crontabEntry); err != nil {
+		return false, err
+	}
+	return editCrontab(
+		ctx,
+		func(lines []string) []string {
+			managed := []string{
+				crontabMarkerPrefix + crontabEntry.Name,
+				crontabEntry.Schedule + " " + crontabEntry.Command,
+			}
+			i, n := findCrontabEntry(lines, crontabEntry.Name)
+			if i < 0 {
+				return append(lines, managed...)
+			}
+			if n == len(managed) && lines[i+1] == managed[1] {
+				return nil
+			}
+			return append(append(append([]string{}, lines[:i]...), managed...), lines[i+n:]...)
+		},
+	)
+}
+
+// RemoveCrontabEntry removes the managed entry named name from the crontab
+// of the current user and returns whether it was there.
+func RemoveCrontabEntry(ctx context.Context, name string) (bool, error) {
+	if !isValidCrontabName(name) {
+		return false, ErrInvalidCrontabEntry
+	}
+	return editCrontab(
+		ctx,
+		func(lines []string) []string {
+			i, n := findCrontabEntry(lines, name)
+			if i < 0 {
+				return nil
+			}
+			return append(append([]string{}, lines[:i]...), lines[i+n:]...)
+		},
+	)
+}
+
+// ***** PRIVATE *****
+
+func readCrontab(ctx context.Context) ([]string, error) {
+	result, err := NewCommand(crontabCommand, "-l").Run(ctx)
+	if err != nil {
+		// crontab -l fails with a message rather than printing nothing
+		if result != nil && result.ExitCode == 1 && strings.Contains(strings.ToLower(result.Stderr), "no crontab") {
+			return []string{}, nil
+		}
+		return nil, commandError("crontab", result, err)
+	}
+	return splitLines(result.Stdout), nil
+}
+
+// editCrontab passes the lines of the crontab to fn, which returns nil to
+// leave it as it is.
+func editCrontab(ctx context.Context, fn func([]string) []string) (bool, error) {
+	lines, err := readCrontab(ctx)
+	if err != nil {
+		return false, err
+	}
+	lines = fn(lines)
+	if lines == nil {
+		return false, nil
+	}
+	result, err := NewCommand(crontabCommand, "-").StdinString(joinLines(lines)).Run(ctx)
+	if err != nil {
+		return false, commandError("crontab", result, err)
+	}
+	return true, nil
+}
+
+// findCrontabEntry returns the index of the marker of the managed entry
+// named name and the number of lines it spans, or -1.
+func findCrontabEntry(lines []string, name string) (int, int) {
+	for i, line := range lines {
+		if strings.TrimSpace(line) != crontabMarkerPrefix+name {
+			continue
+		}
+		if i+1 < len(lines) && parseCrontabLine(strings.TrimSpace(lines[i+1])) != nil {
+			return i, 2
+		}
+		return i, 1
+	}
+	return -1, 0
+}
+
+func parseCrontabLine(line string) *CrontabEntry {
+	if line == "" || line[0] == '#' {
+		return nil
+	}
+	fields := strings.Fields(line)
+	scheduleSize := 5
+	if strings.HasPrefix(fields[0], "@") {
+		scheduleSize = 1
+	} else if strings.Contains(fields[0], "=") {
+		// an environment setting
+		return nil
+	}
+	if len(fields) <= scheduleSize {
+		return nil
+	}
+	return &CrontabEntry{
+		Schedule: strings.Join(fields[:scheduleSize], " "),
+		Command:  strings.Join(fields[scheduleSize:], " "),
+	}
+}
+
+func validateCrontabEntry(crontabEntry *CrontabEntry) error {
+	if crontabEntry == nil {
+		return ErrNil
+	}
+	if !isValidCrontabName(crontabEntry.Name) || strings.ContainsAny(crontabEntry.Command, "\r\n") {
+		return ErrInvalidCrontabEntry
+	}
+	parsed := parseCrontabLine(crontabEntry.Schedule + " " + crontabEntry.Command)
+	if parsed == nil || parsed.Schedule != crontabEntry.Schedule {
+		return ErrInvalidCrontabEntry
+	}
+	return nil
+}
+
+func isValidCrontabName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t\r\n")
+}
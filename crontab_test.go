@@ -0,0 +1,73 @@
+package osutils
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCrontab() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("crontab not supported on windows")
+	}
+	ctx := context.Background()
+	file := filepath.Join(s.tempDir, "crontab")
+	fake := filepath.Join(s.tempDir, "fake-crontab")
+	require.NoError(
+		s.T(),
+		ioutil.WriteFile(
+			fake,
+			[]byte(`#!/bin/sh
+if [ "$1" = "-l" ]; then
+	[ -f `+file+` ] || { echo "no crontab for test" >&2; exit 1; }
+	exec cat `+file+`
+fi
+exec cat > `+file+`
+`),
+			0755,
+		),
+	)
+	defer func(original string) { crontabCommand = original }(crontabCommand)
+	crontabCommand = fake
+
+	crontabEntries, err := ListCrontab(ctx)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), crontabEntries)
+	require.NoError(s.T(), ioutil.WriteFile(file, []byte("MAILTO=root\n0 * * * * /bin/other\n"), 0644))
+
+	_, err = InstallCrontabEntry(ctx, &CrontabEntry{Name: "backup", Schedule: "* * *", Command: "/bin/backup"})
+	require.Equal(s.T(), ErrInvalidCrontabEntry, err)
+	changed, err := InstallCrontabEntry(ctx, &CrontabEntry{Name: "backup", Schedule: "@daily", Command: "/bin/backup"})
+	require.NoError(s.T(), err)
+	require.True(s.T(), changed)
+	changed, err = InstallCrontabEntry(ctx, &CrontabEntry{Name: "backup", Schedule: "@daily", Command: "/bin/backup"})
+	require.NoError(s.T(), err)
+	require.False(s.T(), changed)
+	changed, err = InstallCrontabEntry(ctx, &CrontabEntry{Name: "backup", Schedule: "30 2 * * *", Command: "/bin/backup --full"})
+	require.NoError(s.T(), err)
+	require.True(s.T(), changed)
+
+	crontabEntries, err = ListCrontab(ctx)
+	require.NoError(s.T(), err)
+	require.Equal(
+		s.T(),
+		[]*CrontabEntry{
+			{Schedule: "0 * * * *", Command: "/bin/other"},
+			{Name: "backup", Schedule: "30 2 * * *", Command: "/bin/backup --full"},
+		},
+		crontabEntries,
+	)
+
+	changed, err = RemoveCrontabEntry(ctx, "backup")
+	require.NoError(s.T(), err)
+	require.True(s.T(), changed)
+	changed, err = RemoveCrontabEntry(ctx, "backup")
+	require.NoError(s.T(), err)
+	require.False(s.T(), changed)
+	data, err := ioutil.ReadFile(file)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "MAILTO=root\n0 * * * * /bin/other\n", string(data))
+}
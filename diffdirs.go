@@ -0,0 +1,99 @@
+package osutils
+
+import (
+	"sort"
+)
+
+type DiffDirsOptions struct {
+	// IgnoreMode skips comparing permission bits, which mean little on
+	// Windows.
+	IgnoreMode bool
+}
+
+// DirDiff lists the differences between two trees by slash-separated
+// paths relative to their roots, each sorted.
+type DirDiff struct {
+	OnlyInA []string
+	OnlyInB []string
+	// TypeDiffers is a file in one tree and a dir or symlink in the other.
+	TypeDiffers []string
+	// ContentDiffers is regular files with different content and symlinks
+	// with different targets.
+	ContentDiffers []string
+	ModeDiffers    []string
+}
+
+func (d *DirDiff) Empty() bool {
+	return len(d.OnlyInA) == 0 &&
+		len(d.OnlyInB) == 0 &&
+		len(d.TypeDiffers) == 0 &&
+		len(d.ContentDiffers) == 0 &&
+		len(d.ModeDiffers) == 0
+}
+
+// DiffDirs compares the trees at a and b. Entries under a dir that is only
+// in one tree are listed as well.
+func DiffDirs(a string, b string, options *DiffDirsOptions) (*DirDiff, error) {
+	if !isAbsolutePath(a) || !isAbsolutePath(b) {
+		return nil, ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &DiffDirsOptions{}
+	}
+	for _, path := range []string{a, b} {
+		exists, err := isDirExists(path)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrFileDoesNotExist
+		}
+	}
+	snapshotA, err := snapshotTree(a, false)
+	if err != nil {
+		return nil, err
+	}
+	snapshotB, err := snapshotTree(b, false)
+	if err != nil {
+		return nil, err
+	}
+	return diffSnapshots(snapshotA, snapshotB, options), nil
+}
+
+// ***** PRIVATE *****
+
+func diffSnapshots(a *Snapshot, b *Snapshot, options *DiffDirsOptions) *DirDiff {
+	dirDiff := &DirDiff{}
+	for path, entryA := range a.Entries {
+		entryB, ok := b.Entries[path]
+		switch {
+		case !ok:
+			dirDiff.OnlyInA = append(dirDiff.OnlyInA, path)
+		case entryA.Mode.Type() != entryB.Mode.Type():
+			dirDiff.TypeDiffers = append(dirDiff.TypeDiffers, path)
+		default:
+			if entryA.Hash != entryB.Hash || entryA.LinkTarget != entryB.LinkTarget {
+				dirDiff.ContentDiffers = append(dirDiff.ContentDiffers, path)
+			}
+			// symlink permissions are not meaningful
+			if !options.IgnoreMode && entryA.LinkTarget == "" && entryA.Mode.Perm() != entryB.Mode.Perm() {
+				dirDiff.ModeDiffers = append(dirDiff.ModeDiffers, path)
+			}
+		}
+	}
+	for path := range b.Entries {
+		if _, ok := a.Entries[path]; !ok {
+			dirDiff.OnlyInB = append(dirDiff.OnlyInB, path)
+		}
+	}
+	for _, paths := range [][]string{
+		dirDiff.OnlyInA,
+		dirDiff.OnlyInB,
+		dirDiff.TypeDiffers,
+		dirDiff.ContentDiffers,
+		dirDiff.ModeDiffers,
+	} {
+		sort.Strings(paths)
+	}
+	return dirDiff
+}
@@ -0,0 +1,41 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestDiffDirs() {
+	src := s.writeCopyDirSrc()
+	dst := filepath.Join(s.tempDir, "dst")
+	require.NoError(s.T(), CopyDir(src, dst, nil))
+	dirDiff, err := DiffDirs(src, dst, nil)
+	require.NoError(s.T(), err)
+	require.True(s.T(), dirDiff.Empty())
+
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(dst, "a", "1"), []byte("changed"), 0600))
+	require.NoError(s.T(), os.Chmod(filepath.Join(dst, "a", "1"), 0644))
+	require.NoError(s.T(), os.Remove(filepath.Join(dst, "link")))
+	require.NoError(s.T(), os.Mkdir(filepath.Join(dst, "link"), 0755))
+	require.NoError(s.T(), os.RemoveAll(filepath.Join(dst, "b")))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(dst, "extra"), nil, 0644))
+	dirDiff, err = DiffDirs(src, dst, nil)
+	require.NoError(s.T(), err)
+	require.Equal(
+		s.T(),
+		&DirDiff{
+			OnlyInA:        []string{"b", "b/2"},
+			OnlyInB:        []string{"extra"},
+			TypeDiffers:    []string{"link"},
+			ContentDiffers: []string{"a/1"},
+			ModeDiffers:    []string{"a/1"},
+		},
+		dirDiff,
+	)
+	dirDiff, err = DiffDirs(src, dst, &DiffDirsOptions{IgnoreMode: true})
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), dirDiff.ModeDiffers)
+}
@@ -0,0 +1,309 @@
+package osutils
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrPathOutsideDir = errors.New("osutils: path outside dir")
+)
+
+// Dir operates on paths relative to a directory. Relative paths that would
+// resolve outside of the directory are rejected with ErrPathOutsideDir.
+type Dir struct {
+	absolutePath string
+	policy       *Policy
+	statCache    *StatCache
+}
+
+func OpenDir(absolutePath string) (*Dir, error) {
+	return openDir(absolutePath)
+}
+
+// SafeJoin joins relativePath onto absoluteBasePath, failing with
+// ErrPathOutsideDir if the result would not be within absoluteBasePath.
+// Symlinks are not resolved.
+func SafeJoin(absoluteBasePath string, relativePath string) (string, error) {
+	return safeJoin(absoluteBasePath, relativePath)
+}
+
+type RelPathOptions struct {
+	// AllowOutside allows a target outside of base, with a result starting
+	// with .., rather than failing with ErrPathOutsideDir.
+	AllowOutside bool
+}
+
+// RelPath returns the path of absoluteTarget relative to absoluteBasePath,
+// the reverse of SafeJoin. Symlinks are resolved in both paths, the parts
+// that do not exist yet being taken as is, so a target reached through a
+// symlink is compared by where it really is. A target outside of base
+// results in ErrPathOutsideDir, see RelPathOptions.
+func RelPath(absoluteBasePath string, absoluteTarget string, options *RelPathOptions) (string, error) {
+	return relPath(absoluteBasePath, absoluteTarget, options)
+}
+
+// WithPolicy returns a Dir for the same directory that uses policy instead
+// of the package-wide Policy.
+func (d *Dir) WithPolicy(policy *Policy) *Dir {
+	return &Dir{
+		absolutePath: d.absolutePath,
+		policy:       policy,
+		statCache:    d.statCache,
+	}
+}
+
+// WithStatCache returns a Dir for the same directory whose Stat and
+// Is*Exists methods use statCache. Its mutating methods invalidate the
+// paths they change.
+func (d *Dir) WithStatCache(statCache *StatCache) *Dir {
+	return &Dir{
+		absolutePath: d.absolutePath,
+		policy:       d.policy,
+		statCache:    statCache,
+	}
+}
+
+func (d *Dir) Path() string {
+	return d.absolutePath
+}
+
+func (d *Dir) Join(relativePath ...string) (string, error) {
+	return safeJoin(d.absolutePath, filepath.Join(relativePath...))
+}
+
+func (d *Dir) Sub(relativePath string) (*Dir, error) {
+	path, err := d.Join(relativePath)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := openDir(path)
+	if err != nil {
+		return nil, err
+	}
+	sub.policy = d.policy
+	sub.statCache = d.statCache
+	return sub, nil
+}
+
+func (d *Dir) List() ([]os.FileInfo, error) {
+	return ioutil.ReadDir(d.absolutePath)
+}
+
+// Stat returns a nil os.FileInfo and no error if the path does not exist.
+func (d *Dir) Stat(relativePath string) (os.FileInfo, error) {
+	path, err := d.Join(relativePath)
+	if err != nil {
+		return nil, err
+	}
+	return d.stat(path)
+}
+
+func (d *Dir) IsFileExists(relativePath string) (bool, error) {
+	path, err := d.Join(relativePath)
+	if err != nil {
+		return false, err
+	}
+	return isFileExistsWithStat(path, d.stat)
+}
+
+func (d *Dir) IsRegularFileExists(relativePath string) (bool, error) {
+	path, err := d.Join(relativePath)
+	if err != nil {
+		return false, err
+	}
+	return isRegularFileExistsWithStat(path, d.stat)
+}
+
+func (d *Dir) IsDirExists(relativePath string) (bool, error) {
+	path, err := d.Join(relativePath)
+	if err != nil {
+		return false, err
+	}
+	return isDirExistsWithStat(path, d.stat)
+}
+
+func (d *Dir) Open(relativePath string) (*os.File, error) {
+	path, err := d.Join(relativePath)
+	if err != nil {
+		return nil, err
+	}
+	return open(path)
+}
+
+func (d *Dir) Create(relativePath string) (*os.File, error) {
+	path, err := d.Join(relativePath)
+	if err != nil {
+		return nil, err
+	}
+	defer d.invalidate(path)
+	return createWithPolicy(path, d.currentPolicy())
+}
+
+func (d *Dir) Mkdir(relativePath string, perm os.FileMode) error {
+	path, err := d.Join(relativePath)
+	if err != nil {
+		return err
+	}
+	defer d.invalidate(path)
+	return mkdirAllWithPolicy(path, perm, d.currentPolicy())
+}
+
+func (d *Dir) Remove(relativePath string) error {
+	path, err := d.Join(relativePath)
+	if err != nil {
+		return err
+	}
+	if path == d.absolutePath {
+		return ErrPathOutsideDir
+	}
+	defer d.invalidate(path)
+	return os.Remove(path)
+}
+
+func (d *Dir) RemoveAll(relativePath string) error {
+	path, err := d.Join(relativePath)
+	if err != nil {
+		return err
+	}
+	if path == d.absolutePath {
+		return ErrPathOutsideDir
+	}
+	defer d.invalidate(path)
+	return removeAll(path)
+}
+
+// Walk walks the directory, calling walkFunc with paths relative to it.
+func (d *Dir) Walk(walkFunc filepath.WalkFunc) error {
+	return filepath.Walk(
+		d.absolutePath,
+		func(path string, info os.FileInfo, err error) error {
+			relativePath, relErr := filepath.Rel(d.absolutePath, path)
+			if relErr != nil {
+				return relErr
+			}
+			return walkFunc(relativePath, info, err)
+		},
+	)
+}
+
+func (d *Dir) TempFile(pattern string) (*os.File, error) {
+	return ioutil.TempFile(d.absolutePath, pattern)
+}
+
+// ***** PRIVATE *****
+
+func (d *Dir) stat(absolutePath string) (os.FileInfo, error) {
+	if d.statCache != nil {
+		return d.statCache.stat(filepath.Clean(absolutePath))
+	}
+	return stat(absolutePath)
+}
+
+// invalidate drops a changed path and everything below it from the stat
+// cache, and its parents up to the Dir, whose modification times changed
+// or which MkdirAll may have created.
+func (d *Dir) invalidate(absolutePath string) {
+	if d.statCache == nil {
+		return
+	}
+	d.statCache.Invalidate(absolutePath)
+	for path := filepath.Dir(absolutePath); isWithin(d.absolutePath, path); path = filepath.Dir(path) {
+		d.statCache.invalidateEntry(path)
+		if path == d.absolutePath {
+			return
+		}
+	}
+}
+
+func (d *Dir) currentPolicy() *Policy {
+	if d.policy != nil {
+		return d.policy
+	}
+	return currentPolicy()
+}
+
+func openDir(absolutePath string) (*Dir, error) {
+	exists, err := isDirExists(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrFileDoesNotExist
+	}
+	return &Dir{
+		absolutePath: filepath.Clean(absolutePath),
+	}, nil
+}
+
+func safeJoin(absoluteBasePath string, relativePath string) (string, error) {
+	if !isAbsolutePath(absoluteBasePath) {
+		return "", ErrNotAbsolutePath
+	}
+	if filepath.IsAbs(relativePath) || filepath.VolumeName(relativePath) != "" {
+		return "", ErrPathOutsideDir
+	}
+	base := filepath.Clean(absoluteBasePath)
+	path := filepath.Join(base, relativePath)
+	if !isWithin(base, path) {
+		return "", ErrPathOutsideDir
+	}
+	return path, nil
+}
+
+func relPath(absoluteBasePath string, absoluteTarget string, options *RelPathOptions) (string, error) {
+	if !isAbsolutePath(absoluteBasePath) || !isAbsolutePath(absoluteTarget) {
+		return "", ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &RelPathOptions{}
+	}
+	base, err := evalExistingSymlinks(absoluteBasePath)
+	if err != nil {
+		return "", err
+	}
+	target, err := evalExistingSymlinks(absoluteTarget)
+	if err != nil {
+		return "", err
+	}
+	if !options.AllowOutside && !isWithin(base, target) {
+		return "", ErrPathOutsideDir
+	}
+	return filepath.Rel(base, target)
+}
+
+// evalExistingSymlinks resolves the symlinks in the longest existing prefix
+// of the clean absolutePath and joins the rest onto it.
+func evalExistingSymlinks(absolutePath string) (string, error) {
+	path := filepath.Clean(absolutePath)
+	var rest []string
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, rest...)...), nil
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
+// isWithin checks lexically whether the clean path is base or under it.
+func isWithin(base string, path string) bool {
+	if path == base {
+		return true
+	}
+	if !strings.HasSuffix(base, string(filepath.Separator)) {
+		base += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, base)
+}
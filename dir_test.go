@@ -0,0 +1,81 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestDir() {
+	dir, err := OpenDir(s.tempDir)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), dir.Mkdir("one/two", 0755))
+	file, err := dir.Create("one/two/file")
+	require.NoError(s.T(), err)
+	s.checkClose(file)
+	s.checkFileExists(filepath.Join(s.tempDir, "one/two/file"))
+	_, err = dir.Create("../escape")
+	require.Equal(s.T(), ErrPathOutsideDir, err)
+	_, err = dir.Join("/etc/passwd")
+	require.Equal(s.T(), ErrPathOutsideDir, err)
+	path, err := dir.Join("one/../one/two")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), filepath.Join(s.tempDir, "one/two"), path)
+	var walked []string
+	require.NoError(
+		s.T(),
+		dir.Walk(
+			func(path string, info os.FileInfo, err error) error {
+				walked = append(walked, path)
+				return err
+			},
+		),
+	)
+	sort.Strings(walked)
+	require.Equal(s.T(), []string{".", "one", "one/two", "one/two/file"}, walked)
+	require.Equal(s.T(), ErrPathOutsideDir, dir.RemoveAll("."))
+	require.NoError(s.T(), dir.RemoveAll("one"))
+	fileInfos, err := dir.List()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 0, len(fileInfos))
+}
+
+func (s *Suite) TestRelPath() {
+	base := filepath.Join(s.tempDir, "base")
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(base, "a", "b"), 0755))
+	relativePath, err := RelPath(base, filepath.Join(base, "a", "b"), nil)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), filepath.Join("a", "b"), relativePath)
+	relativePath, err = RelPath(base, base, nil)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), ".", relativePath)
+	relativePath, err = RelPath(base, filepath.Join(base, "a", "not", "yet"), nil)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), filepath.Join("a", "not", "yet"), relativePath)
+
+	_, err = RelPath(base, s.tempDir, nil)
+	require.Equal(s.T(), ErrPathOutsideDir, err)
+	_, err = RelPath(base, filepath.Join(base, "..", "baseline"), nil)
+	require.Equal(s.T(), ErrPathOutsideDir, err)
+	relativePath, err = RelPath(base, filepath.Join(s.tempDir, "other"), &RelPathOptions{AllowOutside: true})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), filepath.Join("..", "other"), relativePath)
+	_, err = RelPath("relative", base, nil)
+	require.Equal(s.T(), ErrNotAbsolutePath, err)
+
+	if runtime.GOOS != "windows" {
+		// a symlink inside base pointing out of it is outside
+		require.NoError(s.T(), os.Symlink(s.tempDir, filepath.Join(base, "escape")))
+		_, err = RelPath(base, filepath.Join(base, "escape", "file"), nil)
+		require.Equal(s.T(), ErrPathOutsideDir, err)
+		// and a base reached through a symlink still contains its entries
+		link := filepath.Join(s.tempDir, "link")
+		require.NoError(s.T(), os.Symlink(base, link))
+		relativePath, err = RelPath(link, filepath.Join(base, "a"), nil)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), "a", relativePath)
+	}
+}
@@ -0,0 +1,81 @@
+package osutils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DirFS is an io/fs view of a Dir, for fs.WalkDir, http.FS, template
+// parsing and other standard library consumers of fs.FS.
+type DirFS interface {
+	fs.ReadDirFS
+	fs.ReadFileFS
+	fs.StatFS
+}
+
+// AsFS returns a DirFS rooted at the directory at absolutePath.
+func AsFS(absolutePath string) (DirFS, error) {
+	dir, err := openDir(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+	return dir.FS(), nil
+}
+
+// FS returns a DirFS rooted at the Dir. As with the Dir itself, names that
+// would resolve outside of it are rejected, but symlinks are followed.
+func (d *Dir) FS() DirFS {
+	return &dirFS{d}
+}
+
+// ***** PRIVATE *****
+
+type dirFS struct {
+	dir *Dir
+}
+
+func (d *dirFS) Open(name string) (fs.File, error) {
+	path, err := d.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (d *dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	path, err := d.path("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadDir(path)
+}
+
+func (d *dirFS) ReadFile(name string) ([]byte, error) {
+	path, err := d.path("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func (d *dirFS) Stat(name string) (fs.FileInfo, error) {
+	path, err := d.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(path)
+}
+
+// path validates an fs.FS name, which is always slash-separated and
+// unrooted, and joins it onto the Dir.
+func (d *dirFS) path(op string, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	path, err := d.dir.Join(filepath.FromSlash(name))
+	if err != nil {
+		return "", &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return path, nil
+}
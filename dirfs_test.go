@@ -0,0 +1,28 @@
+package osutils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestAsFS() {
+	src := s.writeCopyDirSrc()
+	dirFS, err := AsFS(src)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), fstest.TestFS(dirFS, "a/1", "b/2", "link"))
+	data, err := fs.ReadFile(dirFS, "b/2")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "2", string(data))
+	_, err = dirFS.Open("../escape")
+	require.Error(s.T(), err)
+	_, err = dirFS.Open("/etc/passwd")
+	require.Error(s.T(), err)
+	_, err = AsFS(filepath.Join(s.tempDir, "missing"))
+	require.Equal(s.T(), ErrFileDoesNotExist, err)
+	_, err = dirFS.Stat("missing")
+	require.True(s.T(), os.IsNotExist(err))
+}
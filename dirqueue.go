@@ -0,0 +1,231 @@
+package osutils
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satori/go.uuid"
+)
+
+// DirQueue is a maildir-style work queue shared between processes.
+// Producers write items to tmp/ and rename them into new/. Consumers claim
+// an item by renaming it from new/ to cur/, which only one of them can win,
+// and remove it from cur/ when done.
+type DirQueue struct {
+	root string
+}
+
+type DirQueueItem struct {
+	Name string
+	// Path is the claimed file in cur/.
+	Path  string
+	queue *DirQueue
+}
+
+// OpenDirQueue creates the queue directories under root if needed.
+func OpenDirQueue(absoluteRoot string) (*DirQueue, error) {
+	if !isAbsolutePath(absoluteRoot) {
+		return nil, ErrNotAbsolutePath
+	}
+	dirQueue := &DirQueue{filepath.Clean(absoluteRoot)}
+	for _, dir := range []string{dirQueue.tmpDir(), dirQueue.newDir(), dirQueue.curDir()} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+	}
+	return dirQueue, nil
+}
+
+// Put adds an item and returns its name. Items are claimed in name order,
+// which is the order they were put.
+func (q *DirQueue) Put(data []byte) (string, error) {
+	name := strconv.FormatInt(time.Now().UnixNano(), 10) + "." + uuid.NewV4().String()
+	tmpPath := filepath.Join(q.tmpDir(), name)
+	if err := writeAtomic(tmpPath, 0600, func(writer io.Writer) error {
+		_, err := writer.Write(data)
+		return err
+	}); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpPath, filepath.Join(q.newDir(), name)); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", err
+	}
+	return name, nil
+}
+
+// Claim claims the oldest item in new/, or returns nil if there is none.
+func (q *DirQueue) Claim() (*DirQueueItem, error) {
+	names, err := q.pendingNames()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		newPath := filepath.Join(q.newDir(), name)
+		curPath := filepath.Join(q.curDir(), name)
+		// the mtime is the claim time for Recover, set before the rename so
+		// that a claimed item never has the old one, and so that a failure
+		// leaves the item in new/
+		now := time.Now()
+		if err := os.Chtimes(newPath, now, now); err != nil {
+			// another consumer won
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if err := os.Rename(newPath, curPath); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		return &DirQueueItem{Name: name, Path: curPath, queue: q}, nil
+	}
+	return nil, nil
+}
+
+// ClaimWait claims the oldest item, waiting for one to be put if the queue
+// is empty. It uses a Watcher on new/ where supported, and a PollWatcher
+// otherwise, and returns ErrWatcherClosed if the watcher stops.
+func (q *DirQueue) ClaimWait(ctx context.Context) (*DirQueueItem, error) {
+	watcher, err := newDirQueueWatcher(q.newDir())
+	if err != nil {
+		return nil, err
+	}
+	defer watcher.Close()
+	for {
+		// claim after the watch is set up so no put is missed
+		dirQueueItem, err := q.Claim()
+		if err != nil || dirQueueItem != nil {
+			return dirQueueItem, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		// a closed channel is always ready, and would spin on Claim
+		case err, ok := <-watcher.Errors():
+			if !ok {
+				return nil, ErrWatcherClosed
+			}
+			if err != nil {
+				return nil, err
+			}
+		case _, ok := <-watcher.Events():
+			if !ok {
+				return nil, ErrWatcherClosed
+			}
+		}
+	}
+}
+
+// Len is the number of unclaimed items.
+func (q *DirQueue) Len() (int, error) {
+	names, err := q.pendingNames()
+	if err != nil {
+		return 0, err
+	}
+	return len(names), nil
+}
+
+// Recover puts items claimed more than olderThan ago back into new/, for
+// consumers that crashed, and removes writes abandoned in tmp/. It returns
+// the number of items put back.
+func (q *DirQueue) Recover(olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	tmpInfos, err := ioutil.ReadDir(q.tmpDir())
+	if err != nil {
+		return 0, err
+	}
+	for _, fileInfo := range tmpInfos {
+		if fileInfo.ModTime().Before(cutoff) {
+			if err := os.Remove(filepath.Join(q.tmpDir(), fileInfo.Name())); err != nil && !os.IsNotExist(err) {
+				return 0, err
+			}
+		}
+	}
+	curInfos, err := ioutil.ReadDir(q.curDir())
+	if err != nil {
+		return 0, err
+	}
+	recovered := 0
+	for _, fileInfo := range curInfos {
+		if !fileInfo.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Rename(filepath.Join(q.curDir(), fileInfo.Name()), filepath.Join(q.newDir(), fileInfo.Name())); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return recovered, err
+		}
+		recovered++
+	}
+	return recovered, nil
+}
+
+func (i *DirQueueItem) Read() ([]byte, error) {
+	return ioutil.ReadFile(i.Path)
+}
+
+// Done removes the item from the queue.
+func (i *DirQueueItem) Done() error {
+	return os.Remove(i.Path)
+}
+
+// Release puts the item back so another consumer can claim it.
+func (i *DirQueueItem) Release() error {
+	return os.Rename(i.Path, filepath.Join(i.queue.newDir(), i.Name))
+}
+
+// ***** PRIVATE *****
+
+var (
+	// newDirQueueWatcher is swapped in tests for a watcher that stops.
+	newDirQueueWatcher = func(dir string) (FileWatcher, error) {
+		watcher, err := NewWatcher(dir)
+		if err == ErrNotSupported {
+			return NewPollWatcher(dir, &PollWatcherOptions{Interval: time.Second})
+		}
+		return watcher, err
+	}
+)
+
+func (q *DirQueue) tmpDir() string {
+	return filepath.Join(q.root, "tmp")
+}
+
+func (q *DirQueue) newDir() string {
+	return filepath.Join(q.root, "new")
+}
+
+func (q *DirQueue) curDir() string {
+	return filepath.Join(q.root, "cur")
+}
+
+func (q *DirQueue) pendingNames() ([]string, error) {
+	dir, err := os.Open(q.newDir())
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+	pending := names[:0]
+	for _, name := range names {
+		if !strings.HasPrefix(name, ".") {
+			pending = append(pending, name)
+		}
+	}
+	sort.Strings(pending)
+	return pending, nil
+}
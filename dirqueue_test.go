@@ -0,0 +1,114 @@
+package osutils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestDirQueue() {
+	dirQueue, err := OpenDirQueue(filepath.Join(s.tempDir, "queue"))
+	require.NoError(s.T(), err)
+	dirQueueItem, err := dirQueue.Claim()
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), dirQueueItem)
+
+	_, err = dirQueue.Put([]byte("one"))
+	require.NoError(s.T(), err)
+	_, err = dirQueue.Put([]byte("two"))
+	require.NoError(s.T(), err)
+	length, err := dirQueue.Len()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 2, length)
+
+	dirQueueItem, err = dirQueue.Claim()
+	require.NoError(s.T(), err)
+	data, err := dirQueueItem.Read()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "one", string(data))
+	require.NoError(s.T(), dirQueueItem.Done())
+
+	// a crashed consumer's claim is recovered
+	dirQueueItem, err = dirQueue.Claim()
+	require.NoError(s.T(), err)
+	old := time.Now().Add(-time.Hour)
+	require.NoError(s.T(), os.Chtimes(dirQueueItem.Path, old, old))
+	recovered, err := dirQueue.Recover(time.Minute)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, recovered)
+	dirQueueItem, err = dirQueue.Claim()
+	require.NoError(s.T(), err)
+	// claiming it again makes it recent
+	recovered, err = dirQueue.Recover(time.Minute)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 0, recovered)
+	data, err = dirQueueItem.Read()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "two", string(data))
+	require.NoError(s.T(), dirQueueItem.Done())
+}
+
+func (s *Suite) TestDirQueueClaimWait() {
+	dirQueue, err := OpenDirQueue(filepath.Join(s.tempDir, "queue"))
+	require.NoError(s.T(), err)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = dirQueue.Put([]byte("hello"))
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	dirQueueItem, err := dirQueue.ClaimWait(ctx)
+	require.NoError(s.T(), err)
+	data, err := dirQueueItem.Read()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "hello", string(data))
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = dirQueue.ClaimWait(ctx)
+	require.Equal(s.T(), context.DeadlineExceeded, err)
+}
+
+func (s *Suite) TestDirQueueClaimWaitWatcherClosed() {
+	dirQueue, err := OpenDirQueue(filepath.Join(s.tempDir, "queue"))
+	require.NoError(s.T(), err)
+	defer func(fn func(string) (FileWatcher, error)) { newDirQueueWatcher = fn }(newDirQueueWatcher)
+	newDirQueueWatcher = func(string) (FileWatcher, error) {
+		return newClosedWatcher(), nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = dirQueue.ClaimWait(ctx)
+	require.Equal(s.T(), ErrWatcherClosed, err)
+}
+
+// closedWatcher is a FileWatcher that has already stopped.
+type closedWatcher struct {
+	events chan *WatchEvent
+	errors chan error
+}
+
+func newClosedWatcher() *closedWatcher {
+	closedWatcher := &closedWatcher{
+		events: make(chan *WatchEvent),
+		errors: make(chan error),
+	}
+	close(closedWatcher.events)
+	close(closedWatcher.errors)
+	return closedWatcher
+}
+
+func (c *closedWatcher) Events() <-chan *WatchEvent {
+	return c.events
+}
+
+func (c *closedWatcher) Errors() <-chan error {
+	return c.errors
+}
+
+func (c *closedWatcher) Close() error {
+	return nil
+}
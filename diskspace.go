@@ -0,0 +1,81 @@
+package osutils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var (
+	ErrInsufficientSpace = errors.New("osutils: insufficient disk space")
+)
+
+// AvailableDiskSpace returns the bytes available to the current user on
+// the filesystem holding absolutePath, which need not exist yet.
+func AvailableDiskSpace(absolutePath string) (uint64, error) {
+	if !isAbsolutePath(absolutePath) {
+		return 0, ErrNotAbsolutePath
+	}
+	existingPath, err := nearestExistingPath(absolutePath)
+	if err != nil {
+		return 0, err
+	}
+	return availableDiskSpace(existingPath)
+}
+
+// PreflightSpaceCheck fails with ErrInsufficientSpace if fewer than
+// requiredBytes are available at dst, so a large write can fail before it
+// starts rather than part way.
+func PreflightSpaceCheck(dst string, requiredBytes int64) error {
+	available, err := AvailableDiskSpace(dst)
+	if err != nil {
+		return err
+	}
+	if requiredBytes > 0 && uint64(requiredBytes) > available {
+		return ErrInsufficientSpace
+	}
+	return nil
+}
+
+// PreflightTreeSpaceCheck runs PreflightSpaceCheck for copying the tree at
+// src to dst, estimating the space as the total size of its files.
+func PreflightTreeSpaceCheck(src string, dst string) error {
+	if !isAbsolutePath(src) {
+		return ErrNotAbsolutePath
+	}
+	treeStatistics, err := treeStats(src)
+	if err != nil {
+		return err
+	}
+	return PreflightSpaceCheck(dst, treeStatistics.Bytes)
+}
+
+// ***** PRIVATE *****
+
+// availableBlocks handles the count of available blocks being signed on
+// some platforms, where it is negative once root has used into the blocks
+// reserved for it.
+func availableBlocks[T int64 | uint64](bavail T) uint64 {
+	if bavail < 0 {
+		return 0
+	}
+	return uint64(bavail)
+}
+
+func nearestExistingPath(absolutePath string) (string, error) {
+	path := filepath.Clean(absolutePath)
+	for {
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,13 @@
+package osutils
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func availableDiskSpace(absolutePath string) (uint64, error) {
+	var stat unix.Statvfs_t
+	if err := unix.Statvfs(absolutePath, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Frsize), nil
+}
@@ -0,0 +1,13 @@
+package osutils
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func availableDiskSpace(absolutePath string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(absolutePath, &stat); err != nil {
+		return 0, err
+	}
+	return availableBlocks(stat.F_bavail) * uint64(stat.F_bsize), nil
+}
@@ -0,0 +1,7 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+
+package osutils
+
+func availableDiskSpace(absolutePath string) (uint64, error) {
+	return 0, ErrNotSupported
+}
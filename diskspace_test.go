@@ -0,0 +1,26 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"math"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestPreflightSpaceCheck() {
+	available, err := AvailableDiskSpace(filepath.Join(s.tempDir, "does", "not", "exist"))
+	require.NoError(s.T(), err)
+	require.NotZero(s.T(), available)
+	require.NoError(s.T(), PreflightSpaceCheck(s.tempDir, 1))
+	require.Equal(s.T(), ErrInsufficientSpace, PreflightSpaceCheck(s.tempDir, math.MaxInt64))
+
+	src := filepath.Join(s.tempDir, "file")
+	require.NoError(s.T(), ioutil.WriteFile(src, []byte("hello"), 0644))
+	require.NoError(s.T(), PreflightTreeSpaceCheck(src, filepath.Join(s.tempDir, "dst")))
+
+	srcDir := s.writeCopyDirSrc()
+	dstDir := filepath.Join(s.tempDir, "dstdir")
+	require.NoError(s.T(), CopyDir(srcDir, dstDir, &CopyDirOptions{CheckSpace: true}))
+	s.checkFileExists(filepath.Join(dstDir, "a", "1"))
+}
@@ -0,0 +1,15 @@
+//go:build darwin || dragonfly || freebsd || linux
+
+package osutils
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func availableDiskSpace(absolutePath string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(absolutePath, &stat); err != nil {
+		return 0, err
+	}
+	return availableBlocks(stat.Bavail) * uint64(stat.Bsize), nil
+}
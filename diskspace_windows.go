@@ -0,0 +1,28 @@
+package osutils
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procGetDiskFreeSpaceExW = kernel32.NewProc("GetDiskFreeSpaceExW")
+)
+
+func availableDiskSpace(absolutePath string) (uint64, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(absolutePath)
+	if err != nil {
+		return 0, err
+	}
+	var available, total, free uint64
+	ret, _, err := procGetDiskFreeSpaceExW.Call(
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(unsafe.Pointer(&available)),
+		uintptr(unsafe.Pointer(&total)),
+		uintptr(unsafe.Pointer(&free)),
+	)
+	if ret == 0 {
+		return 0, err
+	}
+	return available, nil
+}
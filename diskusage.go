@@ -0,0 +1,41 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DiskUsage returns the bytes allocated on disk for the tree at
+// absolutePath, like du -s. A file with several hard links in the tree is
+// counted once per link. The progress has an unknown total and steps by
+// the allocated size of each entry.
+func DiskUsage(absolutePath string, progress Progress) (int64, error) {
+	if !isAbsolutePath(absolutePath) {
+		return 0, ErrNotAbsolutePath
+	}
+	return diskUsage(absolutePath, progressOrNop(progress))
+}
+
+// ***** PRIVATE *****
+
+func diskUsage(absolutePath string, progress Progress) (int64, error) {
+	progress.Start(-1)
+	var total int64
+	err := filepath.Walk(
+		absolutePath,
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			size := allocatedSize(info)
+			total += size
+			progress.Step(size, path)
+			return nil
+		},
+	)
+	progress.Done(err)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
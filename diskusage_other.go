@@ -0,0 +1,12 @@
+//go:build !unix && !windows
+
+package osutils
+
+import (
+	"os"
+)
+
+// allocatedSize is the apparent size, the allocated blocks are not known.
+func allocatedSize(info os.FileInfo) int64 {
+	return info.Size()
+}
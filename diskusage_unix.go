@@ -0,0 +1,16 @@
+//go:build unix
+
+package osutils
+
+import (
+	"os"
+	"syscall"
+)
+
+func allocatedSize(info os.FileInfo) int64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		// st_blocks is in 512-byte units regardless of the block size
+		return int64(stat.Blocks) * 512
+	}
+	return info.Size()
+}
@@ -0,0 +1,12 @@
+package osutils
+
+import (
+	"os"
+)
+
+func allocatedSize(info os.FileInfo) int64 {
+	if info.IsDir() {
+		return 0
+	}
+	return info.Size()
+}
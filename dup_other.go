@@ -0,0 +1,11 @@
+//go:build unix && !linux
+
+package osutils
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func dup2(oldfd int, newfd int) error {
+	return unix.Dup2(oldfd, newfd)
+}
@@ -0,0 +1,24 @@
+package osutils
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// dupCloexec duplicates fd onto the lowest free fd at or above min. AIX
+// has no F_DUPFD_CLOEXEC, so the flag is set afterwards while holding
+// syscall.ForkLock so that no child inherits the new fd in between.
+func dupCloexec(fd int, min int) (int, error) {
+	syscall.ForkLock.RLock()
+	defer syscall.ForkLock.RUnlock()
+	newfd, err := unix.FcntlInt(uintptr(fd), unix.F_DUPFD, min)
+	if err != nil {
+		return -1, err
+	}
+	if _, err := unix.FcntlInt(uintptr(newfd), unix.F_SETFD, unix.FD_CLOEXEC); err != nil {
+		_ = unix.Close(newfd)
+		return -1, err
+	}
+	return newfd, nil
+}
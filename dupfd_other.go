@@ -0,0 +1,12 @@
+//go:build unix && !aix
+
+package osutils
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// dupCloexec duplicates fd onto the lowest free fd at or above min.
+func dupCloexec(fd int, min int) (int, error) {
+	return unix.FcntlInt(uintptr(fd), unix.F_DUPFD_CLOEXEC, min)
+}
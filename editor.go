@@ -0,0 +1,150 @@
+package osutils
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var ErrAnchorNotFound = errors.New("osutils: anchor not found")
+
+// Editor makes idempotent line edits to text files such as /etc/hosts,
+// each an atomic rewrite under the same lock as UpdateFile. Files that are
+// not changed are not written. Line endings are matched without a
+// trailing \r, and a changed file always ends with a newline.
+type Editor struct {
+	options *WriteOptions
+}
+
+// NewEditor returns an Editor writing with options, which may be nil.
+// Existing files keep their permissions unless options.Perm is set.
+func NewEditor(options *WriteOptions) *Editor {
+	return &Editor{
+		options: options,
+	}
+}
+
+// EnsureLine appends line to the file at absolutePath, creating it if
+// needed, unless the file already has the line, and returns whether it
+// did.
+func (e *Editor) EnsureLine(absolutePath string, line string) (bool, error) {
+	return e.edit(
+		absolutePath,
+		true,
+		func(lines []string) ([]string, error) {
+			if indexLine(lines, line) >= 0 {
+				return nil, nil
+			}
+			return append(lines, line), nil
+		},
+	)
+}
+
+// RemoveLinesMatching removes the lines of the file at absolutePath
+// matching re and returns how many it removed.
+func (e *Editor) RemoveLinesMatching(absolutePath string, re *regexp.Regexp) (int, error) {
+	if re == nil {
+		return 0, ErrNil
+	}
+	removed := 0
+	if _, err := e.edit(
+		absolutePath,
+		false,
+		func(lines []string) ([]string, error) {
+			kept := []string{}
+			for _, existing := range lines {
+				if re.MatchString(strings.TrimSuffix(existing, "\r")) {
+					removed++
+					continue
+				}
+				kept = append(kept, existing)
+			}
+			if removed == 0 {
+				return nil, nil
+			}
+			return kept, nil
+		},
+	); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// InsertAfter inserts line after the last line of the file at absolutePath
+// matching anchor, unless the file already has the line anywhere, and
+// returns whether it did. ErrAnchorNotFound is returned if no line
+// matches anchor.
+func (e *Editor) InsertAfter(absolutePath string, anchor *regexp.Regexp, line string) (bool, error) {
+	if anchor == nil {
+		return false, ErrNil
+	}
+	return e.edit(
+		absolutePath,
+		false,
+		func(lines []string) ([]string, error) {
+			if indexLine(lines, line) >= 0 {
+				return nil, nil
+			}
+			for i := len(lines) - 1; i >= 0; i-- {
+				if anchor.MatchString(strings.TrimSuffix(lines[i], "\r")) {
+					edited := append(append(append([]string{}, lines[:i+1]...), line), lines[i+1:]...)
+					return edited, nil
+				}
+			}
+			return nil, ErrAnchorNotFound
+		},
+	)
+}
+
+// ***** PRIVATE *****
+
+// edit passes the lines of the file to fn, which returns nil to leave the
+// file as it is.
+func (e *Editor) edit(absolutePath string, create bool, fn func([]string) ([]string, error)) (bool, error) {
+	if !isAbsolutePath(absolutePath) {
+		return false, ErrNotAbsolutePath
+	}
+	changed := false
+	err := UpdateFile(
+		absolutePath,
+		func(data []byte) ([]byte, error) {
+			if data == nil && !create {
+				if _, err := os.Stat(absolutePath); err != nil {
+					return nil, err
+				}
+			}
+			lines, err := fn(splitLines(string(data)))
+			if err != nil || lines == nil {
+				return data, err
+			}
+			changed = true
+			return []byte(joinLines(lines)), nil
+		},
+		e.options,
+	)
+	return changed, err
+}
+
+func indexLine(lines []string, line string) int {
+	for i, existing := range lines {
+		if strings.TrimSuffix(existing, "\r") == line {
+			return i
+		}
+	}
+	return -1
+}
+
+func splitLines(data string) []string {
+	if data == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(data, "\n"), "\n")
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
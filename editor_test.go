@@ -0,0 +1,49 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestEditor() {
+	path := filepath.Join(s.tempDir, "hosts")
+	editor := NewEditor(nil)
+
+	changed, err := editor.InsertAfter(path, regexp.MustCompile(`^127`), "a")
+	require.True(s.T(), os.IsNotExist(err))
+	require.False(s.T(), changed)
+
+	changed, err = editor.EnsureLine(path, "127.0.0.1 localhost")
+	require.NoError(s.T(), err)
+	require.True(s.T(), changed)
+	changed, err = editor.EnsureLine(path, "127.0.0.1 localhost")
+	require.NoError(s.T(), err)
+	require.False(s.T(), changed)
+	require.NoError(s.T(), ioutil.WriteFile(path, []byte("127.0.0.1 localhost\r\n10.0.0.1 db"), 0644))
+
+	changed, err = editor.InsertAfter(path, regexp.MustCompile(`^127\.`), "127.0.1.1 host")
+	require.NoError(s.T(), err)
+	require.True(s.T(), changed)
+	changed, err = editor.InsertAfter(path, regexp.MustCompile(`^127\.`), "127.0.1.1 host")
+	require.NoError(s.T(), err)
+	require.False(s.T(), changed)
+	_, err = editor.InsertAfter(path, regexp.MustCompile(`^::1`), "::1 localhost")
+	require.Equal(s.T(), ErrAnchorNotFound, err)
+	data, err := ioutil.ReadFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "127.0.0.1 localhost\r\n127.0.1.1 host\n10.0.0.1 db\n", string(data))
+
+	removed, err := editor.RemoveLinesMatching(path, regexp.MustCompile(`^127\.`))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 2, removed)
+	removed, err = editor.RemoveLinesMatching(path, regexp.MustCompile(`db$`))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, removed)
+	data, err = ioutil.ReadFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "", string(data))
+}
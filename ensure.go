@@ -0,0 +1,145 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+type ModeChange struct {
+	Path    string
+	OldMode os.FileMode
+	NewMode os.FileMode
+}
+
+type OwnershipChange struct {
+	Path   string
+	OldUID int
+	OldGID int
+	NewUID int
+	NewGID int
+	// OldOwner and NewOwner are the owner SIDs set by EnsureOwnerSID.
+	OldOwner string
+	NewOwner string
+}
+
+// EnsureOwnership changes the owner of absolutePath, and everything under it
+// if recursive, to uid and gid where they differ, and returns what was
+// changed. A uid or gid of -1 leaves it unchanged. Symlinks themselves are
+// changed rather than their targets. This is a no-op on Windows, see
+// EnsureOwnerSID.
+func EnsureOwnership(absolutePath string, uid int, gid int, recursive bool) ([]*OwnershipChange, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	if recursive {
+		if err := checkGuard(absolutePath); err != nil {
+			return nil, err
+		}
+	}
+	var changes []*OwnershipChange
+	if err := walkEnsure(
+		absolutePath,
+		recursive,
+		func(path string, info os.FileInfo) error {
+			oldUID, oldGID, ok := fileOwner(info)
+			if !ok {
+				return nil
+			}
+			newUID, newGID := oldUID, oldGID
+			if uid >= 0 {
+				newUID = uid
+			}
+			if gid >= 0 {
+				newGID = gid
+			}
+			if newUID == oldUID && newGID == oldGID {
+				return nil
+			}
+			if err := os.Lchown(path, newUID, newGID); err != nil {
+				return err
+			}
+			changes = append(
+				changes,
+				&OwnershipChange{
+					Path:   path,
+					OldUID: oldUID,
+					OldGID: oldGID,
+					NewUID: newUID,
+					NewGID: newGID,
+				},
+			)
+			return nil
+		},
+	); err != nil {
+		return changes, err
+	}
+	return changes, nil
+}
+
+// EnsureMode sets the permission bits of absolutePath, and everything under
+// it if recursive, to perm where they differ, and returns what was changed.
+// Symlinks are skipped. On Windows the bits are mapped to the DACL, with the
+// group bits for Users and the other bits for Everyone.
+func EnsureMode(absolutePath string, perm os.FileMode, recursive bool) ([]*ModeChange, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	if recursive {
+		if err := checkGuard(absolutePath); err != nil {
+			return nil, err
+		}
+	}
+	var changes []*ModeChange
+	if err := walkEnsure(
+		absolutePath,
+		recursive,
+		func(path string, info os.FileInfo) error {
+			if info.Mode()&os.ModeSymlink != 0 {
+				return nil
+			}
+			oldMode, err := fileMode(path, info)
+			if err != nil {
+				return err
+			}
+			if oldMode == perm.Perm() {
+				return nil
+			}
+			if err := setFileMode(path, perm.Perm(), info.IsDir()); err != nil {
+				return err
+			}
+			changes = append(
+				changes,
+				&ModeChange{
+					Path:    path,
+					OldMode: oldMode,
+					NewMode: perm.Perm(),
+				},
+			)
+			return nil
+		},
+	); err != nil {
+		return changes, err
+	}
+	return changes, nil
+}
+
+// ***** PRIVATE *****
+
+func walkEnsure(absolutePath string, recursive bool, fn func(string, os.FileInfo) error) error {
+	if !recursive {
+		info, err := os.Lstat(absolutePath)
+		if err != nil {
+			return err
+		}
+		return fn(absolutePath, info)
+	}
+	return filepath.Walk(
+		absolutePath,
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			return fn(path, info)
+		},
+	)
+}
@@ -0,0 +1,40 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestEnsureMode() {
+	dir := filepath.Join(s.tempDir, "dir")
+	require.NoError(s.T(), os.Mkdir(dir, 0700))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(dir, "one"), nil, 0600))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(dir, "two"), nil, 0700))
+	changes, err := EnsureMode(dir, 0700, true)
+	require.NoError(s.T(), err)
+	require.Equal(
+		s.T(),
+		[]*ModeChange{
+			&ModeChange{
+				Path:    filepath.Join(dir, "one"),
+				OldMode: 0600,
+				NewMode: 0700,
+			},
+		},
+		changes,
+	)
+	changes, err = EnsureMode(dir, 0700, true)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), changes)
+}
+
+func (s *Suite) TestEnsureOwnership() {
+	path := filepath.Join(s.tempDir, "file")
+	require.NoError(s.T(), ioutil.WriteFile(path, nil, 0600))
+	changes, err := EnsureOwnership(path, os.Getuid(), os.Getgid(), false)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), changes)
+}
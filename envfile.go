@@ -0,0 +1,315 @@
+package osutils
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	envFilePerm = 0600
+)
+
+var (
+	ErrInvalidEnvFile = errors.New("osutils: invalid env file")
+)
+
+func LoadEnvFile(absolutePath string) (map[string]string, error) {
+	return loadEnvFile(absolutePath)
+}
+
+func ParseEnvFile(data []byte) (map[string]string, error) {
+	return parseEnvFile(data)
+}
+
+func WriteEnvFile(absolutePath string, env map[string]string) error {
+	return writeEnvFile(absolutePath, env)
+}
+
+// MergeIntoCmdEnv sets the given variables on cmd.Env, overriding existing
+// values. If cmd.Env is nil, the current process environment is used as the
+// base so that the command still inherits it.
+func MergeIntoCmdEnv(cmd *Cmd, env map[string]string) error {
+	return mergeIntoCmdEnv(cmd, env)
+}
+
+// ***** PRIVATE *****
+
+func loadEnvFile(absolutePath string) (map[string]string, error) {
+	file, err := open(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return parseEnvFile(data)
+}
+
+func parseEnvFile(data []byte) (map[string]string, error) {
+	env := make(map[string]string)
+	parser := &envFileParser{data: string(data)}
+	for {
+		key, value, ok, err := parser.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return env, nil
+		}
+		env[key] = value
+	}
+}
+
+func writeEnvFile(absolutePath string, env map[string]string) error {
+	if env == nil {
+		return ErrNil
+	}
+	var buffer bytes.Buffer
+	for _, key := range sortedKeys(env) {
+		if !isValidEnvKey(key) {
+			return ErrInvalidEnvFile
+		}
+		_, _ = buffer.WriteString(key)
+		_, _ = buffer.WriteString("=")
+		_, _ = buffer.WriteString(quoteEnvValue(env[key]))
+		_, _ = buffer.WriteString("\n")
+	}
+	file, err := openFile(absolutePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, envFilePerm)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(buffer.Bytes()); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func mergeIntoCmdEnv(cmd *Cmd, env map[string]string) error {
+	if cmd == nil || env == nil {
+		return ErrNil
+	}
+	base := cmd.Env
+	if base == nil {
+		base = os.Environ()
+	}
+	cmd.Env = mergeEnv(base, env)
+	return nil
+}
+
+// mergeEnv returns base with the values in env set, keeping the order of
+// base and appending new keys sorted.
+func mergeEnv(base []string, env map[string]string) []string {
+	merged := make([]string, 0, len(base)+len(env))
+	seen := make(map[string]bool, len(env))
+	for _, entry := range base {
+		key := envKey(entry)
+		if value, ok := env[key]; ok {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, key+"="+value)
+			continue
+		}
+		merged = append(merged, entry)
+	}
+	for _, key := range sortedKeys(env) {
+		if !seen[key] {
+			merged = append(merged, key+"="+env[key])
+		}
+	}
+	return merged
+}
+
+// envSliceToMap converts KEY=value entries, with later entries winning.
+func envSliceToMap(env []string) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, entry := range env {
+		key := envKey(entry)
+		m[key] = strings.TrimPrefix(entry[len(key):], "=")
+	}
+	return m
+}
+
+func envKey(entry string) string {
+	if i := strings.Index(entry, "="); i >= 0 {
+		return entry[:i]
+	}
+	return entry
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isValidEnvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, c := range key {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func quoteEnvValue(value string) string {
+	var buffer bytes.Buffer
+	_ = buffer.WriteByte('"')
+	for _, c := range value {
+		switch c {
+		case '\\', '"', '$':
+			_ = buffer.WriteByte('\\')
+			_, _ = buffer.WriteRune(c)
+		case '\n':
+			_, _ = buffer.WriteString(`\n`)
+		case '\r':
+			_, _ = buffer.WriteString(`\r`)
+		case '\t':
+			_, _ = buffer.WriteString(`\t`)
+		default:
+			_, _ = buffer.WriteRune(c)
+		}
+	}
+	_ = buffer.WriteByte('"')
+	return buffer.String()
+}
+
+type envFileParser struct {
+	data string
+	pos  int
+}
+
+func (p *envFileParser) next() (string, string, bool, error) {
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return "", "", false, nil
+		}
+		if c := p.data[p.pos]; c == '#' || c == '\n' || c == '\r' {
+			p.skipLine()
+			continue
+		}
+		break
+	}
+	line := p.data[p.pos:]
+	if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+		p.pos += len("export")
+		p.skipSpace()
+	}
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] != '=' && p.data[p.pos] != '\n' {
+		p.pos++
+	}
+	if p.pos >= len(p.data) || p.data[p.pos] != '=' {
+		return "", "", false, ErrInvalidEnvFile
+	}
+	key := strings.TrimSpace(p.data[start:p.pos])
+	if !isValidEnvKey(key) {
+		return "", "", false, ErrInvalidEnvFile
+	}
+	p.pos++
+	p.skipSpace()
+	value, err := p.value()
+	if err != nil {
+		return "", "", false, err
+	}
+	return key, value, true, nil
+}
+
+func (p *envFileParser) value() (string, error) {
+	if p.pos >= len(p.data) {
+		return "", nil
+	}
+	switch p.data[p.pos] {
+	case '\'':
+		end := strings.IndexByte(p.data[p.pos+1:], '\'')
+		if end < 0 {
+			return "", ErrInvalidEnvFile
+		}
+		value := p.data[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return value, p.endOfLine()
+	case '"':
+		var buffer bytes.Buffer
+		for p.pos++; p.pos < len(p.data); p.pos++ {
+			c := p.data[p.pos]
+			if c == '"' {
+				p.pos++
+				return buffer.String(), p.endOfLine()
+			}
+			if c == '\\' && p.pos+1 < len(p.data) {
+				p.pos++
+				switch e := p.data[p.pos]; e {
+				case 'n':
+					_ = buffer.WriteByte('\n')
+				case 'r':
+					_ = buffer.WriteByte('\r')
+				case 't':
+					_ = buffer.WriteByte('\t')
+				case '\\', '"', '$', '\'':
+					_ = buffer.WriteByte(e)
+				default:
+					_ = buffer.WriteByte('\\')
+					_ = buffer.WriteByte(e)
+				}
+				continue
+			}
+			_ = buffer.WriteByte(c)
+		}
+		return "", ErrInvalidEnvFile
+	default:
+		start := p.pos
+		p.skipLine()
+		value := strings.TrimRight(p.data[start:p.pos], "\r\n")
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = value[:i]
+		}
+		return strings.TrimSpace(value), nil
+	}
+}
+
+// endOfLine consumes trailing whitespace and an optional comment after a
+// quoted value.
+func (p *envFileParser) endOfLine() error {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil
+	}
+	switch p.data[p.pos] {
+	case '\n', '\r', '#':
+		p.skipLine()
+		return nil
+	default:
+		return ErrInvalidEnvFile
+	}
+}
+
+func (p *envFileParser) skipSpace() {
+	for p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *envFileParser) skipLine() {
+	for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+		p.pos++
+	}
+	if p.pos < len(p.data) {
+		p.pos++
+	}
+}
@@ -0,0 +1,51 @@
+package osutils
+
+import (
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestParseEnvFile() {
+	env, err := ParseEnvFile(
+		[]byte(`# comment
+FOO=foo
+export BAR = "bar \"baz\"\n"
+SINGLE='a $b \c'
+INLINE=value # comment
+EMPTY=
+MULTI="one
+two"
+`),
+	)
+	require.NoError(s.T(), err)
+	require.Equal(
+		s.T(),
+		map[string]string{
+			"FOO":    "foo",
+			"BAR":    "bar \"baz\"\n",
+			"SINGLE": `a $b \c`,
+			"INLINE": "value",
+			"EMPTY":  "",
+			"MULTI":  "one\ntwo",
+		},
+		env,
+	)
+	_, err = ParseEnvFile([]byte("NOEQUALS\n"))
+	require.Equal(s.T(), ErrInvalidEnvFile, err)
+}
+
+func (s *Suite) TestWriteEnvFile() {
+	env := map[string]string{
+		"FOO": "foo",
+		"BAR": "a \"b\" $c\nd",
+	}
+	path := filepath.Join(s.tempDir, ".env")
+	require.NoError(s.T(), WriteEnvFile(path, env))
+	loaded, err := LoadEnvFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), env, loaded)
+	cmd := &Cmd{Env: []string{"FOO=old", "OTHER=other"}}
+	require.NoError(s.T(), MergeIntoCmdEnv(cmd, loaded))
+	require.Equal(s.T(), []string{"FOO=foo", "OTHER=other", "BAR=a \"b\" $c\nd"}, cmd.Env)
+}
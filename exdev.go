@@ -0,0 +1,11 @@
+//go:build !plan9
+
+package osutils
+
+import (
+	"syscall"
+)
+
+var (
+	errCrossDevice error = syscall.EXDEV
+)
@@ -0,0 +1,11 @@
+package osutils
+
+import (
+	"errors"
+)
+
+var (
+	// errCrossDevice is never returned, a rename on Plan 9 only changes the
+	// name within the same directory so it cannot cross devices.
+	errCrossDevice = errors.New("osutils: cross-device link")
+)
@@ -0,0 +1,230 @@
+package osutils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+const (
+	expandOrEmptyFunc = "osutilsOrEmpty"
+)
+
+var (
+	ErrMissingKey = errors.New("osutils: missing key")
+)
+
+type ExpandOptions struct {
+	// Template renders values with text/template instead of ${VAR}
+	// substitution.
+	Template bool
+	// Strict fails with an error for keys missing from the data instead of
+	// substituting an empty string.
+	Strict bool
+}
+
+// ExpandCmd returns a copy of cmd with Args, Env and AbsoluteDir expanded
+// against data. With ${VAR} substitution, $${ produces a literal ${.
+func ExpandCmd(cmd *Cmd, data map[string]interface{}, options *ExpandOptions) (*Cmd, error) {
+	return expandCmd(cmd, data, options)
+}
+
+func ExpandPipeCmdList(pipeCmdList *PipeCmdList, data map[string]interface{}, options *ExpandOptions) (*PipeCmdList, error) {
+	return expandPipeCmdList(pipeCmdList, data, options)
+}
+
+func ExpandString(s string, data map[string]interface{}, options *ExpandOptions) (string, error) {
+	return newExpander(data, options).expand(s)
+}
+
+// ***** PRIVATE *****
+
+func expandCmd(cmd *Cmd, data map[string]interface{}, options *ExpandOptions) (*Cmd, error) {
+	if cmd == nil {
+		return nil, ErrNil
+	}
+	expander := newExpander(data, options)
+	expanded := *cmd
+	var err error
+	if expanded.Args, err = expander.expandAll(cmd.Args); err != nil {
+		return nil, err
+	}
+	if expanded.Env, err = expander.expandAll(cmd.Env); err != nil {
+		return nil, err
+	}
+	if expanded.AbsoluteDir, err = expander.expand(cmd.AbsoluteDir); err != nil {
+		return nil, err
+	}
+	return &expanded, nil
+}
+
+func expandPipeCmdList(pipeCmdList *PipeCmdList, data map[string]interface{}, options *ExpandOptions) (*PipeCmdList, error) {
+	if pipeCmdList == nil {
+		return nil, ErrNil
+	}
+	expander := newExpander(data, options)
+	expanded := *pipeCmdList
+	expanded.PipeCmds = make([]*PipeCmd, len(pipeCmdList.PipeCmds))
+	for i, pipeCmd := range pipeCmdList.PipeCmds {
+		if pipeCmd == nil {
+			return nil, ErrNil
+		}
+		expandedPipeCmd := *pipeCmd
+		var err error
+		if expandedPipeCmd.Args, err = expander.expandAll(pipeCmd.Args); err != nil {
+			return nil, err
+		}
+		if expandedPipeCmd.Env, err = expander.expandAll(pipeCmd.Env); err != nil {
+			return nil, err
+		}
+		if expandedPipeCmd.AbsoluteDir, err = expander.expand(pipeCmd.AbsoluteDir); err != nil {
+			return nil, err
+		}
+		expanded.PipeCmds[i] = &expandedPipeCmd
+	}
+	return &expanded, nil
+}
+
+type expander struct {
+	data    map[string]interface{}
+	options *ExpandOptions
+}
+
+func newExpander(data map[string]interface{}, options *ExpandOptions) *expander {
+	if options == nil {
+		options = &ExpandOptions{}
+	}
+	return &expander{
+		data:    data,
+		options: options,
+	}
+}
+
+func (e *expander) expandAll(values []string) ([]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	expanded := make([]string, len(values))
+	for i, value := range values {
+		s, err := e.expand(value)
+		if err != nil {
+			return nil, err
+		}
+		expanded[i] = s
+	}
+	return expanded, nil
+}
+
+func (e *expander) expand(s string) (string, error) {
+	if e.options.Template {
+		return e.expandTemplate(s)
+	}
+	return e.expandVars(s)
+}
+
+func (e *expander) expandTemplate(s string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl := template.New("")
+	if e.options.Strict {
+		tmpl = tmpl.Option("missingkey=error")
+	} else {
+		tmpl = tmpl.Option("missingkey=zero").Funcs(template.FuncMap{expandOrEmptyFunc: orEmpty})
+	}
+	tmpl, err := tmpl.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	if !e.options.Strict {
+		for _, definedTmpl := range tmpl.Templates() {
+			pipeOrEmpty(definedTmpl.Tree, definedTmpl.Tree.Root)
+		}
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, e.data); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func (e *expander) expandVars(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var buffer bytes.Buffer
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			_, _ = buffer.WriteString(s)
+			return buffer.String(), nil
+		}
+		if i > 0 && s[i-1] == '$' {
+			_, _ = buffer.WriteString(s[:i-1])
+			_, _ = buffer.WriteString("${")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.Index(s[i:], "}")
+		if end < 0 {
+			_, _ = buffer.WriteString(s)
+			return buffer.String(), nil
+		}
+		_, _ = buffer.WriteString(s[:i])
+		key := s[i+2 : i+end]
+		value, ok := e.data[key]
+		if !ok && e.options.Strict {
+			return "", ErrMissingKey
+		}
+		if ok && value != nil {
+			_, _ = buffer.WriteString(fmt.Sprint(value))
+		}
+		s = s[i+end+1:]
+	}
+}
+
+// orEmpty renders a missing key or a nil value as an empty string rather
+// than <no value>, the same as ${VAR} substitution.
+func orEmpty(value interface{}) interface{} {
+	if value == nil {
+		return ""
+	}
+	return value
+}
+
+// pipeOrEmpty pipes every action that prints a value through orEmpty.
+func pipeOrEmpty(tree *parse.Tree, node parse.Node) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, child := range node.Nodes {
+			pipeOrEmpty(tree, child)
+		}
+	case *parse.ActionNode:
+		if len(node.Pipe.Decl) > 0 {
+			return
+		}
+		node.Pipe.Cmds = append(
+			node.Pipe.Cmds,
+			&parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      node.Pos,
+				Args:     []parse.Node{parse.NewIdentifier(expandOrEmptyFunc).SetTree(tree).SetPos(node.Pos)},
+			},
+		)
+	case *parse.IfNode:
+		pipeOrEmpty(tree, node.List)
+		pipeOrEmpty(tree, node.ElseList)
+	case *parse.RangeNode:
+		pipeOrEmpty(tree, node.List)
+		pipeOrEmpty(tree, node.ElseList)
+	case *parse.WithNode:
+		pipeOrEmpty(tree, node.List)
+		pipeOrEmpty(tree, node.ElseList)
+	}
+}
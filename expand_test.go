@@ -0,0 +1,35 @@
+package osutils
+
+import (
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestExpandCmd() {
+	data := map[string]interface{}{
+		"name": "foo",
+		"dir":  s.tempDir,
+	}
+	cmd, err := ExpandCmd(
+		&Cmd{
+			Args:        []string{"echo", "${name}-${missing}", "$${name}"},
+			AbsoluteDir: "${dir}",
+			Env:         []string{"NAME=${name}"},
+		},
+		data,
+		nil,
+	)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{"echo", "foo-", "${name}"}, cmd.Args)
+	require.Equal(s.T(), s.tempDir, cmd.AbsoluteDir)
+	require.Equal(s.T(), []string{"NAME=foo"}, cmd.Env)
+	_, err = ExpandCmd(&Cmd{Args: []string{"${missing}"}}, data, &ExpandOptions{Strict: true})
+	require.Equal(s.T(), ErrMissingKey, err)
+	value, err := ExpandString("{{.name}}", data, &ExpandOptions{Template: true})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "foo", value)
+	value, err = ExpandString("{{.name}}-{{.missing}}{{if .name}}-{{.missing}}{{end}}", data, &ExpandOptions{Template: true})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "foo--", value)
+	_, err = ExpandString("{{.missing}}", data, &ExpandOptions{Template: true, Strict: true})
+	require.Error(s.T(), err)
+}
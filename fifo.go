@@ -0,0 +1,38 @@
+package osutils
+
+import (
+	"os"
+)
+
+func MkFifo(absolutePath string, perm os.FileMode) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	return mkFifo(absolutePath, perm)
+}
+
+// OpenFifoReader opens the read end of a FIFO. Unless nonBlocking is set,
+// this blocks until a writer opens the FIFO.
+//
+// On Windows, absolutePath is a named pipe path such as \\.\pipe\name, see
+// NamedPipePath, and the pipe is created as part of opening the reader.
+func OpenFifoReader(absolutePath string, nonBlocking bool) (*os.File, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	return openFifoReader(absolutePath, nonBlocking)
+}
+
+// OpenFifoWriter opens the write end of a FIFO. Unless nonBlocking is set,
+// this blocks until a reader opens the FIFO, otherwise it fails if there is
+// no reader yet.
+func OpenFifoWriter(absolutePath string, nonBlocking bool) (*os.File, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	return openFifoWriter(absolutePath, nonBlocking)
+}
+
+func NamedPipePath(name string) string {
+	return namedPipePath(name)
+}
@@ -0,0 +1,24 @@
+//go:build !unix && !windows
+
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func mkFifo(absolutePath string, perm os.FileMode) error {
+	return ErrNotSupported
+}
+
+func openFifoReader(absolutePath string, nonBlocking bool) (*os.File, error) {
+	return nil, ErrNotSupported
+}
+
+func openFifoWriter(absolutePath string, nonBlocking bool) (*os.File, error) {
+	return nil, ErrNotSupported
+}
+
+func namedPipePath(name string) string {
+	return filepath.Join(os.TempDir(), name)
+}
@@ -0,0 +1,28 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestFifo() {
+	path := filepath.Join(s.tempDir, "fifo")
+	require.NoError(s.T(), MkFifo(path, 0600))
+	_, err := OpenFifoWriter(path, true)
+	require.Error(s.T(), err)
+	reader, err := OpenFifoReader(path, true)
+	require.NoError(s.T(), err)
+	writer, err := OpenFifoWriter(path, false)
+	require.NoError(s.T(), err)
+	_, err = writer.Write([]byte("hello"))
+	require.NoError(s.T(), err)
+	s.checkClose(writer)
+	data, err := ioutil.ReadAll(reader)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "hello", string(data))
+	s.checkClose(reader)
+	_, err = OpenFifoReader(filepath.Join(s.tempDir, "nope"), true)
+	require.Equal(s.T(), ErrFileDoesNotExist, err)
+}
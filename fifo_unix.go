@@ -0,0 +1,43 @@
+//go:build unix
+
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+func mkFifo(absolutePath string, perm os.FileMode) error {
+	return unix.Mkfifo(absolutePath, uint32(perm.Perm()))
+}
+
+func openFifoReader(absolutePath string, nonBlocking bool) (*os.File, error) {
+	return openFifo(absolutePath, os.O_RDONLY, nonBlocking)
+}
+
+func openFifoWriter(absolutePath string, nonBlocking bool) (*os.File, error) {
+	return openFifo(absolutePath, os.O_WRONLY, nonBlocking)
+}
+
+func openFifo(absolutePath string, flag int, nonBlocking bool) (*os.File, error) {
+	fileInfo, err := stat(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+	if fileInfo == nil {
+		return nil, ErrFileDoesNotExist
+	}
+	if fileInfo.Mode()&os.ModeNamedPipe == 0 {
+		return nil, ErrNotFifo
+	}
+	if nonBlocking {
+		flag |= unix.O_NONBLOCK
+	}
+	return os.OpenFile(absolutePath, flag, 0)
+}
+
+func namedPipePath(name string) string {
+	return filepath.Join(os.TempDir(), name)
+}
@@ -0,0 +1,70 @@
+package osutils
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	namedPipePrefix      = `\\.\pipe\`
+	pipeAccessInbound    = 0x00000001
+	pipeTypeByte         = 0x00000000
+	pipeWait             = 0x00000000
+	pipeNoWait           = 0x00000001
+	pipeBufferSize       = 4096
+	errorPipeConnected   = syscall.Errno(535)
+	namedPipeMaxInstance = 1
+)
+
+var (
+	procCreateNamedPipeW = kernel32.NewProc("CreateNamedPipeW")
+	procConnectNamedPipe = kernel32.NewProc("ConnectNamedPipe")
+)
+
+// mkFifo is not supported, named pipes on Windows only exist while a server
+// has them open, which is what openFifoReader does.
+func mkFifo(absolutePath string, perm os.FileMode) error {
+	return ErrNotSupported
+}
+
+func openFifoReader(absolutePath string, nonBlocking bool) (*os.File, error) {
+	name, err := syscall.UTF16PtrFromString(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+	mode := uintptr(pipeTypeByte | pipeWait)
+	if nonBlocking {
+		mode = pipeTypeByte | pipeNoWait
+	}
+	r, _, err := procCreateNamedPipeW.Call(
+		uintptr(unsafe.Pointer(name)),
+		pipeAccessInbound,
+		mode,
+		namedPipeMaxInstance,
+		pipeBufferSize,
+		pipeBufferSize,
+		0,
+		0,
+	)
+	handle := syscall.Handle(r)
+	if handle == syscall.InvalidHandle {
+		return nil, err
+	}
+	if !nonBlocking {
+		r, _, err := procConnectNamedPipe.Call(uintptr(handle), 0)
+		if r == 0 && err != errorPipeConnected {
+			_ = syscall.CloseHandle(handle)
+			return nil, err
+		}
+	}
+	return os.NewFile(uintptr(handle), absolutePath), nil
+}
+
+func openFifoWriter(absolutePath string, nonBlocking bool) (*os.File, error) {
+	return os.OpenFile(absolutePath, os.O_WRONLY, 0)
+}
+
+func namedPipePath(name string) string {
+	return namedPipePrefix + name
+}
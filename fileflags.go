@@ -0,0 +1,54 @@
+package osutils
+
+// FileFlags are the portable subset of chattr(1) and chflags(1) flags.
+// Setting or clearing either flag usually requires root.
+type FileFlags uint32
+
+const (
+	FileFlagImmutable FileFlags = 1 << iota
+	FileFlagAppendOnly
+)
+
+func GetFileFlags(absolutePath string) (FileFlags, error) {
+	if !isAbsolutePath(absolutePath) {
+		return 0, ErrNotAbsolutePath
+	}
+	return getFileFlags(absolutePath)
+}
+
+// SetFileFlags sets exactly the given flags, leaving any other
+// platform-specific flags on the file as they were.
+func SetFileFlags(absolutePath string, flags FileFlags) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	return setFileFlags(absolutePath, flags)
+}
+
+// ***** PRIVATE *****
+
+type fileFlagMapping struct {
+	flag   FileFlags
+	native uint32
+}
+
+func fileFlagsFromNative(mappings []fileFlagMapping, native uint32) FileFlags {
+	var flags FileFlags
+	for _, mapping := range mappings {
+		if native&mapping.native != 0 {
+			flags |= mapping.flag
+		}
+	}
+	return flags
+}
+
+func fileFlagsToNative(mappings []fileFlagMapping, native uint32, flags FileFlags) uint32 {
+	for _, mapping := range mappings {
+		if flags&mapping.flag != 0 {
+			native |= mapping.native
+		} else {
+			native &^= mapping.native
+		}
+	}
+	return native
+}
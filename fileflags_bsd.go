@@ -0,0 +1,36 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package osutils
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// see sys/stat.h, the system flags match the root-only semantics of chattr
+	bsdFileFlagMappings = []fileFlagMapping{
+		{FileFlagImmutable, 0x00020000},  // SF_IMMUTABLE
+		{FileFlagAppendOnly, 0x00040000}, // SF_APPEND
+	}
+)
+
+func getFileFlags(absolutePath string) (FileFlags, error) {
+	var stat unix.Stat_t
+	if err := unix.Lstat(absolutePath, &stat); err != nil {
+		return 0, err
+	}
+	return fileFlagsFromNative(bsdFileFlagMappings, uint32(stat.Flags)), nil
+}
+
+func setFileFlags(absolutePath string, flags FileFlags) error {
+	var stat unix.Stat_t
+	if err := unix.Lstat(absolutePath, &stat); err != nil {
+		return err
+	}
+	native := uint32(stat.Flags)
+	newNative := fileFlagsToNative(bsdFileFlagMappings, native, flags)
+	if newNative == native {
+		return nil
+	}
+	return unix.Chflags(absolutePath, int(newNative))
+}
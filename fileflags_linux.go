@@ -0,0 +1,46 @@
+package osutils
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// see linux/fs.h
+	linuxFileFlagMappings = []fileFlagMapping{
+		{FileFlagImmutable, 0x00000010},  // FS_IMMUTABLE_FL
+		{FileFlagAppendOnly, 0x00000020}, // FS_APPEND_FL
+	}
+)
+
+func getFileFlags(absolutePath string) (FileFlags, error) {
+	file, err := os.OpenFile(absolutePath, os.O_RDONLY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	native, err := unix.IoctlGetUint32(int(file.Fd()), unix.FS_IOC_GETFLAGS)
+	if err != nil {
+		return 0, err
+	}
+	return fileFlagsFromNative(linuxFileFlagMappings, native), nil
+}
+
+func setFileFlags(absolutePath string, flags FileFlags) error {
+	file, err := os.OpenFile(absolutePath, os.O_RDONLY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	fd := int(file.Fd())
+	native, err := unix.IoctlGetUint32(fd, unix.FS_IOC_GETFLAGS)
+	if err != nil {
+		return err
+	}
+	newNative := fileFlagsToNative(linuxFileFlagMappings, native, flags)
+	if newNative == native {
+		return nil
+	}
+	return unix.IoctlSetPointerInt(fd, unix.FS_IOC_SETFLAGS, int(newNative))
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package osutils
+
+func getFileFlags(absolutePath string) (FileFlags, error) {
+	return 0, ErrNotSupported
+}
+
+func setFileFlags(absolutePath string, flags FileFlags) error {
+	return ErrNotSupported
+}
@@ -0,0 +1,34 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestFileFlags() {
+	path := filepath.Join(s.tempDir, "file")
+	require.NoError(s.T(), ioutil.WriteFile(path, []byte("hello"), 0644))
+	flags, err := GetFileFlags(path)
+	if err != nil {
+		s.T().Skipf("file flags not supported: %v", err)
+	}
+	require.Equal(s.T(), FileFlags(0), flags)
+	if err := SetFileFlags(path, FileFlagImmutable); err != nil {
+		s.T().Skipf("cannot set file flags: %v", err)
+	}
+	defer func() {
+		require.NoError(s.T(), SetFileFlags(path, 0))
+	}()
+	flags, err = GetFileFlags(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), FileFlagImmutable, flags)
+	_, err = os.OpenFile(path, os.O_WRONLY, 0)
+	require.Error(s.T(), err)
+	require.NoError(s.T(), SetFileFlags(path, FileFlagAppendOnly))
+	flags, err = GetFileFlags(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), FileFlagAppendOnly, flags)
+}
@@ -0,0 +1,24 @@
+package osutils
+
+import (
+	"time"
+)
+
+// FileIdentity identifies a file independently of its path. On Windows,
+// Device is the volume serial number and Inode is the file index.
+// BirthTime is the zero time where the platform or filesystem does not
+// record it.
+type FileIdentity struct {
+	Device    uint64
+	Inode     uint64
+	NLink     uint64
+	BirthTime time.Time
+}
+
+// FileID follows symlinks, as os.Stat does.
+func FileID(absolutePath string) (*FileIdentity, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	return fileID(absolutePath)
+}
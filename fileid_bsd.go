@@ -0,0 +1,22 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package osutils
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func fileID(absolutePath string) (*FileIdentity, error) {
+	var stat unix.Stat_t
+	if err := unix.Stat(absolutePath, &stat); err != nil {
+		return nil, &os.PathError{Op: "stat", Path: absolutePath, Err: err}
+	}
+	return &FileIdentity{
+		Device:    uint64(stat.Dev),
+		Inode:     uint64(stat.Ino),
+		NLink:     uint64(stat.Nlink),
+		BirthTime: birthTime(&stat),
+	}, nil
+}
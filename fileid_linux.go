@@ -0,0 +1,41 @@
+package osutils
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func fileID(absolutePath string) (*FileIdentity, error) {
+	var statx unix.Statx_t
+	err := unix.Statx(unix.AT_FDCWD, absolutePath, 0, unix.STATX_BASIC_STATS|unix.STATX_BTIME, &statx)
+	if err == unix.ENOSYS {
+		return fileIDStat(absolutePath)
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "statx", Path: absolutePath, Err: err}
+	}
+	fileIdentity := &FileIdentity{
+		Device: unix.Mkdev(statx.Dev_major, statx.Dev_minor),
+		Inode:  statx.Ino,
+		NLink:  uint64(statx.Nlink),
+	}
+	if statx.Mask&unix.STATX_BTIME != 0 {
+		fileIdentity.BirthTime = time.Unix(statx.Btime.Sec, int64(statx.Btime.Nsec))
+	}
+	return fileIdentity, nil
+}
+
+// fileIDStat is for kernels older than 4.11, which have no statx.
+func fileIDStat(absolutePath string) (*FileIdentity, error) {
+	var stat unix.Stat_t
+	if err := unix.Stat(absolutePath, &stat); err != nil {
+		return nil, &os.PathError{Op: "stat", Path: absolutePath, Err: err}
+	}
+	return &FileIdentity{
+		Device: uint64(stat.Dev),
+		Inode:  uint64(stat.Ino),
+		NLink:  uint64(stat.Nlink),
+	}, nil
+}
@@ -0,0 +1,7 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+
+package osutils
+
+func fileID(absolutePath string) (*FileIdentity, error) {
+	return nil, ErrNotSupported
+}
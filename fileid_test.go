@@ -0,0 +1,35 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestFileID() {
+	path := filepath.Join(s.tempDir, "file")
+	require.NoError(s.T(), ioutil.WriteFile(path, []byte("hello"), 0644))
+	fileIdentity, err := FileID(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(1), fileIdentity.NLink)
+	require.NotZero(s.T(), fileIdentity.Inode)
+
+	linkPath := filepath.Join(s.tempDir, "link")
+	require.NoError(s.T(), os.Link(path, linkPath))
+	linkIdentity, err := FileID(linkPath)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), fileIdentity.Device, linkIdentity.Device)
+	require.Equal(s.T(), fileIdentity.Inode, linkIdentity.Inode)
+	require.Equal(s.T(), uint64(2), linkIdentity.NLink)
+
+	otherPath := filepath.Join(s.tempDir, "other")
+	require.NoError(s.T(), ioutil.WriteFile(otherPath, nil, 0644))
+	otherIdentity, err := FileID(otherPath)
+	require.NoError(s.T(), err)
+	require.NotEqual(s.T(), fileIdentity.Inode, otherIdentity.Inode)
+
+	_, err = FileID("file")
+	require.Equal(s.T(), ErrNotAbsolutePath, err)
+}
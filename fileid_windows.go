@@ -0,0 +1,38 @@
+package osutils
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func fileID(absolutePath string) (*FileIdentity, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+	// FILE_FLAG_BACKUP_SEMANTICS is required to open directories
+	handle, err := syscall.CreateFile(
+		pathPtr,
+		0,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil,
+		syscall.OPEN_EXISTING,
+		syscall.FILE_FLAG_BACKUP_SEMANTICS,
+		0,
+	)
+	if err != nil {
+		return nil, &os.PathError{Op: "CreateFile", Path: absolutePath, Err: err}
+	}
+	defer syscall.CloseHandle(handle)
+	var info syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(handle, &info); err != nil {
+		return nil, &os.PathError{Op: "GetFileInformationByHandle", Path: absolutePath, Err: err}
+	}
+	return &FileIdentity{
+		Device:    uint64(info.VolumeSerialNumber),
+		Inode:     uint64(info.FileIndexHigh)<<32 | uint64(info.FileIndexLow),
+		NLink:     uint64(info.NumberOfLinks),
+		BirthTime: time.Unix(0, info.CreationTime.Nanoseconds()),
+	}, nil
+}
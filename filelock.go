@@ -0,0 +1,68 @@
+package osutils
+
+import (
+	"errors"
+	"os"
+)
+
+const (
+	lockFileSuffix = ".lock"
+	lockFilePerm   = 0644
+)
+
+var (
+	ErrLocked = errors.New("osutils: locked")
+)
+
+// FileLock is an advisory lock on a file, flock(2) on Unix and LockFileEx
+// on Windows. It coordinates processes but not goroutines of the same
+// process sharing a FileLock.
+type FileLock struct {
+	file *os.File
+}
+
+// LockFile blocks until it holds the lock on the file at absolutePath,
+// creating the file if necessary. The lock is shared if exclusive is false.
+func LockFile(absolutePath string, exclusive bool) (*FileLock, error) {
+	return lockFile(absolutePath, exclusive, true)
+}
+
+// TryLockFile is like LockFile but fails with ErrLocked instead of blocking.
+func TryLockFile(absolutePath string, exclusive bool) (*FileLock, error) {
+	return lockFile(absolutePath, exclusive, false)
+}
+
+func (f *FileLock) Path() string {
+	return f.file.Name()
+}
+
+func (f *FileLock) Unlock() error {
+	err := unlockFile(f.file)
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ***** PRIVATE *****
+
+func lockFile(absolutePath string, exclusive bool, block bool) (*FileLock, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	file, err := os.OpenFile(absolutePath, os.O_RDWR|os.O_CREATE, lockFilePerm)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFileHandle(file, exclusive, block); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &FileLock{file: file}, nil
+}
+
+// lockPathFor is the sidecar lock file for a file that is replaced by
+// renames, which would otherwise leave a lock on the old inode.
+func lockPathFor(absolutePath string) string {
+	return absolutePath + lockFileSuffix
+}
@@ -0,0 +1,52 @@
+//go:build aix || (solaris && !illumos)
+
+package osutils
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockFileHandle uses fcntl locks as there is no flock. These are held by
+// the process rather than the open file, so they do not exclude other
+// handles in the same process and are dropped when any of its handles on
+// the file is closed.
+func lockFileHandle(file *os.File, exclusive bool, block bool) error {
+	lock := &unix.Flock_t{
+		Type:   unix.F_RDLCK,
+		Whence: io.SeekStart,
+	}
+	if exclusive {
+		lock.Type = unix.F_WRLCK
+	}
+	cmd := unix.F_SETLKW
+	if !block {
+		cmd = unix.F_SETLK
+	}
+	for {
+		err := unix.FcntlFlock(file.Fd(), cmd, lock)
+		switch err {
+		case nil:
+			return nil
+		case unix.EINTR:
+			continue
+		case unix.EAGAIN, unix.EACCES:
+			return ErrLocked
+		default:
+			return err
+		}
+	}
+}
+
+func unlockFile(file *os.File) error {
+	return unix.FcntlFlock(
+		file.Fd(),
+		unix.F_SETLK,
+		&unix.Flock_t{
+			Type:   unix.F_UNLCK,
+			Whence: io.SeekStart,
+		},
+	)
+}
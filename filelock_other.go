@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package osutils
+
+import (
+	"os"
+)
+
+func lockFileHandle(file *os.File, exclusive bool, block bool) error {
+	return ErrNotSupported
+}
+
+func unlockFile(file *os.File) error {
+	return ErrNotSupported
+}
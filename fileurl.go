@@ -0,0 +1,106 @@
+package osutils
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"runtime"
+	"strings"
+)
+
+var (
+	ErrNotFileURL      = errors.New("osutils: not file url")
+	ErrNotLocalFileURL = errors.New("osutils: not local file url")
+)
+
+// PathToFileURL returns the file URL for absolutePath, percent-encoding as
+// needed. On Windows, C:\dir\file becomes file:///C:/dir/file and the UNC
+// path \\server\share\file becomes file://server/share/file.
+func PathToFileURL(absolutePath string) (string, error) {
+	return pathToFileURL(absolutePath, runtime.GOOS == "windows")
+}
+
+// FileURLToPath is the reverse of PathToFileURL. A host of localhost is the
+// same as no host. Elsewhere than Windows, where a host is a UNC server, a
+// file URL with a host results in ErrNotLocalFileURL.
+func FileURLToPath(fileURL string) (string, error) {
+	return fileURLToPath(fileURL, runtime.GOOS == "windows")
+}
+
+// ***** PRIVATE *****
+
+func pathToFileURL(absolutePath string, windows bool) (string, error) {
+	if !windows {
+		if !strings.HasPrefix(absolutePath, "/") {
+			return "", ErrNotAbsolutePath
+		}
+		return (&url.URL{Scheme: "file", Path: path.Clean(absolutePath)}).String(), nil
+	}
+	slashed := strings.Replace(absolutePath, `\`, "/", -1)
+	if strings.HasPrefix(slashed, "//") {
+		// UNC, the server is the host and the share the first element
+		i := strings.IndexByte(slashed[2:], '/')
+		if i <= 0 {
+			return "", ErrNotAbsolutePath
+		}
+		return (&url.URL{Scheme: "file", Host: slashed[2 : 2+i], Path: path.Clean(slashed[2+i:])}).String(), nil
+	}
+	if !isWindowsDrivePath(slashed) {
+		return "", ErrNotAbsolutePath
+	}
+	return (&url.URL{Scheme: "file", Path: "/" + slashed[:2] + cleanDrivePath(slashed[2:])}).String(), nil
+}
+
+func fileURLToPath(fileURL string, windows bool) (string, error) {
+	parsed, err := url.Parse(fileURL)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(parsed.Scheme, "file") || parsed.Opaque != "" {
+		return "", ErrNotFileURL
+	}
+	host := parsed.Host
+	if strings.EqualFold(host, "localhost") {
+		host = ""
+	}
+	urlPath := parsed.Path
+	if urlPath == "" {
+		urlPath = "/"
+	}
+	if !windows {
+		if host != "" {
+			return "", ErrNotLocalFileURL
+		}
+		return path.Clean(urlPath), nil
+	}
+	if host != "" {
+		return `\\` + host + strings.Replace(path.Clean(urlPath), "/", `\`, -1), nil
+	}
+	// file:///C:/dir or file:/C:/dir
+	trimmed := strings.TrimPrefix(urlPath, "/")
+	if !isWindowsDrivePath(trimmed) {
+		return "", ErrNotAbsolutePath
+	}
+	return strings.Replace(trimmed[:2]+cleanDrivePath(trimmed[2:]), "/", `\`, -1), nil
+}
+
+// isWindowsDrivePath checks for a drive letter followed by a slash, or
+// nothing for the root of the drive.
+func isWindowsDrivePath(slashed string) bool {
+	if len(slashed) < 2 || slashed[1] != ':' {
+		return false
+	}
+	letter := slashed[0] | 0x20
+	if letter < 'a' || letter > 'z' {
+		return false
+	}
+	return len(slashed) == 2 || slashed[2] == '/'
+}
+
+// cleanDrivePath cleans the part of a Windows path after the drive letter.
+func cleanDrivePath(slashed string) string {
+	if slashed == "" {
+		return "/"
+	}
+	return path.Clean(slashed)
+}
@@ -0,0 +1,56 @@
+package osutils
+
+import (
+	"path/filepath"
+	"runtime"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestFileURL() {
+	for _, testCase := range []struct {
+		path    string
+		url     string
+		windows bool
+	}{
+		{"/", "file:///", false},
+		{"/a/b c/d#e?f", "file:///a/b%20c/d%23e%3Ff", false},
+		{"/ünïcode/100%", "file:///%C3%BCn%C3%AFcode/100%25", false},
+		{`C:\`, "file:///C:/", true},
+		{`C:\Program Files\a#b`, "file:///C:/Program%20Files/a%23b", true},
+		{`d:\dir`, "file:///d:/dir", true},
+		{`\\server\share\dir\file`, "file://server/share/dir/file", true},
+	} {
+		fileURL, err := pathToFileURL(testCase.path, testCase.windows)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), testCase.url, fileURL)
+		path, err := fileURLToPath(fileURL, testCase.windows)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), testCase.path, path)
+	}
+	path, err := fileURLToPath("file://localhost/etc/hosts", false)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "/etc/hosts", path)
+	path, err = fileURLToPath("file:/C:/dir", true)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), `C:\dir`, path)
+	_, err = fileURLToPath("file://server/share", false)
+	require.Equal(s.T(), ErrNotLocalFileURL, err)
+	_, err = fileURLToPath("http://example.com/", false)
+	require.Equal(s.T(), ErrNotFileURL, err)
+	_, err = fileURLToPath("file:///dir", true)
+	require.Equal(s.T(), ErrNotAbsolutePath, err)
+	_, err = pathToFileURL("relative", false)
+	require.Equal(s.T(), ErrNotAbsolutePath, err)
+	_, err = pathToFileURL(`\dir`, true)
+	require.Equal(s.T(), ErrNotAbsolutePath, err)
+
+	fileURL, err := PathToFileURL(s.tempDir)
+	require.NoError(s.T(), err)
+	path, err = FileURLToPath(fileURL)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), filepath.Clean(s.tempDir), path)
+	if runtime.GOOS != "windows" {
+		require.Equal(s.T(), "file://"+s.tempDir, fileURL)
+	}
+}
@@ -0,0 +1,77 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+type GlobOptions struct {
+	OverwritePolicy OverwritePolicy
+	// CreateDstDir creates the destination dir if it does not exist.
+	CreateDstDir bool
+}
+
+// CopyGlob copies every regular file matching the absolute pattern, see
+// filepath.Match, into dstDir, and returns the destination paths written.
+func CopyGlob(pattern string, dstDir string, options *GlobOptions) ([]string, error) {
+	return globTransfer(pattern, dstDir, options, copyFile)
+}
+
+// MoveGlob is like CopyGlob but moves the files.
+func MoveGlob(pattern string, dstDir string, options *GlobOptions) ([]string, error) {
+	return globTransfer(pattern, dstDir, options, moveFile)
+}
+
+// ***** PRIVATE *****
+
+func globTransfer(
+	pattern string,
+	dstDir string,
+	options *GlobOptions,
+	transfer func(string, string) error,
+) ([]string, error) {
+	if !isAbsolutePath(pattern) || !isAbsolutePath(dstDir) {
+		return nil, ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &GlobOptions{}
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if options.CreateDstDir {
+		if err := os.MkdirAll(dstDir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	exists, err := isDirExists(dstDir)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrFileDoesNotExist
+	}
+	var written []string
+	for _, match := range matches {
+		fileInfo, err := os.Stat(match)
+		if err != nil {
+			return written, err
+		}
+		if !fileInfo.Mode().IsRegular() {
+			continue
+		}
+		dst, err := resolveDst(filepath.Join(dstDir, filepath.Base(match)), options.OverwritePolicy)
+		if err != nil {
+			return written, err
+		}
+		if dst == "" {
+			continue
+		}
+		if err := transfer(match, dst); err != nil {
+			return written, err
+		}
+		written = append(written, dst)
+	}
+	return written, nil
+}
@@ -0,0 +1,37 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCopyGlob() {
+	src := filepath.Join(s.tempDir, "src")
+	dst := filepath.Join(s.tempDir, "dst")
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(src, "dir.log"), 0755))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(src, "one.log"), []byte("one"), 0600))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(src, "two.log"), []byte("two"), 0644))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(src, "other.txt"), nil, 0644))
+	written, err := CopyGlob(filepath.Join(src, "*.log"), dst, &GlobOptions{CreateDstDir: true})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{filepath.Join(dst, "one.log"), filepath.Join(dst, "two.log")}, written)
+	fileInfo, err := os.Stat(filepath.Join(dst, "one.log"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), os.FileMode(0600), fileInfo.Mode().Perm())
+
+	_, err = CopyGlob(filepath.Join(src, "*.log"), dst, nil)
+	require.Equal(s.T(), ErrFileExists, err)
+	written, err = CopyGlob(filepath.Join(src, "*.log"), dst, &GlobOptions{OverwritePolicy: OverwritePolicySkip})
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), written)
+	written, err = MoveGlob(filepath.Join(src, "*.log"), dst, &GlobOptions{OverwritePolicy: OverwritePolicyRenameUnique})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{filepath.Join(dst, "one-1.log"), filepath.Join(dst, "two-1.log")}, written)
+	s.checkFileDoesNotExist(filepath.Join(src, "one.log"))
+	data, err := ioutil.ReadFile(filepath.Join(dst, "two-1.log"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "two", string(data))
+}
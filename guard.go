@@ -0,0 +1,147 @@
+package osutils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrGuardedPath = errors.New("osutils: path is protected by the guard")
+
+	globalGuard = DefaultGuard()
+	guardLock   = &sync.RWMutex{}
+)
+
+// Guard protects system paths from destructive operations: RemoveAll,
+// ReplaceDir, SwapDirs, and recursive EnsureOwnership and EnsureMode. These
+// fail with ErrGuardedPath for a denied path, which catches bugs such as
+// RemoveAll(filepath.Join(os.Getenv("UNSET"), "/")) before they do damage.
+type Guard struct {
+	// DenyExact are denied themselves, but not the paths below them.
+	DenyExact []string
+	// DenyPrefixes are denied along with everything below them.
+	DenyPrefixes []string
+	// Allow overrides DenyPrefixes for the paths below them.
+	Allow []string
+}
+
+// DefaultGuard denies the filesystem root, the home directory, and the
+// operating system directories. It is installed unless SetGuard is called.
+func DefaultGuard() *Guard {
+	guard := &Guard{}
+	switch runtime.GOOS {
+	case "windows":
+		systemDrive := os.Getenv("SystemDrive")
+		if systemDrive == "" {
+			systemDrive = "C:"
+		}
+		guard.DenyExact = []string{systemDrive + `\`, systemDrive + `\Users`}
+		guard.DenyPrefixes = []string{
+			systemDrive + `\Windows`,
+			systemDrive + `\Program Files`,
+			systemDrive + `\Program Files (x86)`,
+		}
+	default:
+		guard.DenyExact = []string{"/", "/home", "/Users", "/opt", "/tmp", "/var"}
+		guard.DenyPrefixes = []string{
+			"/bin",
+			"/boot",
+			"/dev",
+			"/etc",
+			"/lib",
+			"/lib32",
+			"/lib64",
+			"/proc",
+			"/sbin",
+			"/sys",
+			"/usr",
+			"/System",
+			"/Library",
+		}
+		// the temp dir on darwin lives under /private/var
+		if runtime.GOOS == "darwin" {
+			guard.DenyExact = append(guard.DenyExact, "/private", "/private/var")
+			guard.DenyPrefixes = append(guard.DenyPrefixes, "/private/etc")
+		}
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil && homeDir != "" {
+		guard.DenyExact = append(guard.DenyExact, filepath.Clean(homeDir))
+	}
+	return guard
+}
+
+// SetGuard replaces the package-wide Guard. Pass nil to disable it.
+func SetGuard(guard *Guard) {
+	guardLock.Lock()
+	defer guardLock.Unlock()
+	globalGuard = guard
+}
+
+func CurrentGuard() *Guard {
+	return currentGuard()
+}
+
+// Check returns ErrGuardedPath if absolutePath is denied. A nil Guard
+// allows everything.
+func (g *Guard) Check(absolutePath string) error {
+	if g == nil {
+		return nil
+	}
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	path := filepath.Clean(absolutePath)
+	for _, exact := range g.DenyExact {
+		if guardPathEqual(path, filepath.Clean(exact)) {
+			return ErrGuardedPath
+		}
+	}
+	for _, allow := range g.Allow {
+		if guardIsWithin(filepath.Clean(allow), path) {
+			return nil
+		}
+	}
+	for _, prefix := range g.DenyPrefixes {
+		if guardIsWithin(filepath.Clean(prefix), path) {
+			return ErrGuardedPath
+		}
+	}
+	return nil
+}
+
+// ***** PRIVATE *****
+
+func currentGuard() *Guard {
+	guardLock.RLock()
+	defer guardLock.RUnlock()
+	return globalGuard
+}
+
+func checkGuard(absolutePaths ...string) error {
+	guard := currentGuard()
+	for _, absolutePath := range absolutePaths {
+		if err := guard.Check(absolutePath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// paths are case-insensitive on Windows
+func guardPathEqual(a string, b string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+func guardIsWithin(base string, path string) bool {
+	if runtime.GOOS == "windows" {
+		return isWithin(strings.ToLower(base), strings.ToLower(path))
+	}
+	return isWithin(base, path)
+}
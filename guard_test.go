@@ -0,0 +1,34 @@
+package osutils
+
+import (
+	"path/filepath"
+	"runtime"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestGuard() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("default guard paths are unix paths")
+	}
+	guard := DefaultGuard()
+	require.Equal(s.T(), ErrGuardedPath, guard.Check("/"))
+	require.Equal(s.T(), ErrGuardedPath, guard.Check("/usr/lib/"))
+	require.Equal(s.T(), ErrGuardedPath, guard.Check("/etc/.."))
+	require.NoError(s.T(), guard.Check("/opt/app"))
+	require.NoError(s.T(), guard.Check("/usrlocal"))
+	guard.Allow = []string{"/usr/local/app"}
+	require.NoError(s.T(), guard.Check("/usr/local/app/cache"))
+	require.Equal(s.T(), ErrGuardedPath, guard.Check("/usr/local"))
+
+	require.Equal(s.T(), ErrGuardedPath, RemoveAll("/etc/osutils-guard-test"))
+	_, err := EnsureMode("/usr", 0777, true)
+	require.Equal(s.T(), ErrGuardedPath, err)
+	path := filepath.Join(s.tempDir, "dir")
+	require.NoError(s.T(), MkdirAll(path, 0755))
+	SetGuard(&Guard{DenyPrefixes: []string{s.tempDir}})
+	defer SetGuard(DefaultGuard())
+	require.Equal(s.T(), ErrGuardedPath, RemoveAll(path))
+	SetGuard(nil)
+	require.NoError(s.T(), RemoveAll(path))
+}
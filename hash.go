@@ -0,0 +1,83 @@
+package osutils
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"time"
+)
+
+var (
+	ErrUnknownHashAlgorithm = errors.New("osutils: unknown hash algorithm")
+	ErrChecksumMismatch     = errors.New("osutils: checksum mismatch")
+)
+
+type HashAlgorithm string
+
+const (
+	HashAlgorithmSHA256 HashAlgorithm = "sha256"
+	HashAlgorithmSHA512 HashAlgorithm = "sha512"
+	HashAlgorithmSHA1   HashAlgorithm = "sha1"
+	HashAlgorithmMD5    HashAlgorithm = "md5"
+)
+
+func HashFile(absolutePath string) (string, error) {
+	if !isAbsolutePath(absolutePath) {
+		return "", ErrNotAbsolutePath
+	}
+	return hashFile(absolutePath)
+}
+
+// ***** PRIVATE *****
+
+// hashFile returns the hex SHA-256 of a file.
+func hashFile(absolutePath string) (string, error) {
+	return hashFileWithAlgorithm(absolutePath, HashAlgorithmSHA256)
+}
+
+func hashFileWithAlgorithm(absolutePath string, hashAlgorithm HashAlgorithm) (string, error) {
+	return hashFileThrottled(absolutePath, hashAlgorithm, nil)
+}
+
+func hashFileThrottled(absolutePath string, hashAlgorithm HashAlgorithm, throttle *Throttle) (_ string, retErr error) {
+	start := time.Now()
+	var n int64
+	defer func() {
+		observeOperation(MetricsOperationHashFile, start, n, retErr)
+	}()
+	hash, err := newHash(hashAlgorithm)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(absolutePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	throttle.WaitOp()
+	if n, err = io.Copy(hash, throttle.Reader(file)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func newHash(hashAlgorithm HashAlgorithm) (hash.Hash, error) {
+	switch hashAlgorithm {
+	case HashAlgorithmSHA256:
+		return sha256.New(), nil
+	case HashAlgorithmSHA512:
+		return sha512.New(), nil
+	case HashAlgorithmSHA1:
+		return sha1.New(), nil
+	case HashAlgorithmMD5:
+		return md5.New(), nil
+	default:
+		return nil, ErrUnknownHashAlgorithm
+	}
+}
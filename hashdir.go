@@ -0,0 +1,80 @@
+package osutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type HashDirOptions struct {
+	// Progress steps by the size of each file hashed.
+	Progress Progress
+	Throttle *Throttle
+}
+
+// HashDir returns a hex SHA-256 over the relative paths, file contents and
+// symlink targets of the tree at absolutePath. It does not depend on
+// modification times or permissions, so equal trees hash equally wherever
+// they are.
+func HashDir(absolutePath string, options *HashDirOptions) (string, error) {
+	if !isAbsolutePath(absolutePath) {
+		return "", ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &HashDirOptions{}
+	}
+	return hashDir(filepath.Clean(absolutePath), progressOrNop(options.Progress), options.Throttle)
+}
+
+// ***** PRIVATE *****
+
+func hashDir(absolutePath string, progress Progress, throttle *Throttle) (_ string, retErr error) {
+	defer func(start time.Time) {
+		observeOperation(MetricsOperationHashDir, start, 0, retErr)
+	}(time.Now())
+	if err := startTreeProgress(absolutePath, progress); err != nil {
+		return "", err
+	}
+	defer func() {
+		progress.Done(retErr)
+	}()
+	hash := sha256.New()
+	// filepath.Walk visits in lexical order, which makes this deterministic
+	if err := filepath.Walk(
+		absolutePath,
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(absolutePath, path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			switch {
+			case info.IsDir():
+				_, err = io.WriteString(hash, "d "+rel+"\x00")
+			case info.Mode().IsRegular():
+				var digest string
+				if digest, err = hashFileThrottled(path, HashAlgorithmSHA256, throttle); err != nil {
+					return err
+				}
+				_, err = io.WriteString(hash, "f "+rel+"\x00"+digest+"\x00")
+				progress.Step(info.Size(), path)
+			case info.Mode()&os.ModeSymlink != 0:
+				var linkTarget string
+				if linkTarget, err = os.Readlink(path); err != nil {
+					return err
+				}
+				_, err = io.WriteString(hash, "l "+rel+"\x00"+linkTarget+"\x00")
+			}
+			return err
+		},
+	); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
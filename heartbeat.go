@@ -0,0 +1,167 @@
+package osutils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHeartbeatInterval = 30 * time.Second
+	// heartbeatTailSize is how much recent output is kept for the summary.
+	heartbeatTailSize  = 4096
+	heartbeatTailLines = 5
+)
+
+// Heartbeat reports on a command every Interval while it runs, so that
+// stuck commands can be detected and quiet ones keep CI logs alive.
+type Heartbeat struct {
+	// Interval defaults to 30s.
+	Interval time.Duration
+	// Func is called with each beat. If nil, a line is written to Writer
+	// instead.
+	Func func(*HeartbeatEvent)
+	// Writer defaults to os.Stderr.
+	Writer io.Writer
+}
+
+type HeartbeatEvent struct {
+	Args    []string
+	PID     int
+	Elapsed time.Duration
+	// OutputBytes is the output written so far, and SinceOutput the time
+	// since the last write, or Elapsed if there was none.
+	OutputBytes int64
+	SinceOutput time.Duration
+	// RecentOutput is the last few lines of output.
+	RecentOutput string
+}
+
+// ***** PRIVATE *****
+
+type heartbeat struct {
+	heartbeat  *Heartbeat
+	args       []string
+	tail       []byte
+	n          int64
+	lastOutput time.Time
+	lock       sync.Mutex
+	stop       chan struct{}
+	stopOnce   sync.Once
+	// done is closed when the beats stop, nil if they never started.
+	done chan struct{}
+}
+
+// setupCmdHeartbeat records the output of the command for the beats, which
+// start once the command has started.
+func setupCmdHeartbeat(process *process, cmd *Cmd) {
+	heartbeat := &heartbeat{
+		heartbeat: cmd.Heartbeat,
+		args:      cmd.Args,
+		stop:      make(chan struct{}),
+	}
+	if process.combined != nil {
+		process.combined = teeWriter(nil, process.combined, heartbeat)
+	} else {
+		stdout, stderr := lockIfShared(process.execCmd.Stdout, process.execCmd.Stderr)
+		process.execCmd.Stdout = teeWriter(nil, stdout, heartbeat)
+		process.execCmd.Stderr = teeWriter(nil, stderr, heartbeat)
+	}
+	process.heartbeat = heartbeat
+	process.cleanups = append(
+		process.cleanups,
+		func() error {
+			heartbeat.stopOnce.Do(func() { close(heartbeat.stop) })
+			if heartbeat.done != nil {
+				<-heartbeat.done
+			}
+			return nil
+		},
+	)
+}
+
+func (h *heartbeat) Write(p []byte) (int, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.n += int64(len(p))
+	h.lastOutput = time.Now()
+	h.tail = append(h.tail, p...)
+	if len(h.tail) > heartbeatTailSize {
+		h.tail = append([]byte{}, h.tail[len(h.tail)-heartbeatTailSize:]...)
+	}
+	return len(p), nil
+}
+
+func (h *heartbeat) run(pid int, start time.Time) {
+	interval := h.heartbeat.Interval
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+	ticker := time.NewTicker(interval)
+	h.done = make(chan struct{})
+	go func() {
+		defer close(h.done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-h.stop:
+				return
+			case now := <-ticker.C:
+				h.beat(h.event(pid, start, now))
+			}
+		}
+	}()
+}
+
+func (h *heartbeat) event(pid int, start time.Time, now time.Time) *HeartbeatEvent {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	heartbeatEvent := &HeartbeatEvent{
+		Args:         h.args,
+		PID:          pid,
+		Elapsed:      now.Sub(start),
+		OutputBytes:  h.n,
+		RecentOutput: lastLines(string(h.tail), heartbeatTailLines),
+	}
+	if h.lastOutput.IsZero() {
+		heartbeatEvent.SinceOutput = heartbeatEvent.Elapsed
+	} else {
+		heartbeatEvent.SinceOutput = now.Sub(h.lastOutput)
+	}
+	return heartbeatEvent
+}
+
+func (h *heartbeat) beat(heartbeatEvent *HeartbeatEvent) {
+	if h.heartbeat.Func != nil {
+		h.heartbeat.Func(heartbeatEvent)
+		return
+	}
+	writer := h.heartbeat.Writer
+	if writer == nil {
+		writer = os.Stderr
+	}
+	line := fmt.Sprintf(
+		"osutils: heartbeat: %s (pid %d) running for %s, %d bytes of output, last %s ago",
+		strings.Join(heartbeatEvent.Args, " "),
+		heartbeatEvent.PID,
+		heartbeatEvent.Elapsed.Round(time.Second),
+		heartbeatEvent.OutputBytes,
+		heartbeatEvent.SinceOutput.Round(time.Second),
+	)
+	if recent := lastLines(heartbeatEvent.RecentOutput, 1); recent != "" {
+		line += ": " + recent
+	}
+	_, _ = fmt.Fprintln(writer, line)
+}
+
+// lastLines returns the last n non-empty lines of s.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\r\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,71 @@
+package osutils
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestHeartbeat() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sh not available on windows")
+	}
+	var lock sync.Mutex
+	var heartbeatEvents []*HeartbeatEvent
+	var stdout bytes.Buffer
+	wait, err := Execute(
+		&Cmd{
+			Args:   []string{"sh", "-c", "echo one; echo two; sleep 0.5"},
+			Stdout: &stdout,
+			Heartbeat: &Heartbeat{
+				Interval: 100 * time.Millisecond,
+				Func: func(heartbeatEvent *HeartbeatEvent) {
+					lock.Lock()
+					defer lock.Unlock()
+					heartbeatEvents = append(heartbeatEvents, heartbeatEvent)
+				},
+			},
+		},
+	)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), wait())
+	require.Equal(s.T(), "one\ntwo\n", stdout.String())
+	lock.Lock()
+	defer lock.Unlock()
+	require.NotEmpty(s.T(), heartbeatEvents)
+	last := heartbeatEvents[len(heartbeatEvents)-1]
+	require.Equal(s.T(), "one\ntwo", last.RecentOutput)
+	require.Equal(s.T(), int64(8), last.OutputBytes)
+	require.True(s.T(), last.PID > 0)
+	require.True(s.T(), last.Elapsed >= last.SinceOutput)
+
+	var log bytes.Buffer
+	_, err = NewCommand("sh", "-c", "sleep 0.3").Heartbeat(&Heartbeat{Interval: 100 * time.Millisecond, Writer: &log}).Run(context.Background())
+	require.NoError(s.T(), err)
+	require.True(s.T(), strings.HasPrefix(log.String(), "osutils: heartbeat: sh -c sleep 0.3 (pid "), log.String())
+}
+
+// TestHeartbeatSharedOutput fails under -race if the streams are written
+// to the shared writer concurrently.
+func (s *Suite) TestHeartbeatSharedOutput() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sh not available on windows")
+	}
+	var buffer bytes.Buffer
+	wait, err := Execute(
+		&Cmd{
+			Args:      []string{"sh", "-c", "for i in 1 2 3 4 5; do echo out; echo err >&2; done"},
+			Stdout:    &buffer,
+			Stderr:    &buffer,
+			Heartbeat: &Heartbeat{Interval: time.Hour},
+		},
+	)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), wait())
+	require.Equal(s.T(), 40, buffer.Len())
+}
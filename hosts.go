@@ -0,0 +1,264 @@
+package osutils
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+var ErrInvalidHostEntry = errors.New("osutils: invalid host entry")
+
+type HostEntry struct {
+	IP        string
+	Hostnames []string
+	// Comment is the text after # on the line, if any.
+	Comment string
+}
+
+// HostsFilePath returns the path of the system hosts file, /etc/hosts or
+// its equivalent under %SystemRoot% on Windows.
+func HostsFilePath() string {
+	if runtime.GOOS == "windows" {
+		systemRoot := os.Getenv("SystemRoot")
+		if systemRoot == "" {
+			systemRoot = `C:\Windows`
+		}
+		return filepath.Join(systemRoot, "System32", "drivers", "etc", "hosts")
+	}
+	return "/etc/hosts"
+}
+
+// ParseHostsFile returns the entries of the hosts file at absolutePath.
+// Malformed lines are skipped, as the resolver does.
+func ParseHostsFile(absolutePath string) ([]*HostEntry, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	data, err := ioutil.ReadFile(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+	var hostEntries []*HostEntry
+	for _, line := range splitLines(string(data)) {
+		if hostEntry := parseHostLine(line); hostEntry != nil {
+			hostEntries = append(hostEntries, hostEntry)
+		}
+	}
+	return hostEntries, nil
+}
+
+// AddHostEntry maps the hostnames of hostEntry to its IP in the hosts file
+// at absolutePath, removing them from any other line first so each
+// hostname resolves to one address, and returns whether the file changed.
+// Other lines and comments are kept as they are. The file is rewritten
+// atomically under the same lock as UpdateFile.
+func AddHostEntry(absolutePath string, hostEntry *HostEntry, options *WriteOptions) (bool, error) {
+	if err := validateHostEntry(hostEntry); err != nil {
+		return false, err
+	}
+	return editHostsFile(
+		absolutePath,
+		options,
+		func(lines []string) []string {
+			covered, conflicting := false, false
+			for _, line := range lines {
+				existing := parseHostLine(line)
+				switch {
+				case existing == nil:
+				case hostEntryCovers(existing, hostEntry):
+					covered = true
+				case hostEntryOverlaps(existing, hostEntry):
+					conflicting = true
+				}
+			}
+			if covered && !conflicting {
+				return nil
+			}
+			var edited []string
+			for _, line := range lines {
+				if line, keep := removeHostnames(line, hostEntry.Hostnames); keep {
+					edited = append(edited, line)
+				}
+			}
+			return append(edited, formatHostEntry(hostEntry))
+		},
+	)
+}
+
+// RemoveHostEntry removes hostname from the hosts file at absolutePath,
+// dropping lines left without hostnames, and returns whether the file
+// changed.
+func RemoveHostEntry(absolutePath string, hostname string, options *WriteOptions) (bool, error) {
+	if !isValidHostname(hostname) {
+		return false, ErrInvalidHostEntry
+	}
+	return editHostsFile(
+		absolutePath,
+		options,
+		func(lines []string) []string {
+			changed := false
+			edited := []string{}
+			for _, line := range lines {
+				newLine, keep := removeHostnames(line, []string{hostname})
+				if keep {
+					edited = append(edited, newLine)
+				}
+				if !keep || newLine != line {
+					changed = true
+				}
+			}
+			if !changed {
+				return nil
+			}
+			return edited
+		},
+	)
+}
+
+// ***** PRIVATE *****
+
+// editHostsFile passes the lines of the hosts file to fn, which returns nil
+// to leave the file as it is.
+func editHostsFile(absolutePath string, options *WriteOptions, fn func([]string) []string) (bool, error) {
+	if !isAbsolutePath(absolutePath) {
+		return false, ErrNotAbsolutePath
+	}
+	changed := false
+	err := UpdateFile(
+		absolutePath,
+		func(data []byte) ([]byte, error) {
+			if data == nil {
+				if _, err := os.Stat(absolutePath); err != nil {
+					return nil, err
+				}
+			}
+			lines := fn(splitLines(string(data)))
+			if lines == nil {
+				return data, nil
+			}
+			changed = true
+			return []byte(joinLines(lines)), nil
+		},
+		options,
+	)
+	return changed, err
+}
+
+func parseHostLine(line string) *HostEntry {
+	hostEntry := &HostEntry{}
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		hostEntry.Comment = strings.TrimSpace(line[i+1:])
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 2 || net.ParseIP(fields[0]) == nil {
+		return nil
+	}
+	hostEntry.IP = fields[0]
+	hostEntry.Hostnames = fields[1:]
+	return hostEntry
+}
+
+func formatHostEntry(hostEntry *HostEntry) string {
+	line := hostEntry.IP + "\t" + strings.Join(hostEntry.Hostnames, " ")
+	if hostEntry.Comment != "" {
+		line += " # " + hostEntry.Comment
+	}
+	return line
+}
+
+// removeHostnames returns line without hostnames and whether to keep it at
+// all. Lines that are not entries are always kept unchanged.
+func removeHostnames(line string, hostnames []string) (string, bool) {
+	hostEntry := parseHostLine(line)
+	if hostEntry == nil {
+		return line, true
+	}
+	var kept []string
+	for _, existing := range hostEntry.Hostnames {
+		if !containsHostname(hostnames, existing) {
+			kept = append(kept, existing)
+		}
+	}
+	if len(kept) == len(hostEntry.Hostnames) {
+		return line, true
+	}
+	if len(kept) == 0 {
+		return "", false
+	}
+	hostEntry.Hostnames = kept
+	return formatHostEntry(hostEntry), true
+}
+
+// hostEntryCovers returns whether existing already maps all the hostnames
+// of hostEntry to its IP.
+func hostEntryCovers(existing *HostEntry, hostEntry *HostEntry) bool {
+	if !net.ParseIP(existing.IP).Equal(net.ParseIP(hostEntry.IP)) {
+		return false
+	}
+	for _, hostname := range hostEntry.Hostnames {
+		if !containsHostname(existing.Hostnames, hostname) {
+			return false
+		}
+	}
+	return true
+}
+
+func hostEntryOverlaps(existing *HostEntry, hostEntry *HostEntry) bool {
+	for _, hostname := range hostEntry.Hostnames {
+		if containsHostname(existing.Hostnames, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsHostname(hostnames []string, hostname string) bool {
+	for _, existing := range hostnames {
+		if strings.EqualFold(existing, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateHostEntry(hostEntry *HostEntry) error {
+	if hostEntry == nil {
+		return ErrNil
+	}
+	if net.ParseIP(hostEntry.IP) == nil || len(hostEntry.Hostnames) == 0 {
+		return ErrInvalidHostEntry
+	}
+	for _, hostname := range hostEntry.Hostnames {
+		if !isValidHostname(hostname) {
+			return ErrInvalidHostEntry
+		}
+	}
+	if strings.ContainsAny(hostEntry.Comment, "\r\n") {
+		return ErrInvalidHostEntry
+	}
+	return nil
+}
+
+// isValidHostname checks for RFC 1123 hostnames, allowing underscores as
+// resolvers do.
+func isValidHostname(hostname string) bool {
+	if hostname == "" || len(hostname) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(hostname, "."), ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
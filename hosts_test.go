@@ -0,0 +1,50 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestHostsFile() {
+	path := filepath.Join(s.tempDir, "hosts")
+	require.NoError(s.T(), ioutil.WriteFile(path, []byte("# static\n127.0.0.1\tlocalhost\n10.0.0.1 db cache # old\nbogus\n"), 0644))
+
+	hostEntries, err := ParseHostsFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(
+		s.T(),
+		[]*HostEntry{
+			{IP: "127.0.0.1", Hostnames: []string{"localhost"}},
+			{IP: "10.0.0.1", Hostnames: []string{"db", "cache"}, Comment: "old"},
+		},
+		hostEntries,
+	)
+
+	_, err = AddHostEntry(path, &HostEntry{IP: "10.0.0.300", Hostnames: []string{"db"}}, nil)
+	require.Equal(s.T(), ErrInvalidHostEntry, err)
+	_, err = AddHostEntry(path, &HostEntry{IP: "10.0.0.2", Hostnames: []string{"bad host"}}, nil)
+	require.Equal(s.T(), ErrInvalidHostEntry, err)
+
+	changed, err := AddHostEntry(path, &HostEntry{IP: "10.0.0.2", Hostnames: []string{"db"}}, &WriteOptions{Backup: true})
+	require.NoError(s.T(), err)
+	require.True(s.T(), changed)
+	changed, err = AddHostEntry(path, &HostEntry{IP: "10.0.0.2", Hostnames: []string{"db"}}, nil)
+	require.NoError(s.T(), err)
+	require.False(s.T(), changed)
+	data, err := ioutil.ReadFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "# static\n127.0.0.1\tlocalhost\n10.0.0.1\tcache # old\nbogus\n10.0.0.2\tdb\n", string(data))
+	s.checkFileExists(path + ".bak")
+
+	changed, err = RemoveHostEntry(path, "cache", nil)
+	require.NoError(s.T(), err)
+	require.True(s.T(), changed)
+	changed, err = RemoveHostEntry(path, "cache", nil)
+	require.NoError(s.T(), err)
+	require.False(s.T(), changed)
+	data, err = ioutil.ReadFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "# static\n127.0.0.1\tlocalhost\nbogus\n10.0.0.2\tdb\n", string(data))
+}
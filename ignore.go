@@ -0,0 +1,262 @@
+package osutils
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// GitIgnoreFileName is the name of git's per-directory ignore file.
+const GitIgnoreFileName = ".gitignore"
+
+// Ignore matches paths under a root against .gitignore-style patterns.
+// Patterns follow git: a leading ! negates, a trailing / matches only
+// directories, a pattern with a / other than at the end is relative to the
+// directory of its ignore file, ** matches any number of directories, and
+// the last matching pattern wins, with deeper ignore files taking
+// precedence. As with git, nothing under an ignored directory can be
+// re-included. Ignore files are read lazily and cached, so an Ignore
+// reflects the files as they were when first needed.
+type Ignore struct {
+	root     string
+	fileName string
+	patterns []*ignorePattern
+	dirs     map[string][]*ignorePattern
+	lock     sync.Mutex
+}
+
+// NewIgnore returns an Ignore for the tree at root. patterns apply as if
+// they were at the top of an ignore file in root. If fileName is not
+// empty, files of that name in root and its subdirectories are read as
+// well, typically GitIgnoreFileName.
+func NewIgnore(root string, fileName string, patterns ...string) (*Ignore, error) {
+	if !isAbsolutePath(root) {
+		return nil, ErrNotAbsolutePath
+	}
+	ignore := &Ignore{
+		root:     filepath.Clean(root),
+		fileName: fileName,
+		dirs:     make(map[string][]*ignorePattern),
+	}
+	for _, line := range patterns {
+		if pattern := parseIgnorePattern(line, nil); pattern != nil {
+			ignore.patterns = append(ignore.patterns, pattern)
+		}
+	}
+	return ignore, nil
+}
+
+// Match returns whether path is ignored. A relative path is taken to be
+// relative to the root, and paths outside the root are never ignored.
+func (i *Ignore) Match(path string, isDir bool) (bool, error) {
+	parts, ok, err := i.relativeParts(path)
+	if err != nil || !ok {
+		return false, err
+	}
+	for j := 1; j < len(parts); j++ {
+		ignored, err := i.matchParts(parts[:j], true)
+		if err != nil || ignored {
+			return ignored, err
+		}
+	}
+	return i.matchParts(parts, isDir)
+}
+
+// WalkDirFunc wraps fn for WalkDir or Dir.WalkDir so that fn is not called
+// for ignored paths and ignored directories are skipped.
+func (i *Ignore) WalkDirFunc(fn fs.WalkDirFunc) fs.WalkDirFunc {
+	return func(path string, dirEntry fs.DirEntry, err error) error {
+		if dirEntry != nil {
+			ignored, matchErr := i.Match(path, dirEntry.IsDir())
+			if matchErr != nil {
+				return matchErr
+			}
+			if ignored {
+				return skipIgnored(dirEntry.IsDir())
+			}
+		}
+		return fn(path, dirEntry, err)
+	}
+}
+
+// WalkFunc is WalkDirFunc for filepath.Walk and Dir.Walk.
+func (i *Ignore) WalkFunc(walkFunc filepath.WalkFunc) filepath.WalkFunc {
+	return func(path string, info os.FileInfo, err error) error {
+		if info != nil {
+			ignored, matchErr := i.Match(path, info.IsDir())
+			if matchErr != nil {
+				return matchErr
+			}
+			if ignored {
+				return skipIgnored(info.IsDir())
+			}
+		}
+		return walkFunc(path, info, err)
+	}
+}
+
+// ***** PRIVATE *****
+
+type ignorePattern struct {
+	// base is the directory of the ignore file relative to the root
+	base     []string
+	segments []string
+	negate   bool
+	dirOnly  bool
+}
+
+func (i *Ignore) relativeParts(path string) ([]string, bool, error) {
+	rel := path
+	if filepath.IsAbs(path) {
+		var err error
+		rel, err = filepath.Rel(i.root, path)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	rel = filepath.ToSlash(filepath.Clean(rel))
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return nil, false, nil
+	}
+	return strings.Split(rel, "/"), true, nil
+}
+
+// matchParts matches against the patterns of the root and of the ignore
+// files in each parent directory of parts, ignoring the parents themselves.
+func (i *Ignore) matchParts(parts []string, isDir bool) (bool, error) {
+	ignored := false
+	apply := func(patterns []*ignorePattern) {
+		for _, pattern := range patterns {
+			if pattern.match(parts, isDir) {
+				ignored = !pattern.negate
+			}
+		}
+	}
+	apply(i.patterns)
+	for j := 0; j < len(parts); j++ {
+		patterns, err := i.dirPatterns(parts[:j])
+		if err != nil {
+			return false, err
+		}
+		apply(patterns)
+	}
+	return ignored, nil
+}
+
+func (i *Ignore) dirPatterns(dir []string) ([]*ignorePattern, error) {
+	if i.fileName == "" {
+		return nil, nil
+	}
+	key := strings.Join(dir, "/")
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if patterns, ok := i.dirs[key]; ok {
+		return patterns, nil
+	}
+	patterns, err := readIgnoreFile(filepath.Join(i.root, filepath.FromSlash(key), i.fileName), dir)
+	if err != nil {
+		return nil, err
+	}
+	i.dirs[key] = patterns
+	return patterns, nil
+}
+
+func readIgnoreFile(path string, base []string) ([]*ignorePattern, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	var patterns []*ignorePattern
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if pattern := parseIgnorePattern(scanner.Text(), base); pattern != nil {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
+
+func parseIgnorePattern(line string, base []string) *ignorePattern {
+	line = strings.TrimSuffix(line, "\r")
+	// trailing spaces are dropped unless escaped with a backslash
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || line[0] == '#' {
+		return nil
+	}
+	pattern := &ignorePattern{base: base}
+	if line[0] == '!' {
+		pattern.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return nil
+	}
+	if strings.Contains(line, "/") {
+		pattern.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	} else {
+		// a pattern without a slash matches at any depth
+		pattern.segments = []string{"**", line}
+	}
+	return pattern
+}
+
+func (p *ignorePattern) match(parts []string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if len(parts) <= len(p.base) {
+		return false
+	}
+	return matchIgnoreSegments(p.segments, parts[len(p.base):])
+}
+
+func matchIgnoreSegments(segments []string, parts []string) bool {
+	for len(segments) > 0 {
+		if segments[0] == "**" {
+			// a trailing ** matches everything inside, but not the dir itself
+			if len(segments) == 1 {
+				return len(parts) > 0
+			}
+			for j := 0; j <= len(parts); j++ {
+				if matchIgnoreSegments(segments[1:], parts[j:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, err := path.Match(segments[0], parts[0]); err != nil || !ok {
+			return false
+		}
+		segments, parts = segments[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+func skipIgnored(isDir bool) error {
+	if isDir {
+		return filepath.SkipDir
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package osutils
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestIgnore() {
+	root := filepath.Join(s.tempDir, "root")
+	for _, dir := range []string{"build/out", "src/vendor", "src/keep"} {
+		require.NoError(s.T(), os.MkdirAll(filepath.Join(root, dir), 0755))
+	}
+	for path, data := range map[string]string{
+		".gitignore":        "# comment\n*.log\n!important.log\nbuild/\n/top.txt\n",
+		"src/.gitignore":    "vendor\n!*.txt\nkeep/**\n",
+		"a.log":             "",
+		"important.log":     "",
+		"top.txt":           "",
+		"src/top.txt":       "",
+		"src/b.log":         "",
+		"src/vendor/v.go":   "",
+		"src/keep/k.go":     "",
+		"build/out/o.go":    "",
+		"src/main.go":       "",
+		"src/notes.txt.log": "",
+	} {
+		require.NoError(s.T(), ioutil.WriteFile(filepath.Join(root, path), []byte(data), 0644))
+	}
+	ignore, err := NewIgnore(root, GitIgnoreFileName, "*.tmp")
+	require.NoError(s.T(), err)
+
+	for path, expected := range map[string]bool{
+		"a.log":             true,
+		"important.log":     false,
+		"top.txt":           true,
+		"src/top.txt":       false,
+		"src/b.log":         true,
+		"src/vendor/v.go":   true,
+		"src/keep":          false,
+		"src/keep/k.go":     true,
+		"build/out/o.go":    true,
+		"src/main.go":       false,
+		"src/x.tmp":         true,
+		"src/notes.txt.log": true,
+	} {
+		ignored, err := ignore.Match(filepath.FromSlash(path), false)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), expected, ignored, path)
+	}
+	ignored, err := ignore.Match(filepath.Join(root, "build"), true)
+	require.NoError(s.T(), err)
+	require.True(s.T(), ignored)
+	ignored, err = ignore.Match(filepath.Join(s.tempDir, "a.log"), false)
+	require.NoError(s.T(), err)
+	require.False(s.T(), ignored)
+
+	var paths []string
+	require.NoError(
+		s.T(),
+		WalkDir(
+			root,
+			ignore.WalkDirFunc(
+				func(path string, dirEntry fs.DirEntry, err error) error {
+					if err == nil && !dirEntry.IsDir() {
+						rel, err := filepath.Rel(root, path)
+						require.NoError(s.T(), err)
+						paths = append(paths, filepath.ToSlash(rel))
+					}
+					return err
+				},
+			),
+		),
+	)
+	require.Equal(
+		s.T(),
+		[]string{".gitignore", "important.log", "src/.gitignore", "src/main.go", "src/top.txt"},
+		paths,
+	)
+
+	dst := filepath.Join(s.tempDir, "dst")
+	require.NoError(s.T(), CopyDir(root, dst, &CopyDirOptions{Ignore: ignore}))
+	s.checkFileExists(filepath.Join(dst, "src", "main.go"))
+	s.checkFileDoesNotExist(filepath.Join(dst, "build"))
+	s.checkFileDoesNotExist(filepath.Join(dst, "a.log"))
+}
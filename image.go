@@ -0,0 +1,118 @@
+package osutils
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+)
+
+var (
+	ErrInvalidImageSize = errors.New("osutils: invalid image size")
+	// these are swapped in tests
+	losetupCommand  = "losetup"
+	mkfsExt4Command = "mkfs.ext4"
+	mkfsVfatCommand = "mkfs.vfat"
+)
+
+type LoopOptions struct {
+	ReadOnly bool
+	// PartitionScan creates devices for the partitions of the image, such
+	// as /dev/loop0p1.
+	PartitionScan bool
+}
+
+type MkfsOptions struct {
+	Label string
+}
+
+// CreateImageFile creates a sparse file of size bytes for a disk image. It
+// fails if the file already exists.
+func CreateImageFile(absolutePath string, size int64) (retErr error) {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	if size <= 0 {
+		return ErrInvalidImageSize
+	}
+	file, err := os.OpenFile(absolutePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+		if retErr != nil {
+			_ = os.Remove(absolutePath)
+		}
+	}()
+	return file.Truncate(size)
+}
+
+// AttachLoop attaches the image at absolutePath to the first free loop
+// device with losetup, and returns the device path. This requires root.
+func AttachLoop(ctx context.Context, absolutePath string, options *LoopOptions) (string, error) {
+	if !isAbsolutePath(absolutePath) {
+		return "", ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &LoopOptions{}
+	}
+	args := []string{losetupCommand, "--find", "--show"}
+	if options.ReadOnly {
+		args = append(args, "--read-only")
+	}
+	if options.PartitionScan {
+		args = append(args, "--partscan")
+	}
+	result, err := NewCommand(append(args, absolutePath)...).Run(ctx)
+	if err != nil {
+		return "", commandError("losetup", result, err)
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
+func DetachLoop(ctx context.Context, device string) error {
+	if !isAbsolutePath(device) {
+		return ErrNotAbsolutePath
+	}
+	result, err := NewCommand(losetupCommand, "--detach", device).Run(ctx)
+	if err != nil {
+		return commandError("losetup", result, err)
+	}
+	return nil
+}
+
+// MkfsExt4 creates an ext4 filesystem on the device or image file at
+// absolutePath, overwriting whatever is there.
+func MkfsExt4(ctx context.Context, absolutePath string, options *MkfsOptions) error {
+	args := []string{mkfsExt4Command, "-F", "-q"}
+	if options != nil && options.Label != "" {
+		args = append(args, "-L", options.Label)
+	}
+	return mkfs(ctx, "mkfs.ext4", absolutePath, args)
+}
+
+// MkfsVfat creates a FAT filesystem on the device or image file at
+// absolutePath. A vfat label is at most 11 characters.
+func MkfsVfat(ctx context.Context, absolutePath string, options *MkfsOptions) error {
+	args := []string{mkfsVfatCommand}
+	if options != nil && options.Label != "" {
+		args = append(args, "-n", options.Label)
+	}
+	return mkfs(ctx, "mkfs.vfat", absolutePath, args)
+}
+
+// ***** PRIVATE *****
+
+func mkfs(ctx context.Context, name string, absolutePath string, args []string) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	result, err := NewCommand(append(args, absolutePath)...).Run(ctx)
+	if err != nil {
+		return commandError(name, result, err)
+	}
+	return nil
+}
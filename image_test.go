@@ -0,0 +1,75 @@
+package osutils
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCreateImageFile() {
+	path := filepath.Join(s.tempDir, "disk.img")
+	require.Equal(s.T(), ErrInvalidImageSize, CreateImageFile(path, 0))
+	require.NoError(s.T(), CreateImageFile(path, 64<<20))
+	info, err := os.Stat(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(64<<20), info.Size())
+	require.True(s.T(), os.IsExist(CreateImageFile(path, 1<<20)))
+}
+
+func (s *Suite) TestLoopAndMkfs() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sh not available on windows")
+	}
+	ctx := context.Background()
+	argsPath := filepath.Join(s.tempDir, "args")
+	fake := filepath.Join(s.tempDir, "fake")
+	require.NoError(
+		s.T(),
+		ioutil.WriteFile(
+			fake,
+			[]byte(`#!/bin/sh
+echo "$@" >> `+argsPath+`
+case "$*" in
+	*--find*) echo /dev/loop7 ;;
+	*bad*) echo "bad: no such device" >&2; exit 1 ;;
+esac
+`),
+			0755,
+		),
+	)
+	defer func(losetup string, mkfsExt4 string, mkfsVfat string) {
+		losetupCommand, mkfsExt4Command, mkfsVfatCommand = losetup, mkfsExt4, mkfsVfat
+	}(losetupCommand, mkfsExt4Command, mkfsVfatCommand)
+	losetupCommand, mkfsExt4Command, mkfsVfatCommand = fake, fake, fake
+
+	image := filepath.Join(s.tempDir, "disk.img")
+	device, err := AttachLoop(ctx, image, &LoopOptions{PartitionScan: true})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "/dev/loop7", device)
+	require.NoError(s.T(), DetachLoop(ctx, device))
+	require.NoError(s.T(), MkfsExt4(ctx, image, &MkfsOptions{Label: "root"}))
+	require.NoError(s.T(), MkfsVfat(ctx, image, nil))
+	data, err := ioutil.ReadFile(argsPath)
+	require.NoError(s.T(), err)
+	require.Equal(
+		s.T(),
+		"--find --show --partscan "+image+"\n"+
+			"--detach /dev/loop7\n"+
+			"-F -q -L root "+image+"\n"+
+			image+"\n",
+		string(data),
+	)
+
+	err = MkfsExt4(ctx, filepath.Join(s.tempDir, "bad"), nil)
+	require.EqualError(s.T(), err, "osutils: mkfs.ext4: bad: no such device: exit status 1")
+	var exitError *exec.ExitError
+	require.True(s.T(), errors.As(err, &exitError))
+	require.Equal(s.T(), 1, exitError.ExitCode())
+	require.Equal(s.T(), ErrNotAbsolutePath, MkfsVfat(ctx, "disk.img", nil))
+}
@@ -0,0 +1,138 @@
+package osutils
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"text/template"
+)
+
+const (
+	defaultInstallFilePerm = 0644
+	defaultInstallDirPerm  = 0755
+)
+
+type InstallOptions struct {
+	// Perms maps slash-separated paths in the source, or path.Match
+	// patterns, to permissions. If several match, the longest key wins.
+	Perms map[string]os.FileMode
+	// FilePerm defaults to 0644 and DirPerm to 0755, for paths not in Perms.
+	FilePerm os.FileMode
+	DirPerm  os.FileMode
+	// Templates are paths or path.Match patterns of files to render with
+	// text/template and TemplateData instead of copying.
+	Templates    []string
+	TemplateData interface{}
+	// OverwritePolicy applies to files. Existing directories are merged
+	// into.
+	OverwritePolicy OverwritePolicy
+}
+
+// InstallFromFS writes the tree of src, such as an embed.FS, to dstRoot.
+// Files are written atomically.
+func InstallFromFS(src fs.FS, dstRoot string, options *InstallOptions) error {
+	if src == nil {
+		return ErrNil
+	}
+	if !isAbsolutePath(dstRoot) {
+		return ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &InstallOptions{}
+	}
+	return installFromFS(src, filepath.Clean(dstRoot), options)
+}
+
+// ***** PRIVATE *****
+
+func installFromFS(src fs.FS, dstRoot string, options *InstallOptions) error {
+	return fs.WalkDir(
+		src,
+		".",
+		func(name string, dirEntry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			dst := filepath.Join(dstRoot, filepath.FromSlash(name))
+			if dirEntry.IsDir() {
+				return mkdirAll(dst, options.perm(name, true))
+			}
+			if !dirEntry.Type().IsRegular() {
+				return nil
+			}
+			target, err := resolveDst(dst, options.OverwritePolicy)
+			if err != nil || target == "" {
+				return err
+			}
+			return installFile(src, name, target, options)
+		},
+	)
+}
+
+func installFile(src fs.FS, name string, dst string, options *InstallOptions) error {
+	isTemplate := matchesAny(name, options.Templates)
+	var parsed *template.Template
+	if isTemplate {
+		data, err := fs.ReadFile(src, name)
+		if err != nil {
+			return err
+		}
+		if parsed, err = template.New(name).Option("missingkey=error").Parse(string(data)); err != nil {
+			return err
+		}
+	}
+	file, err := src.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return writeAtomic(
+		dst,
+		options.perm(name, false),
+		func(writer io.Writer) error {
+			if parsed != nil {
+				return parsed.Execute(writer, options.TemplateData)
+			}
+			_, err := io.Copy(writer, file)
+			return err
+		},
+	)
+}
+
+func (o *InstallOptions) perm(name string, isDir bool) os.FileMode {
+	longest := -1
+	var perm os.FileMode
+	for key, keyPerm := range o.Perms {
+		if len(key) > longest && matchesAny(name, []string{key}) {
+			longest = len(key)
+			perm = keyPerm
+		}
+	}
+	if longest >= 0 {
+		return perm
+	}
+	if isDir {
+		if o.DirPerm != 0 {
+			return o.DirPerm
+		}
+		return defaultInstallDirPerm
+	}
+	if o.FilePerm != 0 {
+		return o.FilePerm
+	}
+	return defaultInstallFilePerm
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if pattern == name {
+			return true
+		}
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
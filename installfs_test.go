@@ -0,0 +1,48 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestInstallFromFS() {
+	src := fstest.MapFS{
+		"bin/run":             {Data: []byte("#!/bin/sh\n")},
+		"etc/app.conf.tmpl":   {Data: []byte("port={{.Port}}\n")},
+		"etc/static.conf":     {Data: []byte("{{.Port}}\n")},
+		"share/data/file.txt": {Data: []byte("data")},
+	}
+	dst := filepath.Join(s.tempDir, "install")
+	require.NoError(
+		s.T(),
+		InstallFromFS(
+			src,
+			dst,
+			&InstallOptions{
+				Perms: map[string]os.FileMode{
+					"bin/*":   0755,
+					"bin/run": 0700,
+					"etc":     0750,
+				},
+				Templates:    []string{"etc/*.tmpl"},
+				TemplateData: map[string]int{"Port": 8080},
+			},
+		),
+	)
+	s.checkPerm(nil, filepath.Join(dst, "bin", "run"), 0700)
+	s.checkPerm(nil, filepath.Join(dst, "etc"), 0750)
+	s.checkPerm(nil, filepath.Join(dst, "share", "data", "file.txt"), 0644)
+	data, err := ioutil.ReadFile(filepath.Join(dst, "etc", "app.conf.tmpl"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "port=8080\n", string(data))
+	data, err = ioutil.ReadFile(filepath.Join(dst, "etc", "static.conf"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "{{.Port}}\n", string(data))
+
+	require.Equal(s.T(), ErrFileExists, InstallFromFS(src, dst, nil))
+	require.NoError(s.T(), InstallFromFS(src, dst, &InstallOptions{OverwritePolicy: OverwritePolicySkip}))
+}
@@ -0,0 +1,59 @@
+package osutils
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	metrics     Metrics
+	metricsLock = &sync.RWMutex{}
+)
+
+type MetricsOperation string
+
+const (
+	// MetricsOperationExecute is reported for each command run, including
+	// each stage of a pipeline.
+	MetricsOperationExecute   MetricsOperation = "execute"
+	MetricsOperationCopyFile  MetricsOperation = "copy_file"
+	MetricsOperationCopyDir   MetricsOperation = "copy_dir"
+	MetricsOperationWriteFile MetricsOperation = "write_file"
+	MetricsOperationHashFile  MetricsOperation = "hash_file"
+	MetricsOperationHashDir   MetricsOperation = "hash_dir"
+)
+
+// Metrics receives counters and timings for operations of this package,
+// for example to export them to Prometheus. Compound operations such as
+// CopyDir only report their duration, the bytes of the files they copy or
+// hash are reported under MetricsOperationCopyFile or
+// MetricsOperationHashFile. Implementations must be safe for concurrent
+// use.
+type Metrics interface {
+	AddBytes(operation MetricsOperation, n int64)
+	// ObserveDuration is called once per operation, err is nil on success.
+	ObserveDuration(operation MetricsOperation, duration time.Duration, err error)
+}
+
+// SetMetrics installs a Metrics for all operations. Pass nil to disable
+// metrics.
+func SetMetrics(m Metrics) {
+	metricsLock.Lock()
+	defer metricsLock.Unlock()
+	metrics = m
+}
+
+// ***** PRIVATE *****
+
+func observeOperation(operation MetricsOperation, start time.Time, bytes int64, err error) {
+	metricsLock.RLock()
+	m := metrics
+	metricsLock.RUnlock()
+	if m == nil {
+		return
+	}
+	if bytes > 0 {
+		m.AddBytes(operation, bytes)
+	}
+	m.ObserveDuration(operation, time.Since(start), err)
+}
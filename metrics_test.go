@@ -0,0 +1,50 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testMetrics struct {
+	lock      sync.Mutex
+	bytes     map[MetricsOperation]int64
+	durations map[MetricsOperation]int
+}
+
+func (t *testMetrics) AddBytes(operation MetricsOperation, n int64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.bytes[operation] += n
+}
+
+func (t *testMetrics) ObserveDuration(operation MetricsOperation, duration time.Duration, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.durations[operation]++
+}
+
+func (s *Suite) TestMetrics() {
+	metrics := &testMetrics{
+		bytes:     make(map[MetricsOperation]int64),
+		durations: make(map[MetricsOperation]int),
+	}
+	SetMetrics(metrics)
+	defer SetMetrics(nil)
+	s.execute([]string{"echo", "foo"}, nil)
+	src := filepath.Join(s.tempDir, "src")
+	require.NoError(s.T(), ioutil.WriteFile(src, []byte("hello"), 0644))
+	require.NoError(s.T(), CopyFile(src, filepath.Join(s.tempDir, "dst")))
+	require.NoError(s.T(), WriteFileAtomic(filepath.Join(s.tempDir, "atomic"), []byte("hi"), 0644))
+	_, err := HashFile(src)
+	require.NoError(s.T(), err)
+
+	require.Equal(s.T(), 1, metrics.durations[MetricsOperationExecute])
+	require.Equal(s.T(), 1, metrics.durations[MetricsOperationCopyFile])
+	require.Equal(s.T(), int64(5), metrics.bytes[MetricsOperationCopyFile])
+	require.Equal(s.T(), int64(2), metrics.bytes[MetricsOperationWriteFile])
+	require.Equal(s.T(), int64(5), metrics.bytes[MetricsOperationHashFile])
+}
@@ -0,0 +1,38 @@
+package osutils
+
+import (
+	"errors"
+	"os"
+)
+
+var (
+	ErrDevicePermission = errors.New("osutils: creating device nodes requires root or CAP_MKNOD")
+)
+
+// MkNod creates a filesystem node. The type is taken from mode, which
+// is one of os.ModeDevice, os.ModeDevice|os.ModeCharDevice,
+// os.ModeNamedPipe, os.ModeSocket or none for a regular file. The
+// permission bits are applied exactly, regardless of the umask.
+func MkNod(absolutePath string, mode os.FileMode, major uint32, minor uint32) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	return mkNod(absolutePath, mode, major, minor)
+}
+
+func MkCharDevice(absolutePath string, perm os.FileMode, major uint32, minor uint32) error {
+	return MkNod(absolutePath, os.ModeDevice|os.ModeCharDevice|perm.Perm(), major, minor)
+}
+
+func MkBlockDevice(absolutePath string, perm os.FileMode, major uint32, minor uint32) error {
+	return MkNod(absolutePath, os.ModeDevice|perm.Perm(), major, minor)
+}
+
+// MkSocketPath creates a unix socket file that nothing is listening on,
+// for example as a placeholder in a chroot that a service binds later.
+func MkSocketPath(absolutePath string, perm os.FileMode) error {
+	if err := validateUnixSocketPath(absolutePath); err != nil {
+		return err
+	}
+	return mkSocketPath(absolutePath, perm)
+}
@@ -0,0 +1,9 @@
+package osutils
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func mknod(absolutePath string, mode uint32, dev uint64) error {
+	return unix.Mknod(absolutePath, mode, dev)
+}
@@ -0,0 +1,11 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+
+package osutils
+
+import (
+	"os"
+)
+
+func mkNod(absolutePath string, mode os.FileMode, major uint32, minor uint32) error {
+	return ErrNotSupported
+}
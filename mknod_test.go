@@ -0,0 +1,46 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestMkNod() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("mknod not supported on windows")
+	}
+	path := filepath.Join(s.tempDir, "fifo")
+	require.NoError(s.T(), MkNod(path, os.ModeNamedPipe|0640, 0, 0))
+	info, err := os.Lstat(path)
+	require.NoError(s.T(), err)
+	require.True(s.T(), info.Mode()&os.ModeNamedPipe != 0)
+	require.Equal(s.T(), os.FileMode(0640), info.Mode().Perm())
+	require.Equal(s.T(), ErrNotAbsolutePath, MkNod("fifo", os.ModeNamedPipe, 0, 0))
+
+	path = filepath.Join(s.tempDir, "null")
+	err = MkCharDevice(path, 0666, 1, 3)
+	if err == ErrDevicePermission {
+		s.T().Skip("no permission to create device nodes")
+	}
+	require.NoError(s.T(), err)
+	info, err = os.Lstat(path)
+	require.NoError(s.T(), err)
+	require.True(s.T(), info.Mode()&os.ModeCharDevice != 0)
+}
+
+func (s *Suite) TestMkSocketPath() {
+	path := filepath.Join(s.tempDir, "socket")
+	require.NoError(s.T(), MkSocketPath(path, 0600))
+	info, err := os.Lstat(path)
+	require.NoError(s.T(), err)
+	require.True(s.T(), info.Mode()&os.ModeSocket != 0)
+	if runtime.GOOS != "windows" {
+		require.Equal(s.T(), os.FileMode(0600), info.Mode().Perm())
+	}
+	removed, err := RemoveStaleSocket(path)
+	require.NoError(s.T(), err)
+	require.True(s.T(), removed)
+}
@@ -0,0 +1,43 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package osutils
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func mkNod(absolutePath string, mode os.FileMode, major uint32, minor uint32) error {
+	unixMode := uint32(mode.Perm())
+	switch {
+	case mode&os.ModeCharDevice != 0:
+		unixMode |= unix.S_IFCHR
+	case mode&os.ModeDevice != 0:
+		unixMode |= unix.S_IFBLK
+	case mode&os.ModeNamedPipe != 0:
+		unixMode |= unix.S_IFIFO
+	case mode&os.ModeSocket != 0:
+		unixMode |= unix.S_IFSOCK
+	default:
+		unixMode |= unix.S_IFREG
+	}
+	if mode&os.ModeSetuid != 0 {
+		unixMode |= unix.S_ISUID
+	}
+	if mode&os.ModeSetgid != 0 {
+		unixMode |= unix.S_ISGID
+	}
+	if mode&os.ModeSticky != 0 {
+		unixMode |= unix.S_ISVTX
+	}
+	err := mknod(absolutePath, unixMode, unix.Mkdev(major, minor))
+	if err == unix.EPERM && mode&os.ModeDevice != 0 {
+		return ErrDevicePermission
+	}
+	if err != nil {
+		return &os.PathError{Op: "mknod", Path: absolutePath, Err: err}
+	}
+	// chmod rather than clear the umask, which is process-wide
+	return os.Chmod(absolutePath, mode.Perm()|mode&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+}
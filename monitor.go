@@ -0,0 +1,143 @@
+package osutils
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const defaultMonitorInterval = time.Second
+
+var ErrMemoryLimitExceeded = errors.New("osutils: memory limit exceeded")
+
+// Monitor samples the memory and CPU usage of a command while it runs.
+// Only the process started is sampled, not its children.
+type Monitor struct {
+	// Interval defaults to 1s.
+	Interval time.Duration
+	// MaxRSS is a soft ceiling on the resident set size in bytes, recorded
+	// in ResourceUsage.Exceeded when crossed. 0 means no ceiling.
+	MaxRSS uint64
+	// KillOnExceed kills the command when it crosses MaxRSS, in which case
+	// waiting for it returns ErrMemoryLimitExceeded.
+	KillOnExceed bool
+}
+
+// ResourceUsage is what a Monitor sampled.
+type ResourceUsage struct {
+	Samples int
+	PeakRSS uint64
+	MeanRSS uint64
+	// PeakCPU and MeanCPU are in CPUs, 1.0 is one core fully used. The
+	// peak is over a single interval.
+	PeakCPU  float64
+	MeanCPU  float64
+	Exceeded bool
+}
+
+// ***** PRIVATE *****
+
+type monitor struct {
+	monitor       *Monitor
+	resourceUsage ResourceUsage
+	rssSum        uint64
+	lastCPUTime   time.Duration
+	lastSample    time.Time
+	killed        bool
+	lock          sync.Mutex
+	stop          chan struct{}
+	done          chan struct{}
+}
+
+// setupCmdMonitor starts sampling once the command has started, and stops
+// when it is waited for.
+func setupCmdMonitor(process *process, cmd *Cmd) {
+	monitor := &monitor{
+		monitor: cmd.Monitor,
+		stop:    make(chan struct{}),
+	}
+	process.monitor = monitor
+	process.cleanups = append(
+		process.cleanups,
+		func() error {
+			close(monitor.stop)
+			if monitor.done != nil {
+				<-monitor.done
+			}
+			return nil
+		},
+	)
+}
+
+func (m *monitor) run(process *process) {
+	interval := m.monitor.Interval
+	if interval <= 0 {
+		interval = defaultMonitorInterval
+	}
+	pid := process.execCmd.Process.Pid
+	m.lastSample = process.start
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			// sample straight away so short commands get a sample too
+			if m.sample(pid) {
+				_ = process.execCmd.Process.Kill()
+				return
+			}
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// sample records a sample and returns whether to kill the command.
+func (m *monitor) sample(pid int) bool {
+	rss, cpuTime, err := sampleProcess(pid)
+	now := time.Now()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	// the process may have exited since the last sample
+	if err != nil {
+		return false
+	}
+	resourceUsage := &m.resourceUsage
+	resourceUsage.Samples++
+	m.rssSum += rss
+	resourceUsage.MeanRSS = m.rssSum / uint64(resourceUsage.Samples)
+	if rss > resourceUsage.PeakRSS {
+		resourceUsage.PeakRSS = rss
+	}
+	if wall := now.Sub(m.lastSample); wall > 0 {
+		if cpu := float64(cpuTime-m.lastCPUTime) / float64(wall); cpu > resourceUsage.PeakCPU {
+			resourceUsage.PeakCPU = cpu
+		}
+	}
+	m.lastCPUTime = cpuTime
+	m.lastSample = now
+	if m.monitor.MaxRSS > 0 && rss > m.monitor.MaxRSS {
+		resourceUsage.Exceeded = true
+		if m.monitor.KillOnExceed {
+			m.killed = true
+			return true
+		}
+	}
+	return false
+}
+
+// finish records the mean CPU over the life of the command, returns the
+// usage and whether the monitor killed the command.
+func (m *monitor) finish(start time.Time) (*ResourceUsage, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	resourceUsage := m.resourceUsage
+	if elapsed := m.lastSample.Sub(start); elapsed > 0 {
+		resourceUsage.MeanCPU = float64(m.lastCPUTime) / float64(elapsed)
+	}
+	return &resourceUsage, m.killed
+}
@@ -0,0 +1,46 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicksPerSecond is USER_HZ, which is 100 on all supported
+// architectures.
+const clockTicksPerSecond = 100
+
+// sampleProcess reads the resident set size and the CPU time used from
+// /proc/<pid>/stat.
+func sampleProcess(pid int) (uint64, time.Duration, error) {
+	data, err := ioutil.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	// the command name in parens may contain spaces
+	i := strings.LastIndexByte(string(data), ')')
+	if i < 0 {
+		return 0, 0, ErrNotSupported
+	}
+	// fields from the state on, utime is the 14th field of the whole line
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 22 {
+		return 0, 0, ErrNotSupported
+	}
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	rssPages, err := strconv.ParseUint(fields[21], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	cpuTime := time.Duration(utime+stime) * time.Second / clockTicksPerSecond
+	return rssPages * uint64(os.Getpagesize()), cpuTime, nil
+}
@@ -0,0 +1,31 @@
+package osutils
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestMonitor() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sh not available on windows")
+	}
+	result, err := NewCommand("sh", "-c", "sleep 0.3").Monitor(&Monitor{Interval: 50 * time.Millisecond}).Run(context.Background())
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), result.Usage)
+	require.True(s.T(), result.Usage.Samples > 0)
+	require.True(s.T(), result.Usage.PeakRSS > 0)
+	require.True(s.T(), result.Usage.PeakRSS >= result.Usage.MeanRSS)
+	require.False(s.T(), result.Usage.Exceeded)
+
+	result, err = NewCommand("sleep", "5").Monitor(&Monitor{Interval: 50 * time.Millisecond, MaxRSS: 1, KillOnExceed: true}).Run(context.Background())
+	require.Equal(s.T(), ErrMemoryLimitExceeded, err)
+	require.True(s.T(), result.Usage.Exceeded)
+	require.True(s.T(), result.Duration < 5*time.Second)
+
+	result, err = NewCommand("true").Run(context.Background())
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), result.Usage)
+}
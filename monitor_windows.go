@@ -0,0 +1,53 @@
+package osutils
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const processQueryLimitedInformation = 0x1000
+
+var (
+	procK32GetProcessMemoryInfo = kernel32.NewProc("K32GetProcessMemoryInfo")
+)
+
+type processMemoryCounters struct {
+	cb                         uint32
+	pageFaultCount             uint32
+	peakWorkingSetSize         uintptr
+	workingSetSize             uintptr
+	quotaPeakPagedPoolUsage    uintptr
+	quotaPagedPoolUsage        uintptr
+	quotaPeakNonPagedPoolUsage uintptr
+	quotaNonPagedPoolUsage     uintptr
+	pagefileUsage              uintptr
+	peakPagefileUsage          uintptr
+}
+
+// sampleProcess returns the working set size and the kernel and user time
+// of the process.
+func sampleProcess(pid int) (uint64, time.Duration, error) {
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer syscall.CloseHandle(handle)
+	var creationTime, exitTime, kernelTime, userTime syscall.Filetime
+	if err := syscall.GetProcessTimes(handle, &creationTime, &exitTime, &kernelTime, &userTime); err != nil {
+		return 0, 0, err
+	}
+	counters := processMemoryCounters{}
+	counters.cb = uint32(unsafe.Sizeof(counters))
+	ret, _, err := procK32GetProcessMemoryInfo.Call(uintptr(handle), uintptr(unsafe.Pointer(&counters)), uintptr(counters.cb))
+	if ret == 0 {
+		return 0, 0, err
+	}
+	cpuTime := time.Duration(filetimeTicks(kernelTime)+filetimeTicks(userTime)) * 100 * time.Nanosecond
+	return uint64(counters.workingSetSize), cpuTime, nil
+}
+
+// filetimeTicks returns a FILETIME duration in 100ns ticks.
+func filetimeTicks(filetime syscall.Filetime) uint64 {
+	return uint64(filetime.HighDateTime)<<32 | uint64(filetime.LowDateTime)
+}
@@ -0,0 +1,187 @@
+package osutils
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	procMountsPath = "/proc/mounts"
+)
+
+var (
+	ErrInvalidMountEntry = errors.New("osutils: invalid mount entry")
+	ErrNotMountPoint     = errors.New("osutils: not a mount point")
+)
+
+// MountEntry is a line of /proc/mounts or fstab.
+type MountEntry struct {
+	Source  string
+	Target  string
+	FSType  string
+	Options []string
+}
+
+type MountOptions struct {
+	ReadOnly bool
+	NoSuid   bool
+	NoDev    bool
+	NoExec   bool
+	// Data is passed to the filesystem, for example "size=64m" for tmpfs.
+	Data string
+}
+
+type BindMountOptions struct {
+	ReadOnly bool
+	// Recursive also binds the mounts below the source.
+	Recursive bool
+}
+
+type UnmountOptions struct {
+	// Lazy detaches the mount now and cleans it up once it is no longer
+	// busy.
+	Lazy bool
+	// Force unmounts even if busy, which only some filesystems such as
+	// NFS support.
+	Force bool
+}
+
+// Mount mounts source at absoluteTarget. Source is a device path, or a
+// name such as tmpfs for filesystems without a device. Only Linux is
+// supported, and it requires CAP_SYS_ADMIN.
+func Mount(source string, absoluteTarget string, fsType string, options *MountOptions) error {
+	if !isAbsolutePath(absoluteTarget) {
+		return ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &MountOptions{}
+	}
+	return mount(source, absoluteTarget, fsType, options)
+}
+
+// BindMount makes the file or dir at absoluteSource also visible at
+// absoluteTarget, which must exist. A read-only bind needs a remount, so the
+// bind is undone if that fails rather than left writable. A recursive
+// read-only bind makes every mount below absoluteTarget read-only too, and
+// the nosuid, nodev and noexec flags the mounts already have are kept.
+func BindMount(absoluteSource string, absoluteTarget string, options *BindMountOptions) error {
+	if !isAbsolutePath(absoluteSource) || !isAbsolutePath(absoluteTarget) {
+		return ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &BindMountOptions{}
+	}
+	return bindMount(absoluteSource, absoluteTarget, options)
+}
+
+func Unmount(absoluteTarget string, options *UnmountOptions) error {
+	if !isAbsolutePath(absoluteTarget) {
+		return ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &UnmountOptions{}
+	}
+	return unmount(absoluteTarget, options)
+}
+
+// ListMounts parses /proc/mounts, in mount order.
+func ListMounts() ([]*MountEntry, error) {
+	file, err := os.Open(procMountsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotSupported
+		}
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	return ParseMounts(file)
+}
+
+// ParseMounts parses the format of /proc/mounts and fstab. Comments and
+// blank lines are skipped, and octal escapes such as \040 for a space are
+// decoded.
+func ParseMounts(reader io.Reader) ([]*MountEntry, error) {
+	var mountEntries []*MountEntry
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			return nil, ErrInvalidMountEntry
+		}
+		for i := 0; i < 4; i++ {
+			field, err := unescapeMountField(fields[i])
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = field
+		}
+		mountEntries = append(
+			mountEntries,
+			&MountEntry{
+				Source:  fields[0],
+				Target:  fields[1],
+				FSType:  fields[2],
+				Options: strings.Split(fields[3], ","),
+			},
+		)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return mountEntries, nil
+}
+
+// FindMount returns the entry of the mount at absoluteTarget, the last one
+// if several are stacked there, or ErrNotMountPoint.
+func FindMount(absoluteTarget string) (*MountEntry, error) {
+	if !isAbsolutePath(absoluteTarget) {
+		return nil, ErrNotAbsolutePath
+	}
+	target, err := cleanPath(absoluteTarget)
+	if err != nil {
+		return nil, err
+	}
+	mountEntries, err := ListMounts()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(mountEntries) - 1; i >= 0; i-- {
+		if mountEntries[i].Target == target {
+			return mountEntries[i], nil
+		}
+	}
+	return nil, ErrNotMountPoint
+}
+
+// ***** PRIVATE *****
+
+func unescapeMountField(field string) (string, error) {
+	if !strings.Contains(field, `\`) {
+		return field, nil
+	}
+	var builder strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] != '\\' {
+			builder.WriteByte(field[i])
+			continue
+		}
+		if i+4 > len(field) {
+			return "", ErrInvalidMountEntry
+		}
+		value, err := strconv.ParseUint(field[i+1:i+4], 8, 8)
+		if err != nil {
+			return "", ErrInvalidMountEntry
+		}
+		builder.WriteByte(byte(value))
+		i += 3
+	}
+	return builder.String(), nil
+}
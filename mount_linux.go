@@ -0,0 +1,129 @@
+package osutils
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// mountSetattr is swapped in tests to exercise the fallback for kernels
+	// without mount_setattr.
+	mountSetattr = unix.MountSetattr
+	// statfsMountFlags maps the statfs flags of a mount to the flags that
+	// keep them on a remount.
+	statfsMountFlags = []struct {
+		statfs uint64
+		mount  uintptr
+	}{
+		{unix.ST_NOSUID, unix.MS_NOSUID},
+		{unix.ST_NODEV, unix.MS_NODEV},
+		{unix.ST_NOEXEC, unix.MS_NOEXEC},
+		{unix.ST_NOATIME, unix.MS_NOATIME},
+		{unix.ST_NODIRATIME, unix.MS_NODIRATIME},
+		{unix.ST_RELATIME, unix.MS_RELATIME},
+	}
+)
+
+func mount(source string, absoluteTarget string, fsType string, options *MountOptions) error {
+	var flags uintptr
+	if options.ReadOnly {
+		flags |= unix.MS_RDONLY
+	}
+	if options.NoSuid {
+		flags |= unix.MS_NOSUID
+	}
+	if options.NoDev {
+		flags |= unix.MS_NODEV
+	}
+	if options.NoExec {
+		flags |= unix.MS_NOEXEC
+	}
+	return unix.Mount(source, absoluteTarget, fsType, flags, options.Data)
+}
+
+func bindMount(absoluteSource string, absoluteTarget string, options *BindMountOptions) error {
+	flags := uintptr(unix.MS_BIND)
+	if options.Recursive {
+		flags |= unix.MS_REC
+	}
+	if err := unix.Mount(absoluteSource, absoluteTarget, "", flags, ""); err != nil {
+		return err
+	}
+	if !options.ReadOnly {
+		return nil
+	}
+	// the read-only flag is ignored on the initial bind
+	if err := remountReadOnly(absoluteTarget, options.Recursive); err != nil {
+		_ = unix.Unmount(absoluteTarget, unix.MNT_DETACH)
+		return err
+	}
+	return nil
+}
+
+// remountReadOnly makes the bind at absoluteTarget read-only, and with
+// recursive every mount below it too, as a remount only changes the flags
+// of the top mount even with MS_REC.
+func remountReadOnly(absoluteTarget string, recursive bool) error {
+	if !recursive {
+		return remountBindReadOnly(absoluteTarget)
+	}
+	err := mountSetattr(
+		unix.AT_FDCWD,
+		absoluteTarget,
+		unix.AT_RECURSIVE,
+		&unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY},
+	)
+	if err != unix.ENOSYS {
+		return err
+	}
+	// mount_setattr needs Linux 5.12, before that remount every mount in the
+	// tree, the top one first as mounts are listed in mount order
+	target, err := cleanPath(absoluteTarget)
+	if err != nil {
+		return err
+	}
+	mountEntries, err := ListMounts()
+	if err != nil {
+		return err
+	}
+	if err := remountBindReadOnly(target); err != nil {
+		return err
+	}
+	for _, mountEntry := range mountEntries {
+		if mountEntry.Target == target || !isWithin(target, mountEntry.Target) {
+			continue
+		}
+		if err := remountBindReadOnly(mountEntry.Target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remountBindReadOnly remounts the single mount at path read-only. A
+// remount replaces the per-mount flags, so the ones the mount already has
+// are passed again, as clearing those locked in a user namespace fails
+// with EPERM.
+func remountBindReadOnly(path string) error {
+	var statfs unix.Statfs_t
+	if err := unix.Statfs(path, &statfs); err != nil {
+		return err
+	}
+	flags := uintptr(unix.MS_REMOUNT | unix.MS_BIND | unix.MS_RDONLY)
+	for _, mountFlag := range statfsMountFlags {
+		if uint64(statfs.Flags)&mountFlag.statfs != 0 {
+			flags |= mountFlag.mount
+		}
+	}
+	return unix.Mount("", path, "", flags, "")
+}
+
+func unmount(absoluteTarget string, options *UnmountOptions) error {
+	var flags int
+	if options.Lazy {
+		flags |= unix.MNT_DETACH
+	}
+	if options.Force {
+		flags |= unix.MNT_FORCE
+	}
+	return unix.Unmount(absoluteTarget, flags)
+}
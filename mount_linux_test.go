@@ -0,0 +1,52 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func (s *Suite) TestBindMountRecursiveReadOnly() {
+	s.testBindMountRecursiveReadOnly()
+}
+
+func (s *Suite) TestBindMountRecursiveReadOnlyNoMountSetattr() {
+	defer func(fn func(int, string, uint, *unix.MountAttr) error) { mountSetattr = fn }(mountSetattr)
+	mountSetattr = func(int, string, uint, *unix.MountAttr) error {
+		return unix.ENOSYS
+	}
+	s.testBindMountRecursiveReadOnly()
+}
+
+func (s *Suite) testBindMountRecursiveReadOnly() {
+	src := filepath.Join(s.tempDir, "src")
+	sub := filepath.Join(src, "sub")
+	require.NoError(s.T(), os.MkdirAll(sub, 0755))
+	err := Mount("tmpfs", sub, "tmpfs", &MountOptions{NoSuid: true, NoDev: true})
+	if os.IsPermission(err) {
+		s.T().Skip("no permission to mount")
+	}
+	require.NoError(s.T(), err)
+	defer func() {
+		_ = Unmount(sub, &UnmountOptions{Lazy: true})
+	}()
+	target := filepath.Join(s.tempDir, "target")
+	require.NoError(s.T(), os.Mkdir(target, 0755))
+
+	require.NoError(s.T(), BindMount(src, target, &BindMountOptions{ReadOnly: true, Recursive: true}))
+	defer func() {
+		_ = Unmount(target, &UnmountOptions{Lazy: true})
+	}()
+	require.Error(s.T(), ioutil.WriteFile(filepath.Join(target, "file"), nil, 0644))
+	require.Error(s.T(), ioutil.WriteFile(filepath.Join(target, "sub", "file"), nil, 0644))
+	mountEntry, err := FindMount(filepath.Join(target, "sub"))
+	require.NoError(s.T(), err)
+	require.Contains(s.T(), mountEntry.Options, "ro")
+	require.Contains(s.T(), mountEntry.Options, "nosuid")
+	require.Contains(s.T(), mountEntry.Options, "nodev")
+	// the source stays writable
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(sub, "file"), nil, 0644))
+}
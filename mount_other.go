@@ -0,0 +1,15 @@
+//go:build !linux
+
+package osutils
+
+func mount(source string, absoluteTarget string, fsType string, options *MountOptions) error {
+	return ErrNotSupported
+}
+
+func bindMount(absoluteSource string, absoluteTarget string, options *BindMountOptions) error {
+	return ErrNotSupported
+}
+
+func unmount(absoluteTarget string, options *UnmountOptions) error {
+	return ErrNotSupported
+}
@@ -0,0 +1,82 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestParseMounts() {
+	mountEntries, err := ParseMounts(
+		strings.NewReader(
+			`# /etc/fstab
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+
+/dev/sda1 /mnt/my\040disk ext4 ro 0 2
+`,
+		),
+	)
+	require.NoError(s.T(), err)
+	require.Equal(
+		s.T(),
+		[]*MountEntry{
+			{
+				Source:  "proc",
+				Target:  "/proc",
+				FSType:  "proc",
+				Options: []string{"rw", "nosuid", "nodev", "noexec", "relatime"},
+			},
+			{
+				Source:  "/dev/sda1",
+				Target:  "/mnt/my disk",
+				FSType:  "ext4",
+				Options: []string{"ro"},
+			},
+		},
+		mountEntries,
+	)
+	_, err = ParseMounts(strings.NewReader("proc /proc\n"))
+	require.Equal(s.T(), ErrInvalidMountEntry, err)
+	_, err = ParseMounts(strings.NewReader("a /mnt\\04 ext4 rw\n"))
+	require.Equal(s.T(), ErrInvalidMountEntry, err)
+}
+
+func (s *Suite) TestBindMount() {
+	if runtime.GOOS != "linux" {
+		s.T().Skip("mounts only supported on linux")
+	}
+	src := filepath.Join(s.tempDir, "src")
+	require.NoError(s.T(), os.Mkdir(src, 0755))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(src, "file"), []byte("hello"), 0644))
+	target := filepath.Join(s.tempDir, "target")
+	require.NoError(s.T(), os.Mkdir(target, 0755))
+	_, err := FindMount(target)
+	require.Equal(s.T(), ErrNotMountPoint, err)
+
+	err = BindMount(src, target, &BindMountOptions{ReadOnly: true})
+	if os.IsPermission(err) {
+		s.T().Skip("no permission to mount")
+	}
+	require.NoError(s.T(), err)
+	unmounted := false
+	defer func() {
+		if !unmounted {
+			_ = Unmount(target, &UnmountOptions{Lazy: true})
+		}
+	}()
+	data, err := ioutil.ReadFile(filepath.Join(target, "file"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "hello", string(data))
+	require.Error(s.T(), ioutil.WriteFile(filepath.Join(target, "other"), nil, 0644))
+	mountEntry, err := FindMount(target)
+	require.NoError(s.T(), err)
+	require.Contains(s.T(), mountEntry.Options, "ro")
+
+	require.NoError(s.T(), Unmount(target, nil))
+	unmounted = true
+	s.checkFileDoesNotExist(filepath.Join(target, "file"))
+}
@@ -0,0 +1,89 @@
+package osutils
+
+import (
+	"os"
+)
+
+// MoveWithPolicy moves oldpath to newpath, unlike os.Rename never silently
+// replacing an existing newpath unless the policy is
+// OverwritePolicyOverwrite. The no-replace check is atomic where the
+// platform supports it, renameat2(RENAME_NOREPLACE) on Linux and a
+// link/unlink pair for files elsewhere. Moves across devices fall back to
+// copying for files. The final path is returned, or "" if the move was
+// skipped.
+func MoveWithPolicy(oldpath string, newpath string, overwritePolicy OverwritePolicy) (string, error) {
+	if !isAbsolutePath(oldpath) || !isAbsolutePath(newpath) {
+		return "", ErrNotAbsolutePath
+	}
+	return moveWithPolicy(oldpath, newpath, overwritePolicy)
+}
+
+// ***** PRIVATE *****
+
+func moveWithPolicy(oldpath string, newpath string, overwritePolicy OverwritePolicy) (string, error) {
+	if _, err := os.Lstat(oldpath); err != nil {
+		return "", err
+	}
+	switch overwritePolicy {
+	case OverwritePolicyOverwrite:
+		return newpath, moveFile(oldpath, newpath)
+	case OverwritePolicySkip:
+		err := moveNoReplace(oldpath, newpath)
+		if err == ErrFileExists {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return newpath, nil
+	case OverwritePolicyRenameUnique:
+		candidate := newpath
+		for {
+			err := moveNoReplace(oldpath, candidate)
+			if err == nil {
+				return candidate, nil
+			}
+			if err != ErrFileExists {
+				return "", err
+			}
+			if candidate, err = uniquePath(newpath); err != nil {
+				return "", err
+			}
+		}
+	default:
+		if err := moveNoReplace(oldpath, newpath); err != nil {
+			return "", err
+		}
+		return newpath, nil
+	}
+}
+
+// moveNoReplace moves oldpath to newpath, failing with ErrFileExists if
+// newpath exists.
+func moveNoReplace(oldpath string, newpath string) error {
+	err := renameNoReplace(oldpath, newpath)
+	if err != ErrNotSupported {
+		return err
+	}
+	fileInfo, err := os.Lstat(oldpath)
+	if err != nil {
+		return err
+	}
+	if fileInfo.Mode().IsRegular() {
+		// a hard link fails if newpath exists, which gives the atomic check
+		if err := os.Link(oldpath, newpath); err == nil {
+			return os.Remove(oldpath)
+		} else if os.IsExist(err) {
+			return ErrFileExists
+		}
+	}
+	// best effort, there is a window between the check and the move
+	exists, err := lexists(newpath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrFileExists
+	}
+	return moveFile(oldpath, newpath)
+}
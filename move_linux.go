@@ -0,0 +1,35 @@
+package osutils
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// renameNoReplace returns ErrNotSupported if the kernel or filesystem does
+// not support renameat2(RENAME_NOREPLACE).
+func renameNoReplace(oldpath string, newpath string) error {
+	err := unix.Renameat2(unix.AT_FDCWD, oldpath, unix.AT_FDCWD, newpath, unix.RENAME_NOREPLACE)
+	switch err {
+	case nil:
+		return nil
+	case unix.EEXIST:
+		return ErrFileExists
+	case unix.ENOSYS, unix.EINVAL, unix.EXDEV:
+		return ErrNotSupported
+	default:
+		return err
+	}
+}
+
+// renameExchange returns ErrNotSupported if the kernel or filesystem does
+// not support renameat2(RENAME_EXCHANGE).
+func renameExchange(a string, b string) error {
+	err := unix.Renameat2(unix.AT_FDCWD, a, unix.AT_FDCWD, b, unix.RENAME_EXCHANGE)
+	switch err {
+	case nil:
+		return nil
+	case unix.ENOSYS, unix.EINVAL:
+		return ErrNotSupported
+	default:
+		return err
+	}
+}
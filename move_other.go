@@ -0,0 +1,11 @@
+//go:build !linux
+
+package osutils
+
+func renameNoReplace(oldpath string, newpath string) error {
+	return ErrNotSupported
+}
+
+func renameExchange(a string, b string) error {
+	return ErrNotSupported
+}
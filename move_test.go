@@ -0,0 +1,31 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestMoveWithPolicy() {
+	one := filepath.Join(s.tempDir, "one")
+	two := filepath.Join(s.tempDir, "two.txt")
+	require.NoError(s.T(), ioutil.WriteFile(one, []byte("one"), 0644))
+	require.NoError(s.T(), ioutil.WriteFile(two, []byte("two"), 0644))
+	_, err := MoveWithPolicy(one, two, OverwritePolicyFail)
+	require.Equal(s.T(), ErrFileExists, err)
+	path, err := MoveWithPolicy(one, two, OverwritePolicySkip)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "", path)
+	s.checkFileExists(one)
+	path, err = MoveWithPolicy(one, two, OverwritePolicyRenameUnique)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), filepath.Join(s.tempDir, "two-1.txt"), path)
+	s.checkFileDoesNotExist(one)
+	path, err = MoveWithPolicy(path, two, OverwritePolicyOverwrite)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), two, path)
+	data, err := ioutil.ReadFile(two)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "one", string(data))
+}
@@ -0,0 +1,140 @@
+package osutils
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	namedSemaphorePollInterval = 50 * time.Millisecond
+)
+
+var (
+	ErrInvalidLockName = errors.New("osutils: invalid lock name")
+	ErrNotLocked       = errors.New("osutils: not locked")
+	ErrInvalidSlots    = errors.New("osutils: semaphore needs at least one slot")
+)
+
+// NamedMutex is a mutex shared by name between the processes of the
+// current user, backed by a lock file in RuntimeDir. For processes of
+// different users, use LockFile on a path they can all open. Locking a
+// NamedMutex this process already holds fails with ErrLocked.
+type NamedMutex struct {
+	path     string
+	lock     sync.Mutex
+	fileLock *FileLock
+}
+
+// NamedSemaphore allows up to n holders across processes, backed by n lock
+// files in RuntimeDir.
+type NamedSemaphore struct {
+	paths []string
+}
+
+func OpenNamedMutex(name string) (*NamedMutex, error) {
+	dir, err := namedLockDir(name)
+	if err != nil {
+		return nil, err
+	}
+	return &NamedMutex{path: filepath.Join(dir, "osutils-mutex-"+name+lockFileSuffix)}, nil
+}
+
+func (m *NamedMutex) Lock() error {
+	return m.doLock(true)
+}
+
+// TryLock fails with ErrLocked if another holder has the mutex.
+func (m *NamedMutex) TryLock() error {
+	return m.doLock(false)
+}
+
+func (m *NamedMutex) Unlock() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.fileLock == nil {
+		return ErrNotLocked
+	}
+	err := m.fileLock.Unlock()
+	m.fileLock = nil
+	return err
+}
+
+// OpenNamedSemaphore opens the semaphore with n slots. All processes must
+// use the same n.
+func OpenNamedSemaphore(name string, n int) (*NamedSemaphore, error) {
+	if n < 1 {
+		return nil, ErrInvalidSlots
+	}
+	dir, err := namedLockDir(name)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, "osutils-semaphore-"+name+"-"+strconv.Itoa(i)+lockFileSuffix)
+	}
+	return &NamedSemaphore{paths}, nil
+}
+
+// Acquire waits for a free slot. Call Unlock on the returned lock to
+// release it.
+func (s *NamedSemaphore) Acquire(ctx context.Context) (*FileLock, error) {
+	ticker := time.NewTicker(namedSemaphorePollInterval)
+	defer ticker.Stop()
+	for {
+		fileLock, err := s.TryAcquire()
+		if err != ErrLocked {
+			return fileLock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// TryAcquire fails with ErrLocked if all slots are held.
+func (s *NamedSemaphore) TryAcquire() (*FileLock, error) {
+	for _, path := range s.paths {
+		fileLock, err := lockFile(path, true, false)
+		if err == nil {
+			return fileLock, nil
+		}
+		if err != ErrLocked {
+			return nil, err
+		}
+	}
+	return nil, ErrLocked
+}
+
+// ***** PRIVATE *****
+
+func (m *NamedMutex) doLock(block bool) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.fileLock != nil {
+		return ErrLocked
+	}
+	fileLock, err := lockFile(m.path, true, block)
+	if err != nil {
+		return err
+	}
+	m.fileLock = fileLock
+	return nil
+}
+
+func namedLockDir(name string) (string, error) {
+	if name == "" {
+		return "", ErrEmpty
+	}
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", ErrInvalidLockName
+	}
+	return runtimeDir()
+}
@@ -0,0 +1,53 @@
+package osutils
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestNamedMutex() {
+	mutex, err := OpenNamedMutex("test-mutex")
+	require.NoError(s.T(), err)
+	other, err := OpenNamedMutex("test-mutex")
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), mutex.Lock())
+	require.Equal(s.T(), ErrLocked, other.TryLock())
+	require.Equal(s.T(), ErrLocked, mutex.TryLock())
+	require.NoError(s.T(), mutex.Unlock())
+	require.Equal(s.T(), ErrNotLocked, mutex.Unlock())
+	require.NoError(s.T(), other.TryLock())
+	require.NoError(s.T(), other.Unlock())
+
+	_, err = OpenNamedMutex("../escape")
+	require.Equal(s.T(), ErrInvalidLockName, err)
+}
+
+func (s *Suite) TestNamedSemaphore() {
+	semaphore, err := OpenNamedSemaphore("test-semaphore", 2)
+	require.NoError(s.T(), err)
+	first, err := semaphore.TryAcquire()
+	require.NoError(s.T(), err)
+	second, err := semaphore.TryAcquire()
+	require.NoError(s.T(), err)
+	_, err = semaphore.TryAcquire()
+	require.Equal(s.T(), ErrLocked, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = semaphore.Acquire(ctx)
+	require.Equal(s.T(), context.DeadlineExceeded, err)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = first.Unlock()
+	}()
+	third, err := semaphore.Acquire(context.Background())
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), second.Unlock())
+	require.NoError(s.T(), third.Unlock())
+
+	_, err = OpenNamedSemaphore("test-semaphore", 0)
+	require.Equal(s.T(), ErrInvalidSlots, err)
+}
@@ -0,0 +1,97 @@
+package osutils
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+var ErrNewlineInPath = errors.New("osutils: newline in path")
+
+type ListOptions struct {
+	// NullDelimited terminates each path with a NUL, as find -print0 does,
+	// rather than a newline.
+	NullDelimited bool
+}
+
+// ListRegularFilesTo writes the paths of the regular files under
+// absolutePath to writer as they are found, one per line or NUL-terminated,
+// see ListOptions. A path containing a newline cannot be written one per
+// line and results in ErrNewlineInPath.
+func ListRegularFilesTo(absolutePath string, writer io.Writer, options *ListOptions) error {
+	if writer == nil {
+		return ErrNil
+	}
+	if options == nil {
+		options = &ListOptions{}
+	}
+	bufferedWriter := bufio.NewWriter(writer)
+	if err := WalkRegularFiles(
+		absolutePath,
+		func(path string, dirEntry fs.DirEntry) error {
+			return writeDelimitedPath(bufferedWriter, path, options.NullDelimited)
+		},
+	); err != nil {
+		return err
+	}
+	return bufferedWriter.Flush()
+}
+
+// WriteNullDelimited writes each path followed by a NUL.
+func WriteNullDelimited(writer io.Writer, paths ...string) error {
+	bufferedWriter := bufio.NewWriter(writer)
+	for _, path := range paths {
+		if err := writeDelimitedPath(bufferedWriter, path, true); err != nil {
+			return err
+		}
+	}
+	return bufferedWriter.Flush()
+}
+
+// ReadNullDelimited reads NUL-delimited paths, as written by find -print0,
+// until EOF. Empty entries are skipped.
+func ReadNullDelimited(reader io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 1<<20)
+	scanner.Split(ScanNull)
+	var paths []string
+	for scanner.Scan() {
+		if path := scanner.Text(); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// ScanNull is a bufio.SplitFunc splitting NUL-delimited entries, with a
+// final entry that is not terminated also returned.
+func ScanNull(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// ***** PRIVATE *****
+
+func writeDelimitedPath(writer *bufio.Writer, path string, nullDelimited bool) error {
+	delimiter := byte('\n')
+	if nullDelimited {
+		delimiter = 0
+	} else if strings.IndexByte(path, '\n') >= 0 {
+		return ErrNewlineInPath
+	}
+	if _, err := writer.WriteString(path); err != nil {
+		return err
+	}
+	return writer.WriteByte(delimiter)
+}
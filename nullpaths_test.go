@@ -0,0 +1,46 @@
+package osutils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestListRegularFilesTo() {
+	dir := filepath.Join(s.tempDir, "list")
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	names := []string{"a", filepath.Join("sub", "b")}
+	if runtime.GOOS != "windows" {
+		names = append(names, "new\nline")
+	}
+	for _, name := range names {
+		require.NoError(s.T(), ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+	}
+	var output bytes.Buffer
+	require.NoError(s.T(), ListRegularFilesTo(dir, &output, &ListOptions{NullDelimited: true}))
+	paths, err := ReadNullDelimited(&output)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), paths, len(names))
+	for _, name := range names {
+		require.Contains(s.T(), paths, filepath.Join(dir, name))
+	}
+
+	output.Reset()
+	require.NoError(s.T(), WriteNullDelimited(&output, paths...))
+	roundTripped, err := ReadNullDelimited(&output)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), paths, roundTripped)
+
+	if runtime.GOOS != "windows" {
+		require.Equal(s.T(), ErrNewlineInPath, ListRegularFilesTo(dir, &bytes.Buffer{}, nil))
+		require.NoError(s.T(), os.Remove(filepath.Join(dir, "new\nline")))
+	}
+	output.Reset()
+	require.NoError(s.T(), ListRegularFilesTo(dir, &output, nil))
+	require.Equal(s.T(), 2, strings.Count(output.String(), "\n"))
+}
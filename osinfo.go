@@ -0,0 +1,108 @@
+package osutils
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+var (
+	osReleasePaths = []string{
+		"/etc/os-release",
+		"/usr/lib/os-release",
+	}
+)
+
+type PlatformInfo struct {
+	Platform      string
+	Arch          string
+	KernelVersion string
+	// the following are only set on Linux, from os-release(5)
+	ID         string
+	VersionID  string
+	PrettyName string
+	OSRelease  map[string]string
+}
+
+func OSInfo() (*PlatformInfo, error) {
+	return osInfo()
+}
+
+// ***** PRIVATE *****
+
+func osInfo() (*PlatformInfo, error) {
+	kernelVersion, err := kernelVersion()
+	if err != nil {
+		return nil, err
+	}
+	platformInfo := &PlatformInfo{
+		Platform:      runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		KernelVersion: kernelVersion,
+	}
+	if runtime.GOOS != "linux" {
+		return platformInfo, nil
+	}
+	osRelease, err := readOSRelease()
+	if err != nil {
+		return nil, err
+	}
+	if osRelease != nil {
+		platformInfo.ID = osRelease["ID"]
+		platformInfo.VersionID = osRelease["VERSION_ID"]
+		platformInfo.PrettyName = osRelease["PRETTY_NAME"]
+		platformInfo.OSRelease = osRelease
+	}
+	return platformInfo, nil
+}
+
+func readOSRelease() (map[string]string, error) {
+	for _, osReleasePath := range osReleasePaths {
+		file, err := os.Open(osReleasePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		defer file.Close()
+		return parseOSRelease(file)
+	}
+	return nil, nil
+}
+
+func parseOSRelease(reader io.Reader) (map[string]string, error) {
+	osRelease := make(map[string]string)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		split := strings.SplitN(line, "=", 2)
+		if len(split) != 2 {
+			continue
+		}
+		osRelease[split[0]] = unquoteOSReleaseValue(split[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return osRelease, nil
+}
+
+func unquoteOSReleaseValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	var buffer []byte
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+		}
+		buffer = append(buffer, value[i])
+	}
+	return string(buffer)
+}
@@ -0,0 +1,11 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package osutils
+
+import (
+	"syscall"
+)
+
+func kernelVersion() (string, error) {
+	return syscall.Sysctl("kern.osrelease")
+}
@@ -0,0 +1,20 @@
+package osutils
+
+import (
+	"syscall"
+)
+
+func kernelVersion() (string, error) {
+	var utsname syscall.Utsname
+	if err := syscall.Uname(&utsname); err != nil {
+		return "", err
+	}
+	release := make([]byte, 0, len(utsname.Release))
+	for _, c := range utsname.Release {
+		if c == 0 {
+			break
+		}
+		release = append(release, byte(c))
+	}
+	return string(release), nil
+}
@@ -0,0 +1,7 @@
+//go:build !unix && !windows
+
+package osutils
+
+func kernelVersion() (string, error) {
+	return "", ErrNotSupported
+}
@@ -0,0 +1,32 @@
+package osutils
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestOSInfo() {
+	platformInfo, err := OSInfo()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), runtime.GOOS, platformInfo.Platform)
+	require.NotEmpty(s.T(), platformInfo.KernelVersion)
+}
+
+func (s *Suite) TestParseOSRelease() {
+	osRelease, err := parseOSRelease(
+		strings.NewReader(
+			`# comment
+NAME="Ubuntu"
+ID=ubuntu
+VERSION_ID='22.04'
+PRETTY_NAME="Ubuntu \"Jammy\" 22.04"
+`,
+		),
+	)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "ubuntu", osRelease["ID"])
+	require.Equal(s.T(), "22.04", osRelease["VERSION_ID"])
+	require.Equal(s.T(), `Ubuntu "Jammy" 22.04`, osRelease["PRETTY_NAME"])
+}
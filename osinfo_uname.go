@@ -0,0 +1,15 @@
+//go:build aix || solaris
+
+package osutils
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func kernelVersion() (string, error) {
+	var utsname unix.Utsname
+	if err := unix.Uname(&utsname); err != nil {
+		return "", err
+	}
+	return unix.ByteSliceToString(utsname.Release[:]), nil
+}
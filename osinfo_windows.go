@@ -0,0 +1,14 @@
+package osutils
+
+import (
+	"fmt"
+	"syscall"
+)
+
+func kernelVersion() (string, error) {
+	version, err := syscall.GetVersion()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d.%d.%d", byte(version), byte(version>>8), uint16(version>>16)), nil
+}
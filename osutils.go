@@ -4,18 +4,28 @@ Package osutils contains OS utilities for Go.
 package osutils
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/satori/go.uuid"
 )
 
 const (
 	tempDirPrefix = "osutils"
+	// timeoutWaitDelay bounds how long waiting for a command killed by its
+	// timeout blocks on copying its input or output.
+	timeoutWaitDelay = time.Second
 )
 
 var (
@@ -26,6 +36,12 @@ var (
 	ErrFileDoesNotExist    = errors.New("osutils: file does not exist")
 	ErrNotRegularFile      = errors.New("osutils: not regular file")
 	ErrNotDir              = errors.New("osutils: not dir")
+	ErrNotSupported        = errors.New("osutils: not supported")
+	ErrInvalidRlimit       = errors.New("osutils: invalid rlimit")
+	ErrNotFifo             = errors.New("osutils: not fifo")
+	ErrMultipleOutputs     = errors.New("osutils: multiple outputs")
+	ErrMultipleInputs      = errors.New("osutils: multiple inputs")
+	ErrInvalidPipeCmd      = errors.New("osutils: pipe cmd has both Args and Func")
 )
 
 type Cmd struct {
@@ -33,29 +49,63 @@ type Cmd struct {
 	AbsoluteDir string
 	Env         []string
 	Stdin       io.Reader
+	// StdinString, StdinBytes and StdinFile are alternatives to Stdin, at
+	// most one stdin source can be set.
+	StdinString string
+	StdinBytes  []byte
+	StdinFile   string
 	Stdout      io.Writer
 	Stderr      io.Writer
+	// CombinedOutput, if set, receives both stdout and stderr through a
+	// single pipe shared by the two streams, so the interleaving is the order
+	// in which the command wrote the output. It cannot be combined with
+	// Stdout or Stderr.
+	CombinedOutput io.Writer
+	// LogDir, if set, additionally tees stdout and stderr into timestamped
+	// log files in this dir, rotated and pruned according to LogRotation.
+	LogDir      string
+	LogRotation *LogRotation
+	// Heartbeat, if set, reports on the command while it runs.
+	Heartbeat *Heartbeat
+	// Monitor, if set, samples the resource usage of the command while it
+	// runs, see Result.Usage.
+	Monitor *Monitor
+	// Transcript, if set, records the output of the command with timings.
+	Transcript *Transcript
+	// Timeout, if set, kills the command once it has run this long, and
+	// the wait function returns ErrTimeout.
+	Timeout time.Duration
 }
 
 type PipeCmd struct {
 	Args        []string
 	AbsoluteDir string
 	Env         []string
+	// Func, if set instead of Args, runs the stage in-process, see PipeFunc.
+	Func PipeFunc
 }
 
 type PipeCmdList struct {
 	PipeCmds []*PipeCmd
-	Stdin    io.Reader
-	Stdout   io.Writer
-	Stderr   io.Writer
+	// Env applies to every stage, with the Env of each stage taking
+	// precedence. If InheritEnv is set, both are merged on top of the
+	// environment of the current process.
+	Env        []string
+	InheritEnv bool
+	Stdin      io.Reader
+	Stdout     io.Writer
+	Stderr     io.Writer
+	// Timeout, if set, kills every stage once the pipeline has run this
+	// long, and the wait function returns ErrTimeout.
+	Timeout time.Duration
 }
 
 func Execute(cmd *Cmd) (func() error, error) {
-	return execute(cmd)
+	return execute(context.Background(), cmd)
 }
 
 func ExecutePiped(pipeCmdList *PipeCmdList) (func() error, error) {
-	return executePiped(pipeCmdList)
+	return executePiped(context.Background(), pipeCmdList)
 }
 
 func ListRegularFiles(absolutePath string) ([]string, error) {
@@ -120,7 +170,61 @@ func CleanPath(absolutePath string) (string, error) {
 
 // ***** PRIVATE *****
 
-func execute(cmd *Cmd) (func() error, error) {
+func execute(ctx context.Context, cmd *Cmd) (func() error, error) {
+	process, err := startCmd(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return process.wait, nil
+}
+
+// process is a started Cmd. The cleanups run after the command exits, in
+// order, and the first error wins.
+type process struct {
+	execCmd   *exec.Cmd
+	start     time.Time
+	combined  io.Writer
+	logPaths  func() []string
+	heartbeat *heartbeat
+	monitor   *monitor
+	usage     *ResourceUsage
+	// timeoutKilled is set if the timeout killed the command
+	timeoutKilled atomic.Bool
+	cleanups      []func() error
+}
+
+func (p *process) wait() error {
+	err := waitExecCmd(p.execCmd, p.start)
+	if killed, timeoutErr := checkTimeoutKill(p.execCmd, &p.timeoutKilled, err); killed {
+		err = ErrTimeout
+	} else {
+		err = timeoutErr
+	}
+	if cleanupErr := p.cleanup(); err == nil {
+		err = cleanupErr
+	}
+	if p.monitor != nil {
+		var killed bool
+		p.usage, killed = p.monitor.finish(p.start)
+		if killed {
+			err = ErrMemoryLimitExceeded
+		}
+	}
+	return err
+}
+
+func (p *process) cleanup() error {
+	var err error
+	for _, cleanup := range p.cleanups {
+		if cleanupErr := cleanup(); err == nil {
+			err = cleanupErr
+		}
+	}
+	p.cleanups = nil
+	return err
+}
+
+func startCmd(ctx context.Context, cmd *Cmd) (*process, error) {
 	if cmd.Args == nil {
 		return nil, ErrNil
 	}
@@ -130,72 +234,176 @@ func execute(cmd *Cmd) (func() error, error) {
 	if cmd.AbsoluteDir != "" && !isAbsolutePath(cmd.AbsoluteDir) {
 		return nil, ErrNotAbsolutePath
 	}
-	execCmd, err := execCmd(cmd)
-	if err != nil {
+	if cmd.LogDir != "" && !isAbsolutePath(cmd.LogDir) {
+		return nil, ErrNotAbsolutePath
+	}
+	if cmd.CombinedOutput != nil && (cmd.Stdout != nil || cmd.Stderr != nil) {
+		return nil, ErrMultipleOutputs
+	}
+	if cmd.Transcript != nil && cmd.Transcript.Writer == nil {
+		return nil, ErrNil
+	}
+	if err := validateCmdStdin(cmd); err != nil {
 		return nil, err
 	}
-	if err := execCmd.Start(); err != nil {
+	ctx, cancel, timedOut := withTimeout(ctx, cmd.Timeout)
+	execCmd, err := execCmd(ctx, cmd)
+	if err != nil {
+		cancel()
 		return nil, err
 	}
-	return func() error { return execCmd.Wait() }, nil
-}
-
-func executePiped(pipeCmdList *PipeCmdList) (func() error, error) {
-	if pipeCmdList.PipeCmds == nil {
-		return nil, ErrNil
+	process := &process{
+		execCmd:  execCmd,
+		combined: cmd.CombinedOutput,
+		cleanups: []func() error{
+			func() error {
+				cancel()
+				return nil
+			},
+		},
 	}
-	numCmds := len(pipeCmdList.PipeCmds)
-	if numCmds == 0 {
-		return nil, ErrEmpty
+	if cmd.Timeout > 0 {
+		execCmd.WaitDelay = timeoutWaitDelay
+		killOnTimeout(execCmd, timedOut, &process.timeoutKilled)
 	}
-	if numCmds <= 1 {
-		return nil, ErrNotMultipleCommands
+	if err := setupCmdStdin(process, cmd); err != nil {
+		_ = process.cleanup()
+		return nil, err
 	}
-	for _, pipeCmd := range pipeCmdList.PipeCmds {
-		if pipeCmd.Args == nil {
-			return nil, ErrNil
+	if cmd.LogDir != "" {
+		if err := setupCmdLogs(process, cmd); err != nil {
+			_ = process.cleanup()
+			return nil, err
 		}
-		if len(pipeCmd.Args) == 0 {
-			return nil, ErrEmpty
+	}
+	if cmd.Transcript != nil {
+		if err := setupCmdTranscript(process, cmd); err != nil {
+			_ = process.cleanup()
+			return nil, err
 		}
-		if pipeCmd.AbsoluteDir != "" && !isAbsolutePath(pipeCmd.AbsoluteDir) {
-			return nil, ErrNotAbsolutePath
+	}
+	if cmd.Heartbeat != nil {
+		setupCmdHeartbeat(process, cmd)
+	}
+	if cmd.Monitor != nil {
+		setupCmdMonitor(process, cmd)
+	}
+	var combinedWriter *os.File
+	if process.combined != nil {
+		if combinedWriter, err = setupCombinedOutput(process); err != nil {
+			_ = process.cleanup()
+			return nil, err
 		}
 	}
-	execCmds := make([]*exec.Cmd, numCmds)
+	process.start = time.Now()
+	err = execCmd.Start()
+	// the child has its own copy of the write end now
+	if combinedWriter != nil {
+		_ = combinedWriter.Close()
+	}
+	if err != nil {
+		_ = process.cleanup()
+		return nil, err
+	}
+	if process.heartbeat != nil {
+		process.heartbeat.run(execCmd.Process.Pid, process.start)
+	}
+	if process.monitor != nil {
+		process.monitor.run(process)
+	}
+	return process, nil
+}
+
+func executePiped(ctx context.Context, pipeCmdList *PipeCmdList) (func() error, error) {
+	if err := validatePipeCmdList(pipeCmdList); err != nil {
+		return nil, err
+	}
+	ctx, cancel, timedOut := withTimeout(ctx, pipeCmdList.Timeout)
+	pipeStages, wait, err := startPiped(ctx, pipeCmdList, timedOut)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return func() error {
+		err := wait()
+		cancel()
+		if err != nil {
+			for _, pipeStage := range pipeStages {
+				if pipeStage.killedByTimeout() {
+					return ErrTimeout
+				}
+			}
+		}
+		return err
+	}, nil
+}
+
+func startPiped(ctx context.Context, pipeCmdList *PipeCmdList, timedOut func() bool) ([]*pipeStage, func() error, error) {
+	numCmds := len(pipeCmdList.PipeCmds)
+	pipeStages := make([]*pipeStage, numCmds)
 	for i, pipeCmd := range pipeCmdList.PipeCmds {
-		execCmd, err := execPipeCmd(pipeCmd)
+		if pipeCmd.Func != nil {
+			pipeStages[i] = &pipeStage{pipeFunc: pipeCmd.Func}
+			continue
+		}
+		execCmd, err := execPipeCmd(ctx, pipeCmd)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		execCmd.Env = pipeCmdEnv(pipeCmdList, pipeCmd)
+		pipeStages[i] = &pipeStage{execCmd: execCmd}
+		if pipeCmdList.Timeout > 0 {
+			execCmd.WaitDelay = timeoutWaitDelay
+			killOnTimeout(execCmd, timedOut, &pipeStages[i].timeoutKilled)
 		}
-		execCmds[i] = execCmd
 	}
 	readers := make([]*io.PipeReader, numCmds-1)
 	writers := make([]*io.PipeWriter, numCmds-1)
 	reader, writer := io.Pipe()
 	readers[0] = reader
 	writers[0] = writer
-	execCmds[0].Stdin = pipeCmdList.Stdin
+	pipeStages[0].setStdin(pipeCmdList.Stdin)
 	for i := 0; i < numCmds-1; i++ {
-		execCmds[i].Stdout = writer
-		execCmds[i].Stderr = pipeCmdList.Stderr
-		execCmds[i+1].Stdin = reader
+		pipeStages[i].setStdout(writer)
+		pipeStages[i].setStderr(pipeCmdList.Stderr)
+		pipeStages[i+1].setStdin(reader)
 		if i != numCmds-2 {
 			reader, writer = io.Pipe()
 			readers[i+1] = reader
 			writers[i+1] = writer
 		}
 	}
-	execCmds[numCmds-1].Stdout = pipeCmdList.Stdout
-	execCmds[numCmds-1].Stderr = pipeCmdList.Stderr
-	for _, execCmd := range execCmds {
-		if err := execCmd.Start(); err != nil {
-			return nil, err
+	pipeStages[numCmds-1].setStdout(pipeCmdList.Stdout)
+	pipeStages[numCmds-1].setStderr(pipeCmdList.Stderr)
+	if pipeCmdList.Timeout > 0 {
+		// killing the processes does not stop a PipeFunc stage blocked on
+		// a pipe, so fail the pipes too
+		context.AfterFunc(
+			ctx,
+			func() {
+				if timedOut() {
+					for _, pipeStage := range pipeStages {
+						if pipeStage.pipeFunc != nil && !pipeStage.done.Load() {
+							pipeStage.timeoutKilled.Store(true)
+						}
+					}
+				}
+				for i := range readers {
+					_ = readers[i].CloseWithError(ctx.Err())
+					_ = writers[i].CloseWithError(ctx.Err())
+				}
+			},
+		)
+	}
+	start := time.Now()
+	for _, pipeStage := range pipeStages {
+		if err := pipeStage.start(); err != nil {
+			return nil, nil, err
 		}
 	}
-	return func() error {
+	return pipeStages, func() error {
 		for i := 0; i < numCmds-1; i++ {
-			if err := execCmds[i].Wait(); err != nil {
+			if err := pipeStages[i].wait(start); err != nil {
 				return err
 			}
 			if i != 0 {
@@ -207,7 +415,7 @@ func executePiped(pipeCmdList *PipeCmdList) (func() error, error) {
 				return err
 			}
 		}
-		if err := execCmds[numCmds-1].Wait(); err != nil {
+		if err := pipeStages[numCmds-1].wait(start); err != nil {
 			return err
 		}
 		if err := readers[numCmds-2].Close(); err != nil {
@@ -217,20 +425,180 @@ func executePiped(pipeCmdList *PipeCmdList) (func() error, error) {
 	}, nil
 }
 
+// withTimeout derives the context a command with timeout runs in, if
+// timeout is set. timedOut reports whether the deadline has passed, as
+// opposed to ctx being done.
+func withTimeout(ctx context.Context, timeout time.Duration) (_ context.Context, cancel func(), timedOut func() bool) {
+	if timeout <= 0 {
+		return ctx, func() {}, func() bool { return false }
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	return timeoutCtx, cancel, func() bool {
+		return ctx.Err() == nil && timeoutCtx.Err() == context.DeadlineExceeded
+	}
+}
+
+// killOnTimeout records in killed whether execCmd was killed by its
+// timeout, rather than by ctx being done.
+func killOnTimeout(execCmd *exec.Cmd, timedOut func() bool, killed *atomic.Bool) {
+	execCmd.Cancel = func() error {
+		err := execCmd.Process.Kill()
+		if err == nil && timedOut() {
+			killed.Store(true)
+		}
+		return err
+	}
+}
+
+// checkTimeoutKill returns whether the timeout kill recorded in killed
+// stopped execCmd, and otherwise err. Killing a process that exited by
+// itself but was not waited for yet still succeeds, so how it exited tells,
+// except on Windows where a killed process exits like any other.
+func checkTimeoutKill(execCmd *exec.Cmd, killed *atomic.Bool, err error) (bool, error) {
+	if !killed.Load() {
+		return false, err
+	}
+	state := execCmd.ProcessState
+	if state == nil {
+		// not waited for
+		return false, err
+	}
+	if !state.Exited() || runtime.GOOS == "windows" {
+		return true, err
+	}
+	// os/exec reports the context error for a command that had succeeded
+	if state.Success() && errors.Is(err, context.DeadlineExceeded) {
+		return false, nil
+	}
+	return false, err
+}
+
+func validatePipeCmdList(pipeCmdList *PipeCmdList) error {
+	if pipeCmdList.PipeCmds == nil {
+		return ErrNil
+	}
+	numCmds := len(pipeCmdList.PipeCmds)
+	if numCmds == 0 {
+		return ErrEmpty
+	}
+	if numCmds <= 1 {
+		return ErrNotMultipleCommands
+	}
+	for _, pipeCmd := range pipeCmdList.PipeCmds {
+		if pipeCmd.Func != nil {
+			if pipeCmd.Args != nil {
+				return ErrInvalidPipeCmd
+			}
+			continue
+		}
+		if pipeCmd.Args == nil {
+			return ErrNil
+		}
+		if len(pipeCmd.Args) == 0 {
+			return ErrEmpty
+		}
+		if pipeCmd.AbsoluteDir != "" && !isAbsolutePath(pipeCmd.AbsoluteDir) {
+			return ErrNotAbsolutePath
+		}
+	}
+	return nil
+}
+
+func validateCmdStdin(cmd *Cmd) error {
+	numStdins := 0
+	if cmd.Stdin != nil {
+		numStdins++
+	}
+	if cmd.StdinString != "" {
+		numStdins++
+	}
+	if cmd.StdinBytes != nil {
+		numStdins++
+	}
+	if cmd.StdinFile != "" {
+		if !isAbsolutePath(cmd.StdinFile) {
+			return ErrNotAbsolutePath
+		}
+		numStdins++
+	}
+	if numStdins > 1 {
+		return ErrMultipleInputs
+	}
+	return nil
+}
+
+func setupCmdStdin(process *process, cmd *Cmd) error {
+	switch {
+	case cmd.StdinString != "":
+		process.execCmd.Stdin = strings.NewReader(cmd.StdinString)
+	case cmd.StdinBytes != nil:
+		process.execCmd.Stdin = bytes.NewReader(cmd.StdinBytes)
+	case cmd.StdinFile != "":
+		file, err := open(cmd.StdinFile)
+		if err != nil {
+			return err
+		}
+		process.execCmd.Stdin = file
+		process.cleanups = append(process.cleanups, file.Close)
+	}
+	return nil
+}
+
+// setupCombinedOutput points both stdout and stderr of the command at the
+// same pipe, and copies the pipe to the combined writer until the command
+// exits. The returned write end has to be closed once the command started.
+func setupCombinedOutput(process *process) (*os.File, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	process.execCmd.Stdout = writer
+	process.execCmd.Stderr = writer
+	copyErrC := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(process.combined, reader)
+		copyErrC <- err
+	}()
+	// the copy has to finish before anything else is cleaned up, so this
+	// goes first
+	process.cleanups = append(
+		[]func() error{
+			func() error {
+				// guards against the write end never being closed if the
+				// command failed to start
+				_ = writer.Close()
+				err := <-copyErrC
+				if closeErr := reader.Close(); err == nil {
+					err = closeErr
+				}
+				return err
+			},
+		},
+		process.cleanups...,
+	)
+	return writer, nil
+}
+
+// waitExecCmd waits for a started command and records it in the audit log
+// if one is set.
+func waitExecCmd(execCmd *exec.Cmd, start time.Time) error {
+	err := execCmd.Wait()
+	observeOperation(MetricsOperationExecute, start, 0, err)
+	if auditErr := auditExecCmd(execCmd, start, err); err == nil {
+		err = auditErr
+	}
+	return err
+}
+
 func listRegularFiles(absolutePath string) ([]string, error) {
 	if !isAbsolutePath(absolutePath) {
 		return nil, ErrNotAbsolutePath
 	}
 	var files []string
-	if err := filepath.Walk(
+	if err := WalkRegularFiles(
 		absolutePath,
-		func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if info.Mode().IsRegular() {
-				files = append(files, path)
-			}
+		func(path string, _ fs.DirEntry) error {
+			files = append(files, path)
 			return nil
 		},
 	); err != nil {
@@ -257,17 +625,21 @@ func create(absolutePath string) (*os.File, error) {
 	if !isAbsolutePath(absolutePath) {
 		return nil, ErrNotAbsolutePath
 	}
-	return os.Create(absolutePath)
+	return createWithPolicy(absolutePath, currentPolicy())
 }
 
 func openFile(absolutePath string, flag int, perm os.FileMode) (*os.File, error) {
 	if !isAbsolutePath(absolutePath) {
 		return nil, ErrNotAbsolutePath
 	}
-	return os.OpenFile(absolutePath, flag, perm)
+	return openFileWithPolicy(absolutePath, flag, perm, currentPolicy())
 }
 
 func isRegularFileExists(absolutePath string) (bool, error) {
+	return isRegularFileExistsWithStat(absolutePath, stat)
+}
+
+func isRegularFileExistsWithStat(absolutePath string, stat func(string) (os.FileInfo, error)) (bool, error) {
 	if !isAbsolutePath(absolutePath) {
 		return false, ErrNotAbsolutePath
 	}
@@ -285,6 +657,10 @@ func isRegularFileExists(absolutePath string) (bool, error) {
 }
 
 func isDirExists(absolutePath string) (bool, error) {
+	return isDirExistsWithStat(absolutePath, stat)
+}
+
+func isDirExistsWithStat(absolutePath string, stat func(string) (os.FileInfo, error)) (bool, error) {
 	if !isAbsolutePath(absolutePath) {
 		return false, ErrNotAbsolutePath
 	}
@@ -302,6 +678,10 @@ func isDirExists(absolutePath string) (bool, error) {
 }
 
 func isFileExists(absolutePath string) (bool, error) {
+	return isFileExistsWithStat(absolutePath, stat)
+}
+
+func isFileExistsWithStat(absolutePath string, stat func(string) (os.FileInfo, error)) (bool, error) {
 	if !isAbsolutePath(absolutePath) {
 		return false, ErrNotAbsolutePath
 	}
@@ -313,20 +693,23 @@ func mkdir(absolutePath string, perm os.FileMode) error {
 	if !isAbsolutePath(absolutePath) {
 		return ErrNotAbsolutePath
 	}
-	return os.Mkdir(absolutePath, perm)
+	return mkdirWithPolicy(absolutePath, perm, currentPolicy())
 }
 
 func mkdirAll(absolutePath string, perm os.FileMode) error {
 	if !isAbsolutePath(absolutePath) {
 		return ErrNotAbsolutePath
 	}
-	return os.MkdirAll(absolutePath, perm)
+	return mkdirAllWithPolicy(absolutePath, perm, currentPolicy())
 }
 
 func removeAll(absolutePath string) error {
 	if !isAbsolutePath(absolutePath) {
 		return ErrNotAbsolutePath
 	}
+	if err := checkGuard(absolutePath); err != nil {
+		return err
+	}
 	return os.RemoveAll(absolutePath)
 }
 
@@ -390,12 +773,12 @@ func stat(absolutePath string) (os.FileInfo, error) {
 	return nil, err
 }
 
-func execCmd(cmd *Cmd) (*exec.Cmd, error) {
+func execCmd(ctx context.Context, cmd *Cmd) (*exec.Cmd, error) {
 	var execCmd *exec.Cmd
 	if len(cmd.Args) == 1 {
-		execCmd = exec.Command(cmd.Args[0])
+		execCmd = exec.CommandContext(ctx, cmd.Args[0])
 	} else {
-		execCmd = exec.Command(cmd.Args[0], cmd.Args[1:]...)
+		execCmd = exec.CommandContext(ctx, cmd.Args[0], cmd.Args[1:]...)
 	}
 	execCmd.Dir = cmd.AbsoluteDir
 	execCmd.Env = cmd.Env
@@ -405,12 +788,30 @@ func execCmd(cmd *Cmd) (*exec.Cmd, error) {
 	return execCmd, nil
 }
 
-func execPipeCmd(pipeCmd *PipeCmd) (*exec.Cmd, error) {
+// pipeCmdEnv returns the environment for a stage. Without a list-wide Env or
+// InheritEnv, the stage Env is used as is, as it always was.
+func pipeCmdEnv(pipeCmdList *PipeCmdList, pipeCmd *PipeCmd) []string {
+	if pipeCmdList.Env == nil && !pipeCmdList.InheritEnv {
+		return pipeCmd.Env
+	}
+	var env []string
+	if pipeCmdList.InheritEnv {
+		env = os.Environ()
+	}
+	env = mergeEnv(env, envSliceToMap(pipeCmdList.Env))
+	env = mergeEnv(env, envSliceToMap(pipeCmd.Env))
+	if env == nil {
+		env = []string{}
+	}
+	return env
+}
+
+func execPipeCmd(ctx context.Context, pipeCmd *PipeCmd) (*exec.Cmd, error) {
 	var execCmd *exec.Cmd
 	if len(pipeCmd.Args) == 1 {
-		execCmd = exec.Command(pipeCmd.Args[0])
+		execCmd = exec.CommandContext(ctx, pipeCmd.Args[0])
 	} else {
-		execCmd = exec.Command(pipeCmd.Args[0], pipeCmd.Args[1:]...)
+		execCmd = exec.CommandContext(ctx, pipeCmd.Args[0], pipeCmd.Args[1:]...)
 	}
 	execCmd.Dir = pipeCmd.AbsoluteDir
 	execCmd.Env = pipeCmd.Env
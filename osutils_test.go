@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -16,7 +17,8 @@ import (
 
 type Suite struct {
 	suite.Suite
-	tempDir string
+	workspace *Workspace
+	tempDir   string
 }
 
 func TestSuite(t *testing.T) {
@@ -27,13 +29,14 @@ func (s *Suite) SetupSuite() {
 }
 
 func (s *Suite) SetupTest() {
-	tempDir, err := NewTempDir()
+	workspace, err := NewWorkspace()
 	require.NoError(s.T(), err)
-	s.tempDir = tempDir
+	s.workspace = workspace
+	s.tempDir = workspace.Root()
 }
 
 func (s *Suite) TearDownTest() {
-	require.NoError(s.T(), os.RemoveAll(s.tempDir))
+	require.NoError(s.T(), s.workspace.Close())
 }
 
 func (s *Suite) TearDownSuite() {
@@ -101,6 +104,112 @@ func (s *Suite) TestPipe() {
 	require.True(s.T(), strings.Contains(output.String(), "3"))
 }
 
+func (s *Suite) TestStdin() {
+	stdinFile := filepath.Join(s.tempDir, "stdin")
+	require.NoError(s.T(), ioutil.WriteFile(stdinFile, []byte("foo"), 0644))
+	for _, cmd := range []*Cmd{
+		&Cmd{StdinString: "foo"},
+		&Cmd{StdinBytes: []byte("foo")},
+		&Cmd{StdinFile: stdinFile},
+	} {
+		var output bytes.Buffer
+		cmd.Args = []string{"cat"}
+		cmd.Stdout = &output
+		wait, err := Execute(cmd)
+		require.NoError(s.T(), err)
+		require.NoError(s.T(), wait())
+		require.Equal(s.T(), "foo", output.String())
+	}
+	_, err := Execute(&Cmd{Args: []string{"cat"}, StdinString: "foo", StdinBytes: []byte("foo")})
+	require.Equal(s.T(), ErrMultipleInputs, err)
+}
+
+func (s *Suite) TestPipeEnv() {
+	var output bytes.Buffer
+	wait, err := ExecutePiped(
+		&PipeCmdList{
+			PipeCmds: []*PipeCmd{
+				&PipeCmd{
+					Args: []string{"bash", "-c", `echo "${FOO} ${BAR}"`},
+					Env:  []string{"BAR=stage"},
+				},
+				&PipeCmd{
+					Args: []string{"cat"},
+				},
+			},
+			Env:        []string{"FOO=list", "BAR=list"},
+			InheritEnv: true,
+			Stdout:     &output,
+		},
+	)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), wait())
+	require.Equal(s.T(), "list stage\n", output.String())
+}
+
+func (s *Suite) TestTimeout() {
+	start := time.Now()
+	wait, err := Execute(&Cmd{Args: []string{"sleep", "10"}, Timeout: 50 * time.Millisecond})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), ErrTimeout, wait())
+	require.True(s.T(), time.Since(start) < 5*time.Second)
+	wait, err = Execute(&Cmd{Args: []string{"true"}, Timeout: 10 * time.Second})
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), wait())
+
+	// the PipeFunc stage is stuck reading until the pipes are failed
+	start = time.Now()
+	wait, err = ExecutePiped(
+		&PipeCmdList{
+			PipeCmds: []*PipeCmd{
+				&PipeCmd{
+					Args: []string{"sleep", "10"},
+				},
+				&PipeCmd{
+					Func: func(reader io.Reader, writer io.Writer) error {
+						_, err := io.Copy(writer, reader)
+						return err
+					},
+				},
+				&PipeCmd{
+					Args: []string{"cat"},
+				},
+			},
+			Timeout: 50 * time.Millisecond,
+		},
+	)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), ErrTimeout, wait())
+	require.True(s.T(), time.Since(start) < 5*time.Second)
+
+	// commands that finished in time keep their result when waited for after
+	// the deadline
+	wait, err = Execute(&Cmd{Args: []string{"sh", "-c", "exit 3"}, Timeout: 100 * time.Millisecond})
+	require.NoError(s.T(), err)
+	time.Sleep(300 * time.Millisecond)
+	require.Equal(s.T(), 3, exitCode(wait()))
+	wait, err = Execute(&Cmd{Args: []string{"true"}, Timeout: 100 * time.Millisecond})
+	require.NoError(s.T(), err)
+	time.Sleep(300 * time.Millisecond)
+	require.NoError(s.T(), wait())
+	wait, err = ExecutePiped(
+		&PipeCmdList{
+			PipeCmds: []*PipeCmd{
+				&PipeCmd{
+					Args: []string{"sh", "-c", "exit 3"},
+				},
+				&PipeCmd{
+					Args: []string{"cat"},
+				},
+			},
+			Timeout: 100 * time.Millisecond,
+		},
+	)
+	require.NoError(s.T(), err)
+	time.Sleep(300 * time.Millisecond)
+	require.Equal(s.T(), 3, exitCode(wait()))
+}
+
 func (s *Suite) TestListFileInfosShallow() {
 	err := os.MkdirAll(filepath.Join(s.tempDir, "dirOne"), 0755)
 	require.NoError(s.T(), err)
@@ -173,3 +282,19 @@ func (s *Suite) checkClose(closer io.Closer) {
 	err := closer.Close()
 	require.NoError(s.T(), err)
 }
+
+func (s *Suite) waitForWatchEvent(events <-chan *WatchEvent, expected *WatchEvent) {
+	timer := time.NewTimer(5 * time.Second)
+	defer timer.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			require.True(s.T(), ok, "events closed waiting for %v", expected)
+			if *event == *expected {
+				return
+			}
+		case <-timer.C:
+			require.Fail(s.T(), "timed out waiting for event", "%v %s", expected.Op, expected.Path)
+		}
+	}
+}
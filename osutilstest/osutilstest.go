@@ -0,0 +1,161 @@
+// Package osutilstest has test helpers for trees written with osutils,
+// such as comparing generated output against a golden directory.
+package osutilstest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peter-edge/go-osutils"
+)
+
+// maxLineDiffs is how many differing lines are shown per file.
+const maxLineDiffs = 5
+
+type TreeEqualOptions struct {
+	// IgnoreMode skips comparing permission bits.
+	IgnoreMode bool
+	// Update replaces expectedDir with a copy of actualDir instead of
+	// comparing them, for regenerating golden dirs, typically set from a
+	// -update flag of the test binary.
+	Update bool
+}
+
+// RequireTreeEqual fails t with a readable report unless the trees at
+// expectedDir and actualDir are the same, listing missing and extra
+// paths, the first differing lines of text files, and mode differences.
+func RequireTreeEqual(t testing.TB, expectedDir string, actualDir string, options *TreeEqualOptions) {
+	t.Helper()
+	if options == nil {
+		options = &TreeEqualOptions{}
+	}
+	if options.Update {
+		if err := updateGolden(expectedDir, actualDir); err != nil {
+			t.Fatalf("osutilstest: update %s: %v", expectedDir, err)
+		}
+		return
+	}
+	dirDiff, err := osutils.DiffDirs(expectedDir, actualDir, &osutils.DiffDirsOptions{IgnoreMode: options.IgnoreMode})
+	if err != nil {
+		t.Fatalf("osutilstest: diff %s %s: %v", expectedDir, actualDir, err)
+	}
+	if dirDiff.Empty() {
+		return
+	}
+	t.Fatalf("osutilstest: %s does not match %s:\n%s", actualDir, expectedDir, formatDirDiff(expectedDir, actualDir, dirDiff))
+}
+
+// ***** PRIVATE *****
+
+func updateGolden(expectedDir string, actualDir string) error {
+	if err := osutils.RemoveAll(expectedDir); err != nil {
+		return err
+	}
+	return osutils.CopyDir(actualDir, expectedDir, nil)
+}
+
+func formatDirDiff(expectedDir string, actualDir string, dirDiff *osutils.DirDiff) string {
+	var buffer bytes.Buffer
+	for _, path := range dirDiff.OnlyInA {
+		fmt.Fprintf(&buffer, "missing: %s\n", path)
+	}
+	for _, path := range dirDiff.OnlyInB {
+		fmt.Fprintf(&buffer, "extra: %s\n", path)
+	}
+	for _, path := range dirDiff.TypeDiffers {
+		fmt.Fprintf(
+			&buffer,
+			"type: %s: expected %s, actual %s\n",
+			path,
+			describeType(filepath.Join(expectedDir, path)),
+			describeType(filepath.Join(actualDir, path)),
+		)
+	}
+	for _, path := range dirDiff.ContentDiffers {
+		fmt.Fprintf(&buffer, "content: %s\n", path)
+		buffer.WriteString(contentDiff(filepath.Join(expectedDir, path), filepath.Join(actualDir, path)))
+	}
+	for _, path := range dirDiff.ModeDiffers {
+		fmt.Fprintf(
+			&buffer,
+			"mode: %s: expected %s, actual %s\n",
+			path,
+			describeMode(filepath.Join(expectedDir, path)),
+			describeMode(filepath.Join(actualDir, path)),
+		)
+	}
+	return buffer.String()
+}
+
+func contentDiff(expectedPath string, actualPath string) string {
+	if target, err := os.Readlink(expectedPath); err == nil {
+		actualTarget, _ := os.Readlink(actualPath)
+		return fmt.Sprintf("\texpected link to %q, actual link to %q\n", target, actualTarget)
+	}
+	expected, err := ioutil.ReadFile(expectedPath)
+	if err != nil {
+		return fmt.Sprintf("\t%v\n", err)
+	}
+	actual, err := ioutil.ReadFile(actualPath)
+	if err != nil {
+		return fmt.Sprintf("\t%v\n", err)
+	}
+	if bytes.IndexByte(expected, 0) >= 0 || bytes.IndexByte(actual, 0) >= 0 {
+		return fmt.Sprintf("\tbinary files differ, expected %d bytes, actual %d bytes\n", len(expected), len(actual))
+	}
+	expectedLines := strings.Split(string(expected), "\n")
+	actualLines := strings.Split(string(actual), "\n")
+	var buffer bytes.Buffer
+	shown := 0
+	for i := 0; i < len(expectedLines) || i < len(actualLines); i++ {
+		expectedLine, actualLine := lineAt(expectedLines, i), lineAt(actualLines, i)
+		if expectedLine == actualLine {
+			continue
+		}
+		if shown == maxLineDiffs {
+			buffer.WriteString("\t...\n")
+			break
+		}
+		shown++
+		fmt.Fprintf(&buffer, "\tline %d:\n\t-%s\n\t+%s\n", i+1, expectedLine, actualLine)
+	}
+	return buffer.String()
+}
+
+// lineAt returns the quoted line, or <none> past the end.
+func lineAt(lines []string, i int) string {
+	if i >= len(lines) {
+		return "<none>"
+	}
+	return fmt.Sprintf("%q", lines[i])
+}
+
+func describeType(path string) string {
+	fileInfo, err := os.Lstat(path)
+	if err != nil {
+		return err.Error()
+	}
+	switch {
+	case fileInfo.IsDir():
+		return "dir"
+	case fileInfo.Mode()&os.ModeSymlink != 0:
+		return "symlink"
+	case fileInfo.Mode().IsRegular():
+		return "file"
+	default:
+		return fileInfo.Mode().Type().String()
+	}
+}
+
+func describeMode(path string) string {
+	fileInfo, err := os.Lstat(path)
+	if err != nil {
+		return err.Error()
+	}
+	return fileInfo.Mode().String()
+}
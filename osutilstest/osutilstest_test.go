@@ -0,0 +1,48 @@
+package osutilstest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTB struct {
+	testing.TB
+	message string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Fatalf(format string, args ...interface{}) {
+	f.message = fmt.Sprintf(format, args...)
+}
+
+func TestRequireTreeEqual(t *testing.T) {
+	expectedDir := filepath.Join(t.TempDir(), "expected")
+	actualDir := filepath.Join(t.TempDir(), "actual")
+	require.NoError(t, os.MkdirAll(filepath.Join(actualDir, "sub"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(actualDir, "sub", "out.txt"), []byte("a\nb\nc\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(actualDir, "gone"), nil, 0644))
+
+	RequireTreeEqual(t, expectedDir, actualDir, &TreeEqualOptions{Update: true})
+	RequireTreeEqual(t, expectedDir, actualDir, nil)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(actualDir, "sub", "out.txt"), []byte("a\nB\nc\n"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(actualDir, "gone")))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(actualDir, "new"), nil, 0644))
+	fakeTB := &fakeTB{TB: t}
+	RequireTreeEqual(fakeTB, expectedDir, actualDir, nil)
+	require.Equal(
+		t,
+		fmt.Sprintf(
+			"osutilstest: %s does not match %s:\nmissing: gone\nextra: new\ncontent: sub/out.txt\n\tline 2:\n\t-\"b\"\n\t+\"B\"\n",
+			actualDir,
+			expectedDir,
+		),
+		fakeTB.message,
+	)
+}
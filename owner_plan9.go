@@ -0,0 +1,24 @@
+package osutils
+
+import (
+	"os"
+	"os/user"
+	"syscall"
+)
+
+// isOwnedByCurrentUser compares user names, files on Plan 9 are owned by a
+// name rather than a uid.
+func isOwnedByCurrentUser(fileInfo os.FileInfo) bool {
+	dir, ok := fileInfo.Sys().(*syscall.Dir)
+	if !ok {
+		return false
+	}
+	current, err := user.Current()
+	return err == nil && dir.Uid == current.Username
+}
+
+// fileOwner is not supported, Plan 9 has user names rather than uids and
+// gids.
+func fileOwner(fileInfo os.FileInfo) (int, int, bool) {
+	return 0, 0, false
+}
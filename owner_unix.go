@@ -0,0 +1,21 @@
+//go:build !windows && !plan9
+
+package osutils
+
+import (
+	"os"
+	"syscall"
+)
+
+func isOwnedByCurrentUser(fileInfo os.FileInfo) bool {
+	stat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
+
+func fileOwner(fileInfo os.FileInfo) (int, int, bool) {
+	stat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
@@ -0,0 +1,128 @@
+package osutils
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+const (
+	PackageManagerApt   PackageManager = "apt"
+	PackageManagerDnf   PackageManager = "dnf"
+	PackageManagerApk   PackageManager = "apk"
+	PackageManagerBrew  PackageManager = "brew"
+	PackageManagerChoco PackageManager = "choco"
+)
+
+var (
+	ErrNoPackageManager      = errors.New("osutils: no package manager")
+	ErrUnknownPackageManager = errors.New("osutils: unknown package manager")
+	ErrInvalidPackageName    = errors.New("osutils: invalid package name")
+
+	// packageManagers is in detection order.
+	packageManagers = []*packageManagerSpec{
+		{
+			packageManager: PackageManagerApt,
+			command:        "apt-get",
+			install:        []string{"install", "-y", "-q"},
+			remove:         []string{"remove", "-y", "-q"},
+			env:            map[string]string{"DEBIAN_FRONTEND": "noninteractive"},
+		},
+		{
+			packageManager: PackageManagerDnf,
+			command:        "dnf",
+			install:        []string{"install", "-y", "-q"},
+			remove:         []string{"remove", "-y", "-q"},
+		},
+		{
+			packageManager: PackageManagerApk,
+			command:        "apk",
+			install:        []string{"add", "--no-cache", "-q"},
+			remove:         []string{"del", "-q"},
+		},
+		{
+			packageManager: PackageManagerBrew,
+			command:        "brew",
+			install:        []string{"install", "-q"},
+			remove:         []string{"uninstall", "-q"},
+			env:            map[string]string{"HOMEBREW_NO_AUTO_UPDATE": "1", "NONINTERACTIVE": "1"},
+		},
+		{
+			packageManager: PackageManagerChoco,
+			command:        "choco",
+			install:        []string{"install", "-y", "--no-progress"},
+			remove:         []string{"uninstall", "-y", "--no-progress"},
+		},
+	}
+)
+
+type PackageManager string
+
+// DetectPackageManager returns the first of apt, dnf, apk, brew and choco
+// found on the PATH, or ErrNoPackageManager.
+func DetectPackageManager() (PackageManager, error) {
+	for _, spec := range packageManagers {
+		if _, err := exec.LookPath(spec.command); err == nil {
+			return spec.packageManager, nil
+		}
+	}
+	return "", ErrNoPackageManager
+}
+
+// InstallPackages installs packages with packageManager, never prompting.
+// Running as a user allowed to install packages is left to the caller. The
+// Result is returned even if the command fails.
+func InstallPackages(ctx context.Context, packageManager PackageManager, packages ...string) (*Result, error) {
+	return runPackageManager(ctx, packageManager, true, packages)
+}
+
+// RemovePackages is like InstallPackages but removes packages.
+func RemovePackages(ctx context.Context, packageManager PackageManager, packages ...string) (*Result, error) {
+	return runPackageManager(ctx, packageManager, false, packages)
+}
+
+// ***** PRIVATE *****
+
+type packageManagerSpec struct {
+	packageManager PackageManager
+	command        string
+	install        []string
+	remove         []string
+	env            map[string]string
+}
+
+func runPackageManager(ctx context.Context, packageManager PackageManager, install bool, packages []string) (*Result, error) {
+	command, err := packageManagerCommand(packageManager, install, packages)
+	if err != nil {
+		return nil, err
+	}
+	return command.Run(ctx)
+}
+
+func packageManagerCommand(packageManager PackageManager, install bool, packages []string) (*Command, error) {
+	if len(packages) == 0 {
+		return nil, ErrEmpty
+	}
+	for _, pkg := range packages {
+		// a leading - would be taken as an option
+		if pkg == "" || strings.HasPrefix(pkg, "-") || strings.ContainsAny(pkg, " \t\r\n") {
+			return nil, ErrInvalidPackageName
+		}
+	}
+	for _, spec := range packageManagers {
+		if spec.packageManager != packageManager {
+			continue
+		}
+		args := append([]string{spec.command}, spec.remove...)
+		if install {
+			args = append([]string{spec.command}, spec.install...)
+		}
+		command := NewCommand(append(args, packages...)...)
+		for key, value := range spec.env {
+			command.Env(key, value)
+		}
+		return command, nil
+	}
+	return nil, ErrUnknownPackageManager
+}
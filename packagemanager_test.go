@@ -0,0 +1,24 @@
+package osutils
+
+import (
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestPackageManagerCommand() {
+	command, err := packageManagerCommand(PackageManagerApt, true, []string{"curl", "git"})
+	require.NoError(s.T(), err)
+	cmd := command.Cmd()
+	require.Equal(s.T(), []string{"apt-get", "install", "-y", "-q", "curl", "git"}, cmd.Args)
+	require.Contains(s.T(), cmd.Env, "DEBIAN_FRONTEND=noninteractive")
+
+	command, err = packageManagerCommand(PackageManagerChoco, false, []string{"git"})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{"choco", "uninstall", "-y", "--no-progress", "git"}, command.Cmd().Args)
+
+	_, err = packageManagerCommand(PackageManagerApk, true, []string{"--allow-untrusted"})
+	require.Equal(s.T(), ErrInvalidPackageName, err)
+	_, err = packageManagerCommand(PackageManagerApk, true, nil)
+	require.Equal(s.T(), ErrEmpty, err)
+	_, err = packageManagerCommand(PackageManager("pacman"), true, []string{"git"})
+	require.Equal(s.T(), ErrUnknownPackageManager, err)
+}
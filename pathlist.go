@@ -0,0 +1,98 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	pathEnvKey = "PATH"
+)
+
+func PathList(pathValue string) []string {
+	return pathList(pathValue)
+}
+
+func JoinPathList(entries []string) string {
+	return joinPathList(entries)
+}
+
+func PrependToPath(pathValue string, entries ...string) string {
+	return prependToPath(pathValue, entries)
+}
+
+func AppendToPath(pathValue string, entries ...string) string {
+	return appendToPath(pathValue, entries)
+}
+
+func ContainsPathEntry(pathValue string, entry string) bool {
+	return containsPathEntry(pathValue, entry)
+}
+
+// PathEnv returns a PATH=value entry suitable for Cmd.Env.
+func PathEnv(pathValue string) string {
+	return pathEnvKey + "=" + pathValue
+}
+
+// ***** PRIVATE *****
+
+func pathList(pathValue string) []string {
+	var entries []string
+	for _, entry := range filepath.SplitList(pathValue) {
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func joinPathList(entries []string) string {
+	return strings.Join(entries, string(os.PathListSeparator))
+}
+
+func prependToPath(pathValue string, entries []string) string {
+	return joinPathList(dedupPathEntries(append(append([]string{}, entries...), pathList(pathValue)...)))
+}
+
+func appendToPath(pathValue string, entries []string) string {
+	return joinPathList(dedupPathEntries(append(pathList(pathValue), entries...)))
+}
+
+func containsPathEntry(pathValue string, entry string) bool {
+	key := pathEntryKey(entry)
+	for _, existing := range pathList(pathValue) {
+		if pathEntryKey(existing) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupPathEntries keeps the first occurrence of each entry, which is the
+// one that wins during lookup.
+func dedupPathEntries(entries []string) []string {
+	deduped := make([]string, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if entry == "" {
+			continue
+		}
+		key := pathEntryKey(entry)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, entry)
+	}
+	return deduped
+}
+
+func pathEntryKey(entry string) string {
+	key := filepath.Clean(entry)
+	if runtime.GOOS == "windows" {
+		key = strings.ToLower(key)
+	}
+	return key
+}
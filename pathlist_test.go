@@ -0,0 +1,16 @@
+package osutils
+
+import (
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestPathList() {
+	pathValue := JoinPathList([]string{"/usr/bin", "/bin"})
+	pathValue = PrependToPath(pathValue, "/opt/bin", "/bin/")
+	require.Equal(s.T(), []string{"/opt/bin", "/bin/", "/usr/bin"}, PathList(pathValue))
+	pathValue = AppendToPath(pathValue, "/usr/bin", "/sbin")
+	require.Equal(s.T(), []string{"/opt/bin", "/bin/", "/usr/bin", "/sbin"}, PathList(pathValue))
+	require.True(s.T(), ContainsPathEntry(pathValue, "/sbin/"))
+	require.False(s.T(), ContainsPathEntry(pathValue, "/usr/sbin"))
+	require.Equal(s.T(), "PATH="+pathValue, PathEnv(pathValue))
+}
@@ -0,0 +1,56 @@
+package osutils
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// QuotePath returns path unchanged if it is valid UTF-8 made of printable
+// characters, and otherwise a double-quoted Go string literal, with invalid
+// UTF-8 as \x escapes, so it can go in an error message or a line-based
+// manifest without being corrupted. UnquotePath reverses it.
+func QuotePath(path string) string {
+	if !needsQuoting(path) {
+		return path
+	}
+	return strconv.Quote(path)
+}
+
+// UnquotePath returns the path that QuotePath was given.
+func UnquotePath(quoted string) (string, error) {
+	if !strings.HasPrefix(quoted, `"`) {
+		return quoted, nil
+	}
+	return strconv.Unquote(quoted)
+}
+
+// SafeArgPath returns path so that a command cannot take it for an option,
+// prefixing a relative path starting with a dash with ./ .
+func SafeArgPath(path string) string {
+	if strings.HasPrefix(path, "-") {
+		return "." + string(filepath.Separator) + path
+	}
+	return path
+}
+
+// ***** PRIVATE *****
+
+// needsQuoting also quotes a leading double quote, so that UnquotePath is
+// not ambiguous, and leading or trailing spaces, which are easily lost.
+func needsQuoting(path string) bool {
+	if path == "" {
+		return false
+	}
+	if strings.HasPrefix(path, `"`) || strings.TrimSpace(path) != path || !utf8.ValidString(path) {
+		return true
+	}
+	for _, r := range path {
+		if !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
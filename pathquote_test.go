@@ -0,0 +1,75 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestQuotePath() {
+	for _, path := range []string{
+		"/plain/path",
+		"/with space/и юникод",
+		"/new\nline",
+		"/carriage\rreturn",
+		"/invalid\xff\xfeutf8",
+		`"leading quote`,
+		" leading space",
+		"",
+	} {
+		quoted := QuotePath(path)
+		require.NotContains(s.T(), quoted, "\n")
+		unquoted, err := UnquotePath(quoted)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), path, unquoted)
+	}
+	require.Equal(s.T(), "/plain/path", QuotePath("/plain/path"))
+	require.Equal(s.T(), `"/invalid\xff"`, QuotePath("/invalid\xff"))
+	require.Equal(s.T(), "."+string(filepath.Separator)+"-rf", SafeArgPath("-rf"))
+	require.Equal(s.T(), "a-b", SafeArgPath("a-b"))
+
+	err := MkdirAllMany([]string{"relative\nname"}, 0755)
+	require.Error(s.T(), err)
+	require.NotContains(s.T(), err.Error(), "\n")
+}
+
+func (s *Suite) TestSpecialCharacterPaths() {
+	// macOS and Windows reject invalid UTF-8 in file names
+	if runtime.GOOS != "linux" {
+		s.T().Skip("invalid UTF-8 file names need linux")
+	}
+	src := filepath.Join(s.tempDir, "src")
+	require.NoError(s.T(), os.Mkdir(src, 0755))
+	names := []string{"invalid\xff\xfe", "new\nline", "-leading-dash", `back\slash`, "carriage\r", " space "}
+	for _, name := range names {
+		require.NoError(s.T(), ioutil.WriteFile(filepath.Join(src, name), []byte(name), 0644))
+	}
+	paths, err := ListRegularFiles(src)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), paths, len(names))
+	for _, name := range names {
+		require.Contains(s.T(), paths, filepath.Join(src, name))
+	}
+
+	dst := filepath.Join(s.tempDir, "dst")
+	require.NoError(s.T(), CopyDir(src, dst, nil))
+	dirDiff, err := DiffDirs(src, dst, nil)
+	require.NoError(s.T(), err)
+	require.True(s.T(), dirDiff.Empty())
+
+	_, err = WriteChecksumFile(dst, HashAlgorithmSHA256)
+	require.NoError(s.T(), err)
+	manifestPath := filepath.Join(dst, "SHA256SUMS")
+	failed, err := VerifyChecksumFile(manifestPath)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), failed)
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(dst, "carriage\r"), []byte("changed"), 0644))
+	failed, err = VerifyChecksumFile(manifestPath)
+	require.Equal(s.T(), ErrChecksumMismatch, err)
+	require.Len(s.T(), failed, 1)
+	require.True(s.T(), strings.HasSuffix(failed[0], "carriage\r"), QuotePath(failed[0]))
+}
@@ -0,0 +1,80 @@
+package osutils
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// PipedResult is the outcome of one pipeline run by ExecutePipedMany.
+type PipedResult struct {
+	PipeCmdList *PipeCmdList
+	Err         error
+	Start       time.Time
+	Duration    time.Duration
+}
+
+// ExecutePipedMany runs independent pipelines concurrently, at most
+// maxConcurrency at a time, or runtime.NumCPU() if maxConcurrency is not
+// positive, and waits for all of them. The results are in the order of
+// pipeCmdLists. Every pipeline is validated before any is started. The
+// returned error joins the errors of the pipelines that failed, with
+// errors.Join, and the results are returned either way.
+func ExecutePipedMany(pipeCmdLists []*PipeCmdList, maxConcurrency int) ([]*PipedResult, error) {
+	return executePipedMany(context.Background(), pipeCmdLists, maxConcurrency)
+}
+
+// ***** PRIVATE *****
+
+func executePipedMany(ctx context.Context, pipeCmdLists []*PipeCmdList, maxConcurrency int) ([]*PipedResult, error) {
+	if pipeCmdLists == nil {
+		return nil, ErrNil
+	}
+	for _, pipeCmdList := range pipeCmdLists {
+		if pipeCmdList == nil {
+			return nil, ErrNil
+		}
+		if err := validatePipeCmdList(pipeCmdList); err != nil {
+			return nil, err
+		}
+	}
+	if maxConcurrency <= 0 {
+		maxConcurrency = runtime.NumCPU()
+	}
+	pipedResults := make([]*PipedResult, len(pipeCmdLists))
+	semaphore := make(chan struct{}, maxConcurrency)
+	var waitGroup sync.WaitGroup
+	for i, pipeCmdList := range pipeCmdLists {
+		pipedResult := &PipedResult{
+			PipeCmdList: pipeCmdList,
+		}
+		pipedResults[i] = pipedResult
+		semaphore <- struct{}{}
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			defer func() { <-semaphore }()
+			pipedResult.Start = time.Now()
+			pipedResult.Err = runPiped(ctx, pipedResult.PipeCmdList)
+			pipedResult.Duration = time.Since(pipedResult.Start)
+		}()
+	}
+	waitGroup.Wait()
+	var errs []error
+	for _, pipedResult := range pipedResults {
+		if pipedResult.Err != nil {
+			errs = append(errs, pipedResult.Err)
+		}
+	}
+	return pipedResults, errors.Join(errs...)
+}
+
+func runPiped(ctx context.Context, pipeCmdList *PipeCmdList) error {
+	wait, err := executePiped(ctx, pipeCmdList)
+	if err != nil {
+		return err
+	}
+	return wait()
+}
@@ -0,0 +1,55 @@
+package osutils
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestExecutePipedMany() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sh not available on windows")
+	}
+	outputs := make([]*bytes.Buffer, 4)
+	pipeCmdLists := make([]*PipeCmdList, 4)
+	for i := range pipeCmdLists {
+		outputs[i] = &bytes.Buffer{}
+		pipeCmdLists[i] = &PipeCmdList{
+			PipeCmds: []*PipeCmd{
+				{Args: []string{"sh", "-c", "sleep 0.2; echo " + strings.Repeat("x", i+1)}},
+				{Args: []string{"wc", "-c"}},
+			},
+			Stdout: outputs[i],
+		}
+	}
+	start := time.Now()
+	pipedResults, err := ExecutePipedMany(pipeCmdLists, 4)
+	require.NoError(s.T(), err)
+	require.True(s.T(), time.Since(start) < 700*time.Millisecond)
+	require.Len(s.T(), pipedResults, 4)
+	for i, pipedResult := range pipedResults {
+		require.NoError(s.T(), pipedResult.Err)
+		require.Equal(s.T(), pipeCmdLists[i], pipedResult.PipeCmdList)
+		require.Equal(s.T(), strconv.Itoa(i+2), strings.TrimSpace(outputs[i].String()))
+	}
+
+	pipedResults, err = ExecutePipedMany(
+		[]*PipeCmdList{
+			{PipeCmds: []*PipeCmd{{Args: []string{"true"}}, {Args: []string{"true"}}}},
+			{PipeCmds: []*PipeCmd{{Args: []string{"true"}}, {Args: []string{"false"}}}},
+		},
+		1,
+	)
+	require.Error(s.T(), err)
+	require.NoError(s.T(), pipedResults[0].Err)
+	require.Error(s.T(), pipedResults[1].Err)
+
+	_, err = ExecutePipedMany([]*PipeCmdList{{PipeCmds: []*PipeCmd{{Args: []string{"true"}}}}}, 1)
+	require.Equal(s.T(), ErrNotMultipleCommands, err)
+	_, err = ExecutePipedMany(nil, 1)
+	require.Equal(s.T(), ErrNil, err)
+}
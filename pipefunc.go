@@ -0,0 +1,102 @@
+package osutils
+
+import (
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// PipeFunc is a pipeline stage run in a goroutine rather than a process. It
+// reads the output of the previous stage, or the Stdin of the PipeCmdList,
+// from reader and writes its output to writer. The stage is done when it
+// returns, the next stage seeing EOF, or the error if it failed.
+type PipeFunc func(reader io.Reader, writer io.Writer) error
+
+// ***** PRIVATE *****
+
+// pipeStage is a stage of a pipeline, either a process or a PipeFunc.
+type pipeStage struct {
+	execCmd  *exec.Cmd
+	pipeFunc PipeFunc
+	stdin    io.Reader
+	stdout   io.Writer
+	errC     chan error
+	// done is set once the PipeFunc returned
+	done atomic.Bool
+	// timeoutKilled is set if the timeout stopped the stage while running
+	timeoutKilled atomic.Bool
+}
+
+func (p *pipeStage) setStdin(stdin io.Reader) {
+	if p.execCmd != nil {
+		p.execCmd.Stdin = stdin
+		return
+	}
+	p.stdin = stdin
+}
+
+func (p *pipeStage) setStdout(stdout io.Writer) {
+	if p.execCmd != nil {
+		p.execCmd.Stdout = stdout
+		return
+	}
+	p.stdout = stdout
+}
+
+// setStderr is a no-op for a PipeFunc, which reports through its error.
+func (p *pipeStage) setStderr(stderr io.Writer) {
+	if p.execCmd != nil {
+		p.execCmd.Stderr = stderr
+	}
+}
+
+func (p *pipeStage) start() error {
+	if p.execCmd != nil {
+		return p.execCmd.Start()
+	}
+	stdin := p.stdin
+	if stdin == nil {
+		stdin = strings.NewReader("")
+	}
+	stdout := p.stdout
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	p.errC = make(chan error, 1)
+	go func() {
+		err := p.pipeFunc(stdin, stdout)
+		// the next stage sees EOF or the error, and the previous one fails
+		// to write rather than blocking if the stage did not read it all
+		if writer, ok := stdout.(*io.PipeWriter); ok {
+			_ = writer.CloseWithError(err)
+		}
+		if reader, ok := stdin.(*io.PipeReader); ok {
+			_ = reader.Close()
+		}
+		p.done.Store(true)
+		p.errC <- err
+	}()
+	return nil
+}
+
+func (p *pipeStage) wait(start time.Time) error {
+	if p.execCmd != nil {
+		err := waitExecCmd(p.execCmd, start)
+		if p.timeoutKilled.Load() {
+			_, err = checkTimeoutKill(p.execCmd, &p.timeoutKilled, err)
+		}
+		return err
+	}
+	return <-p.errC
+}
+
+func (p *pipeStage) killedByTimeout() bool {
+	if p.execCmd == nil {
+		return p.timeoutKilled.Load()
+	}
+	killed, _ := checkTimeoutKill(p.execCmd, &p.timeoutKilled, nil)
+	return killed
+}
@@ -0,0 +1,79 @@
+package osutils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestPipeFunc() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sh not available on windows")
+	}
+	upper := func(reader io.Reader, writer io.Writer) error {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			if _, err := fmt.Fprintln(writer, strings.ToUpper(scanner.Text())); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	}
+	output, err := NewPipeline().Cmd("sort").Func(upper).Cmd("uniq").StdinString("b\na\nb\n").Output(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "A\nB\n", output)
+
+	output, err = NewPipeline().Func(upper).Cmd("cat").StdinString("x\n").Output(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "X\n", output)
+	output, err = NewPipeline().Func(upper).StdinString("y\n").Output(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "Y\n", output)
+
+	// a stage that stops reading early does not block the stages before it
+	first := func(reader io.Reader, writer io.Writer) error {
+		line, err := bufio.NewReader(reader).ReadString('\n')
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(writer, line)
+		return err
+	}
+	output, err = NewPipeline().Func(
+		func(reader io.Reader, writer io.Writer) error {
+			for i := 0; i < 100000; i++ {
+				if _, err := fmt.Fprintln(writer, i); err != nil {
+					return nil
+				}
+			}
+			return nil
+		},
+	).Func(first).Output(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "0\n", output)
+
+	errFailed := errors.New("failed")
+	err = NewPipeline().Cmd("echo", "a").Func(
+		func(reader io.Reader, writer io.Writer) error {
+			_, _ = io.Copy(io.Discard, reader)
+			return errFailed
+		},
+	).Cmd("cat").Run(context.Background())
+	require.Equal(s.T(), errFailed, err)
+
+	_, err = ExecutePiped(
+		&PipeCmdList{
+			PipeCmds: []*PipeCmd{
+				{Args: []string{"true"}, Func: upper},
+				{Args: []string{"true"}},
+			},
+		},
+	)
+	require.Equal(s.T(), ErrInvalidPipeCmd, err)
+}
@@ -0,0 +1,169 @@
+package osutils
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Pipeline builds a PipeCmdList, for example:
+//
+//	output, err := NewPipeline().Cmd("sort").Cmd("uniq").Cmd("wc", "-l").StdinString(s).Output(ctx)
+//
+// Dir and Env apply to the most recently added command.
+type Pipeline struct {
+	pipeCmds []*PipeCmd
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+	err      error
+}
+
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+func (p *Pipeline) Cmd(args ...string) *Pipeline {
+	if len(args) == 0 {
+		p.setErr(ErrEmpty)
+		return p
+	}
+	p.pipeCmds = append(p.pipeCmds, &PipeCmd{Args: args})
+	return p
+}
+
+// Func adds an in-process stage, see PipeFunc.
+func (p *Pipeline) Func(pipeFunc PipeFunc) *Pipeline {
+	if pipeFunc == nil {
+		p.setErr(ErrNil)
+		return p
+	}
+	p.pipeCmds = append(p.pipeCmds, &PipeCmd{Func: pipeFunc})
+	return p
+}
+
+func (p *Pipeline) Dir(absoluteDir string) *Pipeline {
+	if pipeCmd := p.last(); pipeCmd != nil {
+		pipeCmd.AbsoluteDir = absoluteDir
+	}
+	return p
+}
+
+// Env sets KEY=value entries on top of the inherited environment, like
+// Command.Env.
+func (p *Pipeline) Env(env ...string) *Pipeline {
+	if pipeCmd := p.last(); pipeCmd != nil {
+		base := pipeCmd.Env
+		if base == nil {
+			base = os.Environ()
+		}
+		pipeCmd.Env = mergeEnv(base, envSliceToMap(env))
+	}
+	return p
+}
+
+func (p *Pipeline) Stdin(stdin io.Reader) *Pipeline {
+	p.stdin = stdin
+	return p
+}
+
+func (p *Pipeline) StdinString(stdin string) *Pipeline {
+	return p.Stdin(strings.NewReader(stdin))
+}
+
+func (p *Pipeline) Stdout(stdout io.Writer) *Pipeline {
+	p.stdout = stdout
+	return p
+}
+
+func (p *Pipeline) Stderr(stderr io.Writer) *Pipeline {
+	p.stderr = stderr
+	return p
+}
+
+func (p *Pipeline) PipeCmdList() (*PipeCmdList, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &PipeCmdList{
+		PipeCmds: p.pipeCmds,
+		Stdin:    p.stdin,
+		Stdout:   p.stdout,
+		Stderr:   p.stderr,
+	}, nil
+}
+
+// Run runs the pipeline until completion or until ctx is done. A pipeline
+// with a single command is run as a plain Cmd.
+func (p *Pipeline) Run(ctx context.Context) error {
+	wait, err := p.start(ctx)
+	if err != nil {
+		return err
+	}
+	return wait()
+}
+
+// Output runs the pipeline and returns its stdout, overriding any stdout
+// writer set on the pipeline.
+func (p *Pipeline) Output(ctx context.Context) (string, error) {
+	var output bytes.Buffer
+	p.stdout = &output
+	if err := p.Run(ctx); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
+
+// ***** PRIVATE *****
+
+func (p *Pipeline) start(ctx context.Context) (func() error, error) {
+	pipeCmdList, err := p.PipeCmdList()
+	if err != nil {
+		return nil, err
+	}
+	if len(pipeCmdList.PipeCmds) == 1 && pipeCmdList.PipeCmds[0].Func != nil {
+		pipeStage := &pipeStage{
+			pipeFunc: pipeCmdList.PipeCmds[0].Func,
+			stdin:    pipeCmdList.Stdin,
+			stdout:   pipeCmdList.Stdout,
+		}
+		if err := pipeStage.start(); err != nil {
+			return nil, err
+		}
+		return func() error {
+			return pipeStage.wait(time.Time{})
+		}, nil
+	}
+	if len(pipeCmdList.PipeCmds) == 1 {
+		pipeCmd := pipeCmdList.PipeCmds[0]
+		return execute(
+			ctx,
+			&Cmd{
+				Args:        pipeCmd.Args,
+				AbsoluteDir: pipeCmd.AbsoluteDir,
+				Env:         pipeCmd.Env,
+				Stdin:       pipeCmdList.Stdin,
+				Stdout:      pipeCmdList.Stdout,
+				Stderr:      pipeCmdList.Stderr,
+			},
+		)
+	}
+	return executePiped(ctx, pipeCmdList)
+}
+
+func (p *Pipeline) last() *PipeCmd {
+	if len(p.pipeCmds) == 0 {
+		p.setErr(ErrEmpty)
+		return nil
+	}
+	return p.pipeCmds[len(p.pipeCmds)-1]
+}
+
+func (p *Pipeline) setErr(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
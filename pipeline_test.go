@@ -0,0 +1,37 @@
+package osutils
+
+import (
+	"context"
+	"runtime"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestPipeline() {
+	output, err := NewPipeline().
+		Cmd("sort").Dir(s.tempDir).
+		Cmd("uniq").
+		Cmd("wc", "-l").
+		StdinString("hello\nfoo\nhello\nwoot\n").
+		Output(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "3", strings.TrimSpace(output))
+	output, err = NewPipeline().Cmd("echo", "single").Output(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "single\n", output)
+	require.Equal(s.T(), ErrEmpty, NewPipeline().Dir(s.tempDir).Cmd("true").Run(context.Background()))
+}
+
+func (s *Suite) TestPipelineEnv() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sh not available on windows")
+	}
+	s.T().Setenv("OSUTILS_TEST_INHERITED", "inherited")
+	output, err := NewPipeline().
+		Cmd("sh", "-c", "echo $OSUTILS_TEST_INHERITED $OSUTILS_TEST_SET").Env("OSUTILS_TEST_SET=first").Env("OSUTILS_TEST_SET=set").
+		Cmd("cat").
+		Output(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "inherited set\n", output)
+}
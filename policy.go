@@ -0,0 +1,181 @@
+package osutils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	ErrWorldWritable = errors.New("osutils: world-writable permissions forbidden by policy")
+
+	globalPolicy *Policy
+	policyLock   = &sync.RWMutex{}
+)
+
+// Policy is a permissions baseline for the files and directories this
+// package creates. It applies to Create, OpenFile with os.O_CREATE, Mkdir,
+// MkdirAll, atomic writes and copies. When a policy is in effect, a perm
+// of 0 means FilePerm or DirPerm.
+type Policy struct {
+	// FilePerm defaults to 0666.
+	FilePerm os.FileMode
+	// DirPerm defaults to 0777.
+	DirPerm os.FileMode
+	// ForceUmask clears the Umask bits from every perm and then sets the
+	// result exactly, regardless of the process umask.
+	ForceUmask bool
+	Umask      os.FileMode
+	// ForbidWorldWritable fails with ErrWorldWritable instead of creating
+	// anything writable by others.
+	ForbidWorldWritable bool
+}
+
+// SetPolicy installs a package-wide Policy. Pass nil to remove it. A Dir
+// can override it with WithPolicy.
+func SetPolicy(policy *Policy) {
+	policyLock.Lock()
+	defer policyLock.Unlock()
+	globalPolicy = policy
+}
+
+func CurrentPolicy() *Policy {
+	return currentPolicy()
+}
+
+// ***** PRIVATE *****
+
+func currentPolicy() *Policy {
+	policyLock.RLock()
+	defer policyLock.RUnlock()
+	return globalPolicy
+}
+
+// filePerm resolves perm for a new file, a nil policy leaves it alone.
+func (p *Policy) filePerm(perm os.FileMode) (os.FileMode, error) {
+	if p == nil {
+		return perm, nil
+	}
+	if perm == 0 {
+		perm = p.FilePerm
+		if perm == 0 {
+			perm = 0666
+		}
+	}
+	return p.check(perm)
+}
+
+func (p *Policy) dirPerm(perm os.FileMode) (os.FileMode, error) {
+	if p == nil {
+		return perm, nil
+	}
+	if perm == 0 {
+		perm = p.DirPerm
+		if perm == 0 {
+			perm = 0777
+		}
+	}
+	return p.check(perm)
+}
+
+func (p *Policy) check(perm os.FileMode) (os.FileMode, error) {
+	if p.ForceUmask {
+		perm &^= p.Umask.Perm()
+	}
+	if p.ForbidWorldWritable {
+		// what matters is the mode after the umask
+		effective := perm
+		if !p.ForceUmask {
+			if mask, err := GetUmask(); err == nil {
+				effective &^= mask
+			}
+		}
+		if effective&0002 != 0 {
+			return 0, ErrWorldWritable
+		}
+	}
+	return perm, nil
+}
+
+// enforce sets perm exactly on a path just created, if the policy forces
+// the umask.
+func (p *Policy) enforce(absolutePath string, perm os.FileMode) error {
+	if p == nil || !p.ForceUmask {
+		return nil
+	}
+	return os.Chmod(absolutePath, perm)
+}
+
+func createWithPolicy(absolutePath string, policy *Policy) (*os.File, error) {
+	if policy == nil {
+		return os.Create(absolutePath)
+	}
+	return openFileWithPolicy(absolutePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0, policy)
+}
+
+func openFileWithPolicy(absolutePath string, flag int, perm os.FileMode, policy *Policy) (*os.File, error) {
+	if policy == nil || flag&os.O_CREATE == 0 {
+		return os.OpenFile(absolutePath, flag, perm)
+	}
+	perm, err := policy.filePerm(perm)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.ForceUmask {
+		return os.OpenFile(absolutePath, flag, perm)
+	}
+	// only a file created here gets the mode, an existing file keeps its own
+	file, err := os.OpenFile(absolutePath, flag|os.O_EXCL, perm)
+	if os.IsExist(err) && flag&os.O_EXCL == 0 {
+		return os.OpenFile(absolutePath, flag, perm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.enforce(absolutePath, perm); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+func mkdirWithPolicy(absolutePath string, perm os.FileMode, policy *Policy) error {
+	perm, err := policy.dirPerm(perm)
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(absolutePath, perm); err != nil {
+		return err
+	}
+	return policy.enforce(absolutePath, perm)
+}
+
+func mkdirAllWithPolicy(absolutePath string, perm os.FileMode, policy *Policy) error {
+	perm, err := policy.dirPerm(perm)
+	if err != nil {
+		return err
+	}
+	// the dirs that do not exist yet, deepest first
+	var missing []string
+	for path := filepath.Clean(absolutePath); ; path = filepath.Dir(path) {
+		if _, err := os.Lstat(path); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		missing = append(missing, path)
+		if filepath.Dir(path) == path {
+			break
+		}
+	}
+	if err := os.MkdirAll(absolutePath, perm); err != nil {
+		return err
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := policy.enforce(missing[i], perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
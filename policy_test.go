@@ -0,0 +1,62 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestPolicy() {
+	SetPolicy(&Policy{ForceUmask: true, Umask: 0027, ForbidWorldWritable: true})
+	defer SetPolicy(nil)
+	s.checkPerm(WithUmask(0, func() error {
+		file, err := Create(filepath.Join(s.tempDir, "file"))
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	}), filepath.Join(s.tempDir, "file"), 0640)
+	s.checkPerm(Mkdir(filepath.Join(s.tempDir, "dir"), 0), filepath.Join(s.tempDir, "dir"), 0750)
+	s.checkPerm(WriteFileAtomic(filepath.Join(s.tempDir, "atomic"), nil, 0666), filepath.Join(s.tempDir, "atomic"), 0640)
+
+	// an existing file keeps its mode
+	require.NoError(s.T(), os.Chmod(filepath.Join(s.tempDir, "file"), 0600))
+	s.checkPerm(WithUmask(0, func() error {
+		file, err := Create(filepath.Join(s.tempDir, "file"))
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	}), filepath.Join(s.tempDir, "file"), 0600)
+	// every dir created gets the mode, not just the last
+	s.checkPerm(WithUmask(0, func() error {
+		return MkdirAll(filepath.Join(s.tempDir, "x", "y"), 0777)
+	}), filepath.Join(s.tempDir, "x"), 0750)
+	s.checkPerm(nil, filepath.Join(s.tempDir, "x", "y"), 0750)
+
+	// the world-writable check applies to the mode after the umask
+	SetPolicy(&Policy{ForbidWorldWritable: true})
+	require.NoError(s.T(), WithUmask(0022, func() error {
+		return WriteFileAtomic(filepath.Join(s.tempDir, "atomic"), nil, 0666)
+	}))
+	require.Equal(s.T(), ErrWorldWritable, WithUmask(0, func() error {
+		return MkdirAll(filepath.Join(s.tempDir, "a", "b"), 0777)
+	}))
+	require.Equal(s.T(), ErrWorldWritable, WithUmask(0, func() error {
+		return WriteFileAtomic(filepath.Join(s.tempDir, "atomic"), nil, 0666)
+	}))
+
+	// a Dir policy overrides the package-wide one
+	dir, err := OpenDir(s.tempDir)
+	require.NoError(s.T(), err)
+	dir = dir.WithPolicy(&Policy{DirPerm: 0700, ForceUmask: true})
+	s.checkPerm(dir.Mkdir("private", 0), filepath.Join(s.tempDir, "private"), 0700)
+}
+
+func (s *Suite) checkPerm(err error, path string, perm os.FileMode) {
+	require.NoError(s.T(), err)
+	info, err := os.Stat(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), perm, info.Mode().Perm())
+}
@@ -0,0 +1,225 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const defaultPollInterval = 2 * time.Second
+
+type PollWatcherOptions struct {
+	// Interval defaults to two seconds.
+	Interval time.Duration
+	// Hash compares file contents rather than size and modification time,
+	// for filesystems with coarse or unreliable mtimes. Every file is read
+	// on every poll.
+	Hash bool
+}
+
+// PollWatcher watches a directory tree by scanning it periodically, for
+// network filesystems where the native Watcher gets no events. Renames are
+// detected by inode where FileID is supported.
+type PollWatcher struct {
+	*watchChannels
+	root     string
+	options  *PollWatcherOptions
+	previous map[string]*pollEntry
+}
+
+func NewPollWatcher(absolutePath string, options *PollWatcherOptions) (*PollWatcher, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &PollWatcherOptions{}
+	}
+	if options.Interval <= 0 {
+		options = &PollWatcherOptions{Interval: defaultPollInterval, Hash: options.Hash}
+	}
+	w := &PollWatcher{
+		watchChannels: newWatchChannels(),
+		root:          filepath.Clean(absolutePath),
+		options:       options,
+	}
+	previous, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+	w.previous = previous
+	w.run(w.loop)
+	return w, nil
+}
+
+// Close stops the watcher and closes the Events and Errors channels.
+func (w *PollWatcher) Close() error {
+	return w.watchChannels.stop(nil)
+}
+
+// ***** PRIVATE *****
+
+type pollEntry struct {
+	mode    os.FileMode
+	size    int64
+	modTime time.Time
+	hash    string
+	id      *FileIdentity
+}
+
+func (w *PollWatcher) loop() {
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		current, err := w.scan()
+		if err != nil {
+			if !w.sendError(err) {
+				return
+			}
+			continue
+		}
+		for _, event := range diffPollEntries(w.previous, current) {
+			if !w.send(event) {
+				return
+			}
+		}
+		w.previous = current
+	}
+}
+
+func (w *PollWatcher) scan() (map[string]*pollEntry, error) {
+	entries := make(map[string]*pollEntry)
+	err := filepath.Walk(
+		w.root,
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				// removed during the scan
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			entry := &pollEntry{
+				mode:    info.Mode(),
+				size:    info.Size(),
+				modTime: info.ModTime(),
+			}
+			if w.options.Hash && info.Mode().IsRegular() {
+				hash, err := hashFile(path)
+				if err != nil {
+					if os.IsNotExist(err) {
+						return nil
+					}
+					return err
+				}
+				entry.hash = hash
+			}
+			if info.Mode()&os.ModeSymlink == 0 {
+				if id, err := fileID(path); err == nil {
+					entry.id = id
+				}
+			}
+			entries[path] = entry
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func diffPollEntries(previous map[string]*pollEntry, current map[string]*pollEntry) []*WatchEvent {
+	var added, removed, kept []string
+	for path := range current {
+		if _, ok := previous[path]; ok {
+			kept = append(kept, path)
+		} else {
+			added = append(added, path)
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(kept)
+
+	var events []*WatchEvent
+	renamedFrom := make(map[string]bool)
+	renamedTo := make(map[string]bool)
+	for _, oldPath := range removed {
+		if renamedFrom[oldPath] {
+			continue
+		}
+		newPath := findRenamed(previous[oldPath], current, added, renamedTo)
+		if newPath == "" {
+			continue
+		}
+		events = append(events, &WatchEvent{Op: WatchOpRename, Path: newPath, OldPath: oldPath})
+		renamedFrom[oldPath] = true
+		renamedTo[newPath] = true
+		// the contents of a renamed directory moved with it
+		if previous[oldPath].mode.IsDir() {
+			for _, path := range removed {
+				if path != oldPath && isWithin(oldPath, path) {
+					movedPath := newPath + path[len(oldPath):]
+					if _, ok := current[movedPath]; ok {
+						renamedFrom[path] = true
+						renamedTo[movedPath] = true
+					}
+				}
+			}
+		}
+	}
+	for _, path := range added {
+		if !renamedTo[path] {
+			events = append(events, &WatchEvent{Op: WatchOpCreate, Path: path})
+		}
+	}
+	for _, path := range kept {
+		previousEntry, currentEntry := previous[path], current[path]
+		if previousEntry.mode != currentEntry.mode {
+			events = append(events, &WatchEvent{Op: WatchOpChmod, Path: path})
+		}
+		// a directory's mtime changes with its entries, which are reported
+		// themselves
+		if !currentEntry.mode.IsDir() &&
+			(previousEntry.size != currentEntry.size ||
+				!previousEntry.modTime.Equal(currentEntry.modTime) ||
+				previousEntry.hash != currentEntry.hash) {
+			events = append(events, &WatchEvent{Op: WatchOpWrite, Path: path})
+		}
+	}
+	for _, path := range removed {
+		if !renamedFrom[path] {
+			events = append(events, &WatchEvent{Op: WatchOpRemove, Path: path})
+		}
+	}
+	return events
+}
+
+func findRenamed(entry *pollEntry, current map[string]*pollEntry, added []string, renamedTo map[string]bool) string {
+	if entry.id == nil {
+		return ""
+	}
+	for _, path := range added {
+		candidate := current[path]
+		if renamedTo[path] || candidate.id == nil {
+			continue
+		}
+		if candidate.id.Device == entry.id.Device &&
+			candidate.id.Inode == entry.id.Inode &&
+			candidate.mode.IsDir() == entry.mode.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
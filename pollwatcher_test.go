@@ -0,0 +1,66 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestPollWatcher() {
+	sub := filepath.Join(s.tempDir, "sub")
+	require.NoError(s.T(), os.Mkdir(sub, 0755))
+	file := filepath.Join(sub, "a")
+	require.NoError(s.T(), ioutil.WriteFile(file, []byte("hello"), 0644))
+
+	var watcher FileWatcher
+	watcher, err := NewPollWatcher(s.tempDir, &PollWatcherOptions{Interval: 10 * time.Millisecond, Hash: true})
+	require.NoError(s.T(), err)
+	defer func() {
+		require.NoError(s.T(), watcher.Close())
+	}()
+
+	// same size, so only the hash sees it
+	require.NoError(s.T(), ioutil.WriteFile(file, []byte("world"), 0644))
+	s.waitForWatchEvent(watcher.Events(), &WatchEvent{Op: WatchOpWrite, Path: file})
+
+	renamed := filepath.Join(s.tempDir, "renamed")
+	require.NoError(s.T(), os.Rename(sub, renamed))
+	s.waitForWatchEvent(watcher.Events(), &WatchEvent{Op: WatchOpRename, Path: renamed, OldPath: sub})
+
+	file = filepath.Join(renamed, "b")
+	require.NoError(s.T(), ioutil.WriteFile(file, nil, 0644))
+	s.waitForWatchEvent(watcher.Events(), &WatchEvent{Op: WatchOpCreate, Path: file})
+	require.NoError(s.T(), os.Remove(file))
+	s.waitForWatchEvent(watcher.Events(), &WatchEvent{Op: WatchOpRemove, Path: file})
+}
+
+func (s *Suite) TestDiffPollEntries() {
+	previous := map[string]*pollEntry{
+		"/a":     {mode: os.ModeDir | 0755, id: &FileIdentity{Inode: 1}},
+		"/a/b":   {mode: 0644, id: &FileIdentity{Inode: 2}},
+		"/c":     {mode: 0644, size: 1, id: &FileIdentity{Inode: 3}},
+		"/gone":  {mode: 0644, id: &FileIdentity{Inode: 4}},
+		"/perms": {mode: 0644, id: &FileIdentity{Inode: 5}},
+	}
+	current := map[string]*pollEntry{
+		"/d":     {mode: os.ModeDir | 0755, id: &FileIdentity{Inode: 1}},
+		"/d/b":   {mode: 0644, id: &FileIdentity{Inode: 2}},
+		"/c":     {mode: 0644, size: 2, id: &FileIdentity{Inode: 3}},
+		"/new":   {mode: 0644, id: &FileIdentity{Inode: 6}},
+		"/perms": {mode: 0600, id: &FileIdentity{Inode: 5}},
+	}
+	require.Equal(
+		s.T(),
+		[]*WatchEvent{
+			{Op: WatchOpRename, Path: "/d", OldPath: "/a"},
+			{Op: WatchOpCreate, Path: "/new"},
+			{Op: WatchOpWrite, Path: "/c"},
+			{Op: WatchOpChmod, Path: "/perms"},
+			{Op: WatchOpRemove, Path: "/gone"},
+		},
+		diffPollEntries(previous, current),
+	)
+}
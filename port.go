@@ -0,0 +1,112 @@
+package osutils
+
+import (
+	"net"
+	"strconv"
+)
+
+const (
+	localhost = "127.0.0.1"
+)
+
+// PortReservation keeps a free TCP port bound until Release is called, so
+// that nothing else can grab it before it is handed off.
+type PortReservation struct {
+	Port     int
+	Listener net.Listener
+}
+
+func (p *PortReservation) Release() error {
+	return p.Listener.Close()
+}
+
+func GetFreePort() (int, error) {
+	ports, err := getFreePorts(1)
+	if err != nil {
+		return 0, err
+	}
+	return ports[0], nil
+}
+
+func GetFreePorts(n int) ([]int, error) {
+	if n <= 0 {
+		return nil, ErrEmpty
+	}
+	return getFreePorts(n)
+}
+
+func GetFreeUDPPort() (int, error) {
+	return getFreeUDPPort()
+}
+
+func ReservePort() (*PortReservation, error) {
+	return reservePort()
+}
+
+func IsPortAvailable(port int) bool {
+	return isPortAvailable(port)
+}
+
+func IsUDPPortAvailable(port int) bool {
+	return isUDPPortAvailable(port)
+}
+
+// ***** PRIVATE *****
+
+func getFreePorts(n int) ([]int, error) {
+	// hold on to every listener until the end so the ports are distinct
+	reservations := make([]*PortReservation, 0, n)
+	defer func() {
+		for _, reservation := range reservations {
+			_ = reservation.Release()
+		}
+	}()
+	ports := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		reservation, err := reservePort()
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+		ports = append(ports, reservation.Port)
+	}
+	return ports, nil
+}
+
+func getFreeUDPPort() (int, error) {
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(localhost, "0"))
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).Port, nil
+}
+
+func reservePort() (*PortReservation, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(localhost, "0"))
+	if err != nil {
+		return nil, err
+	}
+	return &PortReservation{
+		Port:     listener.Addr().(*net.TCPAddr).Port,
+		Listener: listener,
+	}, nil
+}
+
+func isPortAvailable(port int) bool {
+	listener, err := net.Listen("tcp", net.JoinHostPort(localhost, strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	_ = listener.Close()
+	return true
+}
+
+func isUDPPortAvailable(port int) bool {
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(localhost, strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
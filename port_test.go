@@ -0,0 +1,18 @@
+package osutils
+
+import (
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestGetFreePorts() {
+	ports, err := GetFreePorts(3)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 3, len(ports))
+	require.NotEqual(s.T(), ports[0], ports[1])
+	require.NotEqual(s.T(), ports[1], ports[2])
+	reservation, err := ReservePort()
+	require.NoError(s.T(), err)
+	require.False(s.T(), IsPortAvailable(reservation.Port))
+	require.NoError(s.T(), reservation.Release())
+	require.True(s.T(), IsPortAvailable(reservation.Port))
+}
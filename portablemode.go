@@ -0,0 +1,99 @@
+package osutils
+
+import (
+	"os"
+)
+
+// PortableMode is the intent for the permissions of a file or dir, mapped
+// to permission bits on Unix and to an ACL on Windows, where os.Chmod only
+// toggles the read-only attribute.
+type PortableMode int
+
+const (
+	// PortableModeDefault leaves permissions as they would otherwise be.
+	PortableModeDefault PortableMode = iota
+	// PortableModeSecret is only accessible to the owner: 0600 files and
+	// 0700 dirs, and on Windows the owner, SYSTEM and Administrators.
+	PortableModeSecret
+	// PortableModeConfig is writable by the owner and readable by
+	// everyone: 0644 files and 0755 dirs, on Windows readable by Users.
+	PortableModeConfig
+	// PortableModeExecutable is PortableModeConfig with 0755 files. On
+	// Windows, where whether a file runs depends on its extension, it is
+	// the same as PortableModeConfig.
+	PortableModeExecutable
+)
+
+// FilePerm returns the Unix permission bits for a file, or 0 for
+// PortableModeDefault.
+func (p PortableMode) FilePerm() os.FileMode {
+	switch p {
+	case PortableModeSecret:
+		return 0600
+	case PortableModeConfig:
+		return 0644
+	case PortableModeExecutable:
+		return 0755
+	default:
+		return 0
+	}
+}
+
+// DirPerm returns the Unix permission bits for a dir, or 0 for
+// PortableModeDefault.
+func (p PortableMode) DirPerm() os.FileMode {
+	switch p {
+	case PortableModeSecret:
+		return 0700
+	case PortableModeConfig, PortableModeExecutable:
+		return 0755
+	default:
+		return 0
+	}
+}
+
+// ApplyPortableMode sets the permissions of the file or dir at
+// absolutePath for portableMode, regardless of the umask. It is a no-op
+// for PortableModeDefault.
+func ApplyPortableMode(absolutePath string, portableMode PortableMode) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	if portableMode == PortableModeDefault {
+		return nil
+	}
+	fileInfo, err := os.Stat(absolutePath)
+	if err != nil {
+		return err
+	}
+	return applyPortableMode(absolutePath, portableMode, fileInfo.IsDir())
+}
+
+// CreateWithMode is Create with the permissions of portableMode. A new
+// file is created with them rather than changed afterwards.
+func CreateWithMode(absolutePath string, portableMode PortableMode) (*os.File, error) {
+	if portableMode == PortableModeDefault {
+		return create(absolutePath)
+	}
+	file, err := openFile(absolutePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, portableMode.FilePerm())
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyPortableMode(absolutePath, portableMode); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// MkdirWithMode is Mkdir with the permissions of portableMode.
+func MkdirWithMode(absolutePath string, portableMode PortableMode) error {
+	perm := portableMode.DirPerm()
+	if perm == 0 {
+		perm = 0755
+	}
+	if err := mkdir(absolutePath, perm); err != nil {
+		return err
+	}
+	return ApplyPortableMode(absolutePath, portableMode)
+}
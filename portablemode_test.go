@@ -0,0 +1,40 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestPortableMode() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("permission bits not meaningful on windows")
+	}
+	require.NoError(s.T(), WithUmask(0077, func() error {
+		file, err := CreateWithMode(filepath.Join(s.tempDir, "config"), PortableModeConfig)
+		require.NoError(s.T(), err)
+		s.checkClose(file)
+		return MkdirWithMode(filepath.Join(s.tempDir, "shared"), PortableModeExecutable)
+	}))
+	s.checkPerm(nil, filepath.Join(s.tempDir, "config"), 0644)
+	s.checkPerm(nil, filepath.Join(s.tempDir, "shared"), 0755)
+
+	require.NoError(s.T(), WriteJSONFile(filepath.Join(s.tempDir, "secret.json"), "token", &WriteOptions{Mode: PortableModeSecret}))
+	s.checkPerm(nil, filepath.Join(s.tempDir, "secret.json"), 0600)
+
+	src := s.writeCopyDirSrc()
+	dst := filepath.Join(s.tempDir, "dst")
+	require.NoError(s.T(), CopyDir(src, dst, &CopyDirOptions{Mode: PortableModeSecret}))
+	s.checkPerm(nil, filepath.Join(dst, "b"), 0700)
+	s.checkPerm(nil, filepath.Join(dst, "b", "2"), 0600)
+	_, err := CopyFileWithHash(filepath.Join(src, "a", "1"), filepath.Join(s.tempDir, "run"), HashAlgorithmSHA256, &CopyOptions{Mode: PortableModeExecutable})
+	require.NoError(s.T(), err)
+	s.checkPerm(nil, filepath.Join(s.tempDir, "run"), 0755)
+
+	require.NoError(s.T(), ApplyPortableMode(filepath.Join(s.tempDir, "run"), PortableModeDefault))
+	info, err := os.Stat(filepath.Join(s.tempDir, "run"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), os.FileMode(0755), info.Mode().Perm())
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package osutils
+
+import (
+	"os"
+)
+
+func applyPortableMode(absolutePath string, portableMode PortableMode, isDir bool) error {
+	if isDir {
+		return os.Chmod(absolutePath, portableMode.DirPerm())
+	}
+	return os.Chmod(absolutePath, portableMode.FilePerm())
+}
@@ -0,0 +1,61 @@
+package osutils
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// applyPortableMode replaces the DACL of absolutePath with one for
+// portableMode that does not inherit from the parent.
+func applyPortableMode(absolutePath string, portableMode PortableMode, isDir bool) error {
+	tokenUser, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return err
+	}
+	inheritance := aclInheritance(isDir)
+	entries := []windows.EXPLICIT_ACCESS{
+		explicitAccess(tokenUser.User.Sid, windows.TRUSTEE_IS_USER, windows.GENERIC_ALL, inheritance),
+	}
+	for _, wellKnownSid := range []windows.WELL_KNOWN_SID_TYPE{
+		windows.WinLocalSystemSid,
+		windows.WinBuiltinAdministratorsSid,
+	} {
+		sid, err := windows.CreateWellKnownSid(wellKnownSid)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, explicitAccess(sid, windows.TRUSTEE_IS_WELL_KNOWN_GROUP, windows.GENERIC_ALL, inheritance))
+	}
+	if portableMode != PortableModeSecret {
+		sid, err := windows.CreateWellKnownSid(windows.WinBuiltinUsersSid)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, explicitAccess(sid, windows.TRUSTEE_IS_WELL_KNOWN_GROUP, windows.GENERIC_READ|windows.GENERIC_EXECUTE, inheritance))
+	}
+	acl, err := windows.ACLFromEntries(entries, nil)
+	if err != nil {
+		return err
+	}
+	return windows.SetNamedSecurityInfo(
+		absolutePath,
+		windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION,
+		nil,
+		nil,
+		acl,
+		nil,
+	)
+}
+
+func explicitAccess(sid *windows.SID, trusteeType windows.TRUSTEE_TYPE, accessMask uint32, inheritance uint32) windows.EXPLICIT_ACCESS {
+	return windows.EXPLICIT_ACCESS{
+		AccessPermissions: windows.ACCESS_MASK(accessMask),
+		AccessMode:        windows.GRANT_ACCESS,
+		Inheritance:       inheritance,
+		Trustee: windows.TRUSTEE{
+			TrusteeForm:  windows.TRUSTEE_IS_SID,
+			TrusteeType:  trusteeType,
+			TrusteeValue: windows.TrusteeValueFromSID(sid),
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package osutils
+
+import (
+	"io"
+	"os"
+)
+
+const preallocateChunkSize = 1 << 20
+
+func Truncate(absolutePath string, size int64) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	return os.Truncate(absolutePath, size)
+}
+
+// Preallocate reserves disk space so the file is at least size bytes,
+// creating it if it does not exist. It never shrinks the file. A full disk
+// is reported here rather than on a later write.
+func Preallocate(absolutePath string, size int64) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	return preallocate(absolutePath, size)
+}
+
+// ***** PRIVATE *****
+
+func preallocate(absolutePath string, size int64) (retErr error) {
+	file, err := os.OpenFile(absolutePath, os.O_WRONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if size <= info.Size() {
+		return nil
+	}
+	return preallocateFile(file, info.Size(), size)
+}
+
+// preallocateByWriting writes zeroes from offset to size, which is what
+// posix_fallocate does when the filesystem cannot allocate directly.
+func preallocateByWriting(file *os.File, offset int64, size int64) error {
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	zeroes := make([]byte, preallocateChunkSize)
+	for offset < size {
+		n := int64(len(zeroes))
+		if size-offset < n {
+			n = size - offset
+		}
+		if _, err := file.Write(zeroes[:n]); err != nil {
+			return err
+		}
+		offset += n
+	}
+	return file.Sync()
+}
@@ -0,0 +1,28 @@
+package osutils
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func preallocateFile(file *os.File, offset int64, size int64) error {
+	fstore := &unix.Fstore_t{
+		Flags:   unix.F_ALLOCATECONTIG | unix.F_ALLOCATEALL,
+		Posmode: unix.F_PEOFPOSMODE,
+		Offset:  0,
+		Length:  size - offset,
+	}
+	if err := unix.FcntlFstore(file.Fd(), unix.F_PREALLOCATE, fstore); err != nil {
+		// contiguous space was not available, take any space
+		fstore.Flags = unix.F_ALLOCATEALL
+		if err := unix.FcntlFstore(file.Fd(), unix.F_PREALLOCATE, fstore); err != nil {
+			if err == unix.ENOTSUP {
+				return preallocateByWriting(file, offset, size)
+			}
+			return err
+		}
+	}
+	// F_PREALLOCATE reserves blocks but does not change the file size
+	return file.Truncate(size)
+}
@@ -0,0 +1,15 @@
+package osutils
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func preallocateFile(file *os.File, offset int64, size int64) error {
+	err := unix.Fallocate(int(file.Fd()), 0, offset, size-offset)
+	if err == unix.EOPNOTSUPP || err == unix.ENOSYS {
+		return preallocateByWriting(file, offset, size)
+	}
+	return err
+}
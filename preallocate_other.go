@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package osutils
+
+import (
+	"os"
+)
+
+func preallocateFile(file *os.File, offset int64, size int64) error {
+	return preallocateByWriting(file, offset, size)
+}
@@ -0,0 +1,43 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestPreallocate() {
+	path := filepath.Join(s.tempDir, "file")
+	require.NoError(s.T(), ioutil.WriteFile(path, []byte("hello"), 0644))
+	require.NoError(s.T(), Preallocate(path, 3<<20+1))
+	info, err := os.Stat(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(3<<20+1), info.Size())
+	data, err := ioutil.ReadFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "hello", string(data[:5]))
+	require.Equal(s.T(), byte(0), data[len(data)-1])
+	// never shrinks
+	require.NoError(s.T(), Preallocate(path, 10))
+	info, err = os.Stat(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(3<<20+1), info.Size())
+	require.NoError(s.T(), Truncate(path, 2))
+	data, err = ioutil.ReadFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "he", string(data))
+	require.Equal(s.T(), ErrNotAbsolutePath, Preallocate("file", 10))
+}
+
+func (s *Suite) TestPreallocateByWriting() {
+	path := filepath.Join(s.tempDir, "file")
+	file, err := os.Create(path)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), preallocateByWriting(file, 0, preallocateChunkSize+3))
+	s.checkClose(file)
+	info, err := os.Stat(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(preallocateChunkSize+3), info.Size())
+}
@@ -0,0 +1,64 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Progress receives updates from long operations. Start is called once
+// with the total, in bytes unless documented otherwise, or -1 if it is not
+// known. Step is called as work completes and Done once at the end. Calls
+// are made from one goroutine at a time.
+type Progress interface {
+	Start(total int64)
+	Step(n int64, currentPath string)
+	Done(err error)
+}
+
+// WalkWithProgress is filepath.Walk, stepping the progress by one for
+// each path visited.
+func WalkWithProgress(absolutePath string, walkFunc filepath.WalkFunc, progress Progress) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	progress = progressOrNop(progress)
+	progress.Start(-1)
+	err := filepath.Walk(
+		absolutePath,
+		func(path string, info os.FileInfo, err error) error {
+			progress.Step(1, path)
+			return walkFunc(path, info, err)
+		},
+	)
+	progress.Done(err)
+	return err
+}
+
+// ***** PRIVATE *****
+
+type nopProgress struct{}
+
+func (nopProgress) Start(int64)        {}
+func (nopProgress) Step(int64, string) {}
+func (nopProgress) Done(error)         {}
+
+func progressOrNop(progress Progress) Progress {
+	if progress == nil {
+		return nopProgress{}
+	}
+	return progress
+}
+
+// startTreeProgress starts progress with the total size of the files under
+// absolutePath, skipping the extra walk if there is no progress.
+func startTreeProgress(absolutePath string, progress Progress) error {
+	if _, ok := progress.(nopProgress); ok {
+		return nil
+	}
+	treeStatistics, err := treeStats(absolutePath)
+	if err != nil {
+		return err
+	}
+	progress.Start(treeStatistics.Bytes)
+	return nil
+}
@@ -0,0 +1,65 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testProgress struct {
+	total   int64
+	steps   int64
+	done    bool
+	doneErr error
+}
+
+func (p *testProgress) Start(total int64) {
+	p.total = total
+}
+
+func (p *testProgress) Step(n int64, currentPath string) {
+	p.steps += n
+}
+
+func (p *testProgress) Done(err error) {
+	p.done = true
+	p.doneErr = err
+}
+
+func (s *Suite) TestProgress() {
+	src := s.writeCopyDirSrc()
+	progress := &testProgress{}
+	require.NoError(s.T(), CopyDir(src, filepath.Join(s.tempDir, "dst"), &CopyDirOptions{Progress: progress}))
+	require.Equal(s.T(), int64(2), progress.total)
+	require.Equal(s.T(), int64(2), progress.steps)
+	require.True(s.T(), progress.done)
+
+	progress = &testProgress{}
+	require.NoError(s.T(), WalkWithProgress(src, func(string, os.FileInfo, error) error { return nil }, progress))
+	require.Equal(s.T(), int64(-1), progress.total)
+	require.Equal(s.T(), int64(6), progress.steps)
+
+	progress = &testProgress{}
+	usage, err := DiskUsage(src, progress)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), usage, progress.steps)
+}
+
+func (s *Suite) TestHashDir() {
+	src := s.writeCopyDirSrc()
+	dst := filepath.Join(s.tempDir, "dst")
+	require.NoError(s.T(), CopyDir(src, dst, nil))
+	progress := &testProgress{}
+	srcHash, err := HashDir(src, &HashDirOptions{Progress: progress})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(2), progress.steps)
+	dstHash, err := HashDir(dst, nil)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), srcHash, dstHash)
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(dst, "a", "1"), []byte("changed"), 0600))
+	dstHash, err = HashDir(dst, nil)
+	require.NoError(s.T(), err)
+	require.NotEqual(s.T(), srcHash, dstHash)
+}
@@ -0,0 +1,18 @@
+package osutils
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// reflinkFile creates dst as an APFS clone of src.
+func reflinkFile(src string, dst string, perm os.FileMode) error {
+	if err := unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW); err != nil {
+		if err == unix.ENOTSUP || err == unix.EXDEV {
+			return ErrNotSupported
+		}
+		return err
+	}
+	return os.Chmod(dst, perm)
+}
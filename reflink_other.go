@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package osutils
+
+import (
+	"os"
+)
+
+func reflinkFile(src string, dst string, perm os.FileMode) error {
+	return ErrNotSupported
+}
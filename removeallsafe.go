@@ -0,0 +1,211 @@
+package osutils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultRemoveAllMinDepth = 2
+
+var (
+	ErrFilesystemRoot = errors.New("osutils: refusing to remove a filesystem root")
+	ErrMountPoint     = errors.New("osutils: refusing to remove a mount point")
+	ErrPathTooShallow = errors.New("osutils: path is above the minimum depth")
+)
+
+type RemoveAllSafeOptions struct {
+	// MinDepth is the minimum number of path elements, 2 if zero, so
+	// /srv/app can be removed but /srv cannot.
+	MinDepth int
+	// AllowedBase, if set, must contain the path, which must not be
+	// AllowedBase itself.
+	AllowedBase string
+	// TrashDir, if set, is where the path is moved to instead of being
+	// deleted. It should be on the same filesystem, otherwise the tree is
+	// copied there first.
+	TrashDir string
+}
+
+// RemoveAllSafe is RemoveAll with the checks a tool deleting computed paths
+// should make. Besides the Guard, it refuses filesystem roots, paths above
+// the minimum depth or outside the allowed base, and trees that are or
+// contain a mount point. It returns the path in the trash if TrashDir is
+// set.
+func RemoveAllSafe(absolutePath string, options *RemoveAllSafeOptions) (string, error) {
+	if !isAbsolutePath(absolutePath) {
+		return "", ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &RemoveAllSafeOptions{}
+	}
+	if options.TrashDir != "" && !isAbsolutePath(options.TrashDir) {
+		return "", ErrNotAbsolutePath
+	}
+	return removeAllSafe(filepath.Clean(absolutePath), options)
+}
+
+// ***** PRIVATE *****
+
+func removeAllSafe(path string, options *RemoveAllSafeOptions) (string, error) {
+	if err := checkRemoveAllSafe(path, options); err != nil {
+		return "", err
+	}
+	// a symlink above path can point the removal anywhere, so the checks
+	// are also made on where it actually is
+	path, err := resolveParent(path)
+	if err != nil {
+		return "", err
+	}
+	if err := checkRemoveAllSafe(path, options); err != nil {
+		return "", err
+	}
+	exists, err := lexists(path)
+	if err != nil || !exists {
+		return "", err
+	}
+	if err := checkNoMountPoints(path); err != nil {
+		return "", err
+	}
+	if options.TrashDir == "" {
+		return "", os.RemoveAll(path)
+	}
+	return moveToTrash(path, options.TrashDir)
+}
+
+func checkRemoveAllSafe(path string, options *RemoveAllSafeOptions) error {
+	if err := checkGuard(path); err != nil {
+		return err
+	}
+	if filepath.Dir(path) == path {
+		return ErrFilesystemRoot
+	}
+	minDepth := options.MinDepth
+	if minDepth <= 0 {
+		minDepth = defaultRemoveAllMinDepth
+	}
+	if pathDepth(path) < minDepth {
+		return ErrPathTooShallow
+	}
+	if options.AllowedBase != "" {
+		base := filepath.Clean(options.AllowedBase)
+		if path == base || (!isWithin(base, path) && !isWithinResolved(base, path)) {
+			return ErrPathOutsideDir
+		}
+	}
+	return nil
+}
+
+func pathDepth(path string) int {
+	rest := strings.TrimPrefix(path, filepath.VolumeName(path))
+	depth := 0
+	for _, element := range strings.Split(rest, string(filepath.Separator)) {
+		if element != "" {
+			depth++
+		}
+	}
+	return depth
+}
+
+// resolveParent resolves the symlinks in the dirs above path, but not
+// path itself since removing a symlink only removes the link.
+func resolveParent(path string) (string, error) {
+	parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return path, nil
+		}
+		return "", err
+	}
+	return filepath.Join(parent, filepath.Base(path)), nil
+}
+
+// isWithinResolved is isWithin for a resolved path, with the symlinks in
+// base resolved as well.
+func isWithinResolved(base string, path string) bool {
+	resolvedBase, err := filepath.EvalSymlinks(base)
+	if err != nil {
+		return false
+	}
+	return path != resolvedBase && isWithin(resolvedBase, path)
+}
+
+// checkNoMountPoints fails if path is a mount point or has one below it,
+// where os.RemoveAll would delete the contents of the mounted filesystem.
+// The mount table also catches bind mounts, which may be on the same
+// device, and any directory on a different device is a mount point too.
+func checkNoMountPoints(path string) error {
+	mountEntries, err := ListMounts()
+	if err != nil && err != ErrNotSupported {
+		return err
+	}
+	for _, mountEntry := range mountEntries {
+		if isWithin(path, filepath.Clean(mountEntry.Target)) {
+			return ErrMountPoint
+		}
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+	parentID, err := fileID(filepath.Dir(path))
+	if err == ErrNotSupported {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return filepath.Walk(
+		path,
+		func(walkPath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			id, err := fileID(walkPath)
+			if err != nil {
+				return err
+			}
+			if id.Device != parentID.Device {
+				return ErrMountPoint
+			}
+			return nil
+		},
+	)
+}
+
+func moveToTrash(path string, trashDir string) (string, error) {
+	if err := os.MkdirAll(trashDir, 0700); err != nil {
+		return "", err
+	}
+	trashPath := filepath.Join(trashDir, filepath.Base(path)+"."+strconv.FormatInt(time.Now().UnixNano(), 10))
+	err := os.Rename(path, trashPath)
+	if err == nil {
+		return trashPath, nil
+	}
+	var linkError *os.LinkError
+	if !errors.As(err, &linkError) || linkError.Err != errCrossDevice {
+		return "", err
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		err = copyDir(path, trashPath, &CopyDirOptions{FailureMode: FailureModeRollback})
+	} else {
+		err = moveFile(path, trashPath)
+	}
+	if err != nil {
+		return "", err
+	}
+	return trashPath, os.RemoveAll(path)
+}
@@ -0,0 +1,73 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestRemoveAllSafe() {
+	path := filepath.Join(s.tempDir, "dir")
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(path, "sub"), 0755))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(path, "sub", "file"), []byte("hello"), 0644))
+
+	_, err := RemoveAllSafe(path, &RemoveAllSafeOptions{MinDepth: 100})
+	require.Equal(s.T(), ErrPathTooShallow, err)
+	_, err = RemoveAllSafe(path, &RemoveAllSafeOptions{AllowedBase: path})
+	require.Equal(s.T(), ErrPathOutsideDir, err)
+	_, err = RemoveAllSafe(path, &RemoveAllSafeOptions{AllowedBase: filepath.Join(s.tempDir, "other")})
+	require.Equal(s.T(), ErrPathOutsideDir, err)
+	if runtime.GOOS != "windows" {
+		_, err = RemoveAllSafe("/", &RemoveAllSafeOptions{MinDepth: -1})
+		require.Equal(s.T(), ErrGuardedPath, err)
+		SetGuard(nil)
+		_, err = RemoveAllSafe("/", nil)
+		SetGuard(DefaultGuard())
+		require.Equal(s.T(), ErrFilesystemRoot, err)
+	}
+
+	trashDir := filepath.Join(s.tempDir, "trash")
+	trashPath, err := RemoveAllSafe(path, &RemoveAllSafeOptions{AllowedBase: s.tempDir, TrashDir: trashDir})
+	require.NoError(s.T(), err)
+	s.checkFileDoesNotExist(path)
+	s.checkFileExists(filepath.Join(trashPath, "sub", "file"))
+
+	trashPath, err = RemoveAllSafe(trashPath, nil)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), trashPath)
+	s.checkFileExists(trashDir)
+	_, err = RemoveAllSafe(filepath.Join(s.tempDir, "does-not-exist"), nil)
+	require.NoError(s.T(), err)
+}
+
+func (s *Suite) TestRemoveAllSafeSymlinkedParent() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("symlinks require privileges on windows")
+	}
+	allowed := filepath.Join(s.tempDir, "allowed")
+	victim := filepath.Join(s.tempDir, "outside", "victim")
+	require.NoError(s.T(), os.MkdirAll(allowed, 0755))
+	require.NoError(s.T(), os.MkdirAll(victim, 0755))
+	require.NoError(s.T(), os.Symlink(filepath.Dir(victim), filepath.Join(allowed, "escape")))
+	_, err := RemoveAllSafe(filepath.Join(allowed, "escape", "victim"), &RemoveAllSafeOptions{AllowedBase: allowed})
+	require.Equal(s.T(), ErrPathOutsideDir, err)
+	s.checkFileExists(victim)
+
+	// a symlinked base still allows what is really below it
+	linkedBase := filepath.Join(s.tempDir, "linked")
+	require.NoError(s.T(), os.Symlink(filepath.Dir(victim), linkedBase))
+	_, err = RemoveAllSafe(filepath.Join(linkedBase, "victim"), &RemoveAllSafeOptions{AllowedBase: linkedBase})
+	require.NoError(s.T(), err)
+	s.checkFileDoesNotExist(victim)
+}
+
+func (s *Suite) TestCheckNoMountPoints() {
+	if _, err := os.Stat("/proc/mounts"); err != nil {
+		s.T().Skip("no /proc/mounts")
+	}
+	require.Equal(s.T(), ErrMountPoint, checkNoMountPoints("/proc"))
+	require.NoError(s.T(), checkNoMountPoints(s.tempDir))
+}
@@ -0,0 +1,132 @@
+package osutils
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type RenderTreeOptions struct {
+	// MaxDepth limits how deep to descend, 0 is unlimited.
+	MaxDepth int
+	// ShowHidden includes entries starting with a dot.
+	ShowHidden bool
+	// ShowSizes annotates files with their human-readable size.
+	ShowSizes bool
+}
+
+// RenderTree renders the tree at absolutePath like tree(1), entries sorted
+// by name, followed by a summary line.
+func RenderTree(absolutePath string, options *RenderTreeOptions) (string, error) {
+	if !isAbsolutePath(absolutePath) {
+		return "", ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &RenderTreeOptions{}
+	}
+	return renderTree(absolutePath, options)
+}
+
+func HumanizeBytes(n int64) string {
+	return humanizeBytes(n)
+}
+
+// ***** PRIVATE *****
+
+type treeRenderer struct {
+	options *RenderTreeOptions
+	buffer  bytes.Buffer
+	dirs    int
+	files   int
+}
+
+func renderTree(absolutePath string, options *RenderTreeOptions) (string, error) {
+	info, err := os.Stat(absolutePath)
+	if err != nil {
+		return "", err
+	}
+	treeRenderer := &treeRenderer{options: options}
+	_, _ = treeRenderer.buffer.WriteString(absolutePath)
+	_ = treeRenderer.buffer.WriteByte('\n')
+	if info.IsDir() {
+		if err := treeRenderer.renderDir(absolutePath, "", 1); err != nil {
+			return "", err
+		}
+	}
+	_, _ = fmt.Fprintf(
+		&treeRenderer.buffer,
+		"\n%d %s, %d %s\n",
+		treeRenderer.dirs,
+		plural(treeRenderer.dirs, "directory", "directories"),
+		treeRenderer.files,
+		plural(treeRenderer.files, "file", "files"),
+	)
+	return treeRenderer.buffer.String(), nil
+}
+
+func (t *treeRenderer) renderDir(dir string, prefix string, depth int) error {
+	allInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var infos []os.FileInfo
+	for _, info := range allInfos {
+		if t.options.ShowHidden || !strings.HasPrefix(info.Name(), ".") {
+			infos = append(infos, info)
+		}
+	}
+	for i, info := range infos {
+		connector, childPrefix := "├── ", "│   "
+		if i == len(infos)-1 {
+			connector, childPrefix = "└── ", "    "
+		}
+		_, _ = t.buffer.WriteString(prefix)
+		_, _ = t.buffer.WriteString(connector)
+		path := filepath.Join(dir, info.Name())
+		if t.options.ShowSizes && !info.IsDir() {
+			_, _ = fmt.Fprintf(&t.buffer, "[%6s]  ", humanizeBytes(info.Size()))
+		}
+		_, _ = t.buffer.WriteString(info.Name())
+		if info.Mode()&os.ModeSymlink != 0 {
+			if target, err := os.Readlink(path); err == nil {
+				_, _ = t.buffer.WriteString(" -> ")
+				_, _ = t.buffer.WriteString(target)
+			}
+		}
+		_ = t.buffer.WriteByte('\n')
+		if !info.IsDir() {
+			t.files++
+			continue
+		}
+		t.dirs++
+		if t.options.MaxDepth <= 0 || depth < t.options.MaxDepth {
+			if err := t.renderDir(path, prefix+childPrefix, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func humanizeBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func plural(n int, singular string, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
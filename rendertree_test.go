@@ -0,0 +1,45 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestRenderTree() {
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(s.tempDir, "a/b"), 0755))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(s.tempDir, "a/b/deep"), nil, 0644))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(s.tempDir, "a/one"), []byte("1"), 0644))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(s.tempDir, ".hidden"), nil, 0644))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(s.tempDir, "two"), make([]byte, 2048), 0644))
+	output, err := RenderTree(s.tempDir, nil)
+	require.NoError(s.T(), err)
+	require.Equal(
+		s.T(),
+		s.tempDir+`
+├── a
+│   ├── b
+│   │   └── deep
+│   └── one
+└── two
+
+2 directories, 3 files
+`,
+		output,
+	)
+	output, err = RenderTree(s.tempDir, &RenderTreeOptions{MaxDepth: 1, ShowHidden: true, ShowSizes: true})
+	require.NoError(s.T(), err)
+	require.Equal(
+		s.T(),
+		s.tempDir+`
+├── [    0B]  .hidden
+├── a
+└── [  2.0K]  two
+
+1 directory, 2 files
+`,
+		output,
+	)
+}
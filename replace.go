@@ -0,0 +1,129 @@
+package osutils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+type ReplaceOptions struct {
+	// Regexp treats old as a regexp, in which case new may refer to
+	// submatches as with regexp.Regexp.ReplaceAll.
+	Regexp bool
+	// Backup keeps the previous content at the path with a .bak suffix.
+	Backup bool
+}
+
+// ReplaceInFile replaces every occurrence of old in the file at
+// absolutePath with new and returns the number of replacements. The file
+// is rewritten atomically with its permissions kept, under the same lock
+// as UpdateFile, and not at all if nothing matched, so running it again is
+// a no-op as long as new does not itself contain old.
+func ReplaceInFile(absolutePath string, old []byte, new []byte, options *ReplaceOptions) (int, error) {
+	if !isAbsolutePath(absolutePath) {
+		return 0, ErrNotAbsolutePath
+	}
+	replacer, err := newReplacer(old, new, options)
+	if err != nil {
+		return 0, err
+	}
+	return replacer.replaceInFile(absolutePath)
+}
+
+// ReplaceInFiles is ReplaceInFile for every regular file matching the
+// absolute pattern, see filepath.Match, and returns the number of
+// replacements by path for the files that changed.
+func ReplaceInFiles(pattern string, old []byte, new []byte, options *ReplaceOptions) (map[string]int, error) {
+	if !isAbsolutePath(pattern) {
+		return nil, ErrNotAbsolutePath
+	}
+	replacer, err := newReplacer(old, new, options)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	replaced := make(map[string]int)
+	for _, match := range matches {
+		fileInfo, err := os.Stat(match)
+		if err != nil {
+			return replaced, err
+		}
+		if !fileInfo.Mode().IsRegular() {
+			continue
+		}
+		count, err := replacer.replaceInFile(match)
+		if err != nil {
+			return replaced, err
+		}
+		if count > 0 {
+			replaced[match] = count
+		}
+	}
+	return replaced, nil
+}
+
+// ***** PRIVATE *****
+
+type replacer struct {
+	old          []byte
+	new          []byte
+	regexp       *regexp.Regexp
+	writeOptions *WriteOptions
+}
+
+func newReplacer(old []byte, new []byte, options *ReplaceOptions) (*replacer, error) {
+	if len(old) == 0 {
+		return nil, ErrEmpty
+	}
+	if options == nil {
+		options = &ReplaceOptions{}
+	}
+	replacer := &replacer{
+		old:          old,
+		new:          new,
+		writeOptions: &WriteOptions{Backup: options.Backup},
+	}
+	if options.Regexp {
+		compiled, err := regexp.Compile(string(old))
+		if err != nil {
+			return nil, err
+		}
+		replacer.regexp = compiled
+	}
+	return replacer, nil
+}
+
+func (r *replacer) replaceInFile(absolutePath string) (int, error) {
+	count := 0
+	err := updateFile(
+		absolutePath,
+		func(data []byte) ([]byte, error) {
+			if data == nil {
+				if _, err := os.Stat(absolutePath); err != nil {
+					return nil, err
+				}
+			}
+			if r.regexp != nil {
+				count = len(r.regexp.FindAllIndex(data, -1))
+				if count == 0 {
+					return data, nil
+				}
+				return r.regexp.ReplaceAll(data, r.new), nil
+			}
+			count = bytes.Count(data, r.old)
+			if count == 0 {
+				return data, nil
+			}
+			return bytes.Replace(data, r.old, r.new, -1), nil
+		},
+		r.writeOptions,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
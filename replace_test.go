@@ -0,0 +1,59 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestReplaceInFile() {
+	path := filepath.Join(s.tempDir, "config")
+	require.NoError(s.T(), ioutil.WriteFile(path, []byte("port=80\nhost=a\nport=80\n"), 0600))
+
+	count, err := ReplaceInFile(path, []byte("port=80"), []byte("port=8080"), &ReplaceOptions{Backup: true})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 2, count)
+	data, err := ioutil.ReadFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "port=8080\nhost=a\nport=8080\n", string(data))
+	data, err = ioutil.ReadFile(path + ".bak")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "port=80\nhost=a\nport=80\n", string(data))
+	info, err := os.Stat(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), os.FileMode(0600), info.Mode().Perm())
+
+	count, err = ReplaceInFile(path, []byte(`host=(\w+)`), []byte("host=${1}.example.com"), &ReplaceOptions{Regexp: true})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, count)
+	data, err = ioutil.ReadFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "port=8080\nhost=a.example.com\nport=8080\n", string(data))
+
+	_, err = ReplaceInFile(filepath.Join(s.tempDir, "missing"), []byte("a"), []byte("b"), nil)
+	require.True(s.T(), os.IsNotExist(err))
+	s.checkFileDoesNotExist(filepath.Join(s.tempDir, "missing"))
+}
+
+func (s *Suite) TestReplaceInFiles() {
+	for _, name := range []string{"a.conf", "b.conf", "c.txt"} {
+		require.NoError(s.T(), ioutil.WriteFile(filepath.Join(s.tempDir, name), []byte("old"), 0644))
+	}
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(s.tempDir, "d.conf"), []byte("new"), 0644))
+
+	replaced, err := ReplaceInFiles(filepath.Join(s.tempDir, "*.conf"), []byte("old"), []byte("new"), nil)
+	require.NoError(s.T(), err)
+	require.Equal(
+		s.T(),
+		map[string]int{
+			filepath.Join(s.tempDir, "a.conf"): 1,
+			filepath.Join(s.tempDir, "b.conf"): 1,
+		},
+		replaced,
+	)
+	data, err := ioutil.ReadFile(filepath.Join(s.tempDir, "c.txt"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "old", string(data))
+}
@@ -0,0 +1,195 @@
+package osutils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrRequirementsNotMet = errors.New("osutils: requirements not met")
+	ErrNoVersion          = errors.New("osutils: no version")
+
+	defaultVersionArgs   = []string{"--version"}
+	defaultVersionRegexp = regexp.MustCompile(`(\d+(?:\.\d+)+)`)
+)
+
+type CommandSpec struct {
+	Name string
+	// MinVersion is a dotted version such as 2.17, if empty only the
+	// presence of the command is checked.
+	MinVersion string
+	// VersionArgs defaults to --version.
+	VersionArgs []string
+	// VersionRegexp is matched against the combined output of the version
+	// command, the first submatch or else the whole match is the version.
+	// Defaults to the first dotted number.
+	VersionRegexp *regexp.Regexp
+}
+
+type CommandStatus struct {
+	Spec    *CommandSpec
+	Path    string
+	Version string
+	Err     error
+}
+
+type CommandReport struct {
+	Found    []*CommandStatus
+	Missing  []*CommandStatus
+	Outdated []*CommandStatus
+}
+
+func (c *CommandReport) OK() bool {
+	return len(c.Missing) == 0 && len(c.Outdated) == 0
+}
+
+// RequireCommands checks that every command is on the PATH and at least its
+// minimum version. The report is always returned, and the error is
+// ErrRequirementsNotMet if anything is missing or outdated.
+func RequireCommands(specs ...*CommandSpec) (*CommandReport, error) {
+	return requireCommands(specs)
+}
+
+func CompareVersions(a string, b string) (int, error) {
+	return compareVersions(a, b)
+}
+
+// ***** PRIVATE *****
+
+func requireCommands(specs []*CommandSpec) (*CommandReport, error) {
+	report := &CommandReport{}
+	for _, spec := range specs {
+		if spec == nil {
+			return nil, ErrNil
+		}
+		if spec.Name == "" {
+			return nil, ErrEmpty
+		}
+		status := checkCommand(spec)
+		switch {
+		case status.Path == "":
+			report.Missing = append(report.Missing, status)
+		case status.Err != nil:
+			report.Outdated = append(report.Outdated, status)
+		default:
+			report.Found = append(report.Found, status)
+		}
+	}
+	if !report.OK() {
+		return report, ErrRequirementsNotMet
+	}
+	return report, nil
+}
+
+func checkCommand(spec *CommandSpec) *CommandStatus {
+	status := &CommandStatus{Spec: spec}
+	path, err := exec.LookPath(spec.Name)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	status.Path = path
+	if spec.MinVersion == "" {
+		return status
+	}
+	version, err := commandVersion(path, spec)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	status.Version = version
+	cmp, err := compareVersions(version, spec.MinVersion)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	if cmp < 0 {
+		status.Err = ErrRequirementsNotMet
+	}
+	return status
+}
+
+func commandVersion(path string, spec *CommandSpec) (string, error) {
+	versionArgs := spec.VersionArgs
+	if versionArgs == nil {
+		versionArgs = defaultVersionArgs
+	}
+	versionRegexp := spec.VersionRegexp
+	if versionRegexp == nil {
+		versionRegexp = defaultVersionRegexp
+	}
+	var output bytes.Buffer
+	wait, err := execute(
+		context.Background(),
+		&Cmd{
+			Args:   append([]string{path}, versionArgs...),
+			Stdout: &output,
+			Stderr: &output,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	// some tools exit non-zero for --version, the output is what matters
+	waitErr := wait()
+	match := versionRegexp.FindStringSubmatch(output.String())
+	if match == nil {
+		if waitErr != nil {
+			return "", waitErr
+		}
+		return "", ErrNoVersion
+	}
+	if len(match) > 1 {
+		return match[1], nil
+	}
+	return match[0], nil
+}
+
+func compareVersions(a string, b string) (int, error) {
+	aParts, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bParts, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aPart, bPart int
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+		if aPart < bPart {
+			return -1, nil
+		}
+		if aPart > bPart {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseVersion(version string) ([]int, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" {
+		return nil, ErrNoVersion
+	}
+	split := strings.Split(version, ".")
+	parts := make([]int, len(split))
+	for i, s := range split {
+		part, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, ErrNoVersion
+		}
+		parts[i] = part
+	}
+	return parts, nil
+}
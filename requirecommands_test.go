@@ -0,0 +1,37 @@
+package osutils
+
+import (
+	"regexp"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestRequireCommands() {
+	report, err := RequireCommands(
+		&CommandSpec{
+			Name: "bash",
+		},
+		&CommandSpec{
+			Name:          "bash",
+			MinVersion:    "999.0",
+			VersionRegexp: regexp.MustCompile(`version (\d+\.\d+)`),
+		},
+		&CommandSpec{
+			Name: "osutils-does-not-exist",
+		},
+	)
+	require.Equal(s.T(), ErrRequirementsNotMet, err)
+	require.Equal(s.T(), 1, len(report.Found))
+	require.Equal(s.T(), 1, len(report.Outdated))
+	require.NotEmpty(s.T(), report.Outdated[0].Version)
+	require.Equal(s.T(), 1, len(report.Missing))
+}
+
+func (s *Suite) TestCompareVersions() {
+	cmp, err := CompareVersions("1.10", "1.9.3")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, cmp)
+	cmp, err = CompareVersions("v2.0", "2")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 0, cmp)
+}
@@ -0,0 +1,55 @@
+package osutils
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+const (
+	procSelfExeDeletedSuffix = " (deleted)"
+)
+
+func RestartSelf(args []string, env []string, extraFiles ...*os.File) error {
+	return restartSelf(args, env, extraFiles)
+}
+
+func Executable() (string, error) {
+	return executable()
+}
+
+// ***** PRIVATE *****
+
+func restartSelf(args []string, env []string, extraFiles []*os.File) error {
+	for _, extraFile := range extraFiles {
+		if extraFile == nil {
+			return ErrNil
+		}
+	}
+	executable, err := executable()
+	if err != nil {
+		return err
+	}
+	if args == nil {
+		args = os.Args[1:]
+	}
+	if env == nil {
+		env = os.Environ()
+	}
+	return execSelf(executable, append([]string{os.Args[0]}, args...), env, extraFiles)
+}
+
+func executable() (string, error) {
+	if runtime.GOOS == "linux" {
+		// /proc/self/exe still points at the old inode after an upgrade
+		// has replaced the binary, so resolve it to the path instead
+		if executable, err := os.Readlink("/proc/self/exe"); err == nil {
+			return strings.TrimSuffix(executable, procSelfExeDeletedSuffix), nil
+		}
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return cleanPath(executable)
+}
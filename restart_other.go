@@ -0,0 +1,12 @@
+//go:build !unix && !windows
+
+package osutils
+
+import (
+	"os"
+)
+
+// execSelf is not supported, there is no exec on this platform.
+func execSelf(executable string, argv []string, env []string, extraFiles []*os.File) error {
+	return ErrNotSupported
+}
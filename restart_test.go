@@ -0,0 +1,82 @@
+package osutils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	restartHelperEnv = "OSUTILS_TEST_RESTART_HELPER"
+)
+
+func (s *Suite) TestExecutable() {
+	executable, err := Executable()
+	require.NoError(s.T(), err)
+	require.True(s.T(), filepath.IsAbs(executable))
+	exists, err := IsRegularFileExists(executable)
+	require.NoError(s.T(), err)
+	require.True(s.T(), exists)
+}
+
+func (s *Suite) TestRestartSelf() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("extra files are not supported on windows")
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRestartSelfHelper$")
+	cmd.Env = restartHelperEnviron("restart")
+	output, err := cmd.CombinedOutput()
+	require.NoError(s.T(), err, string(output))
+	require.Contains(s.T(), string(output), "restarted: fd3=b fd4=a")
+}
+
+// TestRestartSelfHelper is run in a subprocess by TestRestartSelf, and
+// restarts itself once with two pipes passed in reverse order.
+func TestRestartSelfHelper(t *testing.T) {
+	switch os.Getenv(restartHelperEnv) {
+	case "restart":
+		a := restartHelperPipe(t, "a")
+		b := restartHelperPipe(t, "b")
+		err := RestartSelf(
+			[]string{"-test.run=^TestRestartSelfHelper$"},
+			restartHelperEnviron("restarted"),
+			b,
+			a,
+		)
+		t.Fatalf("RestartSelf returned: %v", err)
+	case "restarted":
+		fd3, err := ioutil.ReadAll(os.NewFile(3, "fd3"))
+		require.NoError(t, err)
+		fd4, err := ioutil.ReadAll(os.NewFile(4, "fd4"))
+		require.NoError(t, err)
+		fmt.Printf("restarted: fd3=%s fd4=%s\n", fd3, fd4)
+	}
+}
+
+func restartHelperPipe(t *testing.T, data string) *os.File {
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	_, err = writer.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return reader
+}
+
+// restartHelperEnviron replaces the helper variable, as the first of
+// duplicate variables wins.
+func restartHelperEnviron(value string) []string {
+	var env []string
+	for _, variable := range os.Environ() {
+		if !strings.HasPrefix(variable, restartHelperEnv+"=") {
+			env = append(env, variable)
+		}
+	}
+	return append(env, restartHelperEnv+"="+value)
+}
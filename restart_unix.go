@@ -0,0 +1,85 @@
+//go:build unix
+
+package osutils
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// execSelf replaces the current process image. The extra files end up at
+// fds 3, 4, ... in the new process, matching exec.Cmd.ExtraFiles.
+func execSelf(executable string, argv []string, env []string, extraFiles []*os.File) error {
+	first := 3 + len(extraFiles)
+	// stage everything on free fds above the target range first so that a
+	// target fd is never clobbered before it has been moved, and so that
+	// nothing else this process has open is overwritten
+	staged := make([]int, 0, len(extraFiles))
+	defer func() {
+		for _, fd := range staged {
+			_ = syscall.Close(fd)
+		}
+	}()
+	for _, extraFile := range extraFiles {
+		fd, err := dupCloexec(int(extraFile.Fd()), first)
+		if err != nil {
+			return err
+		}
+		staged = append(staged, fd)
+	}
+	// the targets may hold fds of the runtime or of the caller, keep those
+	// so they can be put back if the exec fails
+	saved := make([]savedFd, 0, len(staged))
+	defer func() {
+		restoreFds(saved)
+	}()
+	for i, fd := range staged {
+		save, err := saveFd(3+i, first)
+		if err != nil {
+			return err
+		}
+		saved = append(saved, save)
+		if err := dup2(fd, 3+i); err != nil {
+			return err
+		}
+	}
+	return syscall.Exec(executable, argv, env)
+}
+
+// savedFd is a copy of the fd that was at target, or -1 if target was not
+// open.
+type savedFd struct {
+	target int
+	fd     int
+	flags  int
+}
+
+func saveFd(target int, first int) (savedFd, error) {
+	flags, err := unix.FcntlInt(uintptr(target), unix.F_GETFD, 0)
+	if err == unix.EBADF {
+		return savedFd{target: target, fd: -1}, nil
+	}
+	if err != nil {
+		return savedFd{}, err
+	}
+	fd, err := dupCloexec(target, first)
+	if err != nil {
+		return savedFd{}, err
+	}
+	return savedFd{target: target, fd: fd, flags: flags}, nil
+}
+
+func restoreFds(saved []savedFd) {
+	for i := len(saved) - 1; i >= 0; i-- {
+		if saved[i].fd < 0 {
+			_ = syscall.Close(saved[i].target)
+			continue
+		}
+		if err := dup2(saved[i].fd, saved[i].target); err == nil {
+			_, _ = unix.FcntlInt(uintptr(saved[i].target), unix.F_SETFD, saved[i].flags)
+		}
+		_ = syscall.Close(saved[i].fd)
+	}
+}
@@ -0,0 +1,30 @@
+package osutils
+
+import (
+	"errors"
+	"os"
+)
+
+var (
+	ErrExtraFilesNotSupported = errors.New("osutils: extra files not supported")
+)
+
+// execSelf cannot replace the process image on Windows, so the new process
+// is started and the caller is responsible for exiting.
+func execSelf(executable string, argv []string, env []string, extraFiles []*os.File) error {
+	if len(extraFiles) > 0 {
+		return ErrExtraFilesNotSupported
+	}
+	process, err := os.StartProcess(
+		executable,
+		argv,
+		&os.ProcAttr{
+			Env:   env,
+			Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return process.Release()
+}
@@ -0,0 +1,215 @@
+package osutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+const (
+	resumePartialSuffix   = ".partial"
+	resumeStateSuffix     = ".resume"
+	defaultCheckpointSize = 64 * 1024 * 1024
+)
+
+var (
+	// ErrSrcChanged means src changed size while it was being copied.
+	ErrSrcChanged = errors.New("osutils: src changed during the copy")
+
+	// resumeCheckpointHook is called after each checkpoint, and is swapped
+	// in tests to interrupt a copy.
+	resumeCheckpointHook = func(offset int64) error { return nil }
+)
+
+type ResumableCopyOptions struct {
+	// CheckpointSize is how much is copied between recordings of the
+	// progress, defaulting to 64MiB.
+	CheckpointSize int64
+	Throttle       *Throttle
+}
+
+// CopyFileResumable copies the regular file at src to dst such that an
+// interrupted copy can be resumed by calling it again. The data goes to
+// dst.partial, with the offset reached and the SHA-256 of the data up to
+// it recorded in dst.resume at every checkpoint. On resume, the data
+// already in dst.partial is hashed and compared with the record, and the
+// copy starts over if it differs or if src changed size or modification
+// time. dst.partial is renamed to dst once complete, and ErrSrcChanged is
+// returned instead if src shrank or grew during the copy.
+func CopyFileResumable(src string, dst string, options *ResumableCopyOptions) error {
+	if !isAbsolutePath(src) || !isAbsolutePath(dst) {
+		return ErrNotAbsolutePath
+	}
+	if options == nil {
+		options = &ResumableCopyOptions{}
+	}
+	return copyFileResumable(src, dst, options)
+}
+
+// ***** PRIVATE *****
+
+type resumeState struct {
+	SrcSize    int64     `json:"src_size"`
+	SrcModTime time.Time `json:"src_mod_time"`
+	Offset     int64     `json:"offset"`
+	SHA256     string    `json:"sha256"`
+}
+
+func copyFileResumable(src string, dst string, options *ResumableCopyOptions) (retErr error) {
+	checkpointSize := options.CheckpointSize
+	if checkpointSize <= 0 {
+		checkpointSize = defaultCheckpointSize
+	}
+	start := time.Now()
+	var n int64
+	defer func() {
+		observeOperation(MetricsOperationCopyFile, start, n, retErr)
+	}()
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	fileInfo, err := srcFile.Stat()
+	if err != nil {
+		return err
+	}
+	if !fileInfo.Mode().IsRegular() {
+		return ErrNotRegularFile
+	}
+	partialPath := dst + resumePartialSuffix
+	statePath := dst + resumeStateSuffix
+	perm, err := currentPolicy().filePerm(fileInfo.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	partialFile, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, perm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if partialFile != nil {
+			_ = partialFile.Close()
+		}
+	}()
+	hash := sha256.New()
+	offset, err := resumeOffset(statePath, partialFile, fileInfo, hash)
+	if err != nil {
+		return err
+	}
+	if _, err := partialFile.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := srcFile.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if err := partialFile.Truncate(offset); err != nil {
+		return err
+	}
+	reader := options.Throttle.Reader(srcFile)
+	writer := io.MultiWriter(partialFile, hash)
+	for offset < fileInfo.Size() {
+		copied, copyErr := io.CopyN(writer, reader, checkpointSize)
+		offset += copied
+		n += copied
+		if copyErr != nil && copyErr != io.EOF {
+			return copyErr
+		}
+		if err := partialFile.Sync(); err != nil {
+			return err
+		}
+		if err := writeResumeState(
+			statePath,
+			&resumeState{
+				SrcSize:    fileInfo.Size(),
+				SrcModTime: fileInfo.ModTime(),
+				Offset:     offset,
+				SHA256:     hex.EncodeToString(hash.Sum(nil)),
+			},
+		); err != nil {
+			return err
+		}
+		// src shrank since it was stat'ed
+		if copyErr == io.EOF {
+			break
+		}
+		if err := resumeCheckpointHook(offset); err != nil {
+			return err
+		}
+	}
+	// a short or long copy is not a copy of the src that was stat'ed, and
+	// the recorded state no longer matches so the next call starts over
+	if offset != fileInfo.Size() {
+		return ErrSrcChanged
+	}
+	if grown, err := srcFile.Read(make([]byte, 1)); grown > 0 {
+		return ErrSrcChanged
+	} else if err != nil && err != io.EOF {
+		return err
+	}
+	err = partialFile.Close()
+	partialFile = nil
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(partialPath, dst); err != nil {
+		return err
+	}
+	if err := os.Remove(statePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// resumeOffset returns where to resume from, 0 unless the recorded state
+// matches src and the data in the partial file. The data is written to hash
+// so it continues from the offset.
+func resumeOffset(statePath string, partialFile *os.File, fileInfo os.FileInfo, hash hash.Hash) (int64, error) {
+	data, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	state := &resumeState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return 0, nil
+	}
+	if state.SrcSize != fileInfo.Size() || !state.SrcModTime.Equal(fileInfo.ModTime()) || state.Offset > state.SrcSize {
+		return 0, nil
+	}
+	if _, err := io.CopyN(hash, partialFile, state.Offset); err != nil {
+		hash.Reset()
+		if err == io.EOF {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != state.SHA256 {
+		hash.Reset()
+		return 0, nil
+	}
+	return state.Offset, nil
+}
+
+func writeResumeState(statePath string, state *resumeState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(
+		statePath,
+		0644,
+		func(writer io.Writer) error {
+			_, err := writer.Write(data)
+			return err
+		},
+	)
+}
@@ -0,0 +1,100 @@
+package osutils
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestCopyFileResumable() {
+	src := filepath.Join(s.tempDir, "src")
+	dst := filepath.Join(s.tempDir, "dst")
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	require.NoError(s.T(), ioutil.WriteFile(src, data, 0600))
+
+	errInterrupted := errors.New("interrupted")
+	var offsets []int64
+	defer func(hook func(int64) error) { resumeCheckpointHook = hook }(resumeCheckpointHook)
+	resumeCheckpointHook = func(offset int64) error {
+		offsets = append(offsets, offset)
+		if offset == 3000 {
+			return errInterrupted
+		}
+		return nil
+	}
+	options := &ResumableCopyOptions{CheckpointSize: 1000}
+	require.Equal(s.T(), errInterrupted, CopyFileResumable(src, dst, options))
+	s.checkFileDoesNotExist(dst)
+	s.checkFileExists(dst + ".resume")
+
+	// resumes from the checkpoint
+	offsets = nil
+	require.NoError(s.T(), CopyFileResumable(src, dst, options))
+	require.Equal(s.T(), int64(4000), offsets[0])
+	copied, err := ioutil.ReadFile(dst)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), data, copied)
+	if runtime.GOOS != "windows" {
+		s.checkPerm(nil, dst, 0600)
+	}
+	s.checkFileDoesNotExist(dst + ".resume")
+	s.checkFileDoesNotExist(dst + ".partial")
+
+	// corrupted data already copied is detected and the copy starts over
+	dst = filepath.Join(s.tempDir, "dst2")
+	resumeCheckpointHook = func(offset int64) error {
+		if offset == 2000 {
+			return errInterrupted
+		}
+		return nil
+	}
+	require.Equal(s.T(), errInterrupted, CopyFileResumable(src, dst, options))
+	partial, err := ioutil.ReadFile(dst + ".partial")
+	require.NoError(s.T(), err)
+	partial[10] = 'x'
+	require.NoError(s.T(), ioutil.WriteFile(dst+".partial", partial, 0600))
+	offsets = nil
+	resumeCheckpointHook = func(offset int64) error {
+		offsets = append(offsets, offset)
+		return nil
+	}
+	require.NoError(s.T(), CopyFileResumable(src, dst, options))
+	require.Equal(s.T(), int64(1000), offsets[0])
+	copied, err = ioutil.ReadFile(dst)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), data, copied)
+}
+
+func (s *Suite) TestCopyFileResumableSrcChanged() {
+	src := filepath.Join(s.tempDir, "src")
+	dst := filepath.Join(s.tempDir, "dst")
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	require.NoError(s.T(), ioutil.WriteFile(src, data, 0600))
+
+	defer func(hook func(int64) error) { resumeCheckpointHook = hook }(resumeCheckpointHook)
+	resumeCheckpointHook = func(offset int64) error {
+		if offset == 2000 {
+			return os.Truncate(src, 2500)
+		}
+		return nil
+	}
+	options := &ResumableCopyOptions{CheckpointSize: 1000}
+	require.Equal(s.T(), ErrSrcChanged, CopyFileResumable(src, dst, options))
+	s.checkFileDoesNotExist(dst)
+
+	// grown since it was stat'ed
+	require.NoError(s.T(), ioutil.WriteFile(src, data, 0600))
+	resumeCheckpointHook = func(offset int64) error {
+		if offset == 9000 {
+			return ioutil.WriteFile(src, append(data, data[:500]...), 0600)
+		}
+		return nil
+	}
+	require.Equal(s.T(), ErrSrcChanged, CopyFileResumable(src, dst, options))
+	s.checkFileDoesNotExist(dst)
+}
@@ -0,0 +1,41 @@
+package osutils
+
+func GetRlimitNoFile() (soft uint64, hard uint64, err error) {
+	return getRlimitNoFile()
+}
+
+func SetRlimitNoFile(soft uint64, hard uint64) error {
+	if soft > hard {
+		return ErrInvalidRlimit
+	}
+	return setRlimitNoFile(soft, hard)
+}
+
+// RaiseFDLimit raises the soft open file limit to target, capped at the hard
+// limit, and returns the resulting soft limit. The limit is never lowered.
+func RaiseFDLimit(target uint64) (uint64, error) {
+	return raiseFDLimit(target)
+}
+
+// ***** PRIVATE *****
+
+func raiseFDLimit(target uint64) (uint64, error) {
+	soft, hard, err := getRlimitNoFile()
+	if err != nil {
+		return 0, err
+	}
+	max, err := maxFDLimit(hard)
+	if err != nil {
+		return 0, err
+	}
+	if target > max {
+		target = max
+	}
+	if target <= soft {
+		return soft, nil
+	}
+	if err := setRlimitNoFile(target, hard); err != nil {
+		return 0, err
+	}
+	return target, nil
+}
@@ -0,0 +1,19 @@
+package osutils
+
+import (
+	"syscall"
+)
+
+// maxFDLimit returns the highest soft limit that can actually be set. Darwin
+// reports an unlimited hard limit but rejects anything above
+// kern.maxfilesperproc.
+func maxFDLimit(hard uint64) (uint64, error) {
+	maxFilesPerProc, err := syscall.SysctlUint32("kern.maxfilesperproc")
+	if err != nil {
+		return 0, err
+	}
+	if uint64(maxFilesPerProc) < hard {
+		return uint64(maxFilesPerProc), nil
+	}
+	return hard, nil
+}
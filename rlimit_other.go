@@ -0,0 +1,7 @@
+//go:build unix && !darwin
+
+package osutils
+
+func maxFDLimit(hard uint64) (uint64, error) {
+	return hard, nil
+}
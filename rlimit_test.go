@@ -0,0 +1,18 @@
+package osutils
+
+import (
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestRaiseFDLimit() {
+	soft, hard, err := GetRlimitNoFile()
+	require.NoError(s.T(), err)
+	raised, err := RaiseFDLimit(soft)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), soft, raised)
+	raised, err = RaiseFDLimit(hard)
+	require.NoError(s.T(), err)
+	require.True(s.T(), raised >= soft)
+	require.True(s.T(), raised <= hard)
+	require.Equal(s.T(), ErrInvalidRlimit, SetRlimitNoFile(2, 1))
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package osutils
+
+import (
+	"syscall"
+)
+
+func getRlimitNoFile() (uint64, uint64, error) {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return 0, 0, err
+	}
+	return uint64(rlimit.Cur), uint64(rlimit.Max), nil
+}
+
+func setRlimitNoFile(soft uint64, hard uint64) error {
+	var rlimit syscall.Rlimit
+	setRlimitValue(&rlimit.Cur, soft)
+	setRlimitValue(&rlimit.Max, hard)
+	return syscall.Setrlimit(syscall.RLIMIT_NOFILE, &rlimit)
+}
+
+// setRlimitValue handles the rlimit fields being signed on some platforms.
+func setRlimitValue[T int64 | uint64](field *T, value uint64) {
+	*field = T(value)
+}
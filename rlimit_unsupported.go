@@ -0,0 +1,15 @@
+//go:build !unix
+
+package osutils
+
+func getRlimitNoFile() (uint64, uint64, error) {
+	return 0, 0, ErrNotSupported
+}
+
+func setRlimitNoFile(soft uint64, hard uint64) error {
+	return ErrNotSupported
+}
+
+func maxFDLimit(hard uint64) (uint64, error) {
+	return 0, ErrNotSupported
+}
@@ -0,0 +1,198 @@
+package osutils
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"runtime"
+	"sync"
+)
+
+// searchBinaryPeekSize is how much of a file is checked for a NUL byte,
+// the same heuristic as git and grep.
+const searchBinaryPeekSize = 8000
+
+// errSearchStopped is returned inside a search that another file already
+// stopped, and is never the one recorded.
+var errSearchStopped = errors.New("osutils: search stopped")
+
+type SearchMatch struct {
+	Path string
+	// LineNumber starts at 1.
+	LineNumber int
+	// Line is without the line ending.
+	Line string
+}
+
+type SearchOptions struct {
+	// Literal treats the pattern as a plain string rather than a regexp.
+	Literal    bool
+	IgnoreCase bool
+	// Workers is the number of files searched concurrently, or
+	// runtime.NumCPU() if not positive.
+	Workers int
+	Ignore  *Ignore
+}
+
+// SearchFiles searches the regular files under root for lines matching
+// pattern and calls fn for each match. Files that look binary are
+// skipped. Files are searched concurrently, so matches from different
+// files interleave, but fn is called from one goroutine at a time and the
+// matches of a file come in order. Returning fs.SkipAll from fn stops the
+// search without error, and any other error stops it and is returned.
+func SearchFiles(root string, pattern string, options *SearchOptions, fn func(*SearchMatch) error) error {
+	if !isAbsolutePath(root) {
+		return ErrNotAbsolutePath
+	}
+	if fn == nil {
+		return ErrNil
+	}
+	if options == nil {
+		options = &SearchOptions{}
+	}
+	if options.Literal {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if options.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return newSearcher(compiled, fn).search(root, workers, options.Ignore)
+}
+
+// ***** PRIVATE *****
+
+type searcher struct {
+	regexp   *regexp.Regexp
+	fn       func(*SearchMatch) error
+	fnLock   sync.Mutex
+	done     chan struct{}
+	doneOnce sync.Once
+	err      error
+}
+
+func newSearcher(regexp *regexp.Regexp, fn func(*SearchMatch) error) *searcher {
+	return &searcher{
+		regexp: regexp,
+		fn:     fn,
+		done:   make(chan struct{}),
+	}
+}
+
+func (s *searcher) search(root string, workers int, ignore *Ignore) error {
+	paths := make(chan string)
+	var waitGroup sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for path := range paths {
+				if s.stopped() {
+					continue
+				}
+				if err := s.searchFile(path); err != nil {
+					s.stop(err)
+				}
+			}
+		}()
+	}
+	walkFunc := func(path string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !dirEntry.Type().IsRegular() {
+			return nil
+		}
+		select {
+		case paths <- path:
+			return nil
+		case <-s.done:
+			return fs.SkipAll
+		}
+	}
+	if ignore != nil {
+		walkFunc = ignore.WalkDirFunc(walkFunc)
+	}
+	if err := WalkDir(root, walkFunc); err != nil {
+		s.stop(err)
+	}
+	close(paths)
+	waitGroup.Wait()
+	if s.err == fs.SkipAll {
+		return nil
+	}
+	return s.err
+}
+
+func (s *searcher) searchFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		// removed since the walk saw it
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+	reader := bufio.NewReaderSize(file, searchBinaryPeekSize)
+	peek, err := reader.Peek(searchBinaryPeekSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return err
+	}
+	if bytes.IndexByte(peek, 0) >= 0 {
+		return nil
+	}
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte{'\n'}), []byte{'\r'})
+			if s.regexp.Match(line) {
+				if err := s.match(&SearchMatch{Path: path, LineNumber: lineNumber, Line: string(line)}); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *searcher) match(searchMatch *SearchMatch) error {
+	s.fnLock.Lock()
+	defer s.fnLock.Unlock()
+	if s.stopped() {
+		return errSearchStopped
+	}
+	return s.fn(searchMatch)
+}
+
+func (s *searcher) stop(err error) {
+	s.doneOnce.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
+
+func (s *searcher) stopped() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,72 @@
+package osutils
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestSearchFiles() {
+	root := filepath.Join(s.tempDir, "root")
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(root, "sub"), 0755))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(root, "a.txt"), []byte("foo\nbar\r\nFoo.bar\n"), 0644))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(root, "sub", "b.txt"), []byte("no\nfoo"), 0644))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(root, "binary"), []byte("foo\x00\n"), 0644))
+	// past the default bufio buffer size but within the peek
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(root, "binary2"), append([]byte("foo\n"+strings.Repeat("x", 5000)), 0), 0644))
+
+	var matches []string
+	require.NoError(
+		s.T(),
+		SearchFiles(
+			root,
+			"foo",
+			&SearchOptions{Workers: 2},
+			func(searchMatch *SearchMatch) error {
+				rel, err := filepath.Rel(root, searchMatch.Path)
+				require.NoError(s.T(), err)
+				matches = append(matches, fmt.Sprintf("%s:%d:%s", filepath.ToSlash(rel), searchMatch.LineNumber, searchMatch.Line))
+				return nil
+			},
+		),
+	)
+	sort.Strings(matches)
+	require.Equal(s.T(), []string{"a.txt:1:foo", "sub/b.txt:2:foo"}, matches)
+
+	matches = nil
+	require.NoError(
+		s.T(),
+		SearchFiles(
+			root,
+			"foo.",
+			&SearchOptions{Literal: true, IgnoreCase: true},
+			func(searchMatch *SearchMatch) error {
+				matches = append(matches, searchMatch.Line)
+				return nil
+			},
+		),
+	)
+	require.Equal(s.T(), []string{"Foo.bar"}, matches)
+
+	count := 0
+	require.NoError(
+		s.T(),
+		SearchFiles(
+			root,
+			"o",
+			nil,
+			func(*SearchMatch) error {
+				count++
+				return fs.SkipAll
+			},
+		),
+	)
+	require.Equal(s.T(), 1, count)
+	require.Error(s.T(), SearchFiles(root, "(", nil, func(*SearchMatch) error { return nil }))
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/peter-edge/go-osutils"
+)
+
+const launchdSystemDir = "/Library/LaunchDaemons"
+
+type launchdManager struct {
+	user     bool
+	plistDir string
+	// domain is the launchctl domain target, system or gui/<uid>
+	domain string
+	run    runFunc
+}
+
+func newLaunchdManager(user bool, run runFunc) (*launchdManager, error) {
+	plistDir := launchdSystemDir
+	domain := "system"
+	if user {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		plistDir = filepath.Join(homeDir, "Library", "LaunchAgents")
+		domain = "gui/" + strconv.Itoa(os.Getuid())
+	}
+	return &launchdManager{
+		user:     user,
+		plistDir: plistDir,
+		domain:   domain,
+		run:      run,
+	}, nil
+}
+
+func (l *launchdManager) InstallUnit(ctx context.Context, name string, unitContent string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if err := osutils.MkdirAll(l.plistDir, 0755); err != nil {
+		return err
+	}
+	path := l.plistPath(name)
+	if err := osutils.WriteFileAtomic(path, []byte(unitContent), unitFilePerm); err != nil {
+		return err
+	}
+	// reload so a loaded job picks up the new definition
+	_, _ = l.run(ctx, "launchctl", "bootout", l.domain+"/"+name)
+	_, err := l.run(ctx, "launchctl", "bootstrap", l.domain, path)
+	return err
+}
+
+func (l *launchdManager) Start(ctx context.Context, name string) error {
+	return l.launchctl(ctx, name, "kickstart", l.domain+"/"+name)
+}
+
+func (l *launchdManager) Stop(ctx context.Context, name string) error {
+	return l.launchctl(ctx, name, "kill", "SIGTERM", l.domain+"/"+name)
+}
+
+func (l *launchdManager) Restart(ctx context.Context, name string) error {
+	return l.launchctl(ctx, name, "kickstart", "-k", l.domain+"/"+name)
+}
+
+func (l *launchdManager) Enable(ctx context.Context, name string) error {
+	return l.launchctl(ctx, name, "enable", l.domain+"/"+name)
+}
+
+func (l *launchdManager) Status(ctx context.Context, name string) (*Status, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	status := &Status{Name: name}
+	output, err := l.run(ctx, "launchctl", "print", l.domain+"/"+name)
+	if err != nil {
+		// not loaded
+		if exitCodeOf(err) >= 0 {
+			return status, nil
+		}
+		return nil, err
+	}
+	status.Active = strings.Contains(output, "state = running")
+	output, err = l.run(ctx, "launchctl", "print-disabled", l.domain)
+	if err != nil {
+		return nil, err
+	}
+	status.Enabled = !strings.Contains(output, `"`+name+`" => disabled`) && !strings.Contains(output, `"`+name+`" => true`)
+	return status, nil
+}
+
+func (l *launchdManager) launchctl(ctx context.Context, name string, args ...string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	_, err := l.run(ctx, append([]string{"launchctl"}, args...)...)
+	return err
+}
+
+func (l *launchdManager) plistPath(name string) string {
+	return filepath.Join(l.plistDir, name+".plist")
+}
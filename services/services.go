@@ -0,0 +1,118 @@
+// Package services installs and controls system services with systemd,
+// launchd, or the Windows service manager, driving their command line
+// tools through osutils.Command.
+package services
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/peter-edge/go-osutils"
+)
+
+var (
+	ErrInvalidName = errors.New("services: invalid name")
+)
+
+// Status is the state of a service.
+type Status struct {
+	Name string
+	// Active is whether the service is running.
+	Active bool
+	// Enabled is whether the service starts at boot, or at login for user
+	// services.
+	Enabled bool
+}
+
+// Manager controls services of one service manager. Names are as the
+// service manager knows them, a systemd unit name defaults to the
+// .service type if it has none.
+type Manager interface {
+	// InstallUnit installs or updates the definition of the service: a
+	// unit file for systemd, a property list for launchd, and the command
+	// line of the service binary on Windows.
+	InstallUnit(ctx context.Context, name string, unitContent string) error
+	Start(ctx context.Context, name string) error
+	Stop(ctx context.Context, name string) error
+	Restart(ctx context.Context, name string) error
+	Enable(ctx context.Context, name string) error
+	Status(ctx context.Context, name string) (*Status, error)
+}
+
+type ManagerOptions struct {
+	// User manages the services of the current user rather than system
+	// services. Not supported on Windows.
+	User bool
+}
+
+// NewManager returns the Manager for the service manager of this system,
+// or osutils.ErrNotSupported if there is none.
+func NewManager(options *ManagerOptions) (Manager, error) {
+	if options == nil {
+		options = &ManagerOptions{}
+	}
+	switch runtime.GOOS {
+	case "linux":
+		if _, err := exec.LookPath("systemctl"); err != nil {
+			return nil, osutils.ErrNotSupported
+		}
+		return newSystemdManager(options.User, runCommand)
+	case "darwin":
+		return newLaunchdManager(options.User, runCommand)
+	case "windows":
+		if options.User {
+			return nil, osutils.ErrNotSupported
+		}
+		return newWindowsManager(runCommand), nil
+	default:
+		return nil, osutils.ErrNotSupported
+	}
+}
+
+// ***** PRIVATE *****
+
+// runFunc runs a command and returns its stdout, swapped in tests.
+type runFunc func(ctx context.Context, args ...string) (string, error)
+
+func runCommand(ctx context.Context, args ...string) (string, error) {
+	result, err := osutils.NewCommand(args...).Run(ctx)
+	if err != nil {
+		// the tools explain failures on stderr, or stdout for sc.exe
+		if result != nil {
+			if message := strings.TrimSpace(result.Stderr + result.Stdout); message != "" {
+				return result.Stdout, &commandError{args: args, exitCode: result.ExitCode, message: message}
+			}
+		}
+		return "", err
+	}
+	return result.Stdout, nil
+}
+
+type commandError struct {
+	args     []string
+	exitCode int
+	message  string
+}
+
+func (c *commandError) Error() string {
+	return "services: " + strings.Join(c.args, " ") + ": " + c.message
+}
+
+// exitCodeOf returns the exit code of a failed command, or -1.
+func exitCodeOf(err error) int {
+	var commandError *commandError
+	if errors.As(err, &commandError) {
+		return commandError.exitCode
+	}
+	return -1
+}
+
+func validateName(name string) error {
+	if name == "" || strings.ContainsAny(name, `/\ `+"\t\r\n") || name == "." || name == ".." {
+		return ErrInvalidName
+	}
+	return nil
+}
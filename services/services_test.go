@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRunner struct {
+	calls   []string
+	outputs map[string]string
+	// sequences are outputs returned in turn, the last one repeating.
+	sequences map[string][]string
+	errs      map[string]error
+}
+
+func (f *fakeRunner) run(ctx context.Context, args ...string) (string, error) {
+	call := strings.Join(args, " ")
+	f.calls = append(f.calls, call)
+	if sequence := f.sequences[call]; len(sequence) > 0 {
+		if len(sequence) > 1 {
+			f.sequences[call] = sequence[1:]
+		}
+		return sequence[0], f.errs[call]
+	}
+	return f.outputs[call], f.errs[call]
+}
+
+func TestSystemdManager(t *testing.T) {
+	ctx := context.Background()
+	fakeRunner := &fakeRunner{
+		outputs: map[string]string{
+			"systemctl is-active app.service":  "active\n",
+			"systemctl is-enabled app.service": "disabled\n",
+		},
+		errs: map[string]error{
+			"systemctl is-enabled app.service": &commandError{exitCode: 1},
+		},
+	}
+	systemdManager, err := newSystemdManager(false, fakeRunner.run)
+	require.NoError(t, err)
+	systemdManager.unitDir = t.TempDir()
+
+	require.Equal(t, ErrInvalidName, systemdManager.InstallUnit(ctx, "../app", ""))
+	require.NoError(t, systemdManager.InstallUnit(ctx, "app", "[Service]\nExecStart=/bin/app\n"))
+	data, err := ioutil.ReadFile(filepath.Join(systemdManager.unitDir, "app.service"))
+	require.NoError(t, err)
+	require.Equal(t, "[Service]\nExecStart=/bin/app\n", string(data))
+	require.NoError(t, systemdManager.Enable(ctx, "app"))
+	require.NoError(t, systemdManager.Restart(ctx, "app.service"))
+	status, err := systemdManager.Status(ctx, "app")
+	require.NoError(t, err)
+	require.Equal(t, &Status{Name: "app.service", Active: true}, status)
+	require.Equal(
+		t,
+		[]string{
+			"systemctl daemon-reload",
+			"systemctl enable app.service",
+			"systemctl restart app.service",
+			"systemctl is-active app.service",
+			"systemctl is-enabled app.service",
+		},
+		fakeRunner.calls,
+	)
+}
+
+func TestWindowsManager(t *testing.T) {
+	ctx := context.Background()
+	fakeRunner := &fakeRunner{
+		outputs: map[string]string{
+			"sc.exe qc app": "        START_TYPE         : 2   AUTO_START\n",
+		},
+		sequences: map[string][]string{
+			"sc.exe query app": {
+				"SERVICE_NAME: app\n        STATE              : 4  RUNNING\n",
+				"SERVICE_NAME: app\n        STATE              : 3  STOP_PENDING\n",
+				"SERVICE_NAME: app\n        STATE              : 1  STOPPED\n",
+				"SERVICE_NAME: app\n        STATE              : 4  RUNNING\n",
+			},
+		},
+		errs: map[string]error{
+			"sc.exe query new": &commandError{exitCode: windowsServiceNotExist},
+		},
+	}
+	windowsManager := newWindowsManager(fakeRunner.run)
+	windowsManager.stopPollInterval = time.Millisecond
+	require.NoError(t, windowsManager.InstallUnit(ctx, "new", `C:\app.exe`))
+	require.NoError(t, windowsManager.Restart(ctx, "app"))
+	status, err := windowsManager.Status(ctx, "app")
+	require.NoError(t, err)
+	require.Equal(t, &Status{Name: "app", Active: true, Enabled: true}, status)
+	require.Equal(
+		t,
+		[]string{
+			"sc.exe query new",
+			`sc.exe create new binPath= C:\app.exe start= demand`,
+			"sc.exe query app",
+			"sc.exe qc app",
+			"sc.exe stop app",
+			"sc.exe query app",
+			"sc.exe query app",
+			"sc.exe start app",
+			"sc.exe query app",
+			"sc.exe qc app",
+		},
+		fakeRunner.calls,
+	)
+}
+
+func TestWindowsManagerRestartStuck(t *testing.T) {
+	fakeRunner := &fakeRunner{
+		sequences: map[string][]string{
+			"sc.exe query app": {
+				"SERVICE_NAME: app\n        STATE              : 4  RUNNING\n",
+				"SERVICE_NAME: app\n        STATE              : 3  STOP_PENDING\n",
+			},
+		},
+	}
+	windowsManager := newWindowsManager(fakeRunner.run)
+	windowsManager.stopPollInterval = time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, windowsManager.Restart(ctx, "app"))
+	require.NotContains(t, fakeRunner.calls, "sc.exe start app")
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/peter-edge/go-osutils"
+)
+
+const (
+	systemdSystemUnitDir = "/etc/systemd/system"
+	unitFilePerm         = 0644
+)
+
+type systemdManager struct {
+	user    bool
+	unitDir string
+	run     runFunc
+}
+
+func newSystemdManager(user bool, run runFunc) (*systemdManager, error) {
+	unitDir := systemdSystemUnitDir
+	if user {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		unitDir = filepath.Join(configDir, "systemd", "user")
+	}
+	return &systemdManager{
+		user:    user,
+		unitDir: unitDir,
+		run:     run,
+	}, nil
+}
+
+func (s *systemdManager) InstallUnit(ctx context.Context, name string, unitContent string) error {
+	unit, err := systemdUnitName(name)
+	if err != nil {
+		return err
+	}
+	if err := osutils.MkdirAll(s.unitDir, 0755); err != nil {
+		return err
+	}
+	if err := osutils.WriteFileAtomic(filepath.Join(s.unitDir, unit), []byte(unitContent), unitFilePerm); err != nil {
+		return err
+	}
+	return s.systemctl(ctx, "daemon-reload")
+}
+
+func (s *systemdManager) Start(ctx context.Context, name string) error {
+	return s.unitCommand(ctx, "start", name)
+}
+
+func (s *systemdManager) Stop(ctx context.Context, name string) error {
+	return s.unitCommand(ctx, "stop", name)
+}
+
+func (s *systemdManager) Restart(ctx context.Context, name string) error {
+	return s.unitCommand(ctx, "restart", name)
+}
+
+func (s *systemdManager) Enable(ctx context.Context, name string) error {
+	return s.unitCommand(ctx, "enable", name)
+}
+
+func (s *systemdManager) Status(ctx context.Context, name string) (*Status, error) {
+	unit, err := systemdUnitName(name)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{Name: unit}
+	// is-active and is-enabled answer with their exit code
+	output, err := s.run(ctx, s.args("is-active", unit)...)
+	if err != nil && exitCodeOf(err) < 0 {
+		return nil, err
+	}
+	status.Active = strings.TrimSpace(output) == "active"
+	output, err = s.run(ctx, s.args("is-enabled", unit)...)
+	if err != nil && exitCodeOf(err) < 0 {
+		return nil, err
+	}
+	status.Enabled = strings.TrimSpace(output) == "enabled"
+	return status, nil
+}
+
+func (s *systemdManager) unitCommand(ctx context.Context, command string, name string) error {
+	unit, err := systemdUnitName(name)
+	if err != nil {
+		return err
+	}
+	return s.systemctl(ctx, command, unit)
+}
+
+func (s *systemdManager) systemctl(ctx context.Context, args ...string) error {
+	_, err := s.run(ctx, s.args(args...)...)
+	return err
+}
+
+func (s *systemdManager) args(args ...string) []string {
+	if s.user {
+		return append([]string{"systemctl", "--user"}, args...)
+	}
+	return append([]string{"systemctl"}, args...)
+}
+
+// systemdUnitName adds the .service type to name if it has none.
+func systemdUnitName(name string) (string, error) {
+	if err := validateName(name); err != nil {
+		return "", err
+	}
+	if filepath.Ext(name) == "" {
+		return name + ".service", nil
+	}
+	return name, nil
+}
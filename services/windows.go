@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	// windowsServiceNotExist is the sc.exe exit code for an unknown service.
+	windowsServiceNotExist = 1060
+	// windowsStopPollInterval is how often Restart queries a stopping
+	// service.
+	windowsStopPollInterval = 250 * time.Millisecond
+)
+
+type windowsManager struct {
+	run              runFunc
+	stopPollInterval time.Duration
+}
+
+func newWindowsManager(run runFunc) *windowsManager {
+	return &windowsManager{
+		run:              run,
+		stopPollInterval: windowsStopPollInterval,
+	}
+}
+
+// InstallUnit creates the service with unitContent as its command line,
+// or updates the command line of an existing service.
+func (w *windowsManager) InstallUnit(ctx context.Context, name string, unitContent string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	_, err := w.run(ctx, "sc.exe", "query", name)
+	switch {
+	case err == nil:
+		_, err = w.run(ctx, "sc.exe", "config", name, "binPath=", unitContent)
+	case exitCodeOf(err) == windowsServiceNotExist:
+		_, err = w.run(ctx, "sc.exe", "create", name, "binPath=", unitContent, "start=", "demand")
+	}
+	return err
+}
+
+func (w *windowsManager) Start(ctx context.Context, name string) error {
+	return w.sc(ctx, name, "start")
+}
+
+func (w *windowsManager) Stop(ctx context.Context, name string) error {
+	return w.sc(ctx, name, "stop")
+}
+
+// Restart stops the service if it is running and starts it again. sc.exe
+// stop returns as soon as the stop is requested, so the service is polled
+// until it is STOPPED, or until ctx is done.
+func (w *windowsManager) Restart(ctx context.Context, name string) error {
+	status, err := w.Status(ctx, name)
+	if err != nil {
+		return err
+	}
+	if status.Active {
+		if err := w.Stop(ctx, name); err != nil {
+			return err
+		}
+		if err := w.waitStopped(ctx, name); err != nil {
+			return err
+		}
+	}
+	return w.Start(ctx, name)
+}
+
+func (w *windowsManager) Enable(ctx context.Context, name string) error {
+	return w.sc(ctx, name, "config", "start=", "auto")
+}
+
+func (w *windowsManager) Status(ctx context.Context, name string) (*Status, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	output, err := w.run(ctx, "sc.exe", "query", name)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{
+		Name:   name,
+		Active: windowsField(output, "STATE") == "RUNNING",
+	}
+	output, err = w.run(ctx, "sc.exe", "qc", name)
+	if err != nil {
+		return nil, err
+	}
+	status.Enabled = windowsField(output, "START_TYPE") == "AUTO_START"
+	return status, nil
+}
+
+func (w *windowsManager) waitStopped(ctx context.Context, name string) error {
+	for {
+		output, err := w.run(ctx, "sc.exe", "query", name)
+		if err != nil {
+			return err
+		}
+		if windowsField(output, "STATE") == "STOPPED" {
+			return nil
+		}
+		timer := time.NewTimer(w.stopPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (w *windowsManager) sc(ctx context.Context, name string, command string, args ...string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	_, err := w.run(ctx, append([]string{"sc.exe", command, name}, args...)...)
+	return err
+}
+
+// windowsField returns the symbolic value of a field of sc.exe output such
+// as "        STATE              : 4  RUNNING".
+func windowsField(output string, key string) string {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 4 && fields[0] == key && fields[1] == ":" {
+			return fields[3]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package osutils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// RunWithSharedStdin runs cmds concurrently with each of them reading a
+// copy of stdin, and waits for all of them. The results are in the order
+// of cmds, with a nil Result for a command that failed to start. A command
+// that exits without reading all of stdin stops receiving it without
+// affecting the others. The returned error joins the errors of the commands
+// that failed, with errors.Join. The Cmds themselves are not modified and
+// must not have a stdin of their own.
+func RunWithSharedStdin(stdin io.Reader, cmds []*Cmd) ([]*Result, error) {
+	return runWithSharedStdin(context.Background(), stdin, cmds)
+}
+
+// ***** PRIVATE *****
+
+func runWithSharedStdin(ctx context.Context, stdin io.Reader, cmds []*Cmd) ([]*Result, error) {
+	if stdin == nil || cmds == nil {
+		return nil, ErrNil
+	}
+	for _, cmd := range cmds {
+		if cmd == nil {
+			return nil, ErrNil
+		}
+		if cmd.Stdin != nil || cmd.StdinString != "" || cmd.StdinBytes != nil || cmd.StdinFile != "" {
+			return nil, ErrMultipleInputs
+		}
+	}
+	readers := make([]*io.PipeReader, len(cmds))
+	writers := make([]*io.PipeWriter, len(cmds))
+	for i := range cmds {
+		readers[i], writers[i] = io.Pipe()
+	}
+	go broadcast(stdin, writers)
+	results := make([]*Result, len(cmds))
+	errs := make([]error, len(cmds))
+	var waitGroup sync.WaitGroup
+	for i, cmd := range cmds {
+		sharedCmd := *cmd
+		sharedCmd.Stdin = readers[i]
+		waitGroup.Add(1)
+		go func(i int) {
+			defer waitGroup.Done()
+			results[i], errs[i] = runCmd(ctx, &sharedCmd)
+			// stops the broadcast to this command if it did not read it all
+			_ = readers[i].Close()
+		}(i)
+	}
+	waitGroup.Wait()
+	return results, errors.Join(errs...)
+}
+
+// broadcast copies reader to all writers, dropping a writer as soon as a
+// write to it fails, and closes the writers at the end of reader.
+func broadcast(reader io.Reader, writers []*io.PipeWriter) {
+	live := append([]*io.PipeWriter{}, writers...)
+	buffer := make([]byte, 32*1024)
+	for len(live) > 0 {
+		n, err := reader.Read(buffer)
+		if n > 0 {
+			kept := live[:0]
+			for _, writer := range live {
+				if _, writeErr := writer.Write(buffer[:n]); writeErr == nil {
+					kept = append(kept, writer)
+				}
+			}
+			live = kept
+		}
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			for _, writer := range writers {
+				_ = writer.CloseWithError(err)
+			}
+			return
+		}
+	}
+}
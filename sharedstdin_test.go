@@ -0,0 +1,32 @@
+package osutils
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestRunWithSharedStdin() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sh not available on windows")
+	}
+	input := strings.Repeat("line\n", 20000)
+	cmds := []*Cmd{
+		{Args: []string{"wc", "-l"}},
+		{Args: []string{"head", "-n", "1"}},
+		{Args: []string{"sh", "-c", "cat >/dev/null; exit 3"}},
+	}
+	results, err := RunWithSharedStdin(strings.NewReader(input), cmds)
+	require.Error(s.T(), err)
+	require.Len(s.T(), results, 3)
+	require.Equal(s.T(), "20000", strings.TrimSpace(results[0].Stdout))
+	require.Equal(s.T(), "line\n", results[1].Stdout)
+	require.Equal(s.T(), 3, results[2].ExitCode)
+	require.Nil(s.T(), cmds[0].Stdin)
+
+	_, err = RunWithSharedStdin(strings.NewReader(""), []*Cmd{{Args: []string{"true"}, StdinString: "x"}})
+	require.Equal(s.T(), ErrMultipleInputs, err)
+	_, err = RunWithSharedStdin(nil, cmds)
+	require.Equal(s.T(), ErrNil, err)
+}
@@ -0,0 +1,238 @@
+package osutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TestingT is the part of *testing.T used by AssertUnchanged.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+type SnapshotEntry struct {
+	// Mode has the type and permission bits.
+	Mode os.FileMode
+	// Hash is the hex SHA-256 of a regular file.
+	Hash string
+	// LinkTarget is the target of a symlink.
+	LinkTarget string
+}
+
+// Snapshot is the state of a tree, keyed by slash-separated paths relative
+// to its root, which is not itself included. The content of regular files
+// is kept in memory so the tree can be restored, so snapshots are meant
+// for the small trees of tests.
+type Snapshot struct {
+	Root     string
+	Entries  map[string]*SnapshotEntry
+	contents map[string][]byte
+}
+
+// SnapshotTree captures the structure, modes, symlinks and file contents
+// of the tree at root.
+func SnapshotTree(root string) (*Snapshot, error) {
+	if !isAbsolutePath(root) {
+		return nil, ErrNotAbsolutePath
+	}
+	return snapshotTree(filepath.Clean(root), true)
+}
+
+// Changes returns what changed in the tree since the snapshot. Unlike a
+// ChangeTracker, dirs, symlinks and permission changes count as well.
+func (s *Snapshot) Changes() (*Changes, error) {
+	current, err := snapshotTree(s.Root, false)
+	if err != nil {
+		return nil, err
+	}
+	changes := &Changes{}
+	for path, entry := range current.Entries {
+		previousEntry, ok := s.Entries[path]
+		if !ok {
+			changes.Added = append(changes.Added, path)
+		} else if *previousEntry != *entry {
+			changes.Modified = append(changes.Modified, path)
+		}
+	}
+	for path := range s.Entries {
+		if _, ok := current.Entries[path]; !ok {
+			changes.Removed = append(changes.Removed, path)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Modified)
+	sort.Strings(changes.Removed)
+	return changes, nil
+}
+
+// RestoreTree puts the tree back the way it was when snapshot was taken,
+// removing what was added since. Entries other than regular files, dirs
+// and symlinks are not recreated.
+func RestoreTree(snapshot *Snapshot) error {
+	if snapshot == nil {
+		return ErrNil
+	}
+	current, err := snapshotTree(snapshot.Root, false)
+	if err != nil {
+		return err
+	}
+	for _, path := range sortedSnapshotPaths(current) {
+		entry := current.Entries[path]
+		if previousEntry, ok := snapshot.Entries[path]; ok && previousEntry.Mode.Type() == entry.Mode.Type() {
+			continue
+		}
+		if err := removeAll(snapshot.absolutePath(path)); err != nil {
+			return err
+		}
+	}
+	paths := sortedSnapshotPaths(snapshot)
+	for _, path := range paths {
+		if err := snapshot.restoreEntry(path, current.Entries[path]); err != nil {
+			return err
+		}
+	}
+	// dir permissions last, as they may not allow the writes above
+	for i := len(paths) - 1; i >= 0; i-- {
+		if entry := snapshot.Entries[paths[i]]; entry.Mode.IsDir() {
+			if err := os.Chmod(snapshot.absolutePath(paths[i]), entry.Mode.Perm()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AssertUnchanged reports an error to t and returns false if the tree
+// changed since snapshot.
+func AssertUnchanged(t TestingT, snapshot *Snapshot) bool {
+	changes, err := snapshot.Changes()
+	if err != nil {
+		t.Errorf("osutils: snapshot of %s: %v", snapshot.Root, err)
+		return false
+	}
+	if !changes.Empty() {
+		t.Errorf(
+			"osutils: tree %s changed: added %v, modified %v, removed %v",
+			snapshot.Root,
+			changes.Added,
+			changes.Modified,
+			changes.Removed,
+		)
+		return false
+	}
+	return true
+}
+
+// ***** PRIVATE *****
+
+func snapshotTree(root string, keepContents bool) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		Root:     root,
+		Entries:  make(map[string]*SnapshotEntry),
+		contents: make(map[string][]byte),
+	}
+	if err := filepath.WalkDir(
+		root,
+		func(path string, dirEntry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path == root {
+				return nil
+			}
+			relativePath, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			relativePath = filepath.ToSlash(relativePath)
+			info, err := dirEntry.Info()
+			if err != nil {
+				return err
+			}
+			entry := &SnapshotEntry{Mode: info.Mode().Type() | info.Mode().Perm()}
+			switch {
+			case info.Mode().IsRegular():
+				data, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				sum := sha256.Sum256(data)
+				entry.Hash = hex.EncodeToString(sum[:])
+				if keepContents {
+					snapshot.contents[relativePath] = data
+				}
+			case info.Mode()&os.ModeSymlink != 0:
+				if entry.LinkTarget, err = os.Readlink(path); err != nil {
+					return err
+				}
+			}
+			snapshot.Entries[relativePath] = entry
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// restoreEntry restores path given its current entry, nil if it does not
+// exist now.
+func (s *Snapshot) restoreEntry(path string, current *SnapshotEntry) error {
+	entry := s.Entries[path]
+	absolutePath := s.absolutePath(path)
+	switch {
+	case entry.Mode.IsDir():
+		if current == nil || !current.Mode.IsDir() {
+			return os.Mkdir(absolutePath, 0700)
+		}
+		return nil
+	case entry.Mode.IsRegular():
+		if current == nil || current.Hash != entry.Hash || !current.Mode.IsRegular() {
+			data, ok := s.contents[path]
+			if !ok {
+				return ErrNil
+			}
+			if err := ioutil.WriteFile(absolutePath, data, entry.Mode.Perm()); err != nil {
+				return err
+			}
+		}
+		return os.Chmod(absolutePath, entry.Mode.Perm())
+	case entry.Mode&os.ModeSymlink != 0:
+		if current != nil && current.LinkTarget == entry.LinkTarget && current.Mode&os.ModeSymlink != 0 {
+			return nil
+		}
+		if err := os.Remove(absolutePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return os.Symlink(entry.LinkTarget, absolutePath)
+	default:
+		return nil
+	}
+}
+
+func (s *Snapshot) absolutePath(path string) string {
+	return filepath.Join(s.Root, filepath.FromSlash(path))
+}
+
+// sortedSnapshotPaths returns the paths of snapshot with parents before
+// their children.
+func sortedSnapshotPaths(snapshot *Snapshot) []string {
+	paths := make([]string, 0, len(snapshot.Entries))
+	for path := range snapshot.Entries {
+		paths = append(paths, path)
+	}
+	sort.Slice(
+		paths,
+		func(i int, j int) bool {
+			return strings.Count(paths[i], "/") < strings.Count(paths[j], "/") ||
+				(strings.Count(paths[i], "/") == strings.Count(paths[j], "/") && paths[i] < paths[j])
+		},
+	)
+	return paths
+}
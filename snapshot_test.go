@@ -0,0 +1,50 @@
+package osutils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (s *Suite) TestSnapshotTree() {
+	src := s.writeCopyDirSrc()
+	snapshot, err := SnapshotTree(src)
+	require.NoError(s.T(), err)
+	require.True(s.T(), AssertUnchanged(s.T(), snapshot))
+
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(src, "a", "1"), []byte("changed"), 0600))
+	require.NoError(s.T(), os.Chmod(filepath.Join(src, "b", "2"), 0600))
+	require.NoError(s.T(), os.RemoveAll(filepath.Join(src, "link")))
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(src, "c", "d"), 0755))
+	changes, err := snapshot.Changes()
+	require.NoError(s.T(), err)
+	require.Equal(
+		s.T(),
+		&Changes{
+			Added:    []string{"c", "c/d"},
+			Modified: []string{"a/1", "b/2"},
+			Removed:  []string{"link"},
+		},
+		changes,
+	)
+	recordingT := &recordingT{}
+	require.False(s.T(), AssertUnchanged(recordingT, snapshot))
+	require.Len(s.T(), recordingT.errors, 1)
+
+	require.NoError(s.T(), RestoreTree(snapshot))
+	require.True(s.T(), AssertUnchanged(s.T(), snapshot))
+	data, err := ioutil.ReadFile(filepath.Join(src, "a", "1"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "1", string(data))
+}
@@ -0,0 +1,70 @@
+//go:build !plan9
+
+package osutils
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	privateSocketDirPrefix = ".sock"
+	privateSocketName      = "s"
+)
+
+func mkSocketPath(absolutePath string, perm os.FileMode) error {
+	listener, err := listenUnixPrivate(absolutePath, perm)
+	if err != nil {
+		return err
+	}
+	listener.SetUnlinkOnClose(false)
+	return listener.Close()
+}
+
+// listenUnixPrivate binds the socket in a private temp dir next to
+// absolutePath, where nobody else can connect to it, and links it into
+// place only once it has perm. Chmod rather than change the umask, which is
+// process-wide. The temp name is gone once linked, so the listener cannot
+// remove the socket when closed.
+func listenUnixPrivate(absolutePath string, perm os.FileMode) (*net.UnixListener, error) {
+	if runtime.GOOS == "windows" {
+		// chmod only sets the read-only bit on windows, binding in a
+		// private dir would not make the socket any less open
+		listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: absolutePath, Net: "unix"})
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(absolutePath, perm.Perm()); err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+		return listener, nil
+	}
+	tempDir, err := os.MkdirTemp(filepath.Dir(absolutePath), privateSocketDirPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+	tempPath := filepath.Join(tempDir, privateSocketName)
+	if err := validateUnixSocketPath(tempPath); err != nil {
+		return nil, err
+	}
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: tempPath, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	listener.SetUnlinkOnClose(false)
+	if err := os.Chmod(tempPath, perm.Perm()); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	// link rather than rename so a socket created at absolutePath in the
+	// meantime is not replaced
+	if err := os.Link(tempPath, absolutePath); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
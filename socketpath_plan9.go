@@ -0,0 +1,16 @@
+package osutils
+
+import (
+	"net"
+	"os"
+)
+
+// mkSocketPath is not supported, there are no unix sockets on Plan 9.
+func mkSocketPath(absolutePath string, perm os.FileMode) error {
+	return ErrNotSupported
+}
+
+// listenUnixPrivate is not supported, there are no unix sockets on Plan 9.
+func listenUnixPrivate(absolutePath string, perm os.FileMode) (*net.UnixListener, error) {
+	return nil, ErrNotSupported
+}
@@ -0,0 +1,195 @@
+package osutils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satori/go.uuid"
+)
+
+const (
+	spoolCurrentName = "current"
+	spoolConsumeName = "consume"
+	spoolBatchPrefix = "batch-"
+	spoolHeaderSize  = 4
+)
+
+var (
+	ErrInvalidSpoolRecord = errors.New("osutils: invalid spool record")
+
+	// spoolWrite writes an append, and is swapped in tests to fail part
+	// way through.
+	spoolWrite = func(file *os.File, data []byte) (int, error) { return file.Write(data) }
+)
+
+// Spool is a directory that many processes append records to. Appends go
+// to one file under an exclusive flock, and a consumer atomically renames
+// that file away to take everything written so far as a batch. Compared to
+// DirQueue, a record costs one write instead of a file.
+type Spool struct {
+	dir string
+}
+
+func OpenSpool(absoluteDir string) (*Spool, error) {
+	if !isAbsolutePath(absoluteDir) {
+		return nil, ErrNotAbsolutePath
+	}
+	if err := os.MkdirAll(absoluteDir, 0700); err != nil {
+		return nil, err
+	}
+	return &Spool{filepath.Clean(absoluteDir)}, nil
+}
+
+// Append appends the records in a single write, so they are consumed
+// together. A failed write is truncated away, so none of the records are
+// appended.
+func (s *Spool) Append(records ...[]byte) (retErr error) {
+	var buffer bytes.Buffer
+	header := make([]byte, spoolHeaderSize)
+	for _, record := range records {
+		binary.BigEndian.PutUint32(header, uint32(len(record)))
+		buffer.Write(header)
+		buffer.Write(record)
+	}
+	currentPath := s.currentPath()
+	fileLock, err := lockFile(lockPathFor(currentPath), true, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := fileLock.Unlock(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	file, err := os.OpenFile(currentPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	fileInfo, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	if _, err := spoolWrite(file, buffer.Bytes()); err != nil {
+		// a partial record would make the whole batch unparseable, cut it
+		// off while still holding the lock
+		_ = file.Truncate(fileInfo.Size())
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Consume calls fn with each pending batch of records, oldest first, and
+// removes a batch once fn returns nil. If fn fails, Consume stops and the
+// batch is kept for the next Consume. Only one Consume runs at a time
+// across processes. It returns the number of records consumed.
+func (s *Spool) Consume(fn func(records [][]byte) error) (_ int, retErr error) {
+	fileLock, err := lockFile(lockPathFor(filepath.Join(s.dir, spoolConsumeName)), true, true)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := fileLock.Unlock(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	if err := s.rotate(); err != nil {
+		return 0, err
+	}
+	batchPaths, err := s.batchPaths()
+	if err != nil {
+		return 0, err
+	}
+	consumed := 0
+	for _, batchPath := range batchPaths {
+		data, err := ioutil.ReadFile(batchPath)
+		if err != nil {
+			return consumed, err
+		}
+		records, err := parseSpoolRecords(data)
+		if err != nil {
+			return consumed, err
+		}
+		if len(records) > 0 {
+			if err := fn(records); err != nil {
+				return consumed, err
+			}
+		}
+		if err := os.Remove(batchPath); err != nil {
+			return consumed, err
+		}
+		consumed += len(records)
+	}
+	return consumed, nil
+}
+
+// ***** PRIVATE *****
+
+func (s *Spool) currentPath() string {
+	return filepath.Join(s.dir, spoolCurrentName)
+}
+
+// rotate moves the current file to a new batch under the append lock.
+func (s *Spool) rotate() (retErr error) {
+	currentPath := s.currentPath()
+	fileLock, err := lockFile(lockPathFor(currentPath), true, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := fileLock.Unlock(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	name := spoolBatchPrefix + strconv.FormatInt(time.Now().UnixNano(), 10) + "-" + uuid.NewV4().String()
+	if err := os.Rename(currentPath, filepath.Join(s.dir, name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *Spool) batchPaths() ([]string, error) {
+	dir, err := os.Open(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+	var batchPaths []string
+	for _, name := range names {
+		if strings.HasPrefix(name, spoolBatchPrefix) {
+			batchPaths = append(batchPaths, filepath.Join(s.dir, name))
+		}
+	}
+	sort.Strings(batchPaths)
+	return batchPaths, nil
+}
+
+func parseSpoolRecords(data []byte) ([][]byte, error) {
+	var records [][]byte
+	for len(data) > 0 {
+		if len(data) < spoolHeaderSize {
+			return nil, ErrInvalidSpoolRecord
+		}
+		size := binary.BigEndian.Uint32(data)
+		data = data[spoolHeaderSize:]
+		if uint64(len(data)) < uint64(size) {
+			return nil, ErrInvalidSpoolRecord
+		}
+		records = append(records, data[:size])
+		data = data[size:]
+	}
+	return records, nil
+}
@@ -0,0 +1,83 @@
+package osutils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestSpool() {
+	spool, err := OpenSpool(filepath.Join(s.tempDir, "spool"))
+	require.NoError(s.T(), err)
+	var waitGroup sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		waitGroup.Add(1)
+		go func(i int) {
+			defer waitGroup.Done()
+			require.NoError(s.T(), spool.Append([]byte(fmt.Sprintf("record-%d", i)), nil))
+		}(i)
+	}
+	waitGroup.Wait()
+
+	failErr := errors.New("fail")
+	consumed, err := spool.Consume(func(records [][]byte) error {
+		return failErr
+	})
+	require.Equal(s.T(), failErr, err)
+	require.Equal(s.T(), 0, consumed)
+	require.NoError(s.T(), spool.Append([]byte("last")))
+
+	seen := make(map[string]bool)
+	var last string
+	consumed, err = spool.Consume(func(records [][]byte) error {
+		for _, record := range records {
+			seen[string(record)] = true
+			last = string(record)
+		}
+		return nil
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 21, consumed)
+	require.Equal(s.T(), "last", last)
+	require.Len(s.T(), seen, 12)
+
+	consumed, err = spool.Consume(func(records [][]byte) error {
+		return failErr
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 0, consumed)
+}
+
+func (s *Suite) TestSpoolPartialAppend() {
+	spool, err := OpenSpool(filepath.Join(s.tempDir, "spool"))
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), spool.Append([]byte("first")))
+
+	errNoSpace := errors.New("no space")
+	defer func(write func(*os.File, []byte) (int, error)) { spoolWrite = write }(spoolWrite)
+	spoolWrite = func(file *os.File, data []byte) (int, error) {
+		n, err := file.Write(data[:len(data)/2])
+		if err != nil {
+			return n, err
+		}
+		return n, errNoSpace
+	}
+	require.Equal(s.T(), errNoSpace, spool.Append([]byte("lost"), []byte("also lost")))
+	spoolWrite = func(file *os.File, data []byte) (int, error) { return file.Write(data) }
+	require.NoError(s.T(), spool.Append([]byte("last")))
+
+	var seen []string
+	consumed, err := spool.Consume(func(records [][]byte) error {
+		for _, record := range records {
+			seen = append(seen, string(record))
+		}
+		return nil
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 2, consumed)
+	require.Equal(s.T(), []string{"first", "last"}, seen)
+}
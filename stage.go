@@ -0,0 +1,165 @@
+package osutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+var (
+	ErrStageClosed      = errors.New("osutils: stage already committed or discarded")
+	ErrStageNotVerified = errors.New("osutils: stage not verified")
+)
+
+// Stage is a file written to a staging directory so it can be verified
+// before it is promoted to its destination. Use a staging directory on
+// the same filesystem as the destination so Commit is a rename.
+type Stage struct {
+	path     string
+	size     int64
+	digest   string
+	verified bool
+	closed   bool
+}
+
+// StageReader writes reader to a new file in stagingDir.
+func StageReader(stagingDir string, reader io.Reader, perm os.FileMode) (*Stage, error) {
+	if !isAbsolutePath(stagingDir) {
+		return nil, ErrNotAbsolutePath
+	}
+	if reader == nil {
+		return nil, ErrNil
+	}
+	return stageReader(stagingDir, reader, perm)
+}
+
+// StageFile copies src to a new file in stagingDir, keeping its permission
+// bits.
+func StageFile(stagingDir string, src string) (*Stage, error) {
+	if !isAbsolutePath(stagingDir) || !isAbsolutePath(src) {
+		return nil, ErrNotAbsolutePath
+	}
+	file, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !fileInfo.Mode().IsRegular() {
+		return nil, ErrNotRegularFile
+	}
+	return stageReader(stagingDir, file, fileInfo.Mode().Perm())
+}
+
+func (s *Stage) Path() string {
+	return s.path
+}
+
+func (s *Stage) Size() int64 {
+	return s.size
+}
+
+// Digest is the hex SHA-256 of the staged data.
+func (s *Stage) Digest() string {
+	return s.digest
+}
+
+// Verify checks the staged data against a hex SHA-256 checksum. The
+// staged file is kept on mismatch, call Discard to remove it.
+func (s *Stage) Verify(checksum string) error {
+	if s.closed {
+		return ErrStageClosed
+	}
+	s.verified = strings.EqualFold(s.digest, checksum)
+	if !s.verified {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// Commit moves the staged file to dst, replacing it atomically if it is on
+// the same filesystem. It fails with ErrStageNotVerified unless the last
+// Verify succeeded.
+func (s *Stage) Commit(dst string) error {
+	if !isAbsolutePath(dst) {
+		return ErrNotAbsolutePath
+	}
+	if s.closed {
+		return ErrStageClosed
+	}
+	if !s.verified {
+		return ErrStageNotVerified
+	}
+	return s.commit(dst)
+}
+
+// CommitUnverified is Commit for staged data there is no checksum for.
+func (s *Stage) CommitUnverified(dst string) error {
+	if !isAbsolutePath(dst) {
+		return ErrNotAbsolutePath
+	}
+	if s.closed {
+		return ErrStageClosed
+	}
+	return s.commit(dst)
+}
+
+func (s *Stage) Discard() error {
+	if s.closed {
+		return ErrStageClosed
+	}
+	s.closed = true
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ***** PRIVATE *****
+
+func (s *Stage) commit(dst string) error {
+	if err := moveFile(s.path, dst); err != nil {
+		return err
+	}
+	s.closed = true
+	return nil
+}
+
+func stageReader(stagingDir string, reader io.Reader, perm os.FileMode) (_ *Stage, retErr error) {
+	file, err := ioutil.TempFile(stagingDir, ".osutils-stage-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}
+	}()
+	hash := sha256.New()
+	size, err := io.Copy(file, io.TeeReader(reader, hash))
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Chmod(perm); err != nil {
+		return nil, err
+	}
+	if err := file.Sync(); err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	return &Stage{
+		path:   file.Name(),
+		size:   size,
+		digest: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
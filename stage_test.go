@@ -0,0 +1,49 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestStage() {
+	stagingDir := filepath.Join(s.tempDir, "staging")
+	require.NoError(s.T(), os.Mkdir(stagingDir, 0755))
+	dst := filepath.Join(s.tempDir, "dst")
+	require.NoError(s.T(), ioutil.WriteFile(dst, []byte("old"), 0644))
+
+	stage, err := StageReader(stagingDir, strings.NewReader("hello"), 0600)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(5), stage.Size())
+	require.Equal(s.T(), ErrStageNotVerified, stage.Commit(dst))
+	require.Equal(s.T(), ErrChecksumMismatch, stage.Verify("00"))
+	require.Equal(s.T(), ErrStageNotVerified, stage.Commit(dst))
+	require.NoError(s.T(), stage.Verify("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"))
+	require.NoError(s.T(), stage.Commit(dst))
+	require.Equal(s.T(), ErrStageClosed, stage.Discard())
+	s.checkFileDoesNotExist(stage.Path())
+	data, err := ioutil.ReadFile(dst)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "hello", string(data))
+	info, err := os.Stat(dst)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), os.FileMode(0600), info.Mode().Perm())
+
+	stage, err = StageFile(stagingDir, dst)
+	require.NoError(s.T(), err)
+	s.checkFileExists(stage.Path())
+	require.NoError(s.T(), stage.CommitUnverified(filepath.Join(s.tempDir, "unverified")))
+	s.checkFileExists(filepath.Join(s.tempDir, "unverified"))
+
+	stage, err = StageFile(stagingDir, dst)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), stage.Discard())
+	s.checkFileDoesNotExist(stage.Path())
+	require.Equal(s.T(), ErrStageClosed, stage.Commit(dst))
+	entries, err := ioutil.ReadDir(stagingDir)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), entries)
+}
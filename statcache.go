@@ -0,0 +1,102 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// StatCache caches the results of stat calls, including that a path does
+// not exist, for up to a TTL. Changes made outside of what invalidates the
+// cache are seen once entries expire. It is safe for concurrent use, and
+// is used by a Dir through WithStatCache.
+type StatCache struct {
+	ttl     time.Duration
+	lock    sync.RWMutex
+	entries map[string]*statCacheEntry
+	// generation is bumped by every invalidation, so a stat that raced
+	// with one does not cache what it saw before it
+	generation uint64
+}
+
+func NewStatCache(ttl time.Duration) *StatCache {
+	return &StatCache{
+		ttl:     ttl,
+		entries: make(map[string]*statCacheEntry),
+	}
+}
+
+// Stat is like os.Stat, but returns a nil os.FileInfo and no error if the
+// path does not exist.
+func (c *StatCache) Stat(absolutePath string) (os.FileInfo, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	return c.stat(filepath.Clean(absolutePath))
+}
+
+// Invalidate drops absolutePath and everything below it.
+func (c *StatCache) Invalidate(absolutePath string) {
+	path := filepath.Clean(absolutePath)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.generation++
+	for entryPath := range c.entries {
+		if isWithin(path, entryPath) {
+			delete(c.entries, entryPath)
+		}
+	}
+}
+
+func (c *StatCache) InvalidateAll() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.generation++
+	c.entries = make(map[string]*statCacheEntry)
+}
+
+// ***** PRIVATE *****
+
+var (
+	// statCacheStat is swapped in tests to invalidate while a stat is in
+	// progress.
+	statCacheStat = stat
+)
+
+type statCacheEntry struct {
+	fileInfo os.FileInfo
+	expires  time.Time
+}
+
+func (c *StatCache) invalidateEntry(absolutePath string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.generation++
+	delete(c.entries, absolutePath)
+}
+
+func (c *StatCache) stat(absolutePath string) (os.FileInfo, error) {
+	now := time.Now()
+	c.lock.RLock()
+	entry, ok := c.entries[absolutePath]
+	generation := c.generation
+	c.lock.RUnlock()
+	if ok && now.Before(entry.expires) {
+		return entry.fileInfo, nil
+	}
+	fileInfo, err := statCacheStat(absolutePath)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if ok && c.entries[absolutePath] == entry {
+		delete(c.entries, absolutePath)
+	}
+	// only a result or a missing path is cached, not an error
+	if err != nil {
+		return nil, err
+	}
+	if c.generation == generation {
+		c.entries[absolutePath] = &statCacheEntry{fileInfo: fileInfo, expires: now.Add(c.ttl)}
+	}
+	return fileInfo, nil
+}
@@ -0,0 +1,88 @@
+package osutils
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestStatCache() {
+	statCache := NewStatCache(time.Hour)
+	dir, err := OpenDir(s.tempDir)
+	require.NoError(s.T(), err)
+	dir = dir.WithStatCache(statCache)
+
+	exists, err := dir.IsDirExists("a")
+	require.NoError(s.T(), err)
+	require.False(s.T(), exists)
+	// changes made behind the cache's back are not seen until the TTL
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(s.tempDir, "file"), nil, 0644))
+	exists, err = dir.IsFileExists("file")
+	require.NoError(s.T(), err)
+	require.True(s.T(), exists)
+	require.NoError(s.T(), RemoveAll(filepath.Join(s.tempDir, "file")))
+	exists, err = dir.IsFileExists("file")
+	require.NoError(s.T(), err)
+	require.True(s.T(), exists)
+	statCache.Invalidate(filepath.Join(s.tempDir, "file"))
+	exists, err = dir.IsFileExists("file")
+	require.NoError(s.T(), err)
+	require.False(s.T(), exists)
+
+	// the Dir invalidates what it changes, including created parents
+	require.NoError(s.T(), dir.Mkdir("a/b", 0755))
+	exists, err = dir.IsDirExists("a")
+	require.NoError(s.T(), err)
+	require.True(s.T(), exists)
+	exists, err = dir.IsDirExists("a/b")
+	require.NoError(s.T(), err)
+	require.True(s.T(), exists)
+	require.NoError(s.T(), dir.RemoveAll("a"))
+	fileInfo, err := dir.Stat("a/b")
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), fileInfo)
+
+	statCache = NewStatCache(0)
+	fileInfo, err = statCache.Stat(s.tempDir)
+	require.NoError(s.T(), err)
+	require.True(s.T(), fileInfo.IsDir())
+}
+
+func (s *Suite) TestStatCacheInvalidateDuringStat() {
+	statCache := NewStatCache(time.Hour)
+	path := filepath.Join(s.tempDir, "file")
+	require.NoError(s.T(), ioutil.WriteFile(path, nil, 0644))
+
+	defer func(fn func(string) (os.FileInfo, error)) { statCacheStat = fn }(statCacheStat)
+	statCacheStat = func(absolutePath string) (os.FileInfo, error) {
+		fileInfo, err := stat(absolutePath)
+		// removed and invalidated after the stat saw the file
+		require.NoError(s.T(), os.Remove(path))
+		statCache.Invalidate(path)
+		return fileInfo, err
+	}
+	fileInfo, err := statCache.Stat(path)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), fileInfo)
+	statCacheStat = stat
+	fileInfo, err = statCache.Stat(path)
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), fileInfo)
+
+	// an expired entry is dropped even if it is not replaced
+	statCache = NewStatCache(time.Nanosecond)
+	_, err = statCache.Stat(path)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), statCache.entries, 1)
+	errStat := errors.New("stat")
+	statCacheStat = func(string) (os.FileInfo, error) {
+		return nil, errStat
+	}
+	_, err = statCache.Stat(path)
+	require.Equal(s.T(), errStat, err)
+	require.Len(s.T(), statCache.entries, 0)
+}
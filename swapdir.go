@@ -0,0 +1,104 @@
+package osutils
+
+import (
+	"os"
+
+	"github.com/satori/go.uuid"
+)
+
+// SwapDirs exchanges the dirs at a and b. This is atomic with
+// renameat2(RENAME_EXCHANGE) on Linux, elsewhere the dirs are moved through
+// a temporary name, and moved back if that fails part way.
+func SwapDirs(a string, b string) error {
+	if !isAbsolutePath(a) || !isAbsolutePath(b) {
+		return ErrNotAbsolutePath
+	}
+	if err := checkGuard(a, b); err != nil {
+		return err
+	}
+	for _, path := range []string{a, b} {
+		exists, err := isDirExists(path)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrFileDoesNotExist
+		}
+	}
+	return swapDirs(a, b)
+}
+
+// ReplaceDir puts the dir at newContent in place of target and removes the
+// previous target, if any. For readers of target, the switch is atomic on
+// Linux, elsewhere target briefly does not exist.
+func ReplaceDir(target string, newContent string) error {
+	if !isAbsolutePath(target) || !isAbsolutePath(newContent) {
+		return ErrNotAbsolutePath
+	}
+	if err := checkGuard(target); err != nil {
+		return err
+	}
+	exists, err := isDirExists(newContent)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrFileDoesNotExist
+	}
+	return replaceDir(target, newContent)
+}
+
+// ***** PRIVATE *****
+
+func swapDirs(a string, b string) error {
+	err := renameExchange(a, b)
+	if err != ErrNotSupported {
+		return err
+	}
+	staging := stagingPath(a)
+	if err := os.Rename(a, staging); err != nil {
+		return err
+	}
+	if err := os.Rename(b, a); err != nil {
+		_ = os.Rename(staging, a)
+		return err
+	}
+	if err := os.Rename(staging, b); err != nil {
+		_ = os.Rename(a, b)
+		_ = os.Rename(staging, a)
+		return err
+	}
+	return nil
+}
+
+func replaceDir(target string, newContent string) error {
+	exists, err := isDirExists(target)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return os.Rename(newContent, target)
+	}
+	// after the exchange, newContent holds the old target
+	err = renameExchange(target, newContent)
+	if err == nil {
+		return os.RemoveAll(newContent)
+	}
+	if err != ErrNotSupported {
+		return err
+	}
+	old := stagingPath(target)
+	if err := os.Rename(target, old); err != nil {
+		return err
+	}
+	if err := os.Rename(newContent, target); err != nil {
+		_ = os.Rename(old, target)
+		return err
+	}
+	return os.RemoveAll(old)
+}
+
+// stagingPath returns a unique sibling path of path.
+func stagingPath(path string) string {
+	return path + ".osutils-" + uuid.NewV4().String()
+}
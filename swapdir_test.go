@@ -0,0 +1,32 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestSwapDirs() {
+	a := filepath.Join(s.tempDir, "a")
+	b := filepath.Join(s.tempDir, "b")
+	require.NoError(s.T(), os.Mkdir(a, 0755))
+	require.NoError(s.T(), os.Mkdir(b, 0755))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(a, "a"), nil, 0644))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(b, "b"), nil, 0644))
+	require.NoError(s.T(), SwapDirs(a, b))
+	s.checkFileExists(filepath.Join(a, "b"))
+	s.checkFileExists(filepath.Join(b, "a"))
+
+	require.NoError(s.T(), ReplaceDir(a, b))
+	s.checkFileExists(filepath.Join(a, "a"))
+	s.checkFileDoesNotExist(b)
+	fileInfos, err := ioutil.ReadDir(s.tempDir)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, len(fileInfos))
+
+	c := filepath.Join(s.tempDir, "c")
+	require.NoError(s.T(), ReplaceDir(c, a))
+	s.checkFileExists(filepath.Join(c, "a"))
+}
@@ -0,0 +1,73 @@
+package osutils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var (
+	ErrNotSymlink = errors.New("osutils: not symlink")
+)
+
+// UpdateSymlinkAtomic points the symlink at linkPath to target by creating
+// a new symlink next to it and renaming it over linkPath, so that linkPath
+// always resolves to either the old or the new target. target may be
+// relative to the dir of linkPath. An existing linkPath must be a symlink.
+func UpdateSymlinkAtomic(linkPath string, target string) error {
+	if !isAbsolutePath(linkPath) {
+		return ErrNotAbsolutePath
+	}
+	if target == "" {
+		return ErrEmpty
+	}
+	return updateSymlinkAtomic(linkPath, target)
+}
+
+// ReadCurrentTarget returns the absolute, cleaned target of the symlink at
+// linkPath, resolving a relative target against the dir of linkPath.
+func ReadCurrentTarget(linkPath string) (string, error) {
+	if !isAbsolutePath(linkPath) {
+		return "", ErrNotAbsolutePath
+	}
+	return readCurrentTarget(linkPath)
+}
+
+// ***** PRIVATE *****
+
+func updateSymlinkAtomic(linkPath string, target string) error {
+	fileInfo, err := os.Lstat(linkPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil && fileInfo.Mode()&os.ModeSymlink == 0 {
+		return ErrNotSymlink
+	}
+	staging := stagingPath(linkPath)
+	if err := os.Symlink(target, staging); err != nil {
+		return err
+	}
+	if err := os.Rename(staging, linkPath); err != nil {
+		_ = os.Remove(staging)
+		return err
+	}
+	return nil
+}
+
+func readCurrentTarget(linkPath string) (string, error) {
+	fileInfo, err := os.Lstat(linkPath)
+	if err != nil {
+		return "", err
+	}
+	if fileInfo.Mode()&os.ModeSymlink == 0 {
+		return "", ErrNotSymlink
+	}
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(linkPath), target)
+	}
+	return filepath.Clean(target), nil
+}
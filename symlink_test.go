@@ -0,0 +1,23 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestUpdateSymlinkAtomic() {
+	current := filepath.Join(s.tempDir, "current")
+	for _, release := range []string{"releases/1", "releases/2"} {
+		require.NoError(s.T(), os.MkdirAll(filepath.Join(s.tempDir, release), 0755))
+		require.NoError(s.T(), UpdateSymlinkAtomic(current, release))
+		target, err := ReadCurrentTarget(current)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), filepath.Join(s.tempDir, release), target)
+	}
+	matches, err := filepath.Glob(filepath.Join(s.tempDir, "current*"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, len(matches))
+	require.Equal(s.T(), ErrNotSymlink, UpdateSymlinkAtomic(filepath.Join(s.tempDir, "releases"), "x"))
+}
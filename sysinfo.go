@@ -0,0 +1,36 @@
+package osutils
+
+import (
+	"runtime"
+	"time"
+)
+
+type LoadAvg struct {
+	One     float64
+	Five    float64
+	Fifteen float64
+}
+
+func LogicalCPUCount() int {
+	return runtime.NumCPU()
+}
+
+func PhysicalCPUCount() (int, error) {
+	return physicalCPUCount()
+}
+
+func TotalMemory() (uint64, error) {
+	return totalMemory()
+}
+
+func AvailableMemory() (uint64, error) {
+	return availableMemory()
+}
+
+func LoadAverage() (*LoadAvg, error) {
+	return loadAverage()
+}
+
+func Uptime() (time.Duration, error) {
+	return uptime()
+}
@@ -0,0 +1,93 @@
+//go:build darwin || freebsd
+
+package osutils
+
+import (
+	"encoding/binary"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+func physicalCPUCount() (int, error) {
+	name := "hw.physicalcpu"
+	if runtime.GOOS == "freebsd" {
+		name = "kern.smp.cores"
+	}
+	value, err := syscall.SysctlUint32(name)
+	if err != nil {
+		return 0, err
+	}
+	return int(value), nil
+}
+
+func totalMemory() (uint64, error) {
+	name := "hw.memsize"
+	if runtime.GOOS == "freebsd" {
+		name = "hw.physmem"
+	}
+	data, err := sysctlRaw(name, 8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(data), nil
+}
+
+func availableMemory() (uint64, error) {
+	name := "vm.page_free_count"
+	if runtime.GOOS == "freebsd" {
+		name = "vm.stats.vm.v_free_count"
+	}
+	freePages, err := syscall.SysctlUint32(name)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(freePages) * uint64(syscall.Getpagesize()), nil
+}
+
+func loadAverage() (*LoadAvg, error) {
+	// struct loadavg { fixpt_t ldavg[3]; long fscale; }
+	data, err := sysctlRaw("vm.loadavg", 24)
+	if err != nil {
+		return nil, err
+	}
+	fscale := float64(binary.LittleEndian.Uint64(data[16:24]))
+	if fscale == 0 {
+		return nil, ErrNotSupported
+	}
+	return &LoadAvg{
+		One:     float64(binary.LittleEndian.Uint32(data[0:4])) / fscale,
+		Five:    float64(binary.LittleEndian.Uint32(data[4:8])) / fscale,
+		Fifteen: float64(binary.LittleEndian.Uint32(data[8:12])) / fscale,
+	}, nil
+}
+
+func uptime() (time.Duration, error) {
+	// struct timeval { time_t tv_sec; suseconds_t tv_usec; }
+	data, err := sysctlRaw("kern.boottime", 16)
+	if err != nil {
+		return 0, err
+	}
+	bootTime := time.Unix(
+		int64(binary.LittleEndian.Uint64(data[0:8])),
+		int64(binary.LittleEndian.Uint32(data[8:12]))*int64(time.Microsecond),
+	)
+	return time.Since(bootTime), nil
+}
+
+// sysctlRaw returns the raw bytes of a sysctl value. syscall.Sysctl strips a
+// trailing NUL since it expects strings, so the value is padded back out.
+func sysctlRaw(name string, size int) ([]byte, error) {
+	value, err := syscall.Sysctl(name)
+	if err != nil {
+		return nil, err
+	}
+	data := []byte(value)
+	if len(data) > size {
+		return nil, ErrNotSupported
+	}
+	for len(data) < size {
+		data = append(data, 0)
+	}
+	return data, nil
+}
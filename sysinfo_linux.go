@@ -0,0 +1,128 @@
+package osutils
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func physicalCPUCount() (int, error) {
+	file, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	cores := make(map[string]bool)
+	var physicalID string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value := splitProcLine(scanner.Text())
+		switch key {
+		case "physical id":
+			physicalID = value
+		case "core id":
+			cores[physicalID+"/"+value] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	// not every architecture reports topology in /proc/cpuinfo
+	if len(cores) == 0 {
+		return runtime.NumCPU(), nil
+	}
+	return len(cores), nil
+}
+
+func totalMemory() (uint64, error) {
+	return readMeminfo("MemTotal")
+}
+
+func availableMemory() (uint64, error) {
+	return readMeminfo("MemAvailable")
+}
+
+func loadAverage() (*LoadAvg, error) {
+	fields, err := readProcFields("/proc/loadavg", 3)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, 3)
+	for i := range values {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return &LoadAvg{
+		One:     values[0],
+		Five:    values[1],
+		Fifteen: values[2],
+	}, nil
+}
+
+func uptime() (time.Duration, error) {
+	fields, err := readProcFields("/proc/uptime", 1)
+	if err != nil {
+		return 0, err
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// readMeminfo returns the value of the given /proc/meminfo key in bytes.
+func readMeminfo(key string) (uint64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineKey, value := splitProcLine(scanner.Text())
+		if lineKey != key {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			return 0, ErrEmpty
+		}
+		kilobytes, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return kilobytes * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, ErrNotSupported
+}
+
+func readProcFields(path string, minFields int) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < minFields {
+		return nil, ErrEmpty
+	}
+	return fields, nil
+}
+
+func splitProcLine(line string) (string, string) {
+	split := strings.SplitN(line, ":", 2)
+	if len(split) != 2 {
+		return strings.TrimSpace(line), ""
+	}
+	return strings.TrimSpace(split[0]), strings.TrimSpace(split[1])
+}
@@ -0,0 +1,28 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package osutils
+
+import (
+	"runtime"
+	"time"
+)
+
+func physicalCPUCount() (int, error) {
+	return runtime.NumCPU(), nil
+}
+
+func totalMemory() (uint64, error) {
+	return 0, ErrNotSupported
+}
+
+func availableMemory() (uint64, error) {
+	return 0, ErrNotSupported
+}
+
+func loadAverage() (*LoadAvg, error) {
+	return nil, ErrNotSupported
+}
+
+func uptime() (time.Duration, error) {
+	return 0, ErrNotSupported
+}
@@ -0,0 +1,20 @@
+package osutils
+
+import (
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestMemory() {
+	total, err := TotalMemory()
+	require.NoError(s.T(), err)
+	available, err := AvailableMemory()
+	require.NoError(s.T(), err)
+	require.True(s.T(), total > 0)
+	require.True(s.T(), available <= total)
+}
+
+func (s *Suite) TestCPUCount() {
+	physical, err := PhysicalCPUCount()
+	require.NoError(s.T(), err)
+	require.True(s.T(), physical > 0)
+}
@@ -0,0 +1,100 @@
+package osutils
+
+import (
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	relationProcessorCore = 0
+)
+
+var (
+	kernel32                         = syscall.NewLazyDLL("kernel32.dll")
+	procGlobalMemoryStatusEx         = kernel32.NewProc("GlobalMemoryStatusEx")
+	procGetTickCount64               = kernel32.NewProc("GetTickCount64")
+	procGetLogicalProcessorInfo      = kernel32.NewProc("GetLogicalProcessorInformation")
+	errorInsufficientBuffer          = syscall.Errno(122)
+	systemLogicalProcessorInfoLength = unsafe.Sizeof(systemLogicalProcessorInfo{})
+)
+
+type memoryStatusEx struct {
+	length               uint32
+	memoryLoad           uint32
+	totalPhys            uint64
+	availPhys            uint64
+	totalPageFile        uint64
+	availPageFile        uint64
+	totalVirtual         uint64
+	availVirtual         uint64
+	availExtendedVirtual uint64
+}
+
+type systemLogicalProcessorInfo struct {
+	processorMask uintptr
+	relationship  uint32
+	_             [2]uint64
+}
+
+func physicalCPUCount() (int, error) {
+	var length uint32
+	r, _, err := procGetLogicalProcessorInfo.Call(0, uintptr(unsafe.Pointer(&length)))
+	if r == 0 && err != errorInsufficientBuffer {
+		return 0, err
+	}
+	infos := make([]systemLogicalProcessorInfo, uintptr(length)/systemLogicalProcessorInfoLength+1)
+	r, _, err = procGetLogicalProcessorInfo.Call(
+		uintptr(unsafe.Pointer(&infos[0])),
+		uintptr(unsafe.Pointer(&length)),
+	)
+	if r == 0 {
+		return 0, err
+	}
+	count := 0
+	for _, info := range infos[:uintptr(length)/systemLogicalProcessorInfoLength] {
+		if info.relationship == relationProcessorCore {
+			count++
+		}
+	}
+	if count == 0 {
+		return runtime.NumCPU(), nil
+	}
+	return count, nil
+}
+
+func totalMemory() (uint64, error) {
+	memoryStatus, err := globalMemoryStatus()
+	if err != nil {
+		return 0, err
+	}
+	return memoryStatus.totalPhys, nil
+}
+
+func availableMemory() (uint64, error) {
+	memoryStatus, err := globalMemoryStatus()
+	if err != nil {
+		return 0, err
+	}
+	return memoryStatus.availPhys, nil
+}
+
+func loadAverage() (*LoadAvg, error) {
+	return nil, ErrNotSupported
+}
+
+func uptime() (time.Duration, error) {
+	r, _, _ := procGetTickCount64.Call()
+	return time.Duration(r) * time.Millisecond, nil
+}
+
+func globalMemoryStatus() (*memoryStatusEx, error) {
+	memoryStatus := &memoryStatusEx{}
+	memoryStatus.length = uint32(unsafe.Sizeof(*memoryStatus))
+	r, _, err := procGlobalMemoryStatusEx.Call(uintptr(unsafe.Pointer(memoryStatus)))
+	if r == 0 {
+		return nil, err
+	}
+	return memoryStatus, nil
+}
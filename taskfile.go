@@ -0,0 +1,286 @@
+package osutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnknownTask     = errors.New("osutils: unknown task")
+	ErrDependencyCycle = errors.New("osutils: dependency cycle")
+	ErrInvalidTask     = errors.New("osutils: invalid task")
+)
+
+// TaskFile is a set of named tasks, loaded from JSON or YAML:
+//
+//	env:
+//	  GOFLAGS: -mod=mod
+//	tasks:
+//	  build:
+//	    cmd: [go, build, ./...]
+//	  count:
+//	    pipeline: [[git, ls-files], [wc, -l]]
+//	    deps: [build]
+type TaskFile struct {
+	Env   map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	Dir   string            `json:"dir,omitempty" yaml:"dir,omitempty"`
+	Tasks map[string]*Task  `json:"tasks" yaml:"tasks"`
+	// BaseDir is what relative dirs are resolved against, set by
+	// LoadTaskFile to the dir of the task file.
+	BaseDir string `json:"-" yaml:"-"`
+}
+
+// Task is exactly one of a command or a pipeline of commands. Env is merged
+// on top of the task file env, and Dir defaults to the task file dir.
+type Task struct {
+	Cmd      []string          `json:"cmd,omitempty" yaml:"cmd,omitempty"`
+	Pipeline [][]string        `json:"pipeline,omitempty" yaml:"pipeline,omitempty"`
+	Env      map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	Dir      string            `json:"dir,omitempty" yaml:"dir,omitempty"`
+	Deps     []string          `json:"deps,omitempty" yaml:"deps,omitempty"`
+}
+
+type TaskResult struct {
+	Name   string
+	Result *Result
+	Err    error
+}
+
+// TaskRunner runs tasks of a TaskFile along with their dependencies, each
+// task at most once per Run, stopping at the first failure.
+type TaskRunner struct {
+	TaskFile *TaskFile
+	// Stdout and Stderr additionally receive the output of every task.
+	Stdout io.Writer
+	Stderr io.Writer
+	// OnTaskStart and OnTaskResult are called around each task if set.
+	OnTaskStart  func(name string, task *Task)
+	OnTaskResult func(taskResult *TaskResult)
+}
+
+func LoadTaskFile(absolutePath string) (*TaskFile, error) {
+	return loadTaskFile(absolutePath)
+}
+
+// ParseTaskFile parses a task file, JSON if it looks like JSON and YAML
+// otherwise.
+func ParseTaskFile(data []byte) (*TaskFile, error) {
+	return parseTaskFile(data)
+}
+
+func (t *TaskRunner) Run(ctx context.Context, names ...string) ([]*TaskResult, error) {
+	return t.run(ctx, names)
+}
+
+// ***** PRIVATE *****
+
+func loadTaskFile(absolutePath string) (*TaskFile, error) {
+	file, err := open(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	taskFile, err := parseTaskFile(data)
+	if err != nil {
+		return nil, err
+	}
+	taskFile.BaseDir = filepath.Dir(absolutePath)
+	return taskFile, nil
+}
+
+func parseTaskFile(data []byte) (*TaskFile, error) {
+	taskFile := &TaskFile{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(data, taskFile); err != nil {
+			return nil, err
+		}
+	} else if err := yaml.Unmarshal(data, taskFile); err != nil {
+		return nil, err
+	}
+	for _, task := range taskFile.Tasks {
+		if err := validateTask(task); err != nil {
+			return nil, err
+		}
+	}
+	return taskFile, nil
+}
+
+func validateTask(task *Task) error {
+	if task == nil {
+		return ErrNil
+	}
+	if (len(task.Cmd) == 0) == (len(task.Pipeline) == 0) {
+		return ErrInvalidTask
+	}
+	for _, args := range task.Pipeline {
+		if len(args) == 0 {
+			return ErrEmpty
+		}
+	}
+	return nil
+}
+
+func (t *TaskRunner) run(ctx context.Context, names []string) ([]*TaskResult, error) {
+	if t.TaskFile == nil {
+		return nil, ErrNil
+	}
+	order, err := t.order(names)
+	if err != nil {
+		return nil, err
+	}
+	var taskResults []*TaskResult
+	for _, name := range order {
+		taskResult := t.runTask(ctx, name)
+		taskResults = append(taskResults, taskResult)
+		if taskResult.Err != nil {
+			return taskResults, taskResult.Err
+		}
+	}
+	return taskResults, nil
+}
+
+// order returns the tasks to run with every task after its dependencies.
+func (t *TaskRunner) order(names []string) ([]string, error) {
+	var order []string
+	done := make(map[string]bool)
+	visiting := make(map[string]bool)
+	var visit func(string) error
+	visit = func(name string) error {
+		if done[name] {
+			return nil
+		}
+		if visiting[name] {
+			return ErrDependencyCycle
+		}
+		task, ok := t.TaskFile.Tasks[name]
+		if !ok {
+			return ErrUnknownTask
+		}
+		visiting[name] = true
+		for _, dep := range task.Deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+		done[name] = true
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func (t *TaskRunner) runTask(ctx context.Context, name string) *TaskResult {
+	task := t.TaskFile.Tasks[name]
+	if t.OnTaskStart != nil {
+		t.OnTaskStart(name, task)
+	}
+	taskResult := &TaskResult{Name: name}
+	taskResult.Result, taskResult.Err = t.execTask(ctx, task)
+	if t.OnTaskResult != nil {
+		t.OnTaskResult(taskResult)
+	}
+	return taskResult
+}
+
+func (t *TaskRunner) execTask(ctx context.Context, task *Task) (*Result, error) {
+	dir, err := t.taskDir(task)
+	if err != nil {
+		return nil, err
+	}
+	env := mergeEnv(mergeEnv(os.Environ(), t.TaskFile.Env), task.Env)
+	args := task.Cmd
+	if len(task.Pipeline) == 1 {
+		args = task.Pipeline[0]
+	}
+	if len(args) > 0 {
+		return runCmd(
+			ctx,
+			&Cmd{
+				Args:        args,
+				AbsoluteDir: dir,
+				Env:         env,
+				Stdout:      t.Stdout,
+				Stderr:      t.Stderr,
+			},
+		)
+	}
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	// the last stage writes to stdout while every stage writes to stderr,
+	// so a writer shared by both needs one lock across them
+	stdoutWriter, stderrWriter := lockIfShared(t.Stdout, t.Stderr)
+	pipeCmdList := &PipeCmdList{
+		Stdout: teeWriter(&stdout, stdoutWriter),
+		// every stage writes to the same stderr concurrently
+		Stderr: &lockedWriter{writer: teeWriter(&stderr, stderrWriter)},
+	}
+	for _, args := range task.Pipeline {
+		pipeCmdList.PipeCmds = append(
+			pipeCmdList.PipeCmds,
+			&PipeCmd{
+				Args:        args,
+				AbsoluteDir: dir,
+				Env:         env,
+			},
+		)
+	}
+	result := &Result{
+		Args:  []string{strings.Join(pipelineArgs(task.Pipeline), " | ")},
+		Start: time.Now(),
+	}
+	wait, err := executePiped(ctx, pipeCmdList)
+	if err != nil {
+		return nil, err
+	}
+	err = wait()
+	result.Duration = time.Since(result.Start)
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	result.ExitCode = exitCode(err)
+	return result, err
+}
+
+func (t *TaskRunner) taskDir(task *Task) (string, error) {
+	dir := task.Dir
+	if dir == "" {
+		dir = t.TaskFile.Dir
+	}
+	if dir != "" && !isAbsolutePath(dir) {
+		if t.TaskFile.BaseDir == "" {
+			return "", ErrNotAbsolutePath
+		}
+		dir = filepath.Join(t.TaskFile.BaseDir, dir)
+	}
+	if dir == "" {
+		dir = t.TaskFile.BaseDir
+	}
+	return dir, nil
+}
+
+func pipelineArgs(pipeline [][]string) []string {
+	stages := make([]string, len(pipeline))
+	for i, args := range pipeline {
+		stages[i] = strings.Join(args, " ")
+	}
+	return stages
+}
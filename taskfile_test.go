@@ -0,0 +1,89 @@
+package osutils
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestTaskRunner() {
+	path := filepath.Join(s.tempDir, "tasks.yaml")
+	require.NoError(
+		s.T(),
+		ioutil.WriteFile(
+			path,
+			[]byte(`env:
+  FOO: foo
+tasks:
+  write:
+    cmd: [bash, -c, 'echo "${FOO}${BAR}" > out']
+    env:
+      BAR: bar
+  count:
+    pipeline: [[cat, out], [wc, -c]]
+    deps: [write]
+  cycle:
+    cmd: ["true"]
+    deps: [cycle]
+`),
+			0644,
+		),
+	)
+	taskFile, err := LoadTaskFile(path)
+	require.NoError(s.T(), err)
+	var started []string
+	taskRunner := &TaskRunner{
+		TaskFile: taskFile,
+		OnTaskStart: func(name string, task *Task) {
+			started = append(started, name)
+		},
+	}
+	taskResults, err := taskRunner.Run(context.Background(), "count", "write")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{"write", "count"}, started)
+	require.Equal(s.T(), 2, len(taskResults))
+	require.Equal(s.T(), "7", strings.TrimSpace(taskResults[1].Result.Stdout))
+	_, err = taskRunner.Run(context.Background(), "cycle")
+	require.Equal(s.T(), ErrDependencyCycle, err)
+	_, err = taskRunner.Run(context.Background(), "nope")
+	require.Equal(s.T(), ErrUnknownTask, err)
+}
+
+func (s *Suite) TestTaskRunnerPipelineSharedOutput() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sh not available on windows")
+	}
+	path := filepath.Join(s.tempDir, "tasks.yaml")
+	require.NoError(
+		s.T(),
+		ioutil.WriteFile(
+			path,
+			[]byte(`tasks:
+  noisy:
+    pipeline:
+      - [sh, -c, 'for i in 1 2 3 4 5 6 7 8 9 10; do echo err >&2; echo out; done']
+      - [sh, -c, 'while read line; do echo "${line}"; echo err >&2; done']
+`),
+			0644,
+		),
+	)
+	taskFile, err := LoadTaskFile(path)
+	require.NoError(s.T(), err)
+	// one writer for both, written to by every stage at once
+	var output bytes.Buffer
+	taskRunner := &TaskRunner{
+		TaskFile: taskFile,
+		Stdout:   &output,
+		Stderr:   &output,
+	}
+	taskResults, err := taskRunner.Run(context.Background(), "noisy")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, len(taskResults))
+	require.Equal(s.T(), 10, strings.Count(output.String(), "out\n"))
+	require.Equal(s.T(), 20, strings.Count(output.String(), "err\n"))
+}
@@ -0,0 +1,28 @@
+package osutils
+
+import (
+	"os"
+)
+
+func IsTerminal(file *os.File) bool {
+	if file == nil {
+		return false
+	}
+	return isTerminal(file.Fd())
+}
+
+func TerminalSize(file *os.File) (cols int, rows int, err error) {
+	if file == nil {
+		return 0, 0, ErrNil
+	}
+	return terminalSize(file.Fd())
+}
+
+// OnTerminalResize calls fn every time the controlling terminal is resized
+// until the returned stop function is called.
+func OnTerminalResize(fn func()) (func(), error) {
+	if fn == nil {
+		return nil, ErrNil
+	}
+	return onTerminalResize(fn)
+}
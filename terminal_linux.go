@@ -0,0 +1,9 @@
+package osutils
+
+import (
+	"syscall"
+)
+
+const (
+	ioctlGetTermios = syscall.TCGETS
+)
@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package osutils
+
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func terminalSize(fd uintptr) (int, int, error) {
+	return 0, 0, ErrNotSupported
+}
+
+func onTerminalResize(fn func()) (func(), error) {
+	return nil, ErrNotSupported
+}
@@ -0,0 +1,17 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestIsTerminal() {
+	file, err := os.Create(filepath.Join(s.tempDir, "file"))
+	require.NoError(s.T(), err)
+	defer s.checkClose(file)
+	require.False(s.T(), IsTerminal(file))
+	_, _, err = TerminalSize(file)
+	require.Error(s.T(), err)
+}
@@ -0,0 +1,44 @@
+//go:build unix
+
+package osutils
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func isTerminal(fd uintptr) bool {
+	_, err := unix.IoctlGetTermios(int(fd), ioctlGetTermios)
+	return err == nil
+}
+
+func terminalSize(fd uintptr) (int, int, error) {
+	ws, err := unix.IoctlGetWinsize(int(fd), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
+
+func onTerminalResize(fn func()) (func(), error) {
+	signalC := make(chan os.Signal, 1)
+	doneC := make(chan struct{})
+	signal.Notify(signalC, syscall.SIGWINCH)
+	go func() {
+		for {
+			select {
+			case <-signalC:
+				fn()
+			case <-doneC:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signalC)
+		close(doneC)
+	}, nil
+}
@@ -0,0 +1,111 @@
+package osutils
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Throttle limits the rate of I/O of the operations it is passed to, with
+// token buckets shared by every goroutine using the same Throttle. Bursts
+// of up to one second of the rate are allowed. A nil *Throttle does not
+// limit.
+type Throttle struct {
+	bytes *tokenBucket
+	ops   *tokenBucket
+}
+
+// NewThrottle returns a Throttle for bytesPerSecond and opsPerSecond,
+// where an operation is a file copied or hashed. Zero means unlimited.
+func NewThrottle(bytesPerSecond int64, opsPerSecond int64) *Throttle {
+	return &Throttle{
+		bytes: newTokenBucket(bytesPerSecond),
+		ops:   newTokenBucket(opsPerSecond),
+	}
+}
+
+// Reader wraps reader so reads wait for the byte rate.
+func (t *Throttle) Reader(reader io.Reader) io.Reader {
+	if t == nil || t.bytes == nil {
+		return reader
+	}
+	return &throttledReader{reader, t.bytes}
+}
+
+// Writer wraps writer so writes wait for the byte rate.
+func (t *Throttle) Writer(writer io.Writer) io.Writer {
+	if t == nil || t.bytes == nil {
+		return writer
+	}
+	return &throttledWriter{writer, t.bytes}
+}
+
+// WaitOp waits for the operation rate.
+func (t *Throttle) WaitOp() {
+	if t == nil || t.ops == nil {
+		return
+	}
+	t.ops.wait(1)
+}
+
+// ***** PRIVATE *****
+
+type tokenBucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+	lock   sync.Mutex
+}
+
+func newTokenBucket(perSecond int64) *tokenBucket {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		rate:   float64(perSecond),
+		tokens: float64(perSecond),
+		last:   time.Now(),
+	}
+}
+
+// wait takes n tokens, going into debt if there are not enough and
+// sleeping until the debt is paid off, so large requests are not starved.
+func (b *tokenBucket) wait(n int64) {
+	b.lock.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+	b.tokens -= float64(n)
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.lock.Unlock()
+	time.Sleep(delay)
+}
+
+type throttledReader struct {
+	reader      io.Reader
+	tokenBucket *tokenBucket
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	n, err := t.reader.Read(p)
+	if n > 0 {
+		t.tokenBucket.wait(int64(n))
+	}
+	return n, err
+}
+
+type throttledWriter struct {
+	writer      io.Writer
+	tokenBucket *tokenBucket
+}
+
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	t.tokenBucket.wait(int64(len(p)))
+	return t.writer.Write(p)
+}
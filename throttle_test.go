@@ -0,0 +1,37 @@
+package osutils
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestThrottle() {
+	throttle := NewThrottle(1000, 0)
+	start := time.Now()
+	// the first second of the rate is available as a burst
+	n, err := io.Copy(ioutil.Discard, throttle.Reader(bytes.NewReader(make([]byte, 1500))))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(1500), n)
+	require.True(s.T(), time.Since(start) >= 400*time.Millisecond)
+
+	var nilThrottle *Throttle
+	nilThrottle.WaitOp()
+	reader := bytes.NewReader(nil)
+	require.Equal(s.T(), io.Reader(reader), nilThrottle.Reader(reader))
+
+	src := s.writeCopyDirSrc()
+	throttle = NewThrottle(0, 10)
+	start = time.Now()
+	for i := 0; i < 12; i++ {
+		throttle.WaitOp()
+	}
+	require.True(s.T(), time.Since(start) >= 100*time.Millisecond)
+	require.NoError(s.T(), CopyDir(src, filepath.Join(s.tempDir, "dst"), &CopyDirOptions{Throttle: NewThrottle(1<<20, 100)}))
+	_, err = HashDir(src, &HashDirOptions{Throttle: NewThrottle(1<<20, 100)})
+	require.NoError(s.T(), err)
+}
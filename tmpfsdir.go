@@ -0,0 +1,101 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+)
+
+const (
+	devShmPath = "/dev/shm"
+)
+
+type TmpfsDirKind int
+
+const (
+	// TmpfsDirMounted is a tmpfs mounted for the dir, with the size limit.
+	TmpfsDirMounted TmpfsDirKind = iota
+	// TmpfsDirShm is a dir in /dev/shm, which is memory-backed but shared,
+	// so the size limit does not apply.
+	TmpfsDirShm
+	// TmpfsDirRegular is a regular temp dir.
+	TmpfsDirRegular
+)
+
+var (
+	tmpfsDirKindToString = map[TmpfsDirKind]string{
+		TmpfsDirMounted: "mounted",
+		TmpfsDirShm:     "shm",
+		TmpfsDirRegular: "regular",
+	}
+)
+
+func (t TmpfsDirKind) String() string {
+	return tmpfsDirKindToString[t]
+}
+
+// TmpfsDir is a temp dir in memory for fast scratch space, removed on
+// Close.
+type TmpfsDir struct {
+	root      string
+	kind      TmpfsDirKind
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewTempDirTmpfs mounts a tmpfs of at most sizeLimit bytes on a new temp
+// dir, or the tmpfs default of half the memory if sizeLimit is not
+// positive. Mounting needs privileges, so if it fails a dir in /dev/shm is
+// used instead, and failing that a regular temp dir. Kind tells which.
+func NewTempDirTmpfs(sizeLimit int64) (*TmpfsDir, error) {
+	root, err := newTempDir()
+	if err != nil {
+		return nil, err
+	}
+	data := "mode=0700"
+	if sizeLimit > 0 {
+		data += ",size=" + strconv.FormatInt(sizeLimit, 10)
+	}
+	if err := Mount("tmpfs", root, "tmpfs", &MountOptions{NoSuid: true, NoDev: true, Data: data}); err == nil {
+		return &TmpfsDir{root: root, kind: TmpfsDirMounted}, nil
+	}
+	if err := os.Remove(root); err != nil {
+		return nil, err
+	}
+	if exists, err := isDirExists(devShmPath); err == nil && exists {
+		if shmDir, err := ioutil.TempDir(devShmPath, tempDirPrefix); err == nil {
+			return &TmpfsDir{root: shmDir, kind: TmpfsDirShm}, nil
+		}
+	}
+	root, err = newTempDir()
+	if err != nil {
+		return nil, err
+	}
+	return &TmpfsDir{root: root, kind: TmpfsDirRegular}, nil
+}
+
+func (t *TmpfsDir) Root() string {
+	return t.root
+}
+
+func (t *TmpfsDir) Kind() TmpfsDirKind {
+	return t.kind
+}
+
+// Close unmounts the tmpfs, if mounted, and removes the dir. A busy tmpfs
+// is detached and freed once no longer in use. Closing again is a no-op.
+func (t *TmpfsDir) Close() error {
+	t.closeOnce.Do(func() {
+		if t.kind != TmpfsDirMounted {
+			t.closeErr = removeAll(t.root)
+			return
+		}
+		if err := Unmount(t.root, &UnmountOptions{Lazy: true}); err != nil {
+			t.closeErr = err
+			return
+		}
+		t.closeErr = os.Remove(t.root)
+	})
+	return t.closeErr
+}
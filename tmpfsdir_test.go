@@ -0,0 +1,24 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestNewTempDirTmpfs() {
+	tmpfsDir, err := NewTempDirTmpfs(1 << 20)
+	require.NoError(s.T(), err)
+	file := filepath.Join(tmpfsDir.Root(), "file")
+	require.NoError(s.T(), ioutil.WriteFile(file, []byte("hello"), 0644))
+	if tmpfsDir.Kind() == TmpfsDirMounted {
+		mountEntry, err := FindMount(tmpfsDir.Root())
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), "tmpfs", mountEntry.FSType)
+		require.Error(s.T(), ioutil.WriteFile(filepath.Join(tmpfsDir.Root(), "big"), make([]byte, 2<<20), 0644))
+	}
+	require.NoError(s.T(), tmpfsDir.Close())
+	require.NoError(s.T(), tmpfsDir.Close())
+	s.checkFileDoesNotExist(tmpfsDir.Root())
+}
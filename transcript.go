@@ -0,0 +1,272 @@
+package osutils
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// TranscriptJSONLines writes a TranscriptEvent per line of JSON.
+	TranscriptJSONLines TranscriptFormat = iota
+	// TranscriptAsciicast writes an asciicast v2 recording that can be
+	// played with asciinema. asciicast has no notion of stderr, so both
+	// streams are written as output events.
+	TranscriptAsciicast
+
+	TranscriptStdout   TranscriptStream = "stdout"
+	TranscriptStderr   TranscriptStream = "stderr"
+	TranscriptCombined TranscriptStream = "combined"
+
+	asciicastVersion = 2
+	asciicastWidth   = 80
+	asciicastHeight  = 24
+)
+
+var ErrInvalidTranscript = errors.New("osutils: invalid transcript")
+
+type TranscriptFormat int
+
+// TranscriptStream is where a chunk of output came from. With
+// Cmd.CombinedOutput the streams cannot be told apart and every chunk is
+// TranscriptCombined.
+type TranscriptStream string
+
+// Transcript records every chunk of output of a command as it is written,
+// timed from the start of the command with the monotonic clock.
+type Transcript struct {
+	Writer io.Writer
+	Format TranscriptFormat
+}
+
+type TranscriptEvent struct {
+	Elapsed time.Duration    `json:"elapsed"`
+	Stream  TranscriptStream `json:"stream"`
+	Data    []byte           `json:"data"`
+}
+
+// ReadTranscript reads a transcript in either format. Events read from an
+// asciicast recording are all TranscriptStdout.
+func ReadTranscript(reader io.Reader) ([]*TranscriptEvent, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 1<<24)
+	var transcriptEvents []*TranscriptEvent
+	asciicast := false
+	for first := true; scanner.Scan(); first = false {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if first {
+			header := &asciicastHeader{}
+			if err := json.Unmarshal([]byte(line), header); err == nil && header.Version == asciicastVersion {
+				asciicast = true
+				continue
+			}
+		}
+		transcriptEvent, err := parseTranscriptEvent(line, asciicast)
+		if err != nil {
+			return nil, err
+		}
+		transcriptEvents = append(transcriptEvents, transcriptEvent)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return transcriptEvents, nil
+}
+
+// ReplayTranscript writes the events to stdout and stderr, TranscriptCombined
+// going to stdout. If speed is greater than 0 the original timing is
+// reproduced, 2 being twice as fast, otherwise the events are written
+// without delay.
+func ReplayTranscript(transcriptEvents []*TranscriptEvent, stdout io.Writer, stderr io.Writer, speed float64) error {
+	start := time.Now()
+	for _, transcriptEvent := range transcriptEvents {
+		if speed > 0 {
+			if wait := time.Duration(float64(transcriptEvent.Elapsed)/speed) - time.Since(start); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+		writer := stdout
+		if transcriptEvent.Stream == TranscriptStderr {
+			writer = stderr
+		}
+		if writer == nil {
+			continue
+		}
+		if _, err := writer.Write(transcriptEvent.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ***** PRIVATE *****
+
+type asciicastHeader struct {
+	Version   int    `json:"version"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+	Command   string `json:"command,omitempty"`
+}
+
+type transcriptRecorder struct {
+	transcript *Transcript
+	encoder    *json.Encoder
+	start      time.Time
+	// pending is the start of a rune split across writes, by stream, held
+	// back for asciicast where output is a string
+	pending map[TranscriptStream][]byte
+	err     error
+	lock    sync.Mutex
+}
+
+type transcriptStreamWriter struct {
+	recorder *transcriptRecorder
+	stream   TranscriptStream
+}
+
+// setupCmdTranscript tees the output of the command into the transcript.
+// The clock starts now rather than at exec so that nothing is recorded at a
+// negative elapsed time.
+func setupCmdTranscript(process *process, cmd *Cmd) error {
+	recorder := &transcriptRecorder{
+		transcript: cmd.Transcript,
+		encoder:    json.NewEncoder(cmd.Transcript.Writer),
+		start:      time.Now(),
+		pending:    make(map[TranscriptStream][]byte),
+	}
+	if cmd.Transcript.Format == TranscriptAsciicast {
+		if err := recorder.encoder.Encode(
+			&asciicastHeader{
+				Version:   asciicastVersion,
+				Width:     asciicastWidth,
+				Height:    asciicastHeight,
+				Timestamp: recorder.start.Unix(),
+				Command:   strings.Join(cmd.Args, " "),
+			},
+		); err != nil {
+			return err
+		}
+	}
+	if process.combined != nil {
+		process.combined = teeWriter(nil, process.combined, recorder.streamWriter(TranscriptCombined))
+	} else {
+		stdout, stderr := lockIfShared(process.execCmd.Stdout, process.execCmd.Stderr)
+		process.execCmd.Stdout = teeWriter(nil, stdout, recorder.streamWriter(TranscriptStdout))
+		process.execCmd.Stderr = teeWriter(nil, stderr, recorder.streamWriter(TranscriptStderr))
+	}
+	process.cleanups = append(
+		process.cleanups,
+		func() error {
+			recorder.lock.Lock()
+			defer recorder.lock.Unlock()
+			// whatever is still pending will not become a full rune
+			for _, stream := range []TranscriptStream{TranscriptStdout, TranscriptStderr, TranscriptCombined} {
+				if pending := recorder.pending[stream]; len(pending) > 0 && recorder.err == nil {
+					recorder.err = recorder.encodeAsciicast(time.Since(recorder.start), pending)
+				}
+			}
+			return recorder.err
+		},
+	)
+	return nil
+}
+
+func (t *transcriptRecorder) streamWriter(stream TranscriptStream) io.Writer {
+	return &transcriptStreamWriter{
+		recorder: t,
+		stream:   stream,
+	}
+}
+
+// record keeps the first error and stops recording, rather than failing
+// the write and so the command.
+func (t *transcriptRecorder) record(stream TranscriptStream, p []byte) {
+	elapsed := time.Since(t.start)
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.err != nil {
+		return
+	}
+	if t.transcript.Format == TranscriptAsciicast {
+		data := append(t.pending[stream], p...)
+		n := completeRunesSize(data)
+		t.pending[stream] = append([]byte(nil), data[n:]...)
+		if n > 0 {
+			t.err = t.encodeAsciicast(elapsed, data[:n])
+		}
+		return
+	}
+	t.err = t.encoder.Encode(
+		&TranscriptEvent{
+			Elapsed: elapsed,
+			Stream:  stream,
+			Data:    p,
+		},
+	)
+}
+
+func (t *transcriptRecorder) encodeAsciicast(elapsed time.Duration, data []byte) error {
+	return t.encoder.Encode([]interface{}{elapsed.Seconds(), "o", string(data)})
+}
+
+func (t *transcriptStreamWriter) Write(p []byte) (int, error) {
+	t.recorder.record(t.stream, p)
+	return len(p), nil
+}
+
+func parseTranscriptEvent(line string, asciicast bool) (*TranscriptEvent, error) {
+	if !asciicast {
+		transcriptEvent := &TranscriptEvent{}
+		if err := json.Unmarshal([]byte(line), transcriptEvent); err != nil {
+			return nil, ErrInvalidTranscript
+		}
+		return transcriptEvent, nil
+	}
+	var fields []interface{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil || len(fields) != 3 {
+		return nil, ErrInvalidTranscript
+	}
+	seconds, ok := fields[0].(float64)
+	if !ok {
+		return nil, ErrInvalidTranscript
+	}
+	code, ok := fields[1].(string)
+	if !ok {
+		return nil, ErrInvalidTranscript
+	}
+	data, ok := fields[2].(string)
+	if !ok {
+		return nil, ErrInvalidTranscript
+	}
+	if code != "o" {
+		return nil, ErrInvalidTranscript
+	}
+	return &TranscriptEvent{
+		Elapsed: time.Duration(seconds * float64(time.Second)),
+		Stream:  TranscriptStdout,
+		Data:    []byte(data),
+	}, nil
+}
+
+// completeRunesSize returns the size of data without a trailing incomplete
+// UTF-8 encoding.
+func completeRunesSize(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if utf8.FullRune(data[i:]) {
+				return len(data)
+			}
+			return i
+		}
+	}
+	return len(data)
+}
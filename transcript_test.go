@@ -0,0 +1,98 @@
+package osutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestTranscript() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sh not available on windows")
+	}
+	var transcript bytes.Buffer
+	_, err := NewCommand("sh", "-c", "echo one; sleep 0.1; echo two >&2").Transcript(&Transcript{Writer: &transcript}).Run(context.Background())
+	require.NoError(s.T(), err)
+	transcriptEvents, err := ReadTranscript(&transcript)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), transcriptEvents, 2)
+	require.Equal(s.T(), TranscriptStdout, transcriptEvents[0].Stream)
+	require.Equal(s.T(), "one\n", string(transcriptEvents[0].Data))
+	require.Equal(s.T(), TranscriptStderr, transcriptEvents[1].Stream)
+	require.Equal(s.T(), "two\n", string(transcriptEvents[1].Data))
+	require.True(s.T(), transcriptEvents[1].Elapsed > transcriptEvents[0].Elapsed)
+	var stdout, stderr bytes.Buffer
+	require.NoError(s.T(), ReplayTranscript(transcriptEvents, &stdout, &stderr, 0))
+	require.Equal(s.T(), "one\n", stdout.String())
+	require.Equal(s.T(), "two\n", stderr.String())
+
+	transcript.Reset()
+	var combined bytes.Buffer
+	wait, err := Execute(
+		&Cmd{
+			Args:           []string{"sh", "-c", "echo one; echo two >&2"},
+			CombinedOutput: &combined,
+			Transcript: &Transcript{
+				Writer: &transcript,
+				Format: TranscriptAsciicast,
+			},
+		},
+	)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), wait())
+	require.True(s.T(), strings.HasPrefix(transcript.String(), `{"version":2,`), transcript.String())
+	transcriptEvents, err = ReadTranscript(&transcript)
+	require.NoError(s.T(), err)
+	stdout.Reset()
+	require.NoError(s.T(), ReplayTranscript(transcriptEvents, &stdout, nil, 0))
+	require.Equal(s.T(), combined.String(), stdout.String())
+
+	_, err = ReadTranscript(strings.NewReader("not json\n"))
+	require.Equal(s.T(), ErrInvalidTranscript, err)
+	_, err = Execute(&Cmd{Args: []string{"true"}, Transcript: &Transcript{}})
+	require.Equal(s.T(), ErrNil, err)
+}
+
+func (s *Suite) TestTranscriptAsciicastSplitRune() {
+	var transcript bytes.Buffer
+	recorder := &transcriptRecorder{
+		transcript: &Transcript{Writer: &transcript, Format: TranscriptAsciicast},
+		encoder:    json.NewEncoder(&transcript),
+		start:      time.Now(),
+		pending:    make(map[TranscriptStream][]byte),
+	}
+	require.NoError(s.T(), recorder.encoder.Encode(&asciicastHeader{Version: asciicastVersion}))
+	recorder.record(TranscriptStdout, []byte("caf\xc3"))
+	recorder.record(TranscriptStdout, []byte("\xa9 \xe2\x82"))
+	recorder.record(TranscriptStdout, []byte("\xac"))
+	transcriptEvents, err := ReadTranscript(&transcript)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), transcriptEvents, 3)
+	require.Equal(s.T(), "caf", string(transcriptEvents[0].Data))
+	require.Equal(s.T(), "é ", string(transcriptEvents[1].Data))
+	require.Equal(s.T(), "€", string(transcriptEvents[2].Data))
+}
+
+func (s *Suite) TestTranscriptSharedOutput() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("sh not available on windows")
+	}
+	var buffer bytes.Buffer
+	var transcript bytes.Buffer
+	wait, err := Execute(
+		&Cmd{
+			Args:       []string{"sh", "-c", "for i in 1 2 3 4 5; do echo out; echo err >&2; done"},
+			Stdout:     &buffer,
+			Stderr:     &buffer,
+			Transcript: &Transcript{Writer: &transcript},
+		},
+	)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), wait())
+	require.Equal(s.T(), 40, buffer.Len())
+}
@@ -0,0 +1,134 @@
+package osutils
+
+import (
+	"container/heap"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+type TreeStatistics struct {
+	Files    int64
+	Dirs     int64
+	Symlinks int64
+	// Other counts everything that is not a regular file, dir or symlink.
+	Other       int64
+	Bytes       int64
+	LargestFile *FileSize
+	NewestPath  string
+	NewestMTime time.Time
+}
+
+type FileSize struct {
+	Path string
+	Size int64
+}
+
+// TreeStats gathers statistics for the tree at absolutePath in a single
+// parallel walk. The root itself is included in the counts.
+func TreeStats(absolutePath string) (*TreeStatistics, error) {
+	return treeStats(absolutePath)
+}
+
+// TopNLargestFiles returns the n largest regular files in the tree, largest
+// first.
+func TopNLargestFiles(absolutePath string, n int) ([]*FileSize, error) {
+	return topNLargestFiles(absolutePath, n)
+}
+
+// ***** PRIVATE *****
+
+func treeStats(absolutePath string) (*TreeStatistics, error) {
+	treeStatistics := &TreeStatistics{}
+	lock := &sync.Mutex{}
+	if err := WalkParallel(
+		absolutePath,
+		0,
+		func(path string, info os.FileInfo) error {
+			lock.Lock()
+			defer lock.Unlock()
+			mode := info.Mode()
+			switch {
+			case mode.IsRegular():
+				treeStatistics.Files++
+				treeStatistics.Bytes += info.Size()
+				if treeStatistics.LargestFile == nil || info.Size() > treeStatistics.LargestFile.Size {
+					treeStatistics.LargestFile = &FileSize{Path: path, Size: info.Size()}
+				}
+			case mode.IsDir():
+				treeStatistics.Dirs++
+			case mode&os.ModeSymlink != 0:
+				treeStatistics.Symlinks++
+			default:
+				treeStatistics.Other++
+			}
+			if info.ModTime().After(treeStatistics.NewestMTime) {
+				treeStatistics.NewestMTime = info.ModTime()
+				treeStatistics.NewestPath = path
+			}
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	return treeStatistics, nil
+}
+
+func topNLargestFiles(absolutePath string, n int) ([]*FileSize, error) {
+	if n <= 0 {
+		return nil, ErrEmpty
+	}
+	fileSizeHeap := &fileSizeHeap{}
+	lock := &sync.Mutex{}
+	if err := WalkParallel(
+		absolutePath,
+		0,
+		func(path string, info os.FileInfo) error {
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			if fileSizeHeap.Len() < n {
+				heap.Push(fileSizeHeap, &FileSize{Path: path, Size: info.Size()})
+			} else if (*fileSizeHeap)[0].Size < info.Size() {
+				(*fileSizeHeap)[0] = &FileSize{Path: path, Size: info.Size()}
+				heap.Fix(fileSizeHeap, 0)
+			}
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	fileSizes := []*FileSize(*fileSizeHeap)
+	sort.Slice(
+		fileSizes,
+		func(i int, j int) bool {
+			if fileSizes[i].Size == fileSizes[j].Size {
+				return fileSizes[i].Path < fileSizes[j].Path
+			}
+			return fileSizes[i].Size > fileSizes[j].Size
+		},
+	)
+	return fileSizes, nil
+}
+
+// fileSizeHeap is a min-heap so the smallest of the current top n is
+// evicted first.
+type fileSizeHeap []*FileSize
+
+func (f fileSizeHeap) Len() int           { return len(f) }
+func (f fileSizeHeap) Less(i, j int) bool { return f[i].Size < f[j].Size }
+func (f fileSizeHeap) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+
+func (f *fileSizeHeap) Push(x interface{}) {
+	*f = append(*f, x.(*FileSize))
+}
+
+func (f *fileSizeHeap) Pop() interface{} {
+	old := *f
+	x := old[len(old)-1]
+	*f = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,30 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestTreeStats() {
+	require.NoError(s.T(), os.MkdirAll(filepath.Join(s.tempDir, "a/b"), 0755))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(s.tempDir, "a/one"), []byte("1"), 0644))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(s.tempDir, "a/b/three"), []byte("333"), 0644))
+	require.NoError(s.T(), ioutil.WriteFile(filepath.Join(s.tempDir, "two"), []byte("22"), 0644))
+	require.NoError(s.T(), os.Symlink("two", filepath.Join(s.tempDir, "link")))
+	treeStatistics, err := TreeStats(s.tempDir)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(3), treeStatistics.Files)
+	require.Equal(s.T(), int64(3), treeStatistics.Dirs)
+	require.Equal(s.T(), int64(1), treeStatistics.Symlinks)
+	require.Equal(s.T(), int64(6), treeStatistics.Bytes)
+	require.Equal(s.T(), filepath.Join(s.tempDir, "a/b/three"), treeStatistics.LargestFile.Path)
+	fileSizes, err := TopNLargestFiles(s.tempDir, 2)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 2, len(fileSizes))
+	require.True(s.T(), strings.HasSuffix(fileSizes[0].Path, "three"))
+	require.True(s.T(), strings.HasSuffix(fileSizes[1].Path, "two"))
+}
@@ -0,0 +1,80 @@
+package osutils
+
+import (
+	"os"
+)
+
+const partialMarkerSuffix = ".partial"
+
+// FailureMode is what a tree operation does with its destination when it
+// fails part way.
+type FailureMode int
+
+const (
+	// FailureModeLeave leaves whatever was written in place.
+	FailureModeLeave FailureMode = iota
+	// FailureModeRollback removes every path the operation created.
+	// Files that were overwritten are not restored.
+	FailureModeRollback
+	// FailureModePartialMarker creates dst.partial before starting and
+	// removes it on success, so an interrupted operation, including a
+	// crash, can be detected with IsPartial.
+	FailureModePartialMarker
+)
+
+// IsPartial reports whether a tree operation on absolutePath using
+// FailureModePartialMarker did not complete.
+func IsPartial(absolutePath string) (bool, error) {
+	if !isAbsolutePath(absolutePath) {
+		return false, ErrNotAbsolutePath
+	}
+	return lexists(absolutePath + partialMarkerSuffix)
+}
+
+// ***** PRIVATE *****
+
+// treeTransaction records the paths a tree operation creates so they can
+// be removed if it fails.
+type treeTransaction struct {
+	failureMode FailureMode
+	markerPath  string
+	created     []string
+}
+
+func newTreeTransaction(dst string, failureMode FailureMode) (*treeTransaction, error) {
+	treeTransaction := &treeTransaction{failureMode: failureMode}
+	if failureMode == FailureModePartialMarker {
+		treeTransaction.markerPath = dst + partialMarkerSuffix
+		if err := writeFileAtomic(treeTransaction.markerPath, nil, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return treeTransaction, nil
+}
+
+// create records a path that did not exist before the operation.
+func (t *treeTransaction) create(path string) {
+	if t.failureMode == FailureModeRollback {
+		t.created = append(t.created, path)
+	}
+}
+
+// finish completes the transaction with the result of the operation and
+// returns it, or the first error from rolling back.
+func (t *treeTransaction) finish(err error) error {
+	if err == nil {
+		if t.markerPath != "" {
+			if removeErr := os.Remove(t.markerPath); removeErr != nil && !os.IsNotExist(removeErr) {
+				return removeErr
+			}
+		}
+		return nil
+	}
+	// children were created after their parents
+	for i := len(t.created) - 1; i >= 0; i-- {
+		if removeErr := os.Remove(t.created[i]); removeErr != nil && !os.IsNotExist(removeErr) {
+			return removeErr
+		}
+	}
+	return err
+}
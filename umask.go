@@ -0,0 +1,97 @@
+package osutils
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	procSelfStatusPath = "/proc/self/status"
+)
+
+var (
+	// umaskLock guards the process umask, which is global state.
+	umaskLock = &sync.Mutex{}
+	// activeUmask is the mask set by the running WithUmask, if any.
+	activeUmask     *os.FileMode
+	activeUmaskLock = &sync.Mutex{}
+
+	errNoProcUmask = errors.New("osutils: no umask in /proc")
+)
+
+// GetUmask reads the umask from /proc where available. Elsewhere the umask
+// can only be read by setting it, so files created concurrently by code
+// not using WithUmask briefly see a umask of 0.
+func GetUmask() (os.FileMode, error) {
+	if mask, err := readProcUmask(); err == nil {
+		return mask, nil
+	}
+	activeUmaskLock.Lock()
+	active := activeUmask
+	activeUmaskLock.Unlock()
+	if active != nil {
+		return *active, nil
+	}
+	umaskLock.Lock()
+	defer umaskLock.Unlock()
+	return getUmask()
+}
+
+// WithUmask sets the process umask to mask while fn runs and restores it
+// afterwards. Other WithUmask calls are blocked in the meantime, so fn must
+// not call WithUmask itself, but it can call GetUmask. Note that any other
+// code creating files concurrently is affected too.
+func WithUmask(mask os.FileMode, fn func() error) error {
+	if fn == nil {
+		return ErrNil
+	}
+	umaskLock.Lock()
+	defer umaskLock.Unlock()
+	return withUmask(
+		mask,
+		func() error {
+			mask := mask.Perm()
+			activeUmaskLock.Lock()
+			activeUmask = &mask
+			activeUmaskLock.Unlock()
+			defer func() {
+				activeUmaskLock.Lock()
+				activeUmask = nil
+				activeUmaskLock.Unlock()
+			}()
+			return fn()
+		},
+	)
+}
+
+// ***** PRIVATE *****
+
+// readProcUmask reads the Umask line of /proc/self/status, which Linux has
+// since 4.7.
+func readProcUmask() (os.FileMode, error) {
+	file, err := os.Open(procSelfStatusPath)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = file.Close() }()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Umask:") {
+			continue
+		}
+		mask, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "Umask:")), 8, 32)
+		if err != nil {
+			return 0, errNoProcUmask
+		}
+		return os.FileMode(mask).Perm(), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errNoProcUmask
+}
@@ -0,0 +1,16 @@
+//go:build windows || plan9
+
+package osutils
+
+import (
+	"os"
+)
+
+func getUmask() (os.FileMode, error) {
+	return 0, ErrNotSupported
+}
+
+// withUmask just runs fn, there is no umask on Windows or Plan 9.
+func withUmask(mask os.FileMode, fn func() error) error {
+	return fn()
+}
@@ -0,0 +1,40 @@
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestWithUmask() {
+	before, err := GetUmask()
+	require.NoError(s.T(), err)
+	path := filepath.Join(s.tempDir, "file")
+	var during os.FileMode
+	require.NoError(
+		s.T(),
+		WithUmask(
+			0077,
+			func() error {
+				// GetUmask does not block while WithUmask runs
+				var err error
+				if during, err = GetUmask(); err != nil {
+					return err
+				}
+				file, err := OpenFile(path, os.O_CREATE|os.O_WRONLY, 0666)
+				if err != nil {
+					return err
+				}
+				return file.Close()
+			},
+		),
+	)
+	fileInfo, err := os.Stat(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), os.FileMode(0600), fileInfo.Mode().Perm())
+	require.Equal(s.T(), os.FileMode(0077), during)
+	after, err := GetUmask()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), before, after)
+}
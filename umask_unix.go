@@ -0,0 +1,21 @@
+//go:build !windows && !plan9
+
+package osutils
+
+import (
+	"os"
+	"syscall"
+)
+
+// getUmask has to set the umask to read it, the caller holds umaskLock.
+func getUmask() (os.FileMode, error) {
+	mask := syscall.Umask(0)
+	syscall.Umask(mask)
+	return os.FileMode(mask), nil
+}
+
+func withUmask(mask os.FileMode, fn func() error) error {
+	old := syscall.Umask(int(mask.Perm()))
+	defer syscall.Umask(old)
+	return fn()
+}
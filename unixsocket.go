@@ -0,0 +1,143 @@
+package osutils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+const (
+	runtimeDirEnvKey       = "XDG_RUNTIME_DIR"
+	runtimeDirPerm         = 0700
+	staleSocketDialTimeout = time.Second
+)
+
+var (
+	ErrPathTooLong  = errors.New("osutils: path too long")
+	ErrSocketInUse  = errors.New("osutils: socket in use")
+	ErrNotSocket    = errors.New("osutils: not socket")
+	ErrInsecureDir  = errors.New("osutils: insecure dir")
+	maxUnixPathSize = map[string]int{
+		"darwin":    104,
+		"dragonfly": 104,
+		"freebsd":   104,
+		"netbsd":    104,
+		"openbsd":   104,
+	}
+)
+
+// RuntimeDir returns a directory private to the current user for sockets and
+// other runtime files, $XDG_RUNTIME_DIR if set and otherwise a directory
+// under the temp dir, creating it if necessary.
+func RuntimeDir() (string, error) {
+	return runtimeDir()
+}
+
+// ListenUnix listens on a Unix socket at absolutePath with the given
+// permissions, which it has before anyone can connect. A stale socket left
+// behind by a dead process is removed first, while a socket that is still
+// being served results in ErrSocketInUse. Except on Windows the socket file
+// is left in place when the listener is closed, and is removed as stale by
+// the next ListenUnix. absolutePath must be a few bytes shorter than the
+// platform limit, as the socket is first bound in a temp dir next to it.
+func ListenUnix(absolutePath string, perm os.FileMode) (*net.UnixListener, error) {
+	return listenUnix(absolutePath, perm)
+}
+
+// RemoveStaleSocket removes the socket at absolutePath if connecting to it
+// is refused, as nothing is listening, and returns whether it did. Any other
+// error connecting is returned.
+func RemoveStaleSocket(absolutePath string) (bool, error) {
+	return removeStaleSocket(absolutePath)
+}
+
+func ValidateUnixSocketPath(absolutePath string) error {
+	return validateUnixSocketPath(absolutePath)
+}
+
+// ***** PRIVATE *****
+
+func runtimeDir() (string, error) {
+	if dir := os.Getenv(runtimeDirEnvKey); dir != "" && isAbsolutePath(dir) {
+		return cleanPath(dir)
+	}
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("%s-%d", tempDirPrefix, os.Getuid()))
+	if err := os.Mkdir(dir, runtimeDirPerm); err != nil && !os.IsExist(err) {
+		return "", err
+	}
+	// do not trust a pre-existing dir someone else could have created
+	fileInfo, err := os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !fileInfo.IsDir() || (runtime.GOOS != "windows" && fileInfo.Mode().Perm() != runtimeDirPerm) {
+		return "", ErrInsecureDir
+	}
+	if !isOwnedByCurrentUser(fileInfo) {
+		return "", ErrInsecureDir
+	}
+	return cleanPath(dir)
+}
+
+func listenUnix(absolutePath string, perm os.FileMode) (*net.UnixListener, error) {
+	if err := validateUnixSocketPath(absolutePath); err != nil {
+		return nil, err
+	}
+	if _, err := removeStaleSocket(absolutePath); err != nil {
+		return nil, err
+	}
+	listener, err := listenUnixPrivate(absolutePath, perm)
+	if os.IsExist(err) {
+		return nil, ErrSocketInUse
+	}
+	return listener, err
+}
+
+func removeStaleSocket(absolutePath string) (bool, error) {
+	if !isAbsolutePath(absolutePath) {
+		return false, ErrNotAbsolutePath
+	}
+	fileInfo, err := os.Lstat(absolutePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if fileInfo.Mode()&os.ModeSocket == 0 {
+		return false, ErrNotSocket
+	}
+	conn, err := net.DialTimeout("unix", absolutePath, staleSocketDialTimeout)
+	if err == nil {
+		_ = conn.Close()
+		return false, ErrSocketInUse
+	}
+	// anything but a refusal, such as a timeout against a busy server, does
+	// not mean nothing is listening
+	if !isConnRefused(err) {
+		return false, err
+	}
+	if err := os.Remove(absolutePath); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+func validateUnixSocketPath(absolutePath string) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	maxSize, ok := maxUnixPathSize[runtime.GOOS]
+	if !ok {
+		maxSize = 108
+	}
+	// the size includes the terminating NUL
+	if len(absolutePath) >= maxSize {
+		return ErrPathTooLong
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+//go:build !plan9
+
+package osutils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestListenUnix() {
+	path := filepath.Join(s.tempDir, "sock")
+	listener, err := ListenUnix(path, 0600)
+	require.NoError(s.T(), err)
+	fileInfo, err := os.Lstat(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), os.FileMode(0600), fileInfo.Mode().Perm())
+	_, err = ListenUnix(path, 0600)
+	require.Equal(s.T(), ErrSocketInUse, err)
+	// the private dir the socket was bound in is gone
+	entries, err := os.ReadDir(s.tempDir)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), entries, 1)
+	// closing leaves a stale socket behind
+	s.checkClose(listener)
+	removed, err := RemoveStaleSocket(path)
+	require.NoError(s.T(), err)
+	require.True(s.T(), removed)
+	s.checkFileDoesNotExist(path)
+	require.Equal(s.T(), ErrPathTooLong, ValidateUnixSocketPath("/"+strings.Repeat("a", 200)))
+}
@@ -0,0 +1,64 @@
+package osutils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+)
+
+// UpdateFile reads the file at absolutePath, passes its content to fn, and
+// atomically writes back what fn returns, all while holding an exclusive
+// lock on the .lock sidecar file of absolutePath. fn is passed nil if the
+// file does not exist. Nothing is written if fn returns an error or the
+// unchanged content.
+func UpdateFile(absolutePath string, fn func(old []byte) ([]byte, error), options *WriteOptions) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	if fn == nil {
+		return ErrNil
+	}
+	return updateFile(absolutePath, fn, options)
+}
+
+// ***** PRIVATE *****
+
+func updateFile(absolutePath string, fn func([]byte) ([]byte, error), options *WriteOptions) error {
+	fileLock, err := lockFile(lockPathFor(absolutePath), true, true)
+	if err != nil {
+		return err
+	}
+	defer fileLock.Unlock()
+	exists := true
+	old, err := ioutil.ReadFile(absolutePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		exists = false
+	}
+	data, err := fn(old)
+	if err != nil {
+		return err
+	}
+	if exists && bytes.Equal(old, data) {
+		return nil
+	}
+	if options == nil || options.Perm == 0 {
+		if fileInfo, err := os.Stat(absolutePath); err == nil {
+			options = withPerm(options, fileInfo.Mode().Perm())
+		}
+	}
+	return writeLockedFile(absolutePath, data, options)
+}
+
+// withPerm returns a copy of options with Perm set, keeping existing files
+// at their current permissions.
+func withPerm(options *WriteOptions, perm os.FileMode) *WriteOptions {
+	withPerm := &WriteOptions{}
+	if options != nil {
+		*withPerm = *options
+	}
+	withPerm.Perm = perm
+	return withPerm
+}
@@ -0,0 +1,42 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestUpdateFile() {
+	path := filepath.Join(s.tempDir, "counter")
+	var waitGroup sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			require.NoError(
+				s.T(),
+				UpdateFile(
+					path,
+					func(old []byte) ([]byte, error) {
+						count := 0
+						if old != nil {
+							var err error
+							if count, err = strconv.Atoi(string(old)); err != nil {
+								return nil, err
+							}
+						}
+						return []byte(strconv.Itoa(count + 1)), nil
+					},
+					nil,
+				),
+			)
+		}()
+	}
+	waitGroup.Wait()
+	data, err := ioutil.ReadFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "20", string(data))
+}
@@ -0,0 +1,86 @@
+package osutils
+
+import (
+	"os/user"
+	"strconv"
+)
+
+func CurrentUser() (*user.User, error) {
+	return user.Current()
+}
+
+func LookupUID(username string) (int, error) {
+	return lookupUID(username)
+}
+
+func LookupGID(groupname string) (int, error) {
+	return lookupGID(groupname)
+}
+
+func UserGroups(username string) ([]string, error) {
+	return userGroups(username)
+}
+
+func IsRoot() bool {
+	return isRoot()
+}
+
+func IsElevated() (bool, error) {
+	return isElevated()
+}
+
+// ***** PRIVATE *****
+
+func lookupUID(username string) (int, error) {
+	if username == "" {
+		return 0, ErrEmpty
+	}
+	u, err := user.Lookup(username)
+	if err != nil {
+		return 0, err
+	}
+	return parseID(u.Uid)
+}
+
+func lookupGID(groupname string) (int, error) {
+	if groupname == "" {
+		return 0, ErrEmpty
+	}
+	group, err := user.LookupGroup(groupname)
+	if err != nil {
+		return 0, err
+	}
+	return parseID(group.Gid)
+}
+
+func userGroups(username string) ([]string, error) {
+	if username == "" {
+		return nil, ErrEmpty
+	}
+	u, err := user.Lookup(username)
+	if err != nil {
+		return nil, err
+	}
+	gids, err := u.GroupIds()
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]string, 0, len(gids))
+	for _, gid := range gids {
+		group, err := user.LookupGroupId(gid)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group.Name)
+	}
+	return groups, nil
+}
+
+// parseID fails on Windows, where ids are SIDs rather than integers.
+func parseID(id string) (int, error) {
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, ErrNotSupported
+	}
+	return i, nil
+}
@@ -0,0 +1,18 @@
+package osutils
+
+import (
+	"os"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestLookupUID() {
+	u, err := CurrentUser()
+	require.NoError(s.T(), err)
+	uid, err := LookupUID(u.Username)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), os.Getuid(), uid)
+	require.Equal(s.T(), uid == 0, IsRoot())
+	_, err = LookupUID("")
+	require.Equal(s.T(), ErrEmpty, err)
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package osutils
+
+import (
+	"os"
+)
+
+func isRoot() bool {
+	return os.Geteuid() == 0
+}
+
+func isElevated() (bool, error) {
+	return isRoot(), nil
+}
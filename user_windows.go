@@ -0,0 +1,35 @@
+package osutils
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const (
+	tokenElevation = 20
+)
+
+func isRoot() bool {
+	elevated, err := isElevated()
+	return err == nil && elevated
+}
+
+func isElevated() (bool, error) {
+	token, err := syscall.OpenCurrentProcessToken()
+	if err != nil {
+		return false, err
+	}
+	defer token.Close()
+	var elevation uint32
+	var returnedLen uint32
+	if err := syscall.GetTokenInformation(
+		token,
+		tokenElevation,
+		(*byte)(unsafe.Pointer(&elevation)),
+		uint32(unsafe.Sizeof(elevation)),
+		&returnedLen,
+	); err != nil {
+		return false, err
+	}
+	return elevation != 0, nil
+}
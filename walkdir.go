@@ -0,0 +1,53 @@
+package osutils
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// WalkDir is filepath.WalkDir for a validated path. fn may return
+// fs.SkipDir to skip a directory or fs.SkipAll to stop the walk early.
+func WalkDir(absolutePath string, fn fs.WalkDirFunc) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	if fn == nil {
+		return ErrNil
+	}
+	return filepath.WalkDir(absolutePath, fn)
+}
+
+// WalkRegularFiles calls fn for each regular file under absolutePath in
+// lexical order. fn may return fs.SkipAll to stop early, unlike
+// ListRegularFiles, which always lists the whole tree.
+func WalkRegularFiles(absolutePath string, fn func(path string, dirEntry fs.DirEntry) error) error {
+	if fn == nil {
+		return ErrNil
+	}
+	return WalkDir(
+		absolutePath,
+		func(path string, dirEntry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !dirEntry.Type().IsRegular() {
+				return nil
+			}
+			return fn(path, dirEntry)
+		},
+	)
+}
+
+// WalkDir walks the directory, calling fn with paths relative to it.
+func (d *Dir) WalkDir(fn fs.WalkDirFunc) error {
+	return filepath.WalkDir(
+		d.absolutePath,
+		func(path string, dirEntry fs.DirEntry, err error) error {
+			relativePath, relErr := filepath.Rel(d.absolutePath, path)
+			if relErr != nil {
+				return relErr
+			}
+			return fn(relativePath, dirEntry, err)
+		},
+	)
+}
@@ -0,0 +1,62 @@
+package osutils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestWalkRegularFiles() {
+	src := s.writeCopyDirSrc()
+	var paths []string
+	require.NoError(
+		s.T(),
+		WalkRegularFiles(
+			src,
+			func(path string, dirEntry fs.DirEntry) error {
+				paths = append(paths, path)
+				return fs.SkipAll
+			},
+		),
+	)
+	require.Equal(s.T(), []string{filepath.Join(src, "a", "1")}, paths)
+
+	var relativePaths []string
+	dir, err := OpenDir(src)
+	require.NoError(s.T(), err)
+	require.NoError(
+		s.T(),
+		dir.WalkDir(
+			func(path string, dirEntry fs.DirEntry, err error) error {
+				if dirEntry.IsDir() && path == "b" {
+					return fs.SkipDir
+				}
+				relativePaths = append(relativePaths, path)
+				return err
+			},
+		),
+	)
+	require.Equal(s.T(), []string{".", "a", filepath.Join("a", "1"), "link"}, relativePaths)
+}
+
+func (s *Suite) TestWalkParallelSkipAll() {
+	src := s.writeCopyDirSrc()
+	var count int64
+	require.NoError(
+		s.T(),
+		WalkParallel(
+			src,
+			1,
+			func(path string, info os.FileInfo) error {
+				if atomic.AddInt64(&count, 1) == 2 {
+					return fs.SkipAll
+				}
+				return nil
+			},
+		),
+	)
+	require.Equal(s.T(), int64(2), atomic.LoadInt64(&count))
+}
@@ -0,0 +1,147 @@
+package osutils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// WalkParallel walks the tree at absolutePath, reading directories
+// concurrently on up to workers goroutines, or runtime.NumCPU() if workers
+// is not positive. fn is called for every entry including the root, from
+// multiple goroutines, and must be safe for concurrent use. The order of
+// calls is unspecified, except that a directory is passed to fn before its
+// entries. Symlinks are not followed. Returning filepath.SkipDir for a
+// directory skips it, fs.SkipAll stops the walk without error, and any
+// other error stops the walk and is returned.
+func WalkParallel(absolutePath string, workers int, fn func(path string, info os.FileInfo) error) error {
+	if fn == nil {
+		return ErrNil
+	}
+	return WalkParallelDir(
+		absolutePath,
+		workers,
+		func(path string, dirEntry fs.DirEntry) error {
+			info, err := dirEntry.Info()
+			if err != nil {
+				// removed since the directory was read
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			return fn(path, info)
+		},
+	)
+}
+
+// WalkParallelDir is WalkParallel passing the fs.DirEntry, which saves a
+// stat per entry if fn does not need the full os.FileInfo.
+func WalkParallelDir(absolutePath string, workers int, fn func(path string, dirEntry fs.DirEntry) error) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	if fn == nil {
+		return ErrNil
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return walkParallel(absolutePath, workers, fn)
+}
+
+// ***** PRIVATE *****
+
+type parallelWalker struct {
+	fn        func(string, fs.DirEntry) error
+	semaphore chan struct{}
+	waitGroup sync.WaitGroup
+	lock      sync.Mutex
+	err       error
+}
+
+func walkParallel(root string, workers int, fn func(string, fs.DirEntry) error) error {
+	info, err := os.Lstat(root)
+	if err != nil {
+		return err
+	}
+	if err := fn(root, fs.FileInfoToDirEntry(info)); err != nil {
+		if (err == filepath.SkipDir && info.IsDir()) || err == fs.SkipAll {
+			return nil
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+	walker := &parallelWalker{
+		fn:        fn,
+		semaphore: make(chan struct{}, workers),
+	}
+	walker.waitGroup.Add(1)
+	go walker.walkDir(root)
+	walker.waitGroup.Wait()
+	if walker.err == fs.SkipAll {
+		return nil
+	}
+	return walker.err
+}
+
+func (p *parallelWalker) walkDir(dir string) {
+	defer p.waitGroup.Done()
+	if p.failed() {
+		return
+	}
+	p.semaphore <- struct{}{}
+	subDirs, err := p.readDir(dir)
+	<-p.semaphore
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+	for _, subDir := range subDirs {
+		p.waitGroup.Add(1)
+		go p.walkDir(subDir)
+	}
+}
+
+// readDir calls fn for the entries of dir and returns the subdirs to walk.
+func (p *parallelWalker) readDir(dir string) ([]string, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var subDirs []string
+	for _, dirEntry := range dirEntries {
+		if p.failed() {
+			return nil, nil
+		}
+		path := filepath.Join(dir, dirEntry.Name())
+		if err := p.fn(path, dirEntry); err != nil {
+			if err == filepath.SkipDir && dirEntry.IsDir() {
+				continue
+			}
+			return nil, err
+		}
+		if dirEntry.IsDir() {
+			subDirs = append(subDirs, path)
+		}
+	}
+	return subDirs, nil
+}
+
+func (p *parallelWalker) failed() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.err != nil
+}
+
+func (p *parallelWalker) setErr(err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
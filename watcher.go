@@ -0,0 +1,145 @@
+package osutils
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	ErrWatchOverflow = errors.New("osutils: watch event queue overflowed, events were lost")
+	// ErrWatcherClosed means a watcher closed its channels while it was
+	// still being waited on.
+	ErrWatcherClosed = errors.New("osutils: watcher closed")
+
+	errWatcherStopped = errors.New("osutils: watcher stopped")
+)
+
+type WatchOp int
+
+const (
+	WatchOpCreate WatchOp = iota
+	WatchOpWrite
+	WatchOpRemove
+	// WatchOpRename has the previous path in OldPath.
+	WatchOpRename
+	WatchOpChmod
+)
+
+var (
+	watchOpToString = map[WatchOp]string{
+		WatchOpCreate: "create",
+		WatchOpWrite:  "write",
+		WatchOpRemove: "remove",
+		WatchOpRename: "rename",
+		WatchOpChmod:  "chmod",
+	}
+)
+
+func (w WatchOp) String() string {
+	return watchOpToString[w]
+}
+
+// FileWatcher is implemented by Watcher and PollWatcher, so consumers can
+// switch between them.
+type FileWatcher interface {
+	Events() <-chan *WatchEvent
+	Errors() <-chan error
+	Close() error
+}
+
+type WatchEvent struct {
+	Op      WatchOp
+	Path    string
+	OldPath string
+}
+
+// Watcher watches a directory tree. New subdirectories are watched as they
+// appear, and renames within the tree are reported as one WatchOpRename
+// event when the platform reports both sides together. A rename out of
+// the tree is a WatchOpRemove and a rename into it a WatchOpCreate.
+//
+// Only Linux is supported, see PollWatcher for other platforms.
+type Watcher struct {
+	*watchChannels
+	close func() error
+}
+
+func NewWatcher(absolutePath string) (*Watcher, error) {
+	if !isAbsolutePath(absolutePath) {
+		return nil, ErrNotAbsolutePath
+	}
+	return newWatcher(absolutePath)
+}
+
+// Close stops the watcher and closes the Events and Errors channels.
+func (w *Watcher) Close() error {
+	return w.watchChannels.stop(w.close)
+}
+
+// ***** PRIVATE *****
+
+type watchChannels struct {
+	events   chan *WatchEvent
+	errors   chan error
+	done     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func newWatchChannels() *watchChannels {
+	return &watchChannels{
+		events:  make(chan *WatchEvent, 64),
+		errors:  make(chan error, 8),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (w *watchChannels) Events() <-chan *WatchEvent {
+	return w.events
+}
+
+func (w *watchChannels) Errors() <-chan error {
+	return w.errors
+}
+
+// send returns false if the watcher was stopped.
+func (w *watchChannels) send(event *WatchEvent) bool {
+	select {
+	case w.events <- event:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+func (w *watchChannels) sendError(err error) bool {
+	select {
+	case w.errors <- err:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// run calls loop in a goroutine, closing the channels once it returns.
+func (w *watchChannels) run(loop func()) {
+	go func() {
+		defer close(w.stopped)
+		defer close(w.errors)
+		defer close(w.events)
+		loop()
+	}()
+}
+
+func (w *watchChannels) stop(closeFunc func() error) error {
+	var err error
+	w.stopOnce.Do(func() {
+		close(w.done)
+		if closeFunc != nil {
+			err = closeFunc()
+		}
+		<-w.stopped
+	})
+	return err
+}
@@ -0,0 +1,220 @@
+package osutils
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	inotifyMask = unix.IN_CREATE |
+		unix.IN_DELETE |
+		unix.IN_MODIFY |
+		unix.IN_ATTRIB |
+		unix.IN_MOVED_FROM |
+		unix.IN_MOVED_TO |
+		unix.IN_DELETE_SELF |
+		unix.IN_MOVE_SELF |
+		unix.IN_ONLYDIR |
+		unix.IN_DONT_FOLLOW |
+		unix.IN_EXCL_UNLINK
+	inotifyBufferSize = 64 * (unix.SizeofInotifyEvent + unix.NAME_MAX + 1)
+)
+
+type inotifyWatcher struct {
+	*watchChannels
+	root    string
+	fd      int
+	file    *os.File
+	watches map[int]string
+}
+
+type inotifyMovedFrom struct {
+	cookie uint32
+	path   string
+	isDir  bool
+}
+
+func newWatcher(absolutePath string) (*Watcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	// a non-blocking fd in an os.File uses the runtime poller, so Close
+	// unblocks a pending Read
+	w := &inotifyWatcher{
+		watchChannels: newWatchChannels(),
+		root:          filepath.Clean(absolutePath),
+		fd:            fd,
+		file:          os.NewFile(uintptr(fd), "inotify"),
+		watches:       make(map[int]string),
+	}
+	if err := w.addTree(w.root, false); err != nil {
+		_ = w.file.Close()
+		return nil, err
+	}
+	w.run(w.loop)
+	return &Watcher{
+		watchChannels: w.watchChannels,
+		close:         w.file.Close,
+	}, nil
+}
+
+func (w *inotifyWatcher) loop() {
+	buffer := make([]byte, inotifyBufferSize)
+	for {
+		n, err := w.file.Read(buffer)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				w.sendError(err)
+			}
+			return
+		}
+		if !w.handleEvents(buffer[:n]) {
+			return
+		}
+	}
+}
+
+// handleEvents returns false if the watcher was stopped.
+func (w *inotifyWatcher) handleEvents(buffer []byte) bool {
+	var movedFrom *inotifyMovedFrom
+	// an IN_MOVED_FROM without a matching IN_MOVED_TO moved out of the tree
+	flushMovedFrom := func() bool {
+		if movedFrom == nil {
+			return true
+		}
+		if movedFrom.isDir {
+			w.removeWatches(movedFrom.path)
+		}
+		path := movedFrom.path
+		movedFrom = nil
+		return w.send(&WatchEvent{Op: WatchOpRemove, Path: path})
+	}
+	for offset := 0; offset+unix.SizeofInotifyEvent <= len(buffer); {
+		event := (*unix.InotifyEvent)(unsafe.Pointer(&buffer[offset]))
+		nameStart := offset + unix.SizeofInotifyEvent
+		nameEnd := nameStart + int(event.Len)
+		if nameEnd > len(buffer) {
+			break
+		}
+		name := string(bytes.TrimRight(buffer[nameStart:nameEnd], "\x00"))
+		offset = nameEnd
+
+		if event.Mask&unix.IN_Q_OVERFLOW != 0 {
+			if !w.sendError(ErrWatchOverflow) {
+				return false
+			}
+			continue
+		}
+		dir, ok := w.watches[int(event.Wd)]
+		if !ok {
+			continue
+		}
+		if event.Mask&unix.IN_IGNORED != 0 {
+			delete(w.watches, int(event.Wd))
+			continue
+		}
+		path := dir
+		if name != "" {
+			path = filepath.Join(dir, name)
+		}
+		isDir := event.Mask&unix.IN_ISDIR != 0
+		if movedFrom != nil && (event.Mask&unix.IN_MOVED_TO == 0 || event.Cookie != movedFrom.cookie) {
+			if !flushMovedFrom() {
+				return false
+			}
+		}
+
+		var watchEvent *WatchEvent
+		switch {
+		case event.Mask&unix.IN_MOVED_FROM != 0:
+			movedFrom = &inotifyMovedFrom{cookie: event.Cookie, path: path, isDir: isDir}
+		case event.Mask&unix.IN_MOVED_TO != 0 && movedFrom != nil:
+			if isDir {
+				w.renameWatches(movedFrom.path, path)
+			}
+			watchEvent = &WatchEvent{Op: WatchOpRename, Path: path, OldPath: movedFrom.path}
+			movedFrom = nil
+		case event.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0:
+			if isDir {
+				if err := w.addTree(path, true); err != nil && !w.sendError(err) {
+					return false
+				}
+				continue
+			}
+			watchEvent = &WatchEvent{Op: WatchOpCreate, Path: path}
+		case event.Mask&unix.IN_DELETE != 0:
+			watchEvent = &WatchEvent{Op: WatchOpRemove, Path: path}
+		case event.Mask&(unix.IN_DELETE_SELF|unix.IN_MOVE_SELF) != 0:
+			// subdirectories are reported by their parent
+			if path == w.root {
+				watchEvent = &WatchEvent{Op: WatchOpRemove, Path: path}
+			}
+		case event.Mask&unix.IN_MODIFY != 0:
+			watchEvent = &WatchEvent{Op: WatchOpWrite, Path: path}
+		case event.Mask&unix.IN_ATTRIB != 0:
+			watchEvent = &WatchEvent{Op: WatchOpChmod, Path: path}
+		}
+		if watchEvent != nil && !w.send(watchEvent) {
+			return false
+		}
+	}
+	return flushMovedFrom()
+}
+
+// addTree watches every directory under absolutePath. If sendCreate is
+// set, a create event is sent for everything found, since entries made
+// before the watch was added would otherwise be missed.
+func (w *inotifyWatcher) addTree(absolutePath string, sendCreate bool) error {
+	err := filepath.Walk(
+		absolutePath,
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if info.IsDir() {
+				wd, err := unix.InotifyAddWatch(w.fd, path, inotifyMask)
+				if err != nil {
+					if err == unix.ENOENT {
+						return filepath.SkipDir
+					}
+					return &os.PathError{Op: "inotify_add_watch", Path: path, Err: err}
+				}
+				w.watches[wd] = path
+			}
+			if sendCreate && !w.send(&WatchEvent{Op: WatchOpCreate, Path: path}) {
+				return errWatcherStopped
+			}
+			return nil
+		},
+	)
+	if err == errWatcherStopped {
+		return nil
+	}
+	return err
+}
+
+func (w *inotifyWatcher) renameWatches(oldPath string, newPath string) {
+	for wd, path := range w.watches {
+		if isWithin(oldPath, path) {
+			w.watches[wd] = newPath + path[len(oldPath):]
+		}
+	}
+}
+
+func (w *inotifyWatcher) removeWatches(absolutePath string) {
+	for wd, path := range w.watches {
+		if isWithin(absolutePath, path) {
+			_, _ = unix.InotifyRmWatch(w.fd, uint32(wd))
+			delete(w.watches, wd)
+		}
+	}
+}
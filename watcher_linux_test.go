@@ -0,0 +1,34 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestWatcher() {
+	watcher, err := NewWatcher(s.tempDir)
+	require.NoError(s.T(), err)
+	defer func() {
+		require.NoError(s.T(), watcher.Close())
+	}()
+
+	sub := filepath.Join(s.tempDir, "sub")
+	require.NoError(s.T(), os.Mkdir(sub, 0755))
+	s.waitForWatchEvent(watcher.Events(), &WatchEvent{Op: WatchOpCreate, Path: sub})
+	file := filepath.Join(sub, "a")
+	require.NoError(s.T(), ioutil.WriteFile(file, []byte("hello"), 0644))
+	s.waitForWatchEvent(watcher.Events(), &WatchEvent{Op: WatchOpCreate, Path: file})
+
+	renamed := filepath.Join(s.tempDir, "renamed")
+	require.NoError(s.T(), os.Rename(sub, renamed))
+	s.waitForWatchEvent(watcher.Events(), &WatchEvent{Op: WatchOpRename, Path: renamed, OldPath: sub})
+	// the watch follows the renamed directory
+	file = filepath.Join(renamed, "b")
+	require.NoError(s.T(), ioutil.WriteFile(file, []byte("hello"), 0644))
+	s.waitForWatchEvent(watcher.Events(), &WatchEvent{Op: WatchOpCreate, Path: file})
+	require.NoError(s.T(), os.Remove(file))
+	s.waitForWatchEvent(watcher.Events(), &WatchEvent{Op: WatchOpRemove, Path: file})
+}
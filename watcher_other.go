@@ -0,0 +1,7 @@
+//go:build !linux
+
+package osutils
+
+func newWatcher(absolutePath string) (*Watcher, error) {
+	return nil, ErrNotSupported
+}
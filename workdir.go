@@ -0,0 +1,89 @@
+package osutils
+
+import (
+	"os"
+	"sync"
+)
+
+var (
+	// workingDirLock guards the working dir, which is process-global state.
+	workingDirLock  = &sync.Mutex{}
+	workingDirStack []string
+)
+
+func Chdir(absolutePath string) error {
+	workingDirLock.Lock()
+	defer workingDirLock.Unlock()
+	return chdir(absolutePath)
+}
+
+// WithWorkingDir changes into absolutePath while fn runs and changes back
+// afterwards. fn must not call Chdir, PushD or PopD, which would deadlock.
+func WithWorkingDir(absolutePath string, fn func() error) error {
+	if fn == nil {
+		return ErrNil
+	}
+	workingDirLock.Lock()
+	defer workingDirLock.Unlock()
+	return withWorkingDir(absolutePath, fn)
+}
+
+func PushD(absolutePath string) error {
+	workingDirLock.Lock()
+	defer workingDirLock.Unlock()
+	return pushD(absolutePath)
+}
+
+func PopD() error {
+	workingDirLock.Lock()
+	defer workingDirLock.Unlock()
+	return popD()
+}
+
+// ***** PRIVATE *****
+
+func chdir(absolutePath string) error {
+	if !isAbsolutePath(absolutePath) {
+		return ErrNotAbsolutePath
+	}
+	return os.Chdir(absolutePath)
+}
+
+func withWorkingDir(absolutePath string, fn func() error) error {
+	previous, err := getwd()
+	if err != nil {
+		return err
+	}
+	if err := chdir(absolutePath); err != nil {
+		return err
+	}
+	err = fn()
+	if chdirErr := chdir(previous); err == nil {
+		err = chdirErr
+	}
+	return err
+}
+
+func pushD(absolutePath string) error {
+	previous, err := getwd()
+	if err != nil {
+		return err
+	}
+	if err := chdir(absolutePath); err != nil {
+		return err
+	}
+	workingDirStack = append(workingDirStack, previous)
+	return nil
+}
+
+func popD() error {
+	if len(workingDirStack) == 0 {
+		return ErrEmpty
+	}
+	previous := workingDirStack[len(workingDirStack)-1]
+	if err := chdir(previous); err != nil {
+		return err
+	}
+	workingDirStack = workingDirStack[:len(workingDirStack)-1]
+	return nil
+}
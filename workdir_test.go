@@ -0,0 +1,32 @@
+package osutils
+
+import (
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestWorkingDir() {
+	original, err := Getwd()
+	require.NoError(s.T(), err)
+	require.NoError(
+		s.T(),
+		WithWorkingDir(
+			s.tempDir,
+			func() error {
+				wd, err := Getwd()
+				require.NoError(s.T(), err)
+				require.Equal(s.T(), s.tempDir, wd)
+				return nil
+			},
+		),
+	)
+	require.NoError(s.T(), PushD(s.tempDir))
+	wd, err := Getwd()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), s.tempDir, wd)
+	require.NoError(s.T(), PopD())
+	wd, err = Getwd()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), original, wd)
+	require.Equal(s.T(), ErrEmpty, PopD())
+	require.Equal(s.T(), ErrNotAbsolutePath, Chdir("relative"))
+}
@@ -0,0 +1,123 @@
+package osutils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var ErrWorkspaceClosed = errors.New("osutils: workspace closed")
+
+// Workspace is a temp dir with named subdirs and files, all removed on
+// Close. Names are relative paths within the workspace, paths outside it
+// are rejected with ErrPathOutsideDir.
+type Workspace struct {
+	root    string
+	created []string
+	closed  bool
+	lock    sync.Mutex
+}
+
+func NewWorkspace() (*Workspace, error) {
+	root, err := newTempDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Workspace{
+		root: root,
+	}, nil
+}
+
+func (w *Workspace) Root() string {
+	return w.root
+}
+
+// Dir returns the path of the subdir name, creating it and its parents if
+// needed.
+func (w *Workspace) Dir(name string) (string, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	path, err := w.join(name)
+	if err != nil {
+		return "", err
+	}
+	if err := w.mkdirAll(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// File returns the path of the file name, creating its parent dirs if
+// needed but not the file itself.
+func (w *Workspace) File(name string) (string, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	path, err := w.join(name)
+	if err != nil {
+		return "", err
+	}
+	if path == w.root {
+		return "", ErrEmpty
+	}
+	if err := w.mkdirAll(filepath.Dir(path)); err != nil {
+		return "", err
+	}
+	w.created = append(w.created, path)
+	return path, nil
+}
+
+// Paths returns the dirs created and the files handed out by the
+// Workspace, in order.
+func (w *Workspace) Paths() []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]string{}, w.created...)
+}
+
+// Close removes the workspace and everything in it, whether created
+// through the Workspace or not. Closing again is a no-op.
+func (w *Workspace) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return os.RemoveAll(w.root)
+}
+
+// ***** PRIVATE *****
+
+func (w *Workspace) join(name string) (string, error) {
+	if w.closed {
+		return "", ErrWorkspaceClosed
+	}
+	return safeJoin(w.root, name)
+}
+
+// mkdirAll creates path and its missing parents within the root, recording
+// each one created.
+func (w *Workspace) mkdirAll(path string) error {
+	if path == w.root {
+		return nil
+	}
+	fileInfo, err := os.Lstat(path)
+	if err == nil {
+		if !fileInfo.IsDir() {
+			return ErrFileExists
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	if err := w.mkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := mkdir(path, 0755); err != nil && !os.IsExist(err) {
+		return err
+	}
+	w.created = append(w.created, path)
+	return nil
+}
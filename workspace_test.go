@@ -0,0 +1,41 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestWorkspace() {
+	workspace, err := NewWorkspace()
+	require.NoError(s.T(), err)
+	cacheDir, err := workspace.Dir("cache/objects")
+	require.NoError(s.T(), err)
+	s.checkFileExists(cacheDir)
+	logPath, err := workspace.File("logs/out.log")
+	require.NoError(s.T(), err)
+	s.checkFileDoesNotExist(logPath)
+	require.NoError(s.T(), ioutil.WriteFile(logPath, []byte("log"), 0644))
+	_, err = workspace.Dir("logs/out.log")
+	require.Equal(s.T(), ErrFileExists, err)
+	_, err = workspace.File("../escape")
+	require.Equal(s.T(), ErrPathOutsideDir, err)
+
+	root := workspace.Root()
+	require.Equal(
+		s.T(),
+		[]string{
+			filepath.Join(root, "cache"),
+			filepath.Join(root, "cache", "objects"),
+			filepath.Join(root, "logs"),
+			logPath,
+		},
+		workspace.Paths(),
+	)
+	require.NoError(s.T(), workspace.Close())
+	require.NoError(s.T(), workspace.Close())
+	s.checkFileDoesNotExist(root)
+	_, err = workspace.Dir("cache")
+	require.Equal(s.T(), ErrWorkspaceClosed, err)
+}
@@ -0,0 +1,92 @@
+package osutils
+
+import (
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+type WriteFromReaderOptions struct {
+	// Atomic writes to a temp file and renames it into place, so a failed
+	// or mismatched download never leaves a partial file at the destination.
+	Atomic bool
+	// ExpectedChecksum is a hex digest, compared case-insensitively.
+	// The write fails with ErrChecksumMismatch if it differs.
+	ExpectedChecksum string
+	// HashAlgorithm defaults to HashAlgorithmSHA256.
+	HashAlgorithm HashAlgorithm
+}
+
+// WriteFromReader streams reader into absolutePath and returns the number
+// of bytes written. Without Atomic, a file that fails verification is
+// removed.
+func WriteFromReader(absolutePath string, reader io.Reader, perm os.FileMode, options *WriteFromReaderOptions) (int64, error) {
+	if !isAbsolutePath(absolutePath) {
+		return 0, ErrNotAbsolutePath
+	}
+	if reader == nil {
+		return 0, ErrNil
+	}
+	if options == nil {
+		options = &WriteFromReaderOptions{}
+	}
+	return writeFromReader(absolutePath, reader, perm, options)
+}
+
+// ***** PRIVATE *****
+
+func writeFromReader(absolutePath string, reader io.Reader, perm os.FileMode, options *WriteFromReaderOptions) (int64, error) {
+	var hash hash.Hash
+	if options.ExpectedChecksum != "" {
+		hashAlgorithm := options.HashAlgorithm
+		if hashAlgorithm == "" {
+			hashAlgorithm = HashAlgorithmSHA256
+		}
+		var err error
+		if hash, err = newHash(hashAlgorithm); err != nil {
+			return 0, err
+		}
+		reader = io.TeeReader(reader, hash)
+	}
+	var n int64
+	write := func(writer io.Writer) error {
+		var err error
+		if n, err = io.Copy(writer, reader); err != nil {
+			return err
+		}
+		if hash != nil && !strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), options.ExpectedChecksum) {
+			return ErrChecksumMismatch
+		}
+		return nil
+	}
+	if options.Atomic {
+		if err := writeAtomic(absolutePath, perm, write); err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+	if err := writeInPlace(absolutePath, perm, write); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// writeInPlace truncates absolutePath and calls write with it, removing
+// the file on any error.
+func writeInPlace(absolutePath string, perm os.FileMode, write func(io.Writer) error) (retErr error) {
+	file, err := os.OpenFile(absolutePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+		if retErr != nil {
+			_ = os.Remove(absolutePath)
+		}
+	}()
+	return write(file)
+}
@@ -0,0 +1,46 @@
+package osutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestWriteFromReader() {
+	path := filepath.Join(s.tempDir, "file")
+	checksum := "2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824"
+	for _, atomic := range []bool{false, true} {
+		n, err := WriteFromReader(
+			path,
+			strings.NewReader("hello"),
+			0644,
+			&WriteFromReaderOptions{
+				Atomic:           atomic,
+				ExpectedChecksum: checksum,
+			},
+		)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), int64(5), n)
+		data, err := ioutil.ReadFile(path)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), "hello", string(data))
+	}
+
+	// an atomic mismatch leaves the old file in place
+	_, err := WriteFromReader(path, strings.NewReader("bye"), 0644, &WriteFromReaderOptions{Atomic: true, ExpectedChecksum: checksum})
+	require.Equal(s.T(), ErrChecksumMismatch, err)
+	data, err := ioutil.ReadFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "hello", string(data))
+
+	// a non-atomic mismatch removes the file
+	_, err = WriteFromReader(path, strings.NewReader("bye"), 0644, &WriteFromReaderOptions{ExpectedChecksum: checksum})
+	require.Equal(s.T(), ErrChecksumMismatch, err)
+	s.checkFileDoesNotExist(path)
+
+	n, err := WriteFromReader(path, strings.NewReader("bye"), 0644, nil)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(3), n)
+}
@@ -0,0 +1,176 @@
+package osutils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+)
+
+const (
+	// Linux limits a single argument to 128KiB and the command line and
+	// environment together to a quarter of the stack limit, 2MiB by
+	// default, this stays under both. Windows limits the command line
+	// alone to 32767 characters.
+	defaultXargsMaxBytes        = 128 * 1024
+	defaultXargsMaxBytesWindows = 32000
+)
+
+var ErrArgTooLong = errors.New("osutils: argument too long")
+
+// XargsRunner runs a command over items read from a reader, appending as
+// many items to Args as fit in a command line, like xargs.
+type XargsRunner struct {
+	Args        []string
+	AbsoluteDir string
+	Env         []string
+	// NullDelimited reads NUL-delimited items, as written by find -print0,
+	// instead of lines. Empty items are skipped either way.
+	NullDelimited bool
+	// MaxItems limits the items per command, 0 means no limit.
+	MaxItems int
+	// MaxBytes limits the size of the command line, 0 means a limit safe
+	// for the current platform.
+	MaxBytes int
+	// Parallelism is the number of commands run at once, or
+	// runtime.NumCPU() if not positive.
+	Parallelism int
+}
+
+// Run reads items from reader until EOF and runs the commands, starting
+// each as soon as its batch is full. Results are in the order of the
+// batches, with a nil Result for a command that failed to start. The
+// returned error joins the errors of the commands that failed, with
+// errors.Join. An item that does not fit in a command line on its own
+// stops reading with ErrArgTooLong, after the commands for the items read
+// before it have run.
+func (x *XargsRunner) Run(ctx context.Context, reader io.Reader) ([]*Result, error) {
+	if x.Args == nil || reader == nil {
+		return nil, ErrNil
+	}
+	if len(x.Args) == 0 {
+		return nil, ErrEmpty
+	}
+	if x.AbsoluteDir != "" && !isAbsolutePath(x.AbsoluteDir) {
+		return nil, ErrNotAbsolutePath
+	}
+	maxBytes := x.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultXargsMaxBytes
+		if runtime.GOOS == "windows" {
+			maxBytes = defaultXargsMaxBytesWindows
+		}
+	}
+	parallelism := x.Parallelism
+	if parallelism <= 0 {
+		parallelism = runtime.NumCPU()
+	}
+	baseBytes := argsBytes(x.Args)
+	// the environment shares the limit on Linux, while the Windows limit
+	// is on the command line alone
+	if runtime.GOOS != "windows" {
+		if x.Env == nil {
+			baseBytes += argsBytes(os.Environ())
+		} else {
+			baseBytes += argsBytes(x.Env)
+		}
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, maxBytes+1)
+	if x.NullDelimited {
+		scanner.Split(ScanNull)
+	}
+	var (
+		results   []*Result
+		errs      []error
+		lock      sync.Mutex
+		waitGroup sync.WaitGroup
+		semaphore = make(chan struct{}, parallelism)
+	)
+	run := func(items []string) {
+		lock.Lock()
+		i := len(results)
+		results = append(results, nil)
+		errs = append(errs, nil)
+		lock.Unlock()
+		semaphore <- struct{}{}
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			defer func() { <-semaphore }()
+			result, err := runCmd(
+				ctx,
+				&Cmd{
+					Args:        append(append([]string{}, x.Args...), items...),
+					AbsoluteDir: x.AbsoluteDir,
+					Env:         x.Env,
+				},
+			)
+			lock.Lock()
+			defer lock.Unlock()
+			results[i] = result
+			errs[i] = err
+		}()
+	}
+	var items []string
+	itemsBytes := 0
+	var readErr error
+	for scanner.Scan() {
+		item := scanner.Text()
+		if !x.NullDelimited {
+			item = trimCR(item)
+		}
+		if item == "" {
+			continue
+		}
+		itemBytes := len(item) + 1
+		if baseBytes+itemBytes > maxBytes {
+			readErr = ErrArgTooLong
+			break
+		}
+		if len(items) > 0 && (baseBytes+itemsBytes+itemBytes > maxBytes || (x.MaxItems > 0 && len(items) >= x.MaxItems)) {
+			run(items)
+			items = nil
+			itemsBytes = 0
+		}
+		items = append(items, item)
+		itemsBytes += itemBytes
+	}
+	if readErr == nil {
+		readErr = scanner.Err()
+		if readErr == bufio.ErrTooLong {
+			readErr = ErrArgTooLong
+		}
+	}
+	// the items read before an error are complete, so they still run
+	if len(items) > 0 {
+		run(items)
+	}
+	waitGroup.Wait()
+	if readErr != nil {
+		errs = append(errs, readErr)
+	}
+	return results, errors.Join(errs...)
+}
+
+// ***** PRIVATE *****
+
+// argsBytes is the size of args in a command line, counting the
+// terminating NULs.
+func argsBytes(args []string) int {
+	n := 0
+	for _, arg := range args {
+		n += len(arg) + 1
+	}
+	return n
+}
+
+func trimCR(line string) string {
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		return line[:len(line)-1]
+	}
+	return line
+}
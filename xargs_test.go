@@ -0,0 +1,49 @@
+package osutils
+
+import (
+	"context"
+	"runtime"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestXargsRunner() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("echo not available on windows")
+	}
+	xargsRunner := &XargsRunner{
+		Args:     []string{"echo", "items:"},
+		MaxItems: 2,
+	}
+	results, err := xargsRunner.Run(context.Background(), strings.NewReader("a\nb\n\nc\r\nd\ne"))
+	require.NoError(s.T(), err)
+	require.Len(s.T(), results, 3)
+	require.Equal(s.T(), "items: a b\n", results[0].Stdout)
+	require.Equal(s.T(), "items: c d\n", results[1].Stdout)
+	require.Equal(s.T(), "items: e\n", results[2].Stdout)
+
+	xargsRunner = &XargsRunner{
+		Args:          []string{"echo"},
+		Env:           []string{},
+		NullDelimited: true,
+		MaxBytes:      20,
+		Parallelism:   1,
+	}
+	results, err = xargsRunner.Run(context.Background(), strings.NewReader("one\x00two\nlines\x00three\x00"))
+	require.NoError(s.T(), err)
+	require.Len(s.T(), results, 2)
+	require.Equal(s.T(), "one two\nlines\n", results[0].Stdout)
+	require.Equal(s.T(), "three\n", results[1].Stdout)
+
+	_, err = xargsRunner.Run(context.Background(), strings.NewReader(strings.Repeat("x", 30)))
+	require.ErrorIs(s.T(), err, ErrArgTooLong)
+	// the items before the one that is too long still run, those after it
+	// are not read
+	results, err = xargsRunner.Run(context.Background(), strings.NewReader("one\x00two\x00"+strings.Repeat("x", 30)+"\x00three\x00"))
+	require.ErrorIs(s.T(), err, ErrArgTooLong)
+	require.Len(s.T(), results, 1)
+	require.Equal(s.T(), "one two\n", results[0].Stdout)
+	_, err = (&XargsRunner{Args: []string{"false"}}).Run(context.Background(), strings.NewReader("a\n"))
+	require.Error(s.T(), err)
+}